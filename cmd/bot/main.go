@@ -81,17 +81,21 @@ func main() {
 	accessRepo := repository.NewAccessRepository(pool)
 	inviteCodeRepo := repository.NewInviteCodeRepository(pool)
 	accessRequestRepo := repository.NewAccessRequestRepository(pool)
+	paymentRepo := repository.NewPaymentRepository(pool)
+	creditRepo := repository.NewCreditRepository(pool)
+	ledgerRepo := repository.NewLedgerRepository(pool)
+	promoCodeRepo := repository.NewPromoCodeRepository(pool)
+	reportRepo := repository.NewReportRepository(pool)
+	exportRepo := repository.NewExportRepository(pool)
+	groupRepo := repository.NewGroupRepository(pool)
+	broadcastRepo := repository.NewBroadcastRepository(pool)
+	conversationRepo := repository.NewConversationRepository(pool)
+	lessonNoteRepo := repository.NewLessonNoteRepository(pool)
+	reviewRepo := repository.NewReviewRepository(pool)
+	favoriteRepo := repository.NewFavoriteRepository(pool)
 
 	logger.Info("✅ Repositories initialized")
 
-	// Инициализация сервисов
-	userService := service.NewUserService(userRepo, logger)
-	bookingService := service.NewBookingService(pool, userRepo, subjectRepo, slotRepo, bookingRepo, logger)
-	teacherService := service.NewTeacherService(userRepo, subjectRepo, slotRepo, bookingRepo, recurringRepo, logger)
-	accessService := service.NewStudentAccessService(accessRepo, inviteCodeRepo, accessRequestRepo, userRepo, subjectRepo, logger)
-
-	logger.Info("✅ Services initialized")
-
 	// Создание Telegram бота
 	botInstance, err := bot.New(cfg.TelegramToken)
 	if err != nil {
@@ -100,6 +104,39 @@ func main() {
 
 	logger.Info("✅ Telegram bot created")
 
+	// Провайдер платежей нужен только при настроенном токене
+	var paymentProvider service.PaymentProvider
+	if cfg.PaymentProviderToken != "" {
+		paymentProvider = controller.NewTelegramPaymentProvider(botInstance, cfg.PaymentProviderToken)
+		logger.Info("✅ Telegram Payments enabled")
+	}
+
+	// Уведомления из фоновых задач отправляются сообщениями бота
+	notifier := controller.NewTelegramNotifier(botInstance)
+	broadcastSender := controller.NewTelegramBroadcastSender(botInstance)
+	messageRelay := controller.NewTelegramMessageRelay(botInstance)
+	reviewPrompter := controller.NewTelegramReviewPrompter(botInstance)
+
+	// Инициализация сервисов
+	userService := service.NewUserService(userRepo, logger)
+	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, subjectRepo, paymentProvider, cfg.PaymentTimeout, logger)
+	creditService := service.NewCreditService(creditRepo, subjectRepo, userRepo, logger)
+	ledgerService := service.NewLedgerService(ledgerRepo, userRepo, logger)
+	promoCodeService := service.NewPromoCodeService(promoCodeRepo, subjectRepo, userRepo, logger)
+	reportService := service.NewReportService(reportRepo, userRepo, logger)
+	exportService := service.NewExportService(exportRepo, inviteCodeRepo, userRepo, logger)
+	bookingService := service.NewBookingService(pool, userRepo, subjectRepo, slotRepo, bookingRepo, groupRepo, paymentService, creditService, promoCodeService, logger)
+	teacherService := service.NewTeacherService(userRepo, subjectRepo, slotRepo, bookingRepo, recurringRepo, creditService, promoCodeService, notifier, logger)
+	accessService := service.NewStudentAccessService(accessRepo, inviteCodeRepo, accessRequestRepo, userRepo, subjectRepo, groupRepo, bookingService, notifier, logger)
+	groupService := service.NewGroupService(groupRepo, accessRepo, userRepo, slotRepo, subjectRepo, notifier, logger)
+	broadcastService := service.NewBroadcastService(broadcastRepo, accessRepo, groupRepo, subjectRepo, userRepo, broadcastSender, notifier, logger)
+	conversationService := service.NewConversationService(conversationRepo, accessRepo, bookingRepo, slotRepo, subjectRepo, userRepo, messageRelay, logger)
+	lessonNoteService := service.NewLessonNoteService(lessonNoteRepo, bookingRepo, userRepo, notifier, logger)
+	reviewService := service.NewReviewService(reviewRepo, bookingRepo, subjectRepo, slotRepo, userRepo, reviewPrompter, notifier, logger)
+	favoriteService := service.NewFavoriteService(favoriteRepo, subjectRepo, userRepo, accessService, bookingService, notifier, logger)
+
+	logger.Info("✅ Services initialized")
+
 	// Инициализация контроллера
 	botController := controller.NewBotController(
 		botInstance,
@@ -107,6 +144,18 @@ func main() {
 		bookingService,
 		teacherService,
 		accessService,
+		paymentService,
+		creditService,
+		ledgerService,
+		promoCodeService,
+		reportService,
+		exportService,
+		groupService,
+		broadcastService,
+		conversationService,
+		lessonNoteService,
+		reviewService,
+		favoriteService,
 		userRepo,
 		inviteCodeRepo,
 		accessRepo,
@@ -122,7 +171,7 @@ func main() {
 	logger.Info("✅ Bot handlers registered")
 
 	// Запуск фонового планировщика для автоматической генерации слотов
-	scheduler := app.NewScheduler(teacherService, logger)
+	scheduler := app.NewScheduler(teacherService, paymentService, ledgerService, accessService, lessonNoteService, reviewService, favoriteService, logger)
 	scheduler.Start(ctx)
 	logger.Info("✅ Background scheduler started")
 
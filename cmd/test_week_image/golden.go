@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// Допуски сравнения: сглаживание шрифтов может немного отличаться между
+// платформами, поэтому мелкие расхождения отдельных пикселей не считаются ошибкой
+const (
+	// pixelChannelTolerance максимальная разница канала цвета, при которой пиксели считаются одинаковыми
+	pixelChannelTolerance = 8
+	// maxDiffPixelRatio доля отличающихся пикселей, при которой изображения ещё совпадают
+	maxDiffPixelRatio = 0.0001
+)
+
+// compareImages сравнивает PNG попиксельно с учётом допусков
+func compareImages(expectedData, actualData []byte) error {
+	expected, err := png.Decode(bytes.NewReader(expectedData))
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать эталон: %w", err)
+	}
+	actual, err := png.Decode(bytes.NewReader(actualData))
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать изображение: %w", err)
+	}
+
+	if expected.Bounds() != actual.Bounds() {
+		return fmt.Errorf("размер %v, ожидался %v", actual.Bounds().Size(), expected.Bounds().Size())
+	}
+
+	bounds := expected.Bounds()
+	diff := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !pixelsMatch(expected, actual, x, y) {
+				diff++
+			}
+		}
+	}
+
+	total := bounds.Dx() * bounds.Dy()
+	if float64(diff) > float64(total)*maxDiffPixelRatio {
+		return fmt.Errorf("отличаются %d из %d пикселей", diff, total)
+	}
+
+	return nil
+}
+
+// pixelsMatch проверяет, что каналы пикселя отличаются не больше допуска
+func pixelsMatch(expected, actual image.Image, x, y int) bool {
+	er, eg, eb, ea := expected.At(x, y).RGBA()
+	ar, ag, ab, aa := actual.At(x, y).RGBA()
+
+	for _, pair := range [][2]uint32{{er, ar}, {eg, ag}, {eb, ab}, {ea, aa}} {
+		// RGBA возвращает 16-битные каналы, допуск задан в 8-битных
+		e, a := int(pair[0]>>8), int(pair[1]>>8)
+		if e-a > pixelChannelTolerance || a-e > pixelChannelTolerance {
+			return false
+		}
+	}
+
+	return true
+}
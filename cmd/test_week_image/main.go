@@ -1,114 +1,219 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 )
 
+// Проверка изображений расписания по эталонам.
+// Запуск из корня репозитория:
+//
+//	go run ./cmd/test_week_image          — сравнить с эталонами в testdata
+//	go run ./cmd/test_week_image -update  — перезаписать эталоны после намеренных изменений
+//
+// Данные фиксированы и лежат в прошлом (март 2024), поэтому на картинках нет
+// подсветки сегодняшнего дня и линии текущего времени и результат не зависит от даты запуска.
+
+// goldenCase изображение, сверяемое с эталоном testdata/<name>.png
+type goldenCase struct {
+	name   string
+	render func() ([]byte, error)
+}
+
 func main() {
-	// Создаем тестовые данные
-	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	// Начинаем с понедельника текущей недели
-	for startDate.Weekday() != time.Monday {
-		startDate = startDate.AddDate(0, 0, -1)
+	update := flag.Bool("update", false, "перезаписать эталонные изображения")
+	dir := flag.String("dir", filepath.Join("cmd", "test_week_image", "testdata"), "каталог с эталонами")
+	flag.Parse()
+
+	cases := []goldenCase{
+		{"week_teacher", renderTeacherWeek},
+		{"week_student", renderStudentWeek},
+		{"month", renderMonth},
+		{"agenda", renderAgenda},
+		{"agenda_empty", renderEmptyAgenda},
+	}
+
+	failed := 0
+	for _, c := range cases {
+		if err := runGoldenCase(c, *dir, *update); err != nil {
+			fmt.Printf("❌ %s: %v\n", c.name, err)
+			failed++
+			continue
+		}
+		if *update {
+			fmt.Printf("📝 %s: эталон обновлён\n", c.name)
+		} else {
+			fmt.Printf("✅ %s\n", c.name)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d из %d изображений не совпали с эталонами. Отличающиеся версии сохранены рядом как *.actual.png\n", failed, len(cases))
+		os.Exit(1)
+	}
+}
+
+// runGoldenCase рисует изображение и сравнивает его с эталоном или перезаписывает эталон
+func runGoldenCase(c goldenCase, dir string, update bool) error {
+	actual, err := c.render()
+	if err != nil {
+		return fmt.Errorf("ошибка генерации: %w", err)
+	}
+
+	goldenPath := filepath.Join(dir, c.name+".png")
+	actualPath := filepath.Join(dir, c.name+".actual.png")
+
+	if update {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		os.Remove(actualPath)
+		return os.WriteFile(goldenPath, actual, 0644)
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		return fmt.Errorf("нет эталона (запустите с -update): %w", err)
+	}
+
+	if err := compareImages(expected, actual); err != nil {
+		if writeErr := os.WriteFile(actualPath, actual, 0644); writeErr != nil {
+			return fmt.Errorf("%v; не удалось сохранить %s: %v", err, actualPath, writeErr)
+		}
+		return err
+	}
+
+	os.Remove(actualPath)
+	return nil
+}
+
+// Фиксированные данные для эталонов
+
+var (
+	// weekStart понедельник недели, которую рисуют эталоны недели и дня
+	weekStart = time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	studentNames = map[int64]string{
+		100: "Анна Смирнова",
+		200: "Иван Петров",
+		300: "Мария Кузнецова-Александрова",
 	}
-	endDate := startDate.AddDate(0, 0, 6) // неделя (7 дней)
+)
+
+// at возвращает время в день недели dayOffset от weekStart
+func at(dayOffset, hour, minute int) time.Time {
+	return weekStart.AddDate(0, 0, dayOffset).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
 
-	// Создаем тестовые слоты
+func renderTeacherWeek() ([]byte, error) {
 	slots := []*model.ScheduleSlot{
 		// Понедельник
-		{
-			ID:        1,
-			SubjectID: 1,
-			StartTime: startDate.Add(9 * time.Hour),  // 09:00
-			EndTime:   startDate.Add(10 * time.Hour), // 10:00
-			Status:    model.SlotStatusFree,
-			StudentID: nil,
-		},
-		{
-			ID:        2,
-			SubjectID: 1,
-			StartTime: startDate.Add(14 * time.Hour), // 14:00
-			EndTime:   startDate.Add(15 * time.Hour), // 15:00
-			Status:    model.SlotStatusBooked,
-			StudentID: intPtr(100),
-		},
+		{ID: 1, SubjectID: 1, StartTime: at(0, 9, 0), EndTime: at(0, 10, 0), Status: model.SlotStatusFree},
+		{ID: 2, SubjectID: 1, StartTime: at(0, 14, 0), EndTime: at(0, 15, 0), Status: model.SlotStatusBooked, StudentID: intPtr(100)},
 		// Вторник
-		{
-			ID:        3,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 1).Add(10 * time.Hour), // Вторник 10:00
-			EndTime:   startDate.AddDate(0, 0, 1).Add(11 * time.Hour), // Вторник 11:00
-			Status:    model.SlotStatusFree,
-			StudentID: nil,
-		},
-		{
-			ID:        4,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 1).Add(16 * time.Hour), // Вторник 16:00
-			EndTime:   startDate.AddDate(0, 0, 1).Add(17 * time.Hour), // Вторник 17:00
-			Status:    model.SlotStatusCanceled,
-			StudentID: nil,
-		},
+		{ID: 3, SubjectID: 1, StartTime: at(1, 10, 0), EndTime: at(1, 11, 0), Status: model.SlotStatusFree},
+		{ID: 4, SubjectID: 1, StartTime: at(1, 16, 0), EndTime: at(1, 17, 0), Status: model.SlotStatusCanceled},
 		// Среда
-		{
-			ID:        5,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 2).Add(9 * time.Hour),  // Среда 09:00
-			EndTime:   startDate.AddDate(0, 0, 2).Add(10 * time.Hour), // Среда 10:00
-			Status:    model.SlotStatusBooked,
-			StudentID: intPtr(200),
-		},
-		{
-			ID:        6,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 2).Add(15 * time.Hour), // Среда 15:00
-			EndTime:   startDate.AddDate(0, 0, 2).Add(16 * time.Hour), // Среда 16:00
-			Status:    model.SlotStatusFree,
-			StudentID: nil,
-		},
+		{ID: 5, SubjectID: 1, StartTime: at(2, 9, 0), EndTime: at(2, 10, 0), Status: model.SlotStatusBooked, StudentID: intPtr(200)},
+		{ID: 6, SubjectID: 1, StartTime: at(2, 15, 0), EndTime: at(2, 16, 0), Status: model.SlotStatusFree},
 		// Пятница
-		{
-			ID:        7,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 4).Add(11 * time.Hour), // Пятница 11:00
-			EndTime:   startDate.AddDate(0, 0, 4).Add(12 * time.Hour), // Пятница 12:00
-			Status:    model.SlotStatusFree,
-			StudentID: nil,
-		},
-		{
-			ID:        8,
-			SubjectID: 1,
-			StartTime: startDate.AddDate(0, 0, 4).Add(13 * time.Hour), // Пятница 13:00
-			EndTime:   startDate.AddDate(0, 0, 4).Add(14 * time.Hour), // Пятница 14:00
-			Status:    model.SlotStatusBooked,
-			StudentID: nil, // занят преподавателем без студента
-		},
+		{ID: 7, SubjectID: 1, StartTime: at(4, 11, 0), EndTime: at(4, 12, 0), Status: model.SlotStatusFree},
+		{ID: 8, SubjectID: 1, StartTime: at(4, 13, 0), EndTime: at(4, 14, 0), Status: model.SlotStatusBooked}, // занят преподавателем без студента
+		// Слот другого предмета не попадает на картинку
+		{ID: 9, SubjectID: 2, StartTime: at(3, 12, 0), EndTime: at(3, 13, 0), Status: model.SlotStatusFree},
 	}
 
-	// Генерируем изображение
-	imageData, err := common.GenerateWeekImage(startDate, endDate, slots, 1)
-	if err != nil {
-		fmt.Printf("Ошибка генерации изображения: %v\n", err)
-		os.Exit(1)
+	return common.GenerateWeekImage(weekStart, weekStart.AddDate(0, 0, 7), slots, 1, studentNames)
+}
+
+func renderStudentWeek() ([]byte, error) {
+	math := &model.Subject{ID: 1, TeacherID: 10, Name: "Математика"}
+	english := &model.Subject{ID: 2, TeacherID: 11, Name: "Английский язык"}
+	physics := &model.Subject{ID: 3, TeacherID: 10, Name: "Физика"}
+	teacherA := &model.User{ID: 10, FirstName: "Елена", LastName: "Васильева"}
+	teacherB := &model.User{ID: 11, FirstName: "John", LastName: "Smith"}
+
+	lesson := func(id int64, subject *model.Subject, teacher *model.User, start, end time.Time, status model.BookingStatus, cancelRequested bool) *model.Booking {
+		return &model.Booking{
+			ID:                    id,
+			SubjectID:             subject.ID,
+			TeacherID:             teacher.ID,
+			Status:                status,
+			CancellationRequested: cancelRequested,
+			Subject:               subject,
+			Teacher:               teacher,
+			Slot:                  &model.ScheduleSlot{ID: id, SubjectID: subject.ID, StartTime: start, EndTime: end, Status: model.SlotStatusBooked},
+		}
 	}
 
-	// Сохраняем в файл
-	filename := "week.png"
-	err = os.WriteFile(filename, imageData, 0644)
-	if err != nil {
-		fmt.Printf("Ошибка сохранения файла: %v\n", err)
-		os.Exit(1)
+	bookings := []*model.Booking{
+		lesson(1, math, teacherA, at(0, 10, 0), at(0, 11, 0), model.BookingStatusCompleted, false),
+		lesson(2, english, teacherB, at(1, 18, 0), at(1, 19, 30), model.BookingStatusConfirmed, false),
+		lesson(3, physics, teacherA, at(2, 12, 0), at(2, 13, 0), model.BookingStatusPending, false),
+		lesson(4, math, teacherA, at(3, 10, 0), at(3, 11, 0), model.BookingStatusAwaitingPayment, false),
+		lesson(5, english, teacherB, at(4, 18, 0), at(4, 19, 30), model.BookingStatusConfirmed, true),
+		lesson(6, physics, teacherA, at(5, 11, 0), at(5, 12, 30), model.BookingStatusConfirmed, false),
+	}
+
+	return common.GenerateStudentWeekImage(weekStart, bookings)
+}
+
+func renderMonth() ([]byte, error) {
+	month := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	var slots []*model.ScheduleSlot
+	var id int64
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		// Выходные без слотов, остальные дни с загрузкой от пустой до полной
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		for i, hour := range []int{10, 12, 14, 16} {
+			id++
+			status := model.SlotStatusFree
+			switch {
+			case i < day.Day()%5:
+				status = model.SlotStatusBooked
+			case day.Day()%7 == 0 && i == 3:
+				status = model.SlotStatusCanceled
+			}
+			start := day.Add(time.Duration(hour) * time.Hour)
+			slots = append(slots, &model.ScheduleSlot{ID: id, SubjectID: 1, StartTime: start, EndTime: start.Add(time.Hour), Status: status})
+		}
 	}
 
-	fmt.Printf("✅ Изображение успешно сохранено в %s\n", filename)
-	fmt.Printf("📅 Период: %s - %s\n", startDate.Format("02.01.2006"), endDate.Format("02.01.2006"))
-	fmt.Printf("📊 Слотов: %d\n", len(slots))
+	// Слот за пределами месяца не учитывается
+	slots = append(slots, &model.ScheduleSlot{ID: id + 1, SubjectID: 1, StartTime: month.AddDate(0, 1, 0).Add(10 * time.Hour), EndTime: month.AddDate(0, 1, 0).Add(11 * time.Hour), Status: model.SlotStatusBooked})
+
+	return common.GenerateMonthImage(month, slots)
+}
+
+func renderAgenda() ([]byte, error) {
+	subjectNames := map[int64]string{
+		1: "Математика",
+		2: "Подготовка к ЕГЭ по информатике",
+	}
+	comment := "Принести тетрадь с домашним заданием"
+
+	slots := []*model.ScheduleSlot{
+		{ID: 4, SubjectID: 1, StartTime: at(2, 15, 0), EndTime: at(2, 16, 0), Status: model.SlotStatusFree},
+		{ID: 1, SubjectID: 1, StartTime: at(2, 9, 0), EndTime: at(2, 10, 0), Status: model.SlotStatusBooked, StudentID: intPtr(200)},
+		{ID: 2, SubjectID: 2, StartTime: at(2, 10, 30), EndTime: at(2, 12, 0), Status: model.SlotStatusBooked, StudentID: intPtr(300), Comment: &comment},
+		{ID: 3, SubjectID: 1, StartTime: at(2, 13, 0), EndTime: at(2, 14, 0), Status: model.SlotStatusCanceled},
+		{ID: 5, SubjectID: 2, StartTime: at(2, 17, 0), EndTime: at(2, 18, 0), Status: model.SlotStatusBooked}, // занят преподавателем без студента
+	}
+
+	return common.GenerateAgendaImage(weekStart.AddDate(0, 0, 2), slots, subjectNames, studentNames)
+}
+
+func renderEmptyAgenda() ([]byte, error) {
+	return common.GenerateAgendaImage(weekStart.AddDate(0, 0, 6), nil, nil, nil)
 }
 
 func intPtr(i int64) *int64 {
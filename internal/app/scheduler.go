@@ -10,17 +10,29 @@ import (
 
 // Scheduler управляет фоновыми задачами
 type Scheduler struct {
-	teacherService *service.TeacherService
-	logger         *zap.Logger
-	stopChan       chan struct{}
+	teacherService  *service.TeacherService
+	paymentService  *service.PaymentService
+	ledgerService   *service.LedgerService
+	accessService   *service.StudentAccessService
+	noteService     *service.LessonNoteService
+	reviewService   *service.ReviewService
+	favoriteService *service.FavoriteService
+	logger          *zap.Logger
+	stopChan        chan struct{}
 }
 
 // NewScheduler создаёт новый планировщик
-func NewScheduler(teacherService *service.TeacherService, logger *zap.Logger) *Scheduler {
+func NewScheduler(teacherService *service.TeacherService, paymentService *service.PaymentService, ledgerService *service.LedgerService, accessService *service.StudentAccessService, noteService *service.LessonNoteService, reviewService *service.ReviewService, favoriteService *service.FavoriteService, logger *zap.Logger) *Scheduler {
 	return &Scheduler{
-		teacherService: teacherService,
-		logger:         logger,
-		stopChan:       make(chan struct{}),
+		teacherService:  teacherService,
+		paymentService:  paymentService,
+		ledgerService:   ledgerService,
+		accessService:   accessService,
+		noteService:     noteService,
+		reviewService:   reviewService,
+		favoriteService: favoriteService,
+		logger:          logger,
+		stopChan:        make(chan struct{}),
 	}
 }
 
@@ -30,6 +42,24 @@ func (s *Scheduler) Start(ctx context.Context) {
 
 	// Запускаем задачу генерации слотов
 	go s.runSlotGenerationTask(ctx)
+
+	// Запускаем задачу отмены неоплаченных записей
+	go s.runPaymentExpiryTask(ctx)
+
+	// Запускаем задачу завершения прошедших занятий
+	go s.runLessonCompletionTask(ctx)
+
+	// Запускаем задачу окончания подписок
+	go s.runAccessExpiryTask(ctx)
+
+	// Запускаем задачу напоминаний о домашних заданиях
+	go s.runHomeworkReminderTask(ctx)
+
+	// Запускаем задачу просьб оценить прошедшие занятия
+	go s.runReviewRequestTask(ctx)
+
+	// Запускаем задачу уведомлений о свободных слотах в избранном
+	go s.runSlotAlertTask(ctx)
 }
 
 // Stop останавливает фоновые задачи
@@ -40,6 +70,11 @@ func (s *Scheduler) Stop() {
 
 // runSlotGenerationTask периодически генерирует слоты для recurring schedules
 func (s *Scheduler) runSlotGenerationTask(ctx context.Context) {
+	// Учителя узнают о слотах, изменённых при введении запрета на пересечения
+	if err := s.teacherService.NotifySlotOverlapResolutions(ctx); err != nil {
+		s.logger.Error("Failed to notify slot overlap resolutions", zap.Error(err))
+	}
+
 	// Первый запуск сразу при старте
 	s.generateSlots(ctx)
 
@@ -75,3 +110,162 @@ func (s *Scheduler) generateSlots(ctx context.Context) {
 
 	s.logger.Info("Automatic slot generation completed successfully")
 }
+
+// runPaymentExpiryTask периодически освобождает слоты неоплаченных записей
+func (s *Scheduler) runPaymentExpiryTask(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.paymentService.ReleaseExpired(ctx); err != nil {
+				s.logger.Error("Failed to release unpaid bookings", zap.Error(err))
+			}
+		case <-s.stopChan:
+			s.logger.Info("Payment expiry task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Payment expiry task cancelled")
+			return
+		}
+	}
+}
+
+// runLessonCompletionTask периодически завершает прошедшие занятия и начисляет их стоимость
+func (s *Scheduler) runLessonCompletionTask(ctx context.Context) {
+	s.completeLessons(ctx)
+
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.completeLessons(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Lesson completion task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Lesson completion task cancelled")
+			return
+		}
+	}
+}
+
+// completeLessons завершает прошедшие занятия
+func (s *Scheduler) completeLessons(ctx context.Context) {
+	if _, err := s.ledgerService.CompletePastLessons(ctx); err != nil {
+		s.logger.Error("Failed to complete past lessons", zap.Error(err))
+	}
+}
+
+// runAccessExpiryTask периодически предупреждает об окончании подписок и отзывает истёкший доступ
+func (s *Scheduler) runAccessExpiryTask(ctx context.Context) {
+	s.processAccessExpiry(ctx)
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processAccessExpiry(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Access expiry task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Access expiry task cancelled")
+			return
+		}
+	}
+}
+
+// processAccessExpiry обрабатывает истекающий и истёкший доступ
+func (s *Scheduler) processAccessExpiry(ctx context.Context) {
+	if err := s.accessService.ProcessAccessExpiry(ctx); err != nil {
+		s.logger.Error("Failed to process access expiry", zap.Error(err))
+	}
+}
+
+// runHomeworkReminderTask периодически напоминает студентам о сроках домашних заданий
+func (s *Scheduler) runHomeworkReminderTask(ctx context.Context) {
+	s.sendHomeworkReminders(ctx)
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendHomeworkReminders(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Homework reminder task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Homework reminder task cancelled")
+			return
+		}
+	}
+}
+
+// sendHomeworkReminders отправляет напоминания о домашних заданиях
+func (s *Scheduler) sendHomeworkReminders(ctx context.Context) {
+	if _, err := s.noteService.SendHomeworkReminders(ctx); err != nil {
+		s.logger.Error("Failed to send homework reminders", zap.Error(err))
+	}
+}
+
+// runReviewRequestTask периодически предлагает студентам оценить завершённые занятия
+func (s *Scheduler) runReviewRequestTask(ctx context.Context) {
+	s.requestReviews(ctx)
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.requestReviews(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Review request task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Review request task cancelled")
+			return
+		}
+	}
+}
+
+// requestReviews отправляет просьбы оценить занятия
+func (s *Scheduler) requestReviews(ctx context.Context) {
+	if _, err := s.reviewService.RequestReviews(ctx); err != nil {
+		s.logger.Error("Failed to request reviews", zap.Error(err))
+	}
+}
+
+// runSlotAlertTask периодически уведомляет студентов о новых свободных слотах в избранном
+func (s *Scheduler) runSlotAlertTask(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendSlotAlerts(ctx)
+		case <-s.stopChan:
+			s.logger.Info("Slot alert task stopped")
+			return
+		case <-ctx.Done():
+			s.logger.Info("Slot alert task cancelled")
+			return
+		}
+	}
+}
+
+// sendSlotAlerts отправляет уведомления о свободных слотах
+func (s *Scheduler) sendSlotAlerts(ctx context.Context) {
+	if _, err := s.favoriteService.SendSlotAlerts(ctx); err != nil {
+		s.logger.Error("Failed to send slot alerts", zap.Error(err))
+	}
+}
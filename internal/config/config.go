@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +14,11 @@ type Config struct {
 	TelegramToken string `mapstructure:"TELEGRAM_TOKEN"`
 	DBDSN         string `mapstructure:"DB_DSN"`
 	Environment   string `mapstructure:"ENV"`
+
+	// Telegram Payments (без токена предоплата отключена)
+	// Счёт выставляется в валюте предмета
+	PaymentProviderToken string        `mapstructure:"PAYMENT_PROVIDER_TOKEN"`
+	PaymentTimeout       time.Duration `mapstructure:"PAYMENT_TIMEOUT_MINUTES"`
 }
 
 func Load() (*Config, error) {
@@ -27,6 +34,8 @@ func Load() (*Config, error) {
 		DBDSN:         os.Getenv("DB_DSN"),
 		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
 		Environment:   os.Getenv("ENV"),
+
+		PaymentProviderToken: os.Getenv("PAYMENT_PROVIDER_TOKEN"),
 	}
 
 	// Устанавливаем дефолтные значения
@@ -34,6 +43,15 @@ func Load() (*Config, error) {
 		cfg.Environment = "development"
 	}
 
+	cfg.PaymentTimeout = 30 * time.Minute
+	if raw := os.Getenv("PAYMENT_TIMEOUT_MINUTES"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil || minutes <= 0 {
+			return nil, fmt.Errorf("PAYMENT_TIMEOUT_MINUTES must be a positive number")
+		}
+		cfg.PaymentTimeout = time.Duration(minutes) * time.Minute
+	}
+
 	// Проверяем обязательные поля
 	if cfg.DBDSN == "" {
 		return nil, fmt.Errorf("DB_DSN is required but not set")
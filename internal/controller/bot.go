@@ -26,6 +26,18 @@ func NewBotController(
 	bookingService *service.BookingService,
 	teacherService *service.TeacherService,
 	accessService *service.StudentAccessService,
+	paymentService *service.PaymentService,
+	creditService *service.CreditService,
+	ledgerService *service.LedgerService,
+	promoCodeService *service.PromoCodeService,
+	reportService *service.ReportService,
+	exportService *service.ExportService,
+	groupService *service.GroupService,
+	broadcastService *service.BroadcastService,
+	conversationService *service.ConversationService,
+	lessonNoteService *service.LessonNoteService,
+	reviewService *service.ReviewService,
+	favoriteService *service.FavoriteService,
 	userRepo interface {
 		GetByID(ctx context.Context, id int64) (*model.User, error)
 		UpdatePublicStatus(ctx context.Context, userID int64, isPublic bool) error
@@ -51,6 +63,16 @@ func NewBotController(
 		bookingService,
 		teacherService,
 		accessService,
+		paymentService,
+		creditService,
+		ledgerService,
+		promoCodeService,
+		groupService,
+		broadcastService,
+		conversationService,
+		lessonNoteService,
+		reviewService,
+		favoriteService,
 		stateManager,
 		logger,
 	)
@@ -64,6 +86,17 @@ func NewBotController(
 		bookingService,
 		teacherService,
 		accessService,
+		creditService,
+		ledgerService,
+		promoCodeService,
+		reportService,
+		exportService,
+		groupService,
+		broadcastService,
+		conversationService,
+		lessonNoteService,
+		reviewService,
+		favoriteService,
 		userRepo,
 		inviteCodeRepo,
 		accessRepo,
@@ -92,6 +125,8 @@ func (c *BotController) RegisterHandlers(ctx context.Context) error {
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/findteachers", bot.MatchTypeExact, c.handlers.HandleFindTeachers)
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/mybookings", bot.MatchTypeExact, c.handlers.HandleMyBookings)
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/cancel", bot.MatchTypeExact, c.handlers.HandleCancel)
+	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/chats", bot.MatchTypeExact, c.handlers.HandleChats)
+	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/favorites", bot.MatchTypeExact, c.handlers.HandleFavorites)
 
 	// Команды для учителей
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/becometeacher", bot.MatchTypeExact, c.handlers.HandleBecomeTeacher)
@@ -100,6 +135,21 @@ func (c *BotController) RegisterHandlers(ctx context.Context) error {
 	})
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/myschedule", bot.MatchTypeExact, c.handlers.HandleMySchedule)
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/createsubject", bot.MatchTypeExact, c.handlers.HandleCreateSubjectStart)
+	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/export", bot.MatchTypeExact, c.handlers.HandleExport)
+	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "/broadcast", bot.MatchTypeExact, c.handlers.HandleBroadcast)
+
+	// Платежи: регистрируем до текстового обработчика, т.к. сообщение об оплате не содержит текста
+	c.bot.RegisterHandlerMatchFunc(handlers.IsPreCheckoutQuery, c.handlers.HandlePreCheckoutQuery)
+	c.bot.RegisterHandlerMatchFunc(handlers.IsSuccessfulPayment, c.handlers.HandleSuccessfulPayment)
+
+	// Фото и документы для рассылки учителя тоже приходят без текста
+	c.bot.RegisterHandlerMatchFunc(c.handlers.IsBroadcastMedia, c.handlers.HandleBroadcastMedia)
+
+	// Как и материалы к заметкам занятий
+	c.bot.RegisterHandlerMatchFunc(c.handlers.IsLessonNoteMedia, c.handlers.HandleLessonNoteMedia)
+
+	// Ответ на пересланное ботом сообщение переписки уходит собеседнику
+	c.bot.RegisterHandlerMatchFunc(c.handlers.IsConversationReply, c.handlers.HandleConversationReply)
 
 	// Обработчик текстовых сообщений (для диалогов с состояниями)
 	c.bot.RegisterHandler(bot.HandlerTypeMessageText, "", bot.MatchTypePrefix, c.handlers.HandleTextMessage)
@@ -118,10 +168,14 @@ func (c *BotController) setCommands(ctx context.Context) error {
 		{Command: "help", Description: "❓ Справка по командам"},
 		{Command: "subjects", Description: "📚 Список всех предметов"},
 		{Command: "mybookings", Description: "📅 Мои записи на занятия"},
+		{Command: "chats", Description: "💬 Переписки с учителями и студентами"},
+		{Command: "favorites", Description: "❤️ Избранные учителя и предметы"},
 		{Command: "becometeacher", Description: "🎓 Стать учителем"},
 		{Command: "mysubjects", Description: "📝 Мои предметы (учитель)"},
 		{Command: "myschedule", Description: "🗓 Моё расписание (учитель)"},
 		{Command: "createsubject", Description: "➕ Создать предмет (учитель)"},
+		{Command: "export", Description: "📤 Выгрузка в CSV/XLSX (учитель)"},
+		{Command: "broadcast", Description: "📣 Рассылка студентам (учитель)"},
 	}
 
 	_, err := c.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TelegramBroadcastSender отправляет сообщения рассылок учителя сообщениями бота
+type TelegramBroadcastSender struct {
+	bot *bot.Bot
+}
+
+// NewTelegramBroadcastSender создаёт отправителя рассылок
+func NewTelegramBroadcastSender(botInstance *bot.Bot) *TelegramBroadcastSender {
+	return &TelegramBroadcastSender{bot: botInstance}
+}
+
+// SendBroadcast отправляет сообщение рассылки. Если Telegram просит подождать, ждёт и повторяет отправку один раз
+func (s *TelegramBroadcastSender) SendBroadcast(ctx context.Context, chatID int64, message *model.BroadcastMessage) error {
+	err := s.send(ctx, chatID, message)
+
+	var tooManyRequests *bot.TooManyRequestsError
+	if errors.As(err, &tooManyRequests) {
+		select {
+		case <-time.After(time.Duration(tooManyRequests.RetryAfter) * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		err = s.send(ctx, chatID, message)
+	}
+
+	if errors.Is(err, bot.ErrorForbidden) {
+		return fmt.Errorf("recipient blocked")
+	}
+
+	return err
+}
+
+func (s *TelegramBroadcastSender) send(ctx context.Context, chatID int64, message *model.BroadcastMessage) error {
+	var err error
+
+	switch {
+	case message.PhotoFileID != "":
+		_, err = s.bot.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:    chatID,
+			Photo:     &models.InputFileString{Data: message.PhotoFileID},
+			Caption:   message.Text,
+			ParseMode: models.ParseModeHTML,
+		})
+	case message.DocumentFileID != "":
+		_, err = s.bot.SendDocument(ctx, &bot.SendDocumentParams{
+			ChatID:    chatID,
+			Document:  &models.InputFileString{Data: message.DocumentFileID},
+			Caption:   message.Text,
+			ParseMode: models.ParseModeHTML,
+		})
+	default:
+		_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      message.Text,
+			ParseMode: models.ParseModeHTML,
+		})
+	}
+
+	return err
+}
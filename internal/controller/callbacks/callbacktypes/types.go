@@ -25,12 +25,23 @@ type StateManager interface {
 
 // Handler содержит общие зависимости для всех callback handlers
 type Handler struct {
-	UserService    *service.UserService
-	BookingService *service.BookingService
-	TeacherService *service.TeacherService
-	AccessService  *service.StudentAccessService
-	StateManager   StateManager
-	Logger         *zap.Logger
+	UserService         *service.UserService
+	BookingService      *service.BookingService
+	TeacherService      *service.TeacherService
+	AccessService       *service.StudentAccessService
+	CreditService       *service.CreditService
+	LedgerService       *service.LedgerService
+	PromoCodeService    *service.PromoCodeService
+	ReportService       *service.ReportService
+	ExportService       *service.ExportService
+	GroupService        *service.GroupService
+	BroadcastService    *service.BroadcastService
+	ConversationService *service.ConversationService
+	LessonNoteService   *service.LessonNoteService
+	ReviewService       *service.ReviewService
+	FavoriteService     *service.FavoriteService
+	StateManager        StateManager
+	Logger              *zap.Logger
 
 	// Репозитории (для прямого доступа в некоторых handlers)
 	UserRepo interface {
@@ -0,0 +1,165 @@
+package common
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/fogleman/gg"
+)
+
+// Размеры расписания дня: лист A4 при 150 dpi, при большом числе занятий лист удлиняется
+const (
+	agendaImageWidth     = 1240
+	agendaMinImageHeight = 1754
+	agendaHeaderHeight   = 190
+	agendaFooterHeight   = 80
+	agendaRowHeight      = 84.0
+	agendaPadding        = 80.0
+	agendaTimeWidth      = 200.0
+	agendaStripeWidth    = 10.0
+	agendaLabelMaxLen    = 80
+)
+
+// Шрифты расписания дня
+const (
+	agendaTitleFontSize   = 40.0
+	agendaSummaryFontSize = 22.0
+	agendaTimeFontSize    = 24.0
+	agendaSubjectFontSize = 24.0
+	agendaDetailFontSize  = 19.0
+)
+
+// Цвета расписания дня: белый лист для печати, остальное из схемы изображения недели
+var (
+	agendaPaperColor    = color.RGBA{255, 255, 255, 255}
+	agendaFreeTextColor = color.RGBA{60, 110, 40, 255}
+)
+
+// GenerateAgendaImage генерирует печатное расписание дня: занятия по порядку
+// с временем, предметом и именем студента. slots — слоты этого дня
+func GenerateAgendaImage(day time.Time, slots []*model.ScheduleSlot, subjectNames, studentNames map[int64]string) ([]byte, error) {
+	daySlots := append([]*model.ScheduleSlot(nil), slots...)
+	sort.SliceStable(daySlots, func(i, j int) bool {
+		return daySlots[i].StartTime.Before(daySlots[j].StartTime)
+	})
+
+	height := agendaHeaderHeight + agendaFooterHeight + int(float64(len(daySlots))*agendaRowHeight)
+	if height < agendaMinImageHeight {
+		height = agendaMinImageHeight
+	}
+
+	dc := gg.NewContext(agendaImageWidth, height)
+	dc.SetColor(agendaPaperColor)
+	dc.Clear()
+
+	drawAgendaHeader(dc, day, daySlots)
+
+	if len(daySlots) == 0 {
+		loadFont(dc, agendaSubjectFontSize, FontStyleItalic)
+		dc.SetColor(hourLabelColor)
+		dc.DrawStringAnchored("Занятий нет", agendaPadding, agendaHeaderHeight+agendaRowHeight/2, 0, 0.5)
+		return encodeImage(dc)
+	}
+
+	y := float64(agendaHeaderHeight)
+	for _, slot := range daySlots {
+		drawAgendaRow(dc, slot, subjectNames, studentNames, y)
+		y += agendaRowHeight
+	}
+
+	return encodeImage(dc)
+}
+
+// drawAgendaHeader рисует дату и сводку дня
+func drawAgendaHeader(dc *gg.Context, day time.Time, slots []*model.ScheduleSlot) {
+	loadFont(dc, agendaTitleFontSize, FontStyleBold)
+	dc.SetColor(textColor)
+	title := fmt.Sprintf("%s, %s", formatting.GetWeekdayName(int(day.Weekday())), day.Format("02.01.2006"))
+	dc.DrawStringAnchored(title, agendaPadding, 80, 0, 0.5)
+
+	booked, free := 0, 0
+	for _, slot := range slots {
+		switch slot.Status {
+		case model.SlotStatusBooked:
+			booked++
+		case model.SlotStatusFree:
+			free++
+		}
+	}
+
+	loadFont(dc, agendaSummaryFontSize, FontStyleItalic)
+	dc.SetColor(hourLabelColor)
+	dc.DrawStringAnchored(fmt.Sprintf("Занятий: %d · свободных окон: %d", booked, free), agendaPadding, 125, 0, 0.5)
+
+	dc.SetColor(hourLineColor)
+	dc.SetLineWidth(2)
+	dc.DrawLine(agendaPadding, agendaHeaderHeight-20, float64(agendaImageWidth)-agendaPadding, agendaHeaderHeight-20)
+	dc.Stroke()
+}
+
+// drawAgendaRow рисует строку занятия: время, цветную метку статуса, предмет и студента
+func drawAgendaRow(dc *gg.Context, slot *model.ScheduleSlot, subjectNames, studentNames map[int64]string, y float64) {
+	centerY := y + agendaRowHeight/2 - 6
+
+	loadFont(dc, agendaTimeFontSize, FontStyleSemiBold)
+	dc.SetColor(textColor)
+	timeRange := slot.StartTime.Format("15:04") + " – " + slot.EndTime.Format("15:04")
+	dc.DrawStringAnchored(timeRange, agendaPadding, centerY, 0, 0.35)
+
+	stripeX := agendaPadding + agendaTimeWidth
+	dc.SetColor(getSlotColor(slot.Status))
+	dc.DrawRoundedRectangle(stripeX, y+8, agendaStripeWidth, agendaRowHeight-28, 4)
+	dc.Fill()
+
+	textX := stripeX + agendaStripeWidth + 20
+	subject := subjectNames[slot.SubjectID]
+	if subject == "" {
+		subject = "Предмет"
+	}
+	loadFont(dc, agendaSubjectFontSize, FontStyleBold)
+	dc.SetColor(textColor)
+	dc.DrawStringAnchored(truncateLabel(subject, agendaLabelMaxLen), textX, centerY-13, 0, 0.35)
+
+	detail, detailColor := agendaSlotDetail(slot, studentNames)
+	loadFont(dc, agendaDetailFontSize)
+	dc.SetColor(detailColor)
+	dc.DrawStringAnchored(truncateLabel(detail, agendaLabelMaxLen), textX, centerY+15, 0, 0.35)
+
+	dc.SetColor(hourLineColor)
+	dc.SetLineWidth(0.5)
+	dc.DrawLine(agendaPadding, y+agendaRowHeight-8, float64(agendaImageWidth)-agendaPadding, y+agendaRowHeight-8)
+	dc.Stroke()
+}
+
+// agendaSlotDetail возвращает подпись под предметом: студент, статус слота и комментарий учителя
+func agendaSlotDetail(slot *model.ScheduleSlot, studentNames map[int64]string) (string, color.Color) {
+	var detail string
+	var detailColor color.Color
+
+	switch slot.Status {
+	case model.SlotStatusFree:
+		detail = "Свободно"
+		detailColor = agendaFreeTextColor
+	case model.SlotStatusCanceled:
+		detail = "Отменено"
+		detailColor = hourLabelColor
+	default:
+		detail = "Занято"
+		if slot.StudentID != nil {
+			if name, ok := studentNames[*slot.StudentID]; ok && name != "" {
+				detail = name
+			}
+		}
+		detailColor = slotBookedTextColor
+	}
+
+	if slot.Comment != nil && *slot.Comment != "" {
+		detail += " · " + *slot.Comment
+	}
+
+	return detail, detailColor
+}
@@ -0,0 +1,306 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Conversation Handlers
+// ========================
+// Переписка студента и учителя через бота доступна обеим сторонам
+
+// conversationHistoryLimit сколько последних сообщений показывать на экране переписки
+const conversationHistoryLimit = 10
+
+// HandleMyChats показывает переписки пользователя
+func HandleMyChats(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	conversations, err := h.ConversationService.GetUserConversations(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get conversations", zap.Int64("user_id", user.ID), zap.Error(err))
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке переписок")
+		return
+	}
+
+	text, kb := BuildConversationsScreen(user.ID, conversations)
+	editConversationMessage(ctx, b, callback, text, kb)
+	AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleViewChat показывает последние сообщения переписки и действия с ней
+func HandleViewChat(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	conversationID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	showConversation(ctx, b, callback, h, conversationID)
+	AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showConversation перерисовывает экран переписки в сообщении callback
+func showConversation(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, conversationID int64) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	conversation, err := h.ConversationService.GetConversation(ctx, user.ID, conversationID)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Переписка не найдена")
+		return
+	}
+
+	messages, err := h.ConversationService.GetRecentMessages(ctx, user.ID, conversationID, conversationHistoryLimit)
+	if err != nil {
+		h.Logger.Error("Failed to get conversation messages", zap.Int64("conversation_id", conversationID), zap.Error(err))
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке сообщений")
+		return
+	}
+
+	peerName := conversationPeerName(conversation, user.ID)
+
+	text := fmt.Sprintf("💬 <b>Переписка: %s</b>\n", html.EscapeString(peerName))
+	if conversation.IsMutedFor(user.ID) {
+		text += "🔕 Сообщения приходят без звука\n"
+	}
+	text += "\n"
+
+	if len(messages) == 0 {
+		text += "Сообщений пока нет."
+	} else {
+		for _, message := range messages {
+			text += FormatConversationMessage(message, user.ID, peerName) + "\n"
+		}
+	}
+
+	muteButton := keyboard.Button("🔕 Без звука", fmt.Sprintf("chat_mute:%d", conversationID))
+	if conversation.IsMutedFor(user.ID) {
+		muteButton = keyboard.Button("🔔 Включить звук", fmt.Sprintf("chat_mute:%d", conversationID))
+	}
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.Button("✉️ Написать", fmt.Sprintf("chat_write:%d", conversationID)), muteButton)
+	kb.Row(keyboard.BackButton("my_chats"))
+
+	editConversationMessage(ctx, b, callback, text, kb.Build())
+}
+
+// HandleChatWrite просит ввести сообщение в открытую переписку
+func HandleChatWrite(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	conversationID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	conversation, err := h.ConversationService.GetConversation(ctx, user.ID, conversationID)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Переписка не найдена")
+		return
+	}
+
+	startConversationMessage(ctx, b, callback, h, user.ID, conversation, nil)
+}
+
+// HandleChatMute включает или выключает звук переписки
+func HandleChatMute(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	conversationID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	conversation, err := h.ConversationService.GetConversation(ctx, user.ID, conversationID)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Переписка не найдена")
+		return
+	}
+
+	muted := !conversation.IsMutedFor(user.ID)
+	if err := h.ConversationService.SetMuted(ctx, user.ID, conversationID, muted); err != nil {
+		h.Logger.Error("Failed to mute conversation", zap.Int64("conversation_id", conversationID), zap.Error(err))
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось изменить настройку")
+		return
+	}
+
+	showConversation(ctx, b, callback, h, conversationID)
+	if muted {
+		AnswerCallback(ctx, b, callback.ID, "🔕 Сообщения будут приходить без звука")
+	} else {
+		AnswerCallback(ctx, b, callback.ID, "🔔 Звук включён")
+	}
+}
+
+// HandleChatBooking открывает переписку с другой стороной записи на занятие
+func HandleChatBooking(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: chat_booking:bookingID
+	bookingID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	conversation, err := h.ConversationService.OpenForBooking(ctx, user.ID, bookingID)
+	if err != nil {
+		if err.Error() == "booking not found" {
+			AnswerCallbackAlert(ctx, b, callback.ID, "❌ Запись не найдена")
+			return
+		}
+		h.Logger.Error("Failed to open conversation for booking", zap.Int64("booking_id", bookingID), zap.Error(err))
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось открыть переписку")
+		return
+	}
+
+	conversation, err = h.ConversationService.GetConversation(ctx, user.ID, conversation.ID)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Переписка не найдена")
+		return
+	}
+
+	startConversationMessage(ctx, b, callback, h, user.ID, conversation, &bookingID)
+}
+
+// HandleChatPeer открывает переписку учителя со студентом (chat_student:) или студента с учителем (chat_teacher:)
+func HandleChatPeer(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	peerID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	var conversation *model.Conversation
+	if strings.HasPrefix(callback.Data, "chat_student:") {
+		if !user.IsTeacher {
+			AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+			return
+		}
+		conversation, err = h.ConversationService.OpenWithStudent(ctx, user.ID, peerID)
+	} else {
+		conversation, err = h.ConversationService.OpenWithTeacher(ctx, user.ID, peerID)
+	}
+	if err != nil {
+		if err.Error() == "no access" {
+			AnswerCallbackAlert(ctx, b, callback.ID, "❌ Написать можно только своему учителю или студенту")
+			return
+		}
+		h.Logger.Error("Failed to open conversation", zap.Int64("peer_id", peerID), zap.Error(err))
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось открыть переписку")
+		return
+	}
+
+	conversation, err = h.ConversationService.GetConversation(ctx, user.ID, conversation.ID)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Переписка не найдена")
+		return
+	}
+
+	startConversationMessage(ctx, b, callback, h, user.ID, conversation, nil)
+}
+
+// startConversationMessage переводит пользователя в ввод сообщения для собеседника.
+// Сообщение отправляется новым, чтобы не затирать экран, с которого открыта переписка
+func startConversationMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, userID int64, conversation *model.Conversation, bookingID *int64) {
+	msg := GetMessageFromCallback(callback)
+	if msg == nil {
+		AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateConversationMessage))
+	h.StateManager.SetData(callback.From.ID, "conversation_id", conversation.ID)
+	if bookingID != nil {
+		h.StateManager.SetData(callback.From.ID, "conversation_booking_id", *bookingID)
+	}
+
+	text := fmt.Sprintf("✉️ <b>Сообщение для %s</b>\n\n", html.EscapeString(conversationPeerName(conversation, userID)))
+	if bookingID != nil {
+		text += fmt.Sprintf("Сообщение будет о записи #%d.\n\n", *bookingID)
+	}
+	text += "Отправьте текст — бот перешлёт его собеседнику, ваш контакт он не увидит.\n\n" +
+		"Для отмены используйте /cancel"
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    msg.Chat.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+
+	AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// conversationPeerName возвращает имя собеседника пользователя
+func conversationPeerName(conversation *model.Conversation, userID int64) string {
+	peer := conversation.Teacher
+	if conversation.TeacherID == userID {
+		peer = conversation.Student
+	}
+
+	if peer == nil {
+		return "Пользователь"
+	}
+
+	if peer.LastName == "" {
+		return peer.FirstName
+	}
+	return peer.FirstName + " " + peer.LastName
+}
+
+// editConversationMessage заменяет экран переписки в сообщении callback
+func editConversationMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
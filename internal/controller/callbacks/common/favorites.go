@@ -0,0 +1,45 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot/models"
+)
+
+// BuildFavoritesScreen формирует список избранных учителей и предметов студента (HTML)
+func BuildFavoritesScreen(favorites []*model.Favorite) (string, *models.InlineKeyboardMarkup) {
+	text := "❤️ <b>Избранное</b>\n\n"
+	if len(favorites) == 0 {
+		text += "В избранном пока пусто. Добавляйте учителей и предметы кнопкой «❤️ В избранное» " +
+			"в профиле учителя или в карточке предмета — бот сообщит, когда у них появится свободное время."
+	} else {
+		text += "Бот присылает уведомление, когда у избранных появляется свободное время " +
+			fmt.Sprintf("(не чаще %d раз в сутки). Выберите запись, чтобы настроить удобные дни и время.", service.MaxSlotAlertsPerDay)
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, favorite := range favorites {
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         FavoriteTitle(favorite),
+			CallbackData: fmt.Sprintf("fav_view:%d", favorite.ID),
+		}})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{keyboard.BackButton("subjects_menu")})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// FavoriteTitle короткое название записи избранного для кнопки
+func FavoriteTitle(favorite *model.Favorite) string {
+	title := "👤 " + favorite.TeacherName
+	if favorite.IsSubject() {
+		title = fmt.Sprintf("📚 %s — %s", favorite.SubjectName, favorite.TeacherName)
+	}
+	if !favorite.AlertsEnabled {
+		title += " 🔕"
+	}
+	return title
+}
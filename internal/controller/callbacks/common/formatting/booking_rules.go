@@ -0,0 +1,80 @@
+package formatting
+
+import (
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatMinNotice форматирует минимальное время до начала занятия
+func FormatMinNotice(minutes int) string {
+	if minutes == 0 {
+		return "без ограничения"
+	}
+	if minutes%1440 == 0 {
+		days := minutes / 1440
+		return fmt.Sprintf("за %d %s", days, PluralizeDays(days))
+	}
+	return fmt.Sprintf("за %s", FormatDuration(minutes))
+}
+
+// FormatMaxAdvance форматирует горизонт записи
+func FormatMaxAdvance(days int) string {
+	if days == 0 {
+		return "без ограничения"
+	}
+	return fmt.Sprintf("на %d %s вперёд", days, PluralizeDays(days))
+}
+
+// FormatBookingLimit форматирует лимит записей
+func FormatBookingLimit(limit int) string {
+	if limit == 0 {
+		return "без ограничения"
+	}
+	return fmt.Sprintf("не более %d", limit)
+}
+
+// FormatBookingRules форматирует правила записи предмета (пустая строка, если правил нет)
+func FormatBookingRules(subject *model.Subject) string {
+	if !subject.HasBookingRules() {
+		return ""
+	}
+
+	text := "📏 Правила записи:\n"
+	if subject.MinNoticeMinutes > 0 {
+		text += fmt.Sprintf("• Запись не позднее чем %s до начала\n", FormatMinNotice(subject.MinNoticeMinutes))
+	}
+	if subject.MaxAdvanceDays > 0 {
+		text += fmt.Sprintf("• Запись открыта %s\n", FormatMaxAdvance(subject.MaxAdvanceDays))
+	}
+	if subject.MaxActiveBookings > 0 {
+		text += fmt.Sprintf("• Активных записей одновременно: %s\n", FormatBookingLimit(subject.MaxActiveBookings))
+	}
+	if subject.MaxBookingsPerWeek > 0 {
+		text += fmt.Sprintf("• Записей в неделю: %s\n", FormatBookingLimit(subject.MaxBookingsPerWeek))
+	}
+	if subject.FreeCancelHours > 0 {
+		text += fmt.Sprintf("• Отмена с возвратом занятия из пакета: %s до начала\n", FormatFreeCancel(subject.FreeCancelHours))
+	}
+	return text
+}
+
+// FormatFreeCancel форматирует срок, до которого отмена возвращает занятие в пакет
+func FormatFreeCancel(hours int) string {
+	if hours == 0 {
+		return "в любое время"
+	}
+	if hours%24 == 0 {
+		days := hours / 24
+		return fmt.Sprintf("за %d %s", days, PluralizeDays(days))
+	}
+	return fmt.Sprintf("за %s", FormatDuration(hours*60))
+}
+
+// FormatBuffer форматирует перерыв учителя до и после занятия
+func FormatBuffer(beforeMinutes, afterMinutes int) string {
+	if beforeMinutes == 0 && afterMinutes == 0 {
+		return "☕️ Перерыв между занятиями: нет"
+	}
+	return fmt.Sprintf("☕️ Перерыв: %d мин до, %d мин после занятия", beforeMinutes, afterMinutes)
+}
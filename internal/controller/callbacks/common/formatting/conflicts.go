@@ -0,0 +1,37 @@
+package formatting
+
+import (
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// maxConflictsShown максимальное количество конфликтов, выводимых списком
+const maxConflictsShown = 10
+
+// FormatSlotConflicts форматирует список слотов, пропущенных из-за пересечений
+// (пустая строка, если конфликтов нет)
+func FormatSlotConflicts(conflicts []model.SlotConflict) string {
+	if len(conflicts) == 0 {
+		return ""
+	}
+
+	text := fmt.Sprintf("⚠️ Пропущено из-за пересечения с другими слотами: %d\n", len(conflicts))
+	for i, conflict := range conflicts {
+		if i == maxConflictsShown {
+			text += fmt.Sprintf("…и ещё %d\n", len(conflicts)-maxConflictsShown)
+			break
+		}
+
+		text += fmt.Sprintf("• %s %s %s",
+			GetWeekdayShort(int(conflict.StartTime.Weekday())),
+			conflict.StartTime.Format("02.01"),
+			FormatTimeRange(conflict.StartTime, conflict.EndTime))
+		if conflict.Existing != nil {
+			text += fmt.Sprintf(" — занято %s", FormatTimeRange(conflict.Existing.StartTime, conflict.Existing.EndTime))
+		}
+		text += "\n"
+	}
+
+	return text
+}
@@ -0,0 +1,44 @@
+package formatting
+
+import (
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatPackageValidity форматирует срок действия пакета
+func FormatPackageValidity(days int) string {
+	if days == 0 {
+		return "бессрочно"
+	}
+	return fmt.Sprintf("%d %s", days, PluralizeDays(days))
+}
+
+// FormatPackage форматирует пакет занятий в одну строку
+func FormatPackage(pkg *model.LessonPackage) string {
+	return fmt.Sprintf("%d %s — %s (%s за занятие), срок: %s",
+		pkg.LessonCount,
+		PluralizeLessons(pkg.LessonCount),
+		FormatPriceShort(pkg.Price, pkg.Currency),
+		FormatPriceShort(pkg.PricePerLesson(), pkg.Currency),
+		FormatPackageValidity(pkg.ValidityDays),
+	)
+}
+
+// FormatCreditBalance форматирует остаток занятий по предмету
+func FormatCreditBalance(balance *model.CreditBalance) string {
+	text := fmt.Sprintf("%s: %d %s", balance.SubjectName, balance.Remaining, PluralizeLessons(balance.Remaining))
+	if balance.NextExpiresAt != nil {
+		text += fmt.Sprintf(" (ближайшие сгорают %s)", FormatDate(*balance.NextExpiresAt))
+	}
+	return text
+}
+
+// SumCreditBalances возвращает общее количество занятий в остатках
+func SumCreditBalances(balances []*model.CreditBalance) int {
+	total := 0
+	for _, balance := range balances {
+		total += balance.Remaining
+	}
+	return total
+}
@@ -0,0 +1,38 @@
+package formatting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatTimeWish описывает удобные дни и части дня, заданные битовыми масками
+func FormatTimeWish(weekdays, periodsMask int) string {
+	filter := model.FreeTimeFilterFromMasks(weekdays, periodsMask)
+
+	days := "любой день"
+	if len(filter.Weekdays) > 0 {
+		names := make([]string, 0, len(filter.Weekdays))
+		// Перечисляем с понедельника
+		for i := 1; i <= 7; i++ {
+			day := time.Weekday(i % 7)
+			if weekdays&(1<<day) != 0 {
+				names = append(names, GetWeekdayShort(int(day)))
+			}
+		}
+		days = strings.Join(names, ", ")
+	}
+
+	periods := "любое время"
+	if len(filter.Periods) > 0 {
+		names := make([]string, 0, len(filter.Periods))
+		for _, period := range filter.Periods {
+			names = append(names, fmt.Sprintf("%s (%d–%d)", strings.ToLower(period.Title), period.FromHour, period.ToHour))
+		}
+		periods = strings.Join(names, ", ")
+	}
+
+	return days + "; " + periods
+}
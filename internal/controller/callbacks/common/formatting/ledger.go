@@ -0,0 +1,49 @@
+package formatting
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatLedgerBalance форматирует состояние взаиморасчётов: долг, аванс или закрытые расчёты
+func FormatLedgerBalance(balance *model.LedgerBalance) string {
+	debt := balance.Debt()
+	switch {
+	case debt > 0:
+		return "🔴 долг " + FormatPriceShort(debt, balance.Currency)
+	case debt < 0:
+		return "🟢 аванс " + FormatPriceShort(-debt, balance.Currency)
+	default:
+		return "⚪️ расчёты закрыты"
+	}
+}
+
+// FormatLedgerMethod возвращает название способа оплаты
+func FormatLedgerMethod(method model.LedgerPaymentMethod) string {
+	switch method {
+	case model.LedgerPaymentCash:
+		return "наличные"
+	case model.LedgerPaymentTransfer:
+		return "перевод"
+	default:
+		return string(method)
+	}
+}
+
+// FormatLedgerEntry форматирует запись журнала взаиморасчётов в одну строку для HTML-сообщения
+func FormatLedgerEntry(entry *model.LedgerEntry) string {
+	if entry.Kind == model.LedgerEntryPayment {
+		text := fmt.Sprintf("%s ➕ Оплата %s", entry.OccurredAt.Format("02.01"), FormatPriceShort(entry.Amount, entry.Currency))
+		if entry.Method != nil {
+			text += fmt.Sprintf(" (%s)", FormatLedgerMethod(*entry.Method))
+		}
+		if entry.Description != "" {
+			text += " — " + html.EscapeString(entry.Description)
+		}
+		return text
+	}
+
+	return fmt.Sprintf("%s ➖ %s %s", entry.OccurredAt.Format("02.01"), html.EscapeString(entry.Description), FormatPriceShort(entry.Amount, entry.Currency))
+}
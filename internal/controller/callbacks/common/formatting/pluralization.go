@@ -54,3 +54,47 @@ func PluralizeBookings(count int) string {
 	}
 	return "записей"
 }
+
+// PluralizeDays возвращает правильное склонение слова "день"
+func PluralizeDays(count int) string {
+	if count%10 == 1 && count%100 != 11 {
+		return "день"
+	}
+	if count%10 >= 2 && count%10 <= 4 && (count%100 < 10 || count%100 >= 20) {
+		return "дня"
+	}
+	return "дней"
+}
+
+// PluralizeLessons возвращает правильное склонение слова "занятие"
+func PluralizeLessons(count int) string {
+	if count%10 == 1 && count%100 != 11 {
+		return "занятие"
+	}
+	if count%10 >= 2 && count%10 <= 4 && (count%100 < 10 || count%100 >= 20) {
+		return "занятия"
+	}
+	return "занятий"
+}
+
+// PluralizeReviews возвращает правильное склонение слова "отзыв"
+func PluralizeReviews(count int) string {
+	if count%10 == 1 && count%100 != 11 {
+		return "отзыв"
+	}
+	if count%10 >= 2 && count%10 <= 4 && (count%100 < 10 || count%100 >= 20) {
+		return "отзыва"
+	}
+	return "отзывов"
+}
+
+// PluralizeTeachers возвращает правильное склонение слова "учитель"
+func PluralizeTeachers(count int) string {
+	if count%10 == 1 && count%100 != 11 {
+		return "учитель"
+	}
+	if count%10 >= 2 && count%10 <= 4 && (count%100 < 10 || count%100 >= 20) {
+		return "учителя"
+	}
+	return "учителей"
+}
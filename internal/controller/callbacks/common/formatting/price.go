@@ -1,18 +1,30 @@
 package formatting
 
-import "fmt"
+import (
+	"fmt"
 
-// FormatPrice форматирует цену из копеек в рубли
-func FormatPrice(priceInCents int) string {
-	price := float64(priceInCents) / 100
-	return fmt.Sprintf("%.2f ₽", price)
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatPrice форматирует цену из минимальных единиц валюты: "1500.00 ₽"
+func FormatPrice(amount int, currency model.Currency) string {
+	if currency.MinorUnits() == 0 {
+		return fmt.Sprintf("%d %s", amount, currency.Symbol())
+	}
+
+	value := float64(amount) / float64(currency.MinorPerMajor())
+	return fmt.Sprintf("%.*f %s", currency.MinorUnits(), value, currency.Symbol())
 }
 
-// FormatPriceShort форматирует цену без копеек если они равны 0
-func FormatPriceShort(priceInCents int) string {
-	price := float64(priceInCents) / 100
-	if priceInCents%100 == 0 {
-		return fmt.Sprintf("%.0f ₽", price)
+// FormatPriceShort форматирует цену без дробной части, если она равна 0: "1500 ₽"
+func FormatPriceShort(amount int, currency model.Currency) string {
+	if amount%currency.MinorPerMajor() == 0 {
+		return fmt.Sprintf("%d %s", amount/currency.MinorPerMajor(), currency.Symbol())
 	}
-	return fmt.Sprintf("%.2f ₽", price)
+	return FormatPrice(amount, currency)
+}
+
+// FormatCurrency форматирует валюту для выбора: "₽ RUB — Рубль"
+func FormatCurrency(currency model.Currency) string {
+	return fmt.Sprintf("%s %s — %s", currency.Symbol(), currency, currency.Name())
 }
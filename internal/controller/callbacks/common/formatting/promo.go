@@ -0,0 +1,39 @@
+package formatting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatPromoDiscount форматирует размер скидки промокода: "−20%" или "−500 ₽"
+func FormatPromoDiscount(promo *model.PromoCode) string {
+	if promo.DiscountType == model.PromoDiscountPercent {
+		return fmt.Sprintf("−%d%%", promo.DiscountValue)
+	}
+
+	currency := model.DefaultCurrency
+	if promo.Currency != nil {
+		currency = *promo.Currency
+	}
+	return "−" + FormatPriceShort(promo.DiscountValue, currency)
+}
+
+// FormatPromoLimits форматирует лимит использований и срок действия промокода
+func FormatPromoLimits(promo *model.PromoCode) string {
+	text := fmt.Sprintf("использований: %d/∞", promo.CurrentUses)
+	if promo.MaxUses != nil {
+		text = fmt.Sprintf("использований: %d/%d", promo.CurrentUses, *promo.MaxUses)
+	}
+
+	if promo.ExpiresAt != nil {
+		if promo.ExpiresAt.Before(time.Now()) {
+			text += ", истёк"
+		} else {
+			text += ", до " + FormatDate(*promo.ExpiresAt)
+		}
+	}
+
+	return text
+}
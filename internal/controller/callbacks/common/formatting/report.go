@@ -0,0 +1,92 @@
+package formatting
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// reportWeekOrder порядок дней недели в отчёте: с понедельника
+var reportWeekOrder = []int{1, 2, 3, 4, 5, 6, 0}
+
+// FormatTeacherReport форматирует отчёт учителя для HTML-сообщения
+func FormatTeacherReport(report *model.TeacherReport) string {
+	text := fmt.Sprintf("📈 <b>Отчёт за %s — %s</b>\n\n",
+		report.From.Format("02.01.2006"),
+		report.To.AddDate(0, 0, -1).Format("02.01.2006"))
+
+	text += "<b>Занятия</b>\n"
+	text += fmt.Sprintf("✅ Проведено: %d\n", report.LessonsHeld)
+	text += fmt.Sprintf("❌ Отменено: %d\n", report.Canceled)
+	text += fmt.Sprintf("🚷 Неявки: %d\n\n", report.NoShows)
+
+	text += "<b>Выручка</b>\n"
+	if len(report.Revenue) == 0 {
+		text += "Проведённых платных занятий нет\n"
+	} else {
+		for _, revenue := range report.Revenue {
+			text += fmt.Sprintf("• %s: %s (%d %s)\n",
+				html.EscapeString(revenue.SubjectName),
+				FormatPriceShort(revenue.Amount, revenue.Currency),
+				revenue.Lessons,
+				PluralizeLessons(revenue.Lessons))
+		}
+
+		// Итоги по каждой валюте отдельно
+		totals := report.RevenueByCurrency()
+		var parts []string
+		for _, currency := range model.SupportedCurrencies() {
+			if amount, ok := totals[currency]; ok {
+				parts = append(parts, FormatPriceShort(amount, currency))
+			}
+		}
+		text += fmt.Sprintf("Итого: <b>%s</b>\n", strings.Join(parts, " + "))
+	}
+	text += "\n"
+
+	text += "<b>Загрузка расписания</b>\n"
+	if report.BookedMinutes+report.FreeMinutes == 0 {
+		text += "Слотов за период нет\n\n"
+	} else {
+		text += fmt.Sprintf("Занято %.0f%%: %s из %s\n\n",
+			report.Utilization()*100,
+			FormatDuration(report.BookedMinutes),
+			FormatDuration(report.BookedMinutes+report.FreeMinutes))
+	}
+
+	if len(report.Load) > 0 {
+		byWeekday := report.LoadByWeekday()
+		byHour := report.LoadByHour()
+
+		busiestDay := reportWeekOrder[0]
+		for _, weekday := range reportWeekOrder {
+			if byWeekday[weekday] > byWeekday[busiestDay] {
+				busiestDay = weekday
+			}
+		}
+
+		busiestHour := 0
+		for hour, lessons := range byHour {
+			if lessons > byHour[busiestHour] {
+				busiestHour = hour
+			}
+		}
+
+		text += fmt.Sprintf("📅 Самый загруженный день: %s (%d %s)\n",
+			GetWeekdayName(busiestDay), byWeekday[busiestDay], PluralizeLessons(byWeekday[busiestDay]))
+		text += fmt.Sprintf("🕐 Самое популярное время: %02d:00 (%d %s)\n\n",
+			busiestHour, byHour[busiestHour], PluralizeLessons(byHour[busiestHour]))
+	}
+
+	if len(report.TopStudents) > 0 {
+		text += "<b>Самые активные студенты</b>\n"
+		for i, student := range report.TopStudents {
+			text += fmt.Sprintf("%d. %s — %d %s\n",
+				i+1, html.EscapeString(student.Name), student.Lessons, PluralizeLessons(student.Lessons))
+		}
+	}
+
+	return text
+}
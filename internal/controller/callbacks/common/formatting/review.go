@@ -0,0 +1,29 @@
+package formatting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// markdownEscaper экранирует спецсимволы Markdown в пользовательском тексте
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// EscapeMarkdown экранирует текст для сообщений с ParseModeMarkdown
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// FormatRating форматирует сводную оценку учителя: "⭐ 4.8 (12 отзывов)"
+func FormatRating(rating *model.TeacherRating) string {
+	if rating == nil || rating.Count == 0 {
+		return "⭐ Пока нет отзывов"
+	}
+	return fmt.Sprintf("⭐ %.1f (%d %s)", rating.Average, rating.Count, PluralizeReviews(rating.Count))
+}
+
+// FormatStars изображает оценку звёздами
+func FormatStars(rating int) string {
+	return strings.Repeat("⭐", rating)
+}
@@ -29,7 +29,7 @@ func FormatSubjectInfo(subject *model.Subject) string {
 			"📊 Статус: %s%s",
 		statusEmoji,
 		subject.Name,
-		FormatPrice(subject.Price),
+		FormatPrice(subject.Price, subject.Currency),
 		FormatDuration(subject.Duration),
 		subject.Description,
 		statusText,
@@ -51,7 +51,7 @@ func FormatSubjectShort(subject *model.Subject, index int) string {
 		index,
 		subject.Name,
 		approvalEmoji,
-		FormatPriceShort(subject.Price),
+		FormatPriceShort(subject.Price, subject.Currency),
 		FormatDuration(subject.Duration),
 		subject.Description,
 	)
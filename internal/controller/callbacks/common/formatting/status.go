@@ -32,11 +32,13 @@ type BookingStatusDisplay struct {
 // GetBookingStatusDisplay возвращает emoji и текст для статуса бронирования
 func GetBookingStatusDisplay(status model.BookingStatus) BookingStatusDisplay {
 	displays := map[model.BookingStatus]BookingStatusDisplay{
-		model.BookingStatusPending:   {"⏳", "Ожидает одобрения"},
-		model.BookingStatusConfirmed: {"✅", "Подтверждена"},
-		model.BookingStatusCompleted: {"✔️", "Завершена"},
-		model.BookingStatusCanceled:  {"❌", "Отменена"},
-		model.BookingStatusRejected:  {"🚫", "Отклонена"},
+		model.BookingStatusPending:         {"⏳", "Ожидает одобрения"},
+		model.BookingStatusConfirmed:       {"✅", "Подтверждена"},
+		model.BookingStatusCompleted:       {"✔️", "Завершена"},
+		model.BookingStatusCanceled:        {"❌", "Отменена"},
+		model.BookingStatusRejected:        {"🚫", "Отклонена"},
+		model.BookingStatusAwaitingPayment: {"💳", "Ожидает оплаты"},
+		model.BookingStatusNoShow:          {"🚷", "Неявка"},
 	}
 
 	if display, ok := displays[status]; ok {
@@ -0,0 +1,24 @@
+package formatting
+
+import (
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// FormatSubjectCategory форматирует категорию предмета: "🌐 Иностранные языки"
+func FormatSubjectCategory(code string) string {
+	category, ok := model.FindSubjectCategory(code)
+	if !ok {
+		return "не указана"
+	}
+	return category.Emoji + " " + category.Title
+}
+
+// FormatSubjectTags форматирует теги предмета через запятую (без экранирования)
+func FormatSubjectTags(tags []string) string {
+	if len(tags) == 0 {
+		return "нет"
+	}
+	return strings.Join(tags, ", ")
+}
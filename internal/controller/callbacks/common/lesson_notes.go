@@ -0,0 +1,176 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ========================
+// Lesson Notes
+// ========================
+// Заметки учителя к занятиям видят учитель и студент записи
+
+const (
+	// lessonNotePreviewLength сколько символов заметки показывать в списке
+	lessonNotePreviewLength = 300
+	// lessonNotesTextBudget предел длины списка заметок, чтобы уложиться в лимит сообщения Telegram
+	lessonNotesTextBudget = 3500
+)
+
+// FormatLessonNote форматирует заметку для списка; withLesson добавляет предмет и дату занятия
+func FormatLessonNote(note *model.LessonNote, number int, withLesson bool) string {
+	text := fmt.Sprintf("<b>%d.</b> ", number)
+	if withLesson {
+		text += fmt.Sprintf("📚 %s, %s\n", html.EscapeString(note.SubjectName), note.LessonStart.Format("02.01.2006"))
+	} else {
+		text += fmt.Sprintf("🕐 %s\n", note.CreatedAt.Format("02.01 15:04"))
+	}
+
+	if note.Text != "" {
+		preview := []rune(note.Text)
+		if len(preview) > lessonNotePreviewLength {
+			text += html.EscapeString(string(preview[:lessonNotePreviewLength])) + "…\n"
+		} else {
+			text += html.EscapeString(note.Text) + "\n"
+		}
+	}
+
+	if note.HasAttachment() {
+		text += "📎 Приложен файл\n"
+	}
+
+	if note.HomeworkDueAt != nil {
+		mark := "📅"
+		if note.HomeworkDueAt.Before(time.Now()) {
+			mark = "⌛"
+		}
+		text += fmt.Sprintf("%s Домашнее задание до %s\n", mark, note.HomeworkDueAt.Format("02.01.2006"))
+	}
+
+	return text
+}
+
+// BuildLessonNotesList собирает список заметок, укладываясь в лимит длины сообщения.
+// Возвращает текст и число заметок, которые в него попали
+func BuildLessonNotesList(notes []*model.LessonNote, withLesson bool) (string, int) {
+	var sb strings.Builder
+	shown := 0
+	for i, note := range notes {
+		item := FormatLessonNote(note, i+1, withLesson) + "\n"
+		if sb.Len()+len(item) > lessonNotesTextBudget {
+			break
+		}
+		sb.WriteString(item)
+		shown++
+	}
+
+	if shown < len(notes) {
+		sb.WriteString(fmt.Sprintf("…и ещё %d", len(notes)-shown))
+	}
+
+	return sb.String(), shown
+}
+
+// LessonNoteViewButtons формирует кнопки полного просмотра заметок, по несколько в ряд
+func LessonNoteViewButtons(kb *keyboard.Builder, notes []*model.LessonNote, shown int) {
+	var row []models.InlineKeyboardButton
+	for i := 0; i < shown; i++ {
+		row = append(row, keyboard.Button(fmt.Sprintf("📄 %d", i+1), fmt.Sprintf("view_note:%d", notes[i].ID)))
+		if len(row) == 5 {
+			kb.AddRow(row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		kb.AddRow(row)
+	}
+}
+
+// HandleViewNote присылает заметку целиком вместе с приложенным файлом
+func HandleViewNote(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: view_note:noteID
+	noteID, err := ParseIDFromCallback(callback.Data)
+	if err != nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	note, err := h.LessonNoteService.GetNote(ctx, user.ID, noteID)
+	if err != nil || note == nil {
+		AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заметка не найдена")
+		return
+	}
+
+	msg := GetMessageFromCallback(callback)
+	if msg == nil {
+		AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	text := fmt.Sprintf(
+		"📝 <b>Заметка к занятию</b>\n📚 %s, %s\n\n",
+		html.EscapeString(note.SubjectName),
+		note.LessonStart.Format("02.01.2006 15:04"),
+	)
+	if note.Text != "" {
+		text += html.EscapeString(note.Text) + "\n"
+	}
+	if note.HomeworkDueAt != nil {
+		text += fmt.Sprintf("\n📅 Домашнее задание до %s", note.HomeworkDueAt.Format("02.01.2006"))
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    msg.Chat.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+
+	// Материалы хранятся как file_id Telegram и отправляются без повторной загрузки
+	if note.PhotoFileID != "" {
+		b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID: msg.Chat.ID,
+			Photo:  &models.InputFileString{Data: note.PhotoFileID},
+		})
+	}
+	if note.DocumentFileID != "" {
+		b.SendDocument(ctx, &bot.SendDocumentParams{
+			ChatID:   msg.Chat.ID,
+			Document: &models.InputFileString{Data: note.DocumentFileID},
+		})
+	}
+
+	AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// homeworkDueOptions варианты срока домашнего задания в днях
+var homeworkDueOptions = []int{1, 3, 7}
+
+// BuildHomeworkDueKeyboard предлагает срок домашнего задания по заметке и возврат к заметкам записи
+func BuildHomeworkDueKeyboard(noteID, bookingID int64, weekOffset int) *models.InlineKeyboardMarkup {
+	kb := keyboard.NewBuilder()
+
+	var row []models.InlineKeyboardButton
+	for _, days := range homeworkDueOptions {
+		row = append(row, keyboard.Button(fmt.Sprintf("📅 %d дн.", days), fmt.Sprintf("note_due:%d:%d:%d", noteID, days, weekOffset)))
+	}
+	kb.AddRow(row)
+	kb.Row(keyboard.Button("🚫 Без срока", fmt.Sprintf("note_due:%d:0:%d", noteID, weekOffset)))
+	kb.Row(keyboard.Button("📝 К заметкам", fmt.Sprintf("lesson_notes:%d:%d", bookingID, weekOffset)))
+
+	return kb.Build()
+}
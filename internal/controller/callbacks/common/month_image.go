@@ -0,0 +1,262 @@
+package common
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/fogleman/gg"
+)
+
+// Размеры обзора месяца
+const (
+	monthImageWidth    = 1400
+	monthImageHeight   = 1040
+	monthHeaderHeight  = 150
+	monthLegendHeight  = 70
+	monthPadding       = 40.0
+	monthCellGap       = 6.0
+	monthCellRadius    = 8.0
+	monthHeatBarWidth  = 260.0
+	monthHeatBarHeight = 16.0
+	monthHeatBarSteps  = 40
+)
+
+// Шрифты обзора месяца
+const (
+	monthTitleFontSize   = 32.0
+	monthSummaryFontSize = 20.0
+	monthWeekdayFontSize = 20.0
+	monthDayFontSize     = 24.0
+	monthCountFontSize   = 17.0
+	monthLegendFontSize  = 15.0
+)
+
+// Цвета обзора месяца
+var (
+	monthOutsideColor  = color.RGBA{232, 234, 237, 255}
+	monthNoSlotsColor  = color.RGBA{248, 248, 248, 255}
+	monthOutsideText   = color.RGBA{170, 174, 178, 255}
+	monthFreeTextColor = color.RGBA{60, 110, 40, 255}
+	monthHeatLowColor  = color.RGBA{214, 236, 200, 255}
+	monthHeatHighColor = color.RGBA{255, 120, 90, 255}
+	monthCellBorder    = color.RGBA{200, 203, 207, 255}
+	monthTodayBorder   = currentTimeColor
+	monthPercentColor  = color.RGBA{60, 64, 68, 200}
+)
+
+// monthDayStats занятые и свободные слоты дня
+type monthDayStats struct {
+	booked int
+	free   int
+}
+
+// utilization доля занятых слотов среди занятых и свободных
+func (s monthDayStats) utilization() float64 {
+	if s.booked+s.free == 0 {
+		return 0
+	}
+	return float64(s.booked) / float64(s.booked+s.free)
+}
+
+// GenerateMonthImage генерирует обзор месяца, в который попадает month: сетку дней
+// с числом занятых и свободных слотов и цветом загрузки. Отменённые слоты не учитываются
+func GenerateMonthImage(month time.Time, slots []*model.ScheduleSlot) ([]byte, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	gridStart := normalizeToWeekBounds(monthStart).start
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	rows := int(monthEnd.Sub(gridStart).Hours()/24+6) / totalDaysInWeek
+
+	stats := make(map[string]monthDayStats)
+	var total monthDayStats
+	for _, slot := range slots {
+		if slot.StartTime.Before(monthStart) || !slot.StartTime.Before(monthEnd) {
+			continue
+		}
+		dateKey := slot.StartTime.Format("2006-01-02")
+		day := stats[dateKey]
+		switch slot.Status {
+		case model.SlotStatusBooked:
+			day.booked++
+			total.booked++
+		case model.SlotStatusFree:
+			day.free++
+			total.free++
+		default:
+			continue
+		}
+		stats[dateKey] = day
+	}
+
+	dc := gg.NewContext(monthImageWidth, monthImageHeight)
+	dc.SetColor(bgColor)
+	dc.Clear()
+
+	drawMonthHeader(dc, monthStart, total)
+
+	cellWidth := (float64(monthImageWidth) - 2*monthPadding) / totalDaysInWeek
+	gridTop := float64(monthHeaderHeight)
+	cellHeight := (float64(monthImageHeight-monthLegendHeight) - gridTop) / float64(rows)
+	today := normalizeToDay(time.Now())
+
+	date := gridStart
+	for row := 0; row < rows; row++ {
+		for col := 0; col < totalDaysInWeek; col++ {
+			x := monthPadding + float64(col)*cellWidth
+			y := gridTop + float64(row)*cellHeight
+			inMonth := date.Month() == monthStart.Month()
+			drawMonthDay(dc, date, stats[date.Format("2006-01-02")], inMonth, isSameDay(date, today), x, y, cellWidth, cellHeight)
+			date = date.AddDate(0, 0, 1)
+		}
+	}
+
+	drawMonthLegend(dc)
+
+	return encodeImage(dc)
+}
+
+// drawMonthHeader рисует название месяца, сводку загрузки и дни недели
+func drawMonthHeader(dc *gg.Context, monthStart time.Time, total monthDayStats) {
+	loadFont(dc, monthTitleFontSize, FontStyleBold)
+	dc.SetColor(textColor)
+	title := getMonthNameRussian(monthStart.Month()) + " " + strconv.Itoa(monthStart.Year())
+	dc.DrawStringAnchored(title, monthPadding, 45, 0, 0.5)
+
+	summary := "Слотов в этом месяце нет"
+	if slotsTotal := total.booked + total.free; slotsTotal > 0 {
+		summary = fmt.Sprintf("Слотов: %d · занято: %d · свободно: %d · загрузка %d%%",
+			slotsTotal, total.booked, total.free, int(total.utilization()*100+0.5))
+	}
+	loadFont(dc, monthSummaryFontSize, FontStyleItalic)
+	dc.SetColor(hourLabelColor)
+	dc.DrawStringAnchored(summary, monthPadding, 85, 0, 0.5)
+
+	cellWidth := (float64(monthImageWidth) - 2*monthPadding) / totalDaysInWeek
+	loadFont(dc, monthWeekdayFontSize, FontStyleSemiBold)
+	dc.SetColor(textColor)
+	for col := 0; col < totalDaysInWeek; col++ {
+		weekday := time.Weekday((col + 1) % totalDaysInWeek)
+		dc.DrawStringAnchored(getWeekdayShort(weekday), monthPadding+(float64(col)+0.5)*cellWidth, float64(monthHeaderHeight)-18, 0.5, 0.5)
+	}
+}
+
+// drawMonthDay рисует клетку дня: число, занятые и свободные слоты, цвет загрузки
+func drawMonthDay(dc *gg.Context, date time.Time, stats monthDayStats, inMonth, isToday bool, x, y, width, height float64) {
+	cellX := x + monthCellGap/2
+	cellY := y + monthCellGap/2
+	cellW := width - monthCellGap
+	cellH := height - monthCellGap
+	hasSlots := stats.booked+stats.free > 0
+
+	switch {
+	case !inMonth:
+		dc.SetColor(monthOutsideColor)
+	case hasSlots:
+		dc.SetColor(heatColor(stats.utilization()))
+	default:
+		dc.SetColor(monthNoSlotsColor)
+	}
+	dc.DrawRoundedRectangle(cellX, cellY, cellW, cellH, monthCellRadius)
+	dc.Fill()
+
+	if isToday && inMonth {
+		dc.SetColor(monthTodayBorder)
+		dc.SetLineWidth(3)
+	} else {
+		dc.SetColor(monthCellBorder)
+		dc.SetLineWidth(1)
+	}
+	dc.DrawRoundedRectangle(cellX, cellY, cellW, cellH, monthCellRadius)
+	dc.Stroke()
+
+	loadFont(dc, monthDayFontSize, FontStyleBold)
+	if inMonth {
+		dc.SetColor(textColor)
+	} else {
+		dc.SetColor(monthOutsideText)
+	}
+	dc.DrawStringAnchored(strconv.Itoa(date.Day()), cellX+12, cellY+22, 0, 0.5)
+
+	if !inMonth || !hasSlots {
+		return
+	}
+
+	// Занятые и свободные слоты с цветными метками как на изображении недели
+	loadFont(dc, monthCountFontSize, FontStyleMedium)
+	lines := []struct {
+		label string
+		dot   color.Color
+		text  color.Color
+		count int
+	}{
+		{"Занято", slotBookedColor, slotBookedTextColor, stats.booked},
+		{"Свободно", slotFreeColor, monthFreeTextColor, stats.free},
+	}
+	lineY := cellY + 56
+	for _, line := range lines {
+		dc.SetColor(line.dot)
+		dc.DrawCircle(cellX+18, lineY, 6)
+		dc.Fill()
+		dc.SetColor(line.text)
+		dc.DrawStringAnchored(fmt.Sprintf("%s: %d", line.label, line.count), cellX+32, lineY, 0, 0.35)
+		lineY += 24
+	}
+
+	dc.SetColor(monthPercentColor)
+	dc.DrawStringAnchored(fmt.Sprintf("%d%%", int(stats.utilization()*100+0.5)), cellX+cellW-10, cellY+22, 1, 0.5)
+}
+
+// drawMonthLegend рисует шкалу загрузки и обозначения под сеткой
+func drawMonthLegend(dc *gg.Context) {
+	y := float64(monthImageHeight) - float64(monthLegendHeight)/2
+	x := monthPadding
+
+	loadFont(dc, monthLegendFontSize)
+	dc.SetColor(legendItemColor)
+	dc.DrawStringAnchored("Загрузка:", x, y, 0, 0.35)
+	x += 80
+
+	dc.DrawStringAnchored("0%", x, y, 0, 0.35)
+	x += 30
+	stepWidth := monthHeatBarWidth / float64(monthHeatBarSteps)
+	for i := 0; i < monthHeatBarSteps; i++ {
+		dc.SetColor(heatColor(float64(i) / float64(monthHeatBarSteps-1)))
+		dc.DrawRectangle(x+float64(i)*stepWidth, y-monthHeatBarHeight/2, stepWidth+0.5, monthHeatBarHeight)
+		dc.Fill()
+	}
+	x += monthHeatBarWidth + 8
+	dc.SetColor(legendItemColor)
+	dc.DrawStringAnchored("100%", x, y, 0, 0.35)
+	x += 70
+
+	dc.SetColor(monthNoSlotsColor)
+	dc.DrawRoundedRectangle(x, y-monthHeatBarHeight/2, 28, monthHeatBarHeight, 3)
+	dc.Fill()
+	dc.SetColor(monthCellBorder)
+	dc.SetLineWidth(1)
+	dc.DrawRoundedRectangle(x, y-monthHeatBarHeight/2, 28, monthHeatBarHeight, 3)
+	dc.Stroke()
+	dc.SetColor(legendItemColor)
+	dc.DrawStringAnchored("нет слотов", x+36, y, 0, 0.35)
+}
+
+// heatColor возвращает цвет загрузки дня: от бледно-зелёного (свободно) до кораллового (всё занято)
+func heatColor(ratio float64) color.RGBA {
+	if ratio < 0 {
+		ratio = 0
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	mix := func(from, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*ratio)
+	}
+	return color.RGBA{
+		R: mix(monthHeatLowColor.R, monthHeatHighColor.R),
+		G: mix(monthHeatLowColor.G, monthHeatHighColor.G),
+		B: mix(monthHeatLowColor.B, monthHeatHighColor.B),
+		A: 255,
+	}
+}
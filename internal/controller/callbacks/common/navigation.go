@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
@@ -98,6 +99,11 @@ func HandleBackToSubjects(ctx context.Context, b *bot.Bot, callback *models.Call
 		return
 	}
 
+	// Возврат в меню завершает режим поиска предметов
+	if h.StateManager.GetState(callback.From.ID) == callbacktypes.UserState(state.StateSearchingTeacher) {
+		h.StateManager.ClearState(callback.From.ID)
+	}
+
 	// Редактируем сообщение вместо удаления
 	update := &models.Update{
 		CallbackQuery: callback,
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// SendNewBookingNotification уведомляет учителя о новой записи.
+// Для записей, ожидающих одобрения, добавляет кнопки одобрения/отклонения.
+// booking.Subject и booking.Slot должны быть заполнены
+func SendNewBookingNotification(ctx context.Context, b *bot.Bot, teacherChatID int64, studentName string, booking *model.Booking) {
+	if booking.Subject == nil || booking.Slot == nil {
+		return
+	}
+
+	paidLine := ""
+	if booking.Payment != nil && booking.Payment.Status == model.PaymentStatusPaid {
+		paidLine = fmt.Sprintf("💳 Оплачено: %s\n", formatting.FormatPrice(booking.Payment.Amount, model.Currency(booking.Payment.Currency)))
+	} else if booking.CreditDebited {
+		paidLine = "🎟 Оплачено из пакета занятий\n"
+	}
+	if booking.PromoCode != nil {
+		paidLine += fmt.Sprintf("🏷 Промокод `%s`: %s вместо %s\n",
+			booking.PromoCode.Code,
+			formatting.FormatPrice(booking.Price, booking.Currency),
+			formatting.FormatPrice(booking.Subject.Price, booking.Subject.Currency))
+	}
+
+	if booking.Status == model.BookingStatusPending {
+		// Для pending - запрос на одобрение с кнопками
+		notificationText := fmt.Sprintf(
+			"⏳ **Новый запрос на запись**\n\n"+
+				"👤 Студент: %s\n"+
+				"📚 Предмет: %s\n"+
+				"📅 Дата: %s\n"+
+				"🕐 Время: %s - %s\n"+
+				"%s\n"+
+				"Требуется ваше одобрение:",
+			studentName,
+			booking.Subject.Name,
+			booking.Slot.StartTime.Format("02.01.2006"),
+			booking.Slot.StartTime.Format("15:04"),
+			booking.Slot.EndTime.Format("15:04"),
+			paidLine,
+		)
+
+		keyboard := &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: "✅ Одобрить", CallbackData: fmt.Sprintf("approve_booking:%d", booking.ID)},
+					{Text: "❌ Отклонить", CallbackData: fmt.Sprintf("reject_booking:%d", booking.ID)},
+				},
+			},
+		}
+
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      teacherChatID,
+			Text:        notificationText,
+			ParseMode:   models.ParseModeMarkdown,
+			ReplyMarkup: keyboard,
+		})
+		return
+	}
+
+	// Для confirmed - просто уведомление
+	notificationText := fmt.Sprintf(
+		"✅ **Новая запись**\n\n"+
+			"👤 Студент: %s\n"+
+			"📚 Предмет: %s\n"+
+			"📅 Дата: %s\n"+
+			"🕐 Время: %s - %s\n"+
+			"%s\n"+
+			"Запись подтверждена автоматически.",
+		studentName,
+		booking.Subject.Name,
+		booking.Slot.StartTime.Format("02.01.2006"),
+		booking.Slot.StartTime.Format("15:04"),
+		booking.Slot.EndTime.Format("15:04"),
+		paidLine,
+	)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    teacherChatID,
+		Text:      notificationText,
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
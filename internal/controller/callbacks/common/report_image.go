@@ -0,0 +1,245 @@
+package common
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/fogleman/gg"
+)
+
+// Размеры графика отчёта
+const (
+	reportImageWidth   = 1400
+	reportImageHeight  = 820
+	reportPadding      = 60.0
+	reportUtilTop      = 110.0
+	reportUtilHeight   = 44.0
+	reportChartsTop    = 260.0
+	reportChartsBottom = 740.0
+	reportChartGap     = 80.0
+	reportBarRadius    = 6.0
+	reportMinHourSpan  = 8
+	reportDefaultStart = 9
+	reportDefaultEnd   = 21
+)
+
+// Шрифты графика отчёта
+const (
+	reportTitleFontSize = 30.0
+	reportLabelFontSize = 20.0
+	reportAxisFontSize  = 17.0
+	reportValueFontSize = 16.0
+)
+
+// Цвета графика отчёта
+var (
+	reportBarColor      = color.RGBA{110, 160, 220, 230}
+	reportPeakBarColor  = color.RGBA{255, 140, 120, 240}
+	reportTrackColor    = color.RGBA{225, 227, 230, 255}
+	reportAxisLineColor = color.NRGBA{190, 190, 190, 255}
+)
+
+// reportWeekdays порядок дней недели на графике: с понедельника
+var reportWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// GenerateReportChart рисует график отчёта: загрузку слотов и занятия по дням недели и часам
+func GenerateReportChart(report *model.TeacherReport) ([]byte, error) {
+	dc := gg.NewContext(reportImageWidth, reportImageHeight)
+	dc.SetColor(bgColor)
+	dc.Clear()
+
+	title := fmt.Sprintf("Отчёт за %s — %s",
+		report.From.Format("02.01.2006"),
+		report.To.AddDate(0, 0, -1).Format("02.01.2006"))
+	loadFont(dc, reportTitleFontSize, FontStyleBold)
+	dc.SetColor(textColor)
+	dc.DrawStringAnchored(title, reportPadding, 55, 0, 0.5)
+
+	drawReportUtilization(dc, report)
+
+	chartWidth := (float64(reportImageWidth) - 2*reportPadding - reportChartGap) / 2
+
+	// Занятия по дням недели
+	byWeekday := report.LoadByWeekday()
+	dayLabels := make([]string, 0, len(reportWeekdays))
+	dayValues := make([]int, 0, len(reportWeekdays))
+	for _, weekday := range reportWeekdays {
+		dayLabels = append(dayLabels, getWeekdayShort(weekday))
+		dayValues = append(dayValues, byWeekday[weekday])
+	}
+	drawReportBars(dc, "Занятия по дням недели", reportPadding, chartWidth, dayLabels, dayValues)
+
+	// Занятия по часу начала
+	byHour := report.LoadByHour()
+	startHour, endHour := reportHourRange(byHour)
+	hourLabels := make([]string, 0, endHour-startHour+1)
+	hourValues := make([]int, 0, endHour-startHour+1)
+	for hour := startHour; hour <= endHour; hour++ {
+		hourLabels = append(hourLabels, strconv.Itoa(hour))
+		hourValues = append(hourValues, byHour[hour])
+	}
+	drawReportBars(dc, "Занятия по времени начала", reportPadding+chartWidth+reportChartGap, chartWidth, hourLabels, hourValues)
+
+	return encodeImage(dc)
+}
+
+// drawReportUtilization рисует полосу занятого и свободного времени слотов
+func drawReportUtilization(dc *gg.Context, report *model.TeacherReport) {
+	width := float64(reportImageWidth) - 2*reportPadding
+
+	dc.SetColor(reportTrackColor)
+	dc.DrawRoundedRectangle(reportPadding, reportUtilTop, width, reportUtilHeight, reportBarRadius)
+	dc.Fill()
+
+	totalMinutes := report.BookedMinutes + report.FreeMinutes
+	if totalMinutes > 0 {
+		dc.SetColor(slotFreeColor)
+		dc.DrawRoundedRectangle(reportPadding, reportUtilTop, width, reportUtilHeight, reportBarRadius)
+		dc.Fill()
+
+		if booked := width * report.Utilization(); booked > 0 {
+			dc.SetColor(slotBookedColor)
+			dc.DrawRoundedRectangle(reportPadding, reportUtilTop, booked, reportUtilHeight, reportBarRadius)
+			dc.Fill()
+		}
+	}
+
+	label := "Слотов за период нет"
+	if totalMinutes > 0 {
+		label = fmt.Sprintf("Занято %.0f%% времени: %s из %s",
+			report.Utilization()*100,
+			formatReportHours(report.BookedMinutes),
+			formatReportHours(totalMinutes))
+	}
+
+	loadFont(dc, reportLabelFontSize, FontStyleSemiBold)
+	dc.SetColor(slotTextColor)
+	dc.DrawStringAnchored(label, reportPadding+16, reportUtilTop+reportUtilHeight/2, 0, 0.35)
+
+	// Легенда под полосой
+	legendY := reportUtilTop + reportUtilHeight + 22
+	legendX := reportPadding
+	for _, item := range []struct {
+		label string
+		clr   color.Color
+	}{
+		{"Забронировано", slotBookedColor},
+		{"Свободно", slotFreeColor},
+	} {
+		dc.SetColor(item.clr)
+		dc.DrawRoundedRectangle(legendX, legendY, 20, 14, 3)
+		dc.Fill()
+
+		loadFont(dc, legendItemFontSize+2)
+		dc.SetColor(legendItemColor)
+		w, _ := dc.MeasureString(item.label)
+		dc.DrawStringAnchored(item.label, legendX+28, legendY+8, 0, 0.35)
+		legendX += 28 + w + 30
+	}
+}
+
+// drawReportBars рисует столбчатую диаграмму, самый высокий столбец выделяется цветом
+func drawReportBars(dc *gg.Context, title string, x, width float64, labels []string, values []int) {
+	loadFont(dc, reportLabelFontSize, FontStyleBold)
+	dc.SetColor(textColor)
+	dc.DrawStringAnchored(title, x, reportChartsTop-30, 0, 0.5)
+
+	axisY := reportChartsBottom - 30
+	dc.SetColor(reportAxisLineColor)
+	dc.SetLineWidth(1)
+	dc.DrawLine(x, axisY, x+width, axisY)
+	dc.Stroke()
+
+	maxValue := 0
+	for _, value := range values {
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+
+	slotWidth := width / float64(len(values))
+	barWidth := slotWidth * 0.65
+	chartHeight := axisY - reportChartsTop - 24
+
+	for i, value := range values {
+		barX := x + float64(i)*slotWidth + (slotWidth-barWidth)/2
+		centerX := barX + barWidth/2
+
+		if value > 0 && maxValue > 0 {
+			barHeight := chartHeight * float64(value) / float64(maxValue)
+			if barHeight < reportBarRadius*2 {
+				barHeight = reportBarRadius * 2
+			}
+
+			barColor := reportBarColor
+			if value == maxValue {
+				barColor = reportPeakBarColor
+			}
+
+			dc.SetColor(slotShadowColor)
+			dc.DrawRoundedRectangle(barX+2, axisY-barHeight+2, barWidth, barHeight, reportBarRadius)
+			dc.Fill()
+
+			dc.SetColor(barColor)
+			dc.DrawRoundedRectangle(barX, axisY-barHeight, barWidth, barHeight, reportBarRadius)
+			dc.Fill()
+
+			loadFont(dc, reportValueFontSize, FontStyleSemiBold)
+			dc.SetColor(darkenColor(barColor, 0.5))
+			dc.DrawStringAnchored(strconv.Itoa(value), centerX, axisY-barHeight-12, 0.5, 0.5)
+		}
+
+		loadFont(dc, reportAxisFontSize, FontStyleMedium)
+		dc.SetColor(hourLabelColor)
+		dc.DrawStringAnchored(labels[i], centerX, axisY+18, 0.5, 0.5)
+	}
+
+	if maxValue == 0 {
+		loadFont(dc, reportLabelFontSize, FontStyleItalic)
+		dc.SetColor(legendTextColor)
+		dc.DrawStringAnchored("Занятий за период нет", x+width/2, (reportChartsTop+axisY)/2, 0.5, 0.5)
+	}
+}
+
+// reportHourRange определяет диапазон часов с занятиями, не уже reportMinHourSpan
+func reportHourRange(byHour [24]int) (int, int) {
+	start, end := -1, -1
+	for hour, lessons := range byHour {
+		if lessons == 0 {
+			continue
+		}
+		if start < 0 {
+			start = hour
+		}
+		end = hour
+	}
+
+	if start < 0 {
+		return reportDefaultStart, reportDefaultEnd
+	}
+
+	// Расширяем узкий диапазон, чтобы столбцы не были слишком широкими
+	for end-start+1 < reportMinHourSpan {
+		if start > 0 {
+			start--
+		}
+		if end-start+1 < reportMinHourSpan && end < 23 {
+			end++
+		}
+	}
+
+	return start, end
+}
+
+// formatReportHours форматирует минуты в часы для подписи графика
+func formatReportHours(minutes int) string {
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d ч", minutes/60)
+	}
+	return fmt.Sprintf("%.1f ч", float64(minutes)/60)
+}
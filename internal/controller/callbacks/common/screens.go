@@ -2,14 +2,15 @@ package common
 
 import (
 	"fmt"
+	"html"
 
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot/models"
 )
 
 // BuildEditSubjectScreen формирует экран редактирования предмета
 func BuildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeyboardMarkup) {
-	price := float64(subject.Price) / 100
 	statusText := "Активен ✅"
 	if !subject.IsActive {
 		statusText = "Неактивен ⏸"
@@ -18,21 +19,31 @@ func BuildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 	if subject.RequiresBookingApproval {
 		approvalText = "Да ✅"
 	}
+	prepaymentText := "Нет ❌"
+	if subject.RequiresPrepayment {
+		prepaymentText = "Да ✅"
+	}
 
 	text := fmt.Sprintf(
 		"🛠 <b>Редактирование предмета</b>\n\n"+
 			"📚 Название: %s\n"+
 			"📝 Описание: %s\n"+
-			"💰 Цена: %.2f ₽\n"+
+			"💰 Цена: %s\n"+
 			"⏱ Длительность: %d мин\n"+
+			"🏷 Категория: %s\n"+
+			"🔖 Теги: %s\n"+
 			"⏳ Требуется одобрение: %s\n"+
+			"💳 Предоплата: %s\n"+
 			"📊 Статус: %s\n\n"+
 			"Выберите, что хотите изменить:",
 		subject.Name,
 		subject.Description,
-		price,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration,
+		formatting.FormatSubjectCategory(subject.Category),
+		html.EscapeString(formatting.FormatSubjectTags(subject.Tags)),
 		approvalText,
+		prepaymentText,
 		statusText,
 	)
 
@@ -42,6 +53,11 @@ func BuildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 		approvalButtonText = "⏳ Требуется одобрение: да"
 	}
 
+	prepaymentButtonText := "💳 Предоплата: нет"
+	if subject.RequiresPrepayment {
+		prepaymentButtonText = "💳 Предоплата: да"
+	}
+
 	statusButtonText := "📊 Статус: активен"
 	if !subject.IsActive {
 		statusButtonText = "📊 Статус: неактивен"
@@ -57,9 +73,22 @@ func BuildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 				{Text: "💰 Цена", CallbackData: fmt.Sprintf("edit_field_price:%d", subject.ID)},
 				{Text: "⏱ Длительность", CallbackData: fmt.Sprintf("edit_field_duration:%d", subject.ID)},
 			},
+			{
+				{Text: "💱 Валюта: " + string(subject.Currency), CallbackData: fmt.Sprintf("subject_currency:%d", subject.ID)},
+			},
+			{
+				{Text: "🏷 Категория и теги", CallbackData: fmt.Sprintf("subject_tags:%d", subject.ID)},
+			},
 			{
 				{Text: approvalButtonText, CallbackData: fmt.Sprintf("toggle_approval:%d", subject.ID)},
 			},
+			{
+				{Text: prepaymentButtonText, CallbackData: fmt.Sprintf("toggle_prepayment:%d", subject.ID)},
+			},
+			{
+				{Text: "📏 Правила записи", CallbackData: fmt.Sprintf("booking_rules:%d", subject.ID)},
+				{Text: "🎁 Пакеты занятий", CallbackData: fmt.Sprintf("subject_packages:%d", subject.ID)},
+			},
 			{
 				{Text: statusButtonText, CallbackData: fmt.Sprintf("toggle_subject:%d:edit", subject.ID)},
 			},
@@ -74,7 +103,6 @@ func BuildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 
 // BuildViewSubjectScreen формирует экран просмотра предмета
 func BuildViewSubjectScreen(subject *model.Subject) (string, *models.InlineKeyboardMarkup) {
-	price := float64(subject.Price) / 100
 	statusText := "✅ Активен"
 	if !subject.IsActive {
 		statusText = "⏸ Неактивен"
@@ -88,14 +116,14 @@ func BuildViewSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 	text := fmt.Sprintf(
 		"📚 <b>%s</b>\n\n"+
 			"📝 Описание: %s\n"+
-			"💰 Цена: %.2f ₽\n"+
+			"💰 Цена: %s\n"+
 			"⏱ Длительность: %d мин\n"+
 			"📊 Статус: %s\n"+
 			"⏳ Требуется одобрение: %s\n\n"+
 			"Выберите действие:",
 		subject.Name,
 		subject.Description,
-		price,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration,
 		statusText,
 		approvalText,
@@ -151,14 +179,14 @@ func BuildSubjectsListScreen(subjects []*model.Subject, page int) (string, *mode
 
 		text += fmt.Sprintf(
 			"%d. %s %s\n"+
-				"   💰 Цена: %.2f ₽\n"+
+				"   💰 Цена: %s\n"+
 				"   ⏱ Длительность: %d мин\n"+
 				"   📝 %s\n"+
 				"   Статус: %s\n\n",
 			i+1,
 			statusEmoji,
 			subject.Name,
-			float64(subject.Price)/100,
+			formatting.FormatPrice(subject.Price, subject.Currency),
 			subject.Duration,
 			subject.Description,
 			statusText,
@@ -252,21 +280,38 @@ func BuildDeleteSubjectConfirmScreen(subject *model.Subject, bookingsCount int)
 // ========================
 
 // BuildStudentSubjectDetailsScreen формирует экран деталей subject для студента
-func BuildStudentSubjectDetailsScreen(subject *model.Subject, teacherName string) (string, *models.InlineKeyboardMarkup) {
+func BuildStudentSubjectDetailsScreen(subject *model.Subject, teacherName string, packages []*model.LessonPackage, credits int, promo *model.PromoCode, isFavorite bool) (string, *models.InlineKeyboardMarkup) {
 	approvalText := ""
 	if subject.RequiresBookingApproval {
 		approvalText = "\n⏳ Требуется одобрение учителя"
 	}
+	if rules := formatting.FormatBookingRules(subject); rules != "" {
+		approvalText += "\n\n" + rules
+	}
+	if credits > 0 {
+		approvalText += fmt.Sprintf("\n\n🎟 Оплаченных занятий: %d — запись спишет одно из них", credits)
+	}
+	if len(packages) > 0 {
+		approvalText += "\n\n🎁 Пакеты занятий (оплата у преподавателя):"
+		for _, pkg := range packages {
+			approvalText += "\n• " + formatting.FormatPackage(pkg)
+		}
+	}
+
+	priceText := formatting.FormatPrice(subject.Price, subject.Currency)
+	if promo != nil {
+		priceText += fmt.Sprintf(" → %s по промокоду `%s`", formatting.FormatPrice(promo.Apply(subject.Price), subject.Currency), promo.Code)
+	}
 
 	text := fmt.Sprintf(
 		"📚 **%s**\n\n"+
 			"👤 Преподаватель: %s\n"+
-			"💰 Цена: %.2f ₽\n"+
+			"💰 Цена: %s\n"+
 			"⏱ Длительность: %d мин\n\n"+
 			"📝 Описание:\n%s%s",
 		subject.Name,
 		teacherName,
-		float64(subject.Price)/100,
+		priceText,
 		subject.Duration,
 		subject.Description,
 		approvalText,
@@ -277,12 +322,32 @@ func BuildStudentSubjectDetailsScreen(subject *model.Subject, teacherName string
 			{
 				{Text: "📅 Посмотреть расписание", CallbackData: fmt.Sprintf("view_schedule_subject:%d", subject.ID)},
 			},
-			{
-				{Text: "⬅️ К списку предметов", CallbackData: "book_another"},
-			},
 		},
 	}
 
+	// Промокод имеет смысл только для платных предметов
+	if promo != nil {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+			{Text: "✖️ Убрать промокод", CallbackData: fmt.Sprintf("remove_promo:%d", subject.ID)},
+		})
+	} else if subject.Price > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+			{Text: "🏷 Ввести промокод", CallbackData: fmt.Sprintf("enter_promo:%d", subject.ID)},
+		})
+	}
+
+	favoriteText := "❤️ В избранное"
+	if isFavorite {
+		favoriteText = "💔 Убрать из избранного"
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+		{Text: favoriteText, CallbackData: fmt.Sprintf("fav_subject:%d", subject.ID)},
+	})
+
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+		{Text: "⬅️ К списку предметов", CallbackData: "book_another"},
+	})
+
 	return text, keyboard
 }
 
@@ -319,6 +384,35 @@ func BuildBookingSuccessScreen(bookingID int64, slotID int64, isPending bool) (s
 	return text, keyboard
 }
 
+// BuildAwaitingPaymentScreen формирует экран записи, ожидающей оплаты
+func BuildAwaitingPaymentScreen(bookingID int64, payment *model.Payment) (string, *models.InlineKeyboardMarkup) {
+	paymentInfo := ""
+	if payment != nil {
+		paymentInfo = fmt.Sprintf("💳 К оплате: %s\n⏰ Оплатите до %s, иначе запись будет отменена.\n\n",
+			formatting.FormatPrice(payment.Amount, model.Currency(payment.Currency)),
+			payment.ExpiresAt.Format("15:04 02.01"))
+	}
+
+	text := fmt.Sprintf(
+		"💳 Слот забронирован!\n\n"+
+			"📝 Запись #%d\n"+
+			"📅 Статус: Ожидает оплаты\n\n"+
+			"%s"+
+			"Счёт отправлен отдельным сообщением. После оплаты запись подтвердится, а учитель получит уведомление.",
+		bookingID,
+		paymentInfo,
+	)
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "❌ Отменить запись", CallbackData: fmt.Sprintf("cancel_booking:%d", bookingID)}},
+			{{Text: "📅 Мои записи", CallbackData: "back_to_main"}},
+		},
+	}
+
+	return text, keyboard
+}
+
 // BuildEmptyBookingsScreen формирует экран для пустого списка бронирований
 func BuildEmptyBookingsScreen() (string, *models.InlineKeyboardMarkup) {
 	text := "📅 У вас пока нет записей на занятия.\n\nПосмотрите доступные предметы и запишитесь!"
@@ -340,6 +434,9 @@ func BuildSubjectCategoriesScreen() (string, *models.InlineKeyboardMarkup) {
 		"Выберите категорию:\n\n" +
 		"🎓 *Мои учителя* - учителя, к которым у вас есть доступ\n" +
 		"🌍 *Публичные учителя* - доступны всем студентам\n" +
+		"🔎 *Поиск предметов* - по названию, тегам и категории\n" +
+		"🕐 *Подобрать время* - свободные окна у всех ваших учителей\n" +
+		"❤️ *Избранное* - учителя и предметы с уведомлениями о свободном времени\n" +
 		"🔍 *Найти учителя* - по коду приглашения или заявке\n" +
 		"📋 *Мои заявки* - статус ваших запросов на доступ"
 
@@ -347,6 +444,9 @@ func BuildSubjectCategoriesScreen() (string, *models.InlineKeyboardMarkup) {
 		InlineKeyboard: [][]models.InlineKeyboardButton{
 			{{Text: "🎓 Мои учителя", CallbackData: "my_teachers"}},
 			{{Text: "🌍 Публичные учителя", CallbackData: "public_teachers"}},
+			{{Text: "🔎 Поиск предметов", CallbackData: "subject_search"}},
+			{{Text: "🕐 Подобрать время", CallbackData: "find_time"}},
+			{{Text: "❤️ Избранное", CallbackData: "my_favorites"}},
 			{{Text: "🔍 Найти учителя", CallbackData: "find_teacher"}},
 			{{Text: "📋 Мои заявки", CallbackData: "my_requests"}},
 		},
@@ -354,3 +454,217 @@ func BuildSubjectCategoriesScreen() (string, *models.InlineKeyboardMarkup) {
 
 	return text, keyboard
 }
+
+// BuildSubjectPackagesScreen формирует экран пакетов занятий предмета для учителя
+func BuildSubjectPackagesScreen(subject *model.Subject, packages []*model.LessonPackage) (string, *models.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🎁 <b>Пакеты занятий: %s</b>\n\n", subject.Name)
+
+	if len(packages) == 0 {
+		text += "Пакетов пока нет.\n\n"
+	} else {
+		for i, pkg := range packages {
+			status := "✅"
+			if !pkg.IsActive {
+				status = "⏸"
+			}
+			text += fmt.Sprintf("%d. %s %s\n", i+1, status, formatting.FormatPackage(pkg))
+		}
+		text += "\n"
+	}
+
+	text += "Пакет — это несколько занятий по специальной цене со сроком действия. " +
+		"Начислить пакет студенту после оплаты можно в разделе «Мои студенты». " +
+		"Каждая запись списывает одно занятие из пакета."
+
+	var rows [][]models.InlineKeyboardButton
+	for i, pkg := range packages {
+		buttonText := fmt.Sprintf("⏸ Скрыть пакет %d", i+1)
+		if !pkg.IsActive {
+			buttonText = fmt.Sprintf("▶️ Вернуть пакет %d", i+1)
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: buttonText, CallbackData: fmt.Sprintf("toggle_package:%d", pkg.ID)},
+		})
+	}
+
+	rows = append(rows,
+		[]models.InlineKeyboardButton{{Text: "➕ Добавить пакет", CallbackData: fmt.Sprintf("add_package:%d", subject.ID)}},
+		[]models.InlineKeyboardButton{{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("edit_subject:%d", subject.ID)}},
+	)
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// BuildStudentLedgerScreen формирует экран взаиморасчётов учителя со студентом
+// Итоги показываются отдельно по каждой валюте
+func BuildStudentLedgerScreen(studentID int64, studentName string, balances []*model.LedgerBalance, entries []*model.LedgerEntry) (string, *models.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("💰 <b>Взаиморасчёты: %s</b>\n\n", studentName)
+	for _, balance := range balances {
+		if len(balances) > 1 {
+			text += fmt.Sprintf("<b>%s</b>\n", formatting.FormatCurrency(balance.Currency))
+		}
+		text += fmt.Sprintf("Начислено: %s\n", formatting.FormatPriceShort(balance.Charged, balance.Currency))
+		text += fmt.Sprintf("Оплачено: %s\n", formatting.FormatPriceShort(balance.Paid, balance.Currency))
+		text += fmt.Sprintf("Итог: %s\n\n", formatting.FormatLedgerBalance(balance))
+	}
+
+	if len(entries) == 0 {
+		text += "Операций пока нет. Проведённые занятия начисляются автоматически по цене предмета."
+	} else {
+		text += "<b>Последние операции:</b>\n"
+		for _, entry := range entries {
+			text += formatting.FormatLedgerEntry(entry) + "\n"
+		}
+	}
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "💵 Наличные", CallbackData: fmt.Sprintf("record_payment:%d:%s", studentID, model.LedgerPaymentCash)},
+				{Text: "🏦 Перевод", CallbackData: fmt.Sprintf("record_payment:%d:%s", studentID, model.LedgerPaymentTransfer)},
+			},
+			{{Text: "⬅️ Назад", CallbackData: "ledger_balances"}},
+		},
+	}
+
+	return text, keyboard
+}
+
+// exportPeriods периоды выгрузки слотов и записей: ключ в callback data и подпись кнопки
+var exportPeriods = []struct {
+	key   string
+	label string
+}{
+	{"prev_month", "Прошлый месяц"},
+	{"month", "Этот месяц"},
+	{"next_month", "Следующий месяц"},
+	{"quarter", "3 месяца"},
+}
+
+// BuildExportMenuScreen формирует экран выбора данных для выгрузки
+func BuildExportMenuScreen() (string, *models.InlineKeyboardMarkup) {
+	text := "📤 <b>Выгрузка данных</b>\n\n" +
+		"Выберите, что выгрузить. Файл придёт документом в формате CSV или XLSX — " +
+		"его можно открыть в Excel или Google Таблицах и передать бухгалтеру."
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "🗓 Слоты расписания", CallbackData: "export_dataset:" + string(model.ExportDatasetSlots)}},
+			{{Text: "📋 Записи студентов", CallbackData: "export_dataset:" + string(model.ExportDatasetBookings)}},
+			{{Text: "👥 Студенты", CallbackData: "export_dataset:" + string(model.ExportDatasetStudents)}},
+			{{Text: "🎟️ Коды приглашения", CallbackData: "export_dataset:" + string(model.ExportDatasetInviteCodes)}},
+			{{Text: "⬅️ Назад", CallbackData: "teacher_settings"}},
+		},
+	}
+
+	return text, keyboard
+}
+
+// BuildExportOptionsScreen формирует экран выбора периода и формата выгрузки
+func BuildExportOptionsScreen(dataset model.ExportDataset) (string, *models.InlineKeyboardMarkup) {
+	var title, description string
+	switch dataset {
+	case model.ExportDatasetSlots:
+		title = "🗓 Слоты расписания"
+		description = "Дата и время, предмет, статус слота, студент и комментарий."
+	case model.ExportDatasetBookings:
+		title = "📋 Записи студентов"
+		description = "Дата занятия, предмет, студент, статус записи, цена с валютой и промокод."
+	case model.ExportDatasetStudents:
+		title = "👥 Студенты"
+		description = "Все студенты с доступом: тип доступа, дата выдачи и срок действия."
+	case model.ExportDatasetInviteCodes:
+		title = "🎟️ Коды приглашения"
+		description = "Коды с датой создания, числом использований, лимитом и состоянием."
+	}
+
+	text := fmt.Sprintf("📤 <b>%s</b>\n\n%s\n\n", title, description)
+
+	var rows [][]models.InlineKeyboardButton
+	if dataset.HasPeriod() {
+		text += "Выберите период и формат файла:"
+		for _, period := range exportPeriods {
+			rows = append(rows, []models.InlineKeyboardButton{
+				{Text: period.label + " · CSV", CallbackData: fmt.Sprintf("export_file:%s:%s:%s", dataset, model.ExportFormatCSV, period.key)},
+				{Text: period.label + " · XLSX", CallbackData: fmt.Sprintf("export_file:%s:%s:%s", dataset, model.ExportFormatXLSX, period.key)},
+			})
+		}
+	} else {
+		text += "Выберите формат файла:"
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: "📄 CSV", CallbackData: fmt.Sprintf("export_file:%s:%s:all", dataset, model.ExportFormatCSV)},
+			{Text: "📊 XLSX", CallbackData: fmt.Sprintf("export_file:%s:%s:all", dataset, model.ExportFormatXLSX)},
+		})
+	}
+
+	rows = append(rows, []models.InlineKeyboardButton{{Text: "⬅️ Назад", CallbackData: "export_menu"}})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// BuildBroadcastMenuScreen формирует экран выбора получателей рассылки
+func BuildBroadcastMenuScreen() (string, *models.InlineKeyboardMarkup) {
+	text := "📣 <b>Рассылка студентам</b>\n\n" +
+		"Отправьте объявление, домашнее задание или файл сразу нескольким студентам. " +
+		"Перед отправкой бот покажет, как сообщение будет выглядеть.\n\n" +
+		"Кому отправить?"
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "👥 Всем студентам", CallbackData: "broadcast_audience:" + string(model.BroadcastAudienceAll)}},
+			{{Text: "📚 Студентам предмета", CallbackData: "broadcast_subjects"}},
+			{{Text: "📅 Записанным на период", CallbackData: "broadcast_periods"}},
+			{{Text: "🧑‍🤝‍🧑 Группе", CallbackData: "broadcast_groups"}},
+			{{Text: "✅ Выбрать вручную", CallbackData: "broadcast_pick"}},
+			{{Text: "⬅️ Назад", CallbackData: "view_my_students"}},
+		},
+	}
+
+	return text, keyboard
+}
+
+// BuildConversationsScreen формирует список переписок пользователя со студентами и учителями
+func BuildConversationsScreen(userID int64, conversations []*model.Conversation) (string, *models.InlineKeyboardMarkup) {
+	text := "💬 <b>Переписки</b>\n\n"
+	if len(conversations) == 0 {
+		text += "Переписок пока нет. Написать учителю или студенту можно из записи на занятие " +
+			"или из списка учителей и студентов — бот перешлёт сообщение, не раскрывая контакты."
+	} else {
+		text += "Сообщения пересылаются через бота. Чтобы ответить, ответьте на пересланное сообщение."
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, conversation := range conversations {
+		role := "учитель"
+		if conversation.TeacherID == userID {
+			role = "студент"
+		}
+
+		label := fmt.Sprintf("👤 %s (%s)", conversationPeerName(conversation, userID), role)
+		if conversation.IsMutedFor(userID) {
+			label += " 🔕"
+		}
+
+		rows = append(rows, []models.InlineKeyboardButton{{Text: label, CallbackData: fmt.Sprintf("view_chat:%d", conversation.ID)}})
+	}
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// FormatConversationMessage форматирует сообщение в истории переписки
+func FormatConversationMessage(message *model.ConversationMessage, userID int64, peerName string) string {
+	author := peerName
+	if message.SenderID == userID {
+		author = "Вы"
+	}
+
+	text := []rune(message.Text)
+	if len(text) > 200 {
+		text = append(text[:200], '…')
+	}
+
+	return fmt.Sprintf("<b>%s</b>, %s:\n%s\n",
+		html.EscapeString(author),
+		message.CreatedAt.Format("02.01 15:04"),
+		html.EscapeString(string(text)))
+}
@@ -0,0 +1,244 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Subject Search
+// ========================
+// Студент ищет предметы по тексту, фильтры переключаются кнопками под результатами.
+// Фильтр хранится в данных состояния StateSearchingTeacher
+
+const (
+	// SubjectSearchFilterKey ключ фильтра поиска в данных состояния
+	SubjectSearchFilterKey = "search_filter"
+	// subjectSearchPerPage сколько найденных предметов показывать на странице
+	subjectSearchPerPage = 5
+)
+
+// subjectSearchPricePreset диапазон цен фильтра поиска в основных единицах рубля
+type subjectSearchPricePreset struct {
+	Min, Max int
+	Label    string
+}
+
+// subjectSearchPricePresets переключаются по кругу кнопкой цены; первый — без ограничения
+var subjectSearchPricePresets = []subjectSearchPricePreset{
+	{Label: "💰 Цена: любая"},
+	{Max: 1000, Label: "💰 до 1000 ₽"},
+	{Min: 1000, Max: 2000, Label: "💰 1000–2000 ₽"},
+	{Min: 2000, Max: 3500, Label: "💰 2000–3500 ₽"},
+	{Min: 3500, Label: "💰 от 3500 ₽"},
+}
+
+// subjectSearchDurations переключаются по кругу кнопкой длительности; 0 — без ограничения
+var subjectSearchDurations = []int{0, 45, 60, 90}
+
+// NextSubjectSearchPrice переключает фильтр на следующий диапазон цен
+func NextSubjectSearchPrice(filter model.SubjectSearchFilter) model.SubjectSearchFilter {
+	next := subjectSearchPricePresets[(subjectSearchPriceIndex(filter)+1)%len(subjectSearchPricePresets)]
+
+	filter.Currency = ""
+	filter.MinPrice = next.Min * model.CurrencyRUB.MinorPerMajor()
+	filter.MaxPrice = next.Max * model.CurrencyRUB.MinorPerMajor()
+	if next.Min > 0 || next.Max > 0 {
+		filter.Currency = model.CurrencyRUB
+	}
+	return filter
+}
+
+// NextSubjectSearchDuration переключает фильтр на следующее ограничение длительности
+func NextSubjectSearchDuration(filter model.SubjectSearchFilter) model.SubjectSearchFilter {
+	current := 0
+	for i, duration := range subjectSearchDurations {
+		if duration == filter.MaxDuration {
+			current = i
+		}
+	}
+	filter.MaxDuration = subjectSearchDurations[(current+1)%len(subjectSearchDurations)]
+	return filter
+}
+
+// NextSubjectSearchCategory переключает фильтр на следующую категорию; после последней — любая
+func NextSubjectSearchCategory(filter model.SubjectSearchFilter) model.SubjectSearchFilter {
+	if filter.Category == "" {
+		filter.Category = model.SubjectCategories[0].Code
+		return filter
+	}
+	for i, category := range model.SubjectCategories {
+		if category.Code == filter.Category {
+			if i+1 < len(model.SubjectCategories) {
+				filter.Category = model.SubjectCategories[i+1].Code
+			} else {
+				filter.Category = ""
+			}
+			return filter
+		}
+	}
+	filter.Category = ""
+	return filter
+}
+
+// subjectSearchPriceIndex находит текущий диапазон цен фильтра
+func subjectSearchPriceIndex(filter model.SubjectSearchFilter) int {
+	for i, preset := range subjectSearchPricePresets {
+		if filter.MinPrice == preset.Min*model.CurrencyRUB.MinorPerMajor() && filter.MaxPrice == preset.Max*model.CurrencyRUB.MinorPerMajor() {
+			return i
+		}
+	}
+	return 0
+}
+
+// SubjectSearchFromState получает фильтр поиска из данных состояния студента
+func SubjectSearchFromState(h *callbacktypes.Handler, telegramID int64) (model.SubjectSearchFilter, bool) {
+	if h.StateManager.GetState(telegramID) != callbacktypes.UserState(state.StateSearchingTeacher) {
+		return model.SubjectSearchFilter{}, false
+	}
+	raw, ok := h.StateManager.GetData(telegramID, SubjectSearchFilterKey)
+	filter, okType := raw.(model.SubjectSearchFilter)
+	return filter, ok && okType && filter.Query != ""
+}
+
+// BuildSubjectSearchPrompt формирует приглашение ввести поисковый запрос
+func BuildSubjectSearchPrompt() (string, *models.InlineKeyboardMarkup) {
+	text := "🔎 <b>Поиск предметов</b>\n\n" +
+		"Напишите, что хотите изучать, например: <i>английский ЕГЭ</i>, <i>гитара для начинающих</i>, <i>python</i>.\n\n" +
+		"Ищу по названию, категории, тегам и описанию предметов публичных учителей и ваших учителей. " +
+		"Цену, длительность и наличие свободных окон можно уточнить фильтрами после поиска.\n\n" +
+		"Для выхода из поиска используйте /cancel"
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.BackButton("subjects_menu"))
+
+	return text, kb.Build()
+}
+
+// BuildSubjectSearchScreen формирует страницу результатов поиска с кнопками фильтров
+func BuildSubjectSearchScreen(filter model.SubjectSearchFilter, results []*model.SubjectSearchResult, ratings map[int64]*model.TeacherRating, page int) (string, *models.InlineKeyboardMarkup) {
+	totalPages := (len(results) + subjectSearchPerPage - 1) / subjectSearchPerPage
+	if page > totalPages {
+		page = totalPages
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	text := fmt.Sprintf("🔎 <b>Поиск: %s</b>\n", html.EscapeString(filter.Query))
+	if filter.Category != "" {
+		text += fmt.Sprintf("Категория: %s\n", formatting.FormatSubjectCategory(filter.Category))
+	}
+	text += "\n"
+
+	kb := keyboard.NewBuilder()
+
+	if len(results) == 0 {
+		text += "Ничего не нашлось. Попробуйте другие слова или ослабьте фильтры.\n\n"
+	} else {
+		text += fmt.Sprintf("Найдено предметов: %d\n\n", len(results))
+
+		start := (page - 1) * subjectSearchPerPage
+		end := start + subjectSearchPerPage
+		if end > len(results) {
+			end = len(results)
+		}
+
+		for i, result := range results[start:end] {
+			number := start + i + 1
+			subject := result.Subject
+
+			text += fmt.Sprintf("<b>%d. %s</b> — %s\n", number, html.EscapeString(subject.Name), html.EscapeString(result.TeacherName))
+			text += fmt.Sprintf("   %s · %s", formatting.FormatPriceShort(subject.Price, subject.Currency), formatting.FormatDuration(subject.Duration))
+			if category, ok := model.FindSubjectCategory(subject.Category); ok {
+				text += " · " + category.Emoji + " " + category.Title
+			}
+			text += "\n"
+			if rating := ratings[subject.TeacherID]; rating != nil {
+				text += fmt.Sprintf("   %s\n", formatting.FormatRating(rating))
+			}
+			if result.FreeSlots > 0 {
+				text += fmt.Sprintf("   🗓 Свободных окон на неделе: %d\n", result.FreeSlots)
+			} else {
+				text += "   🗓 На неделе свободных окон нет\n"
+			}
+			if len(subject.Tags) > 0 {
+				text += fmt.Sprintf("   🔖 %s\n", html.EscapeString(formatting.FormatSubjectTags(subject.Tags)))
+			}
+			text += "\n"
+
+			kb.Row(
+				keyboard.Button(fmt.Sprintf("📅 %d. Расписание", number), fmt.Sprintf("view_schedule_subject:%d", subject.ID)),
+				keyboard.Button("👤 Учитель", fmt.Sprintf("teacher_profile:%d", subject.TeacherID)),
+			)
+		}
+
+		if totalPages > 1 {
+			var paginationRow []models.InlineKeyboardButton
+			if page > 1 {
+				paginationRow = append(paginationRow, keyboard.Button("◀️ Назад", fmt.Sprintf("subject_search_page:%d", page-1)))
+			}
+			paginationRow = append(paginationRow, keyboard.Button(fmt.Sprintf("%d/%d", page, totalPages), "noop"))
+			if page < totalPages {
+				paginationRow = append(paginationRow, keyboard.Button("Вперёд ▶️", fmt.Sprintf("subject_search_page:%d", page+1)))
+			}
+			kb.AddRow(paginationRow)
+		}
+	}
+
+	text += "Отправьте новый запрос сообщением или уточните фильтрами."
+
+	durationLabel := "⏱ Любая длительность"
+	if filter.MaxDuration > 0 {
+		durationLabel = fmt.Sprintf("⏱ до %s", formatting.FormatDuration(filter.MaxDuration))
+	}
+	categoryLabel := "🏷 Все категории"
+	if category, ok := model.FindSubjectCategory(filter.Category); ok {
+		categoryLabel = category.Emoji + " " + category.Title
+	}
+	freeLabel := "🗓 Только со свободными окнами: нет"
+	if filter.HasFreeSlots {
+		freeLabel = "🗓 Только со свободными окнами: да"
+	}
+
+	kb.Row(
+		keyboard.Button(subjectSearchPricePresets[subjectSearchPriceIndex(filter)].Label, "subject_search_price"),
+		keyboard.Button(durationLabel, "subject_search_duration"),
+	)
+	kb.Row(keyboard.Button(categoryLabel, "subject_search_category"))
+	kb.Row(keyboard.Button(freeLabel, "subject_search_free"))
+	kb.Row(keyboard.BackButton("subjects_menu"))
+
+	return text, kb.Build()
+}
+
+// SearchSubjectsForStudent выполняет поиск и загружает рейтинги найденных учителей.
+// Без рейтингов результаты всё равно показываются
+func SearchSubjectsForStudent(ctx context.Context, access *service.StudentAccessService, reviews *service.ReviewService, logger *zap.Logger, studentID int64, filter model.SubjectSearchFilter) ([]*model.SubjectSearchResult, map[int64]*model.TeacherRating, error) {
+	results, err := access.SearchSubjects(ctx, studentID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teacherIDs := make([]int64, 0, len(results))
+	for _, result := range results {
+		teacherIDs = append(teacherIDs, result.Subject.TeacherID)
+	}
+
+	ratings, err := reviews.GetRatingsByTeacherIDs(ctx, teacherIDs)
+	if err != nil {
+		logger.Error("Failed to get teacher ratings for search", zap.Error(err))
+	}
+
+	return results, ratings, nil
+}
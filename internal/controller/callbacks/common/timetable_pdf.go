@@ -0,0 +1,669 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-pdf/fpdf"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Период PDF-расписания в callback-данных
+const (
+	TimetablePeriodWeek  = "w"
+	TimetablePeriodMonth = "m"
+
+	// TimetableMaxOffset на сколько недель или месяцев назад и вперёд можно выгрузить расписание
+	TimetableMaxOffset = 12
+)
+
+// Разметка страницы A4 в миллиметрах
+const (
+	timetablePageHeight   = 297.0
+	timetableMargin       = 15.0
+	timetableContentWidth = 180.0
+	timetableHeaderBottom = 42.0
+	timetableFooterHeight = 18.0
+	timetableLineHeight   = 4.6
+	timetableCellPadding  = 1.6
+	timetableDayHeight    = 8.0
+	timetableSwatchSize   = 3.2
+	timetableFooterLines  = 2
+)
+
+// Шрифты PDF-расписания в пунктах
+const (
+	timetableFontFamily    = "Libertinus"
+	timetableTitleFontSize = 16.0
+	timetableTextFontSize  = 10.0
+	timetableDayFontSize   = 11.0
+	timetableSmallFontSize = 8.0
+)
+
+// Цвета PDF-расписания
+var (
+	timetableDayFill    = color.RGBA{235, 237, 240, 255}
+	timetableRuleColor  = color.RGBA{200, 203, 207, 255}
+	timetableMutedColor = color.RGBA{110, 115, 120, 255}
+	timetableTextColor  = color.RGBA{40, 44, 48, 255}
+)
+
+// timetableColumn колонка таблицы занятий
+type timetableColumn struct {
+	title string
+	width float64
+}
+
+// TimetableLesson занятие в PDF-расписании
+type TimetableLesson struct {
+	Start, End time.Time
+	SubjectID  int64
+	Subject    string
+	With       string // студент в расписании учителя, учитель в расписании студента
+	Price      int
+	Currency   model.Currency
+	Note       string
+}
+
+// Timetable данные PDF-расписания
+type Timetable struct {
+	Title      string
+	From, To   time.Time // занятия в диапазоне [From, To)
+	Period     string    // TimetablePeriodWeek или TimetablePeriodMonth
+	WithHeader string    // заголовок колонки With: "Студент" или "Учитель"
+	Lessons    []TimetableLesson
+	Contacts   []string // контакты в нижнем колонтитуле
+}
+
+// TimetableRange возвращает границы недели или месяца со смещением offset от текущих
+func TimetableRange(period string, offset int, now time.Time) (time.Time, time.Time, error) {
+	if offset < -TimetableMaxOffset || offset > TimetableMaxOffset {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid offset")
+	}
+
+	switch period {
+	case TimetablePeriodWeek:
+		start := normalizeToWeekBounds(now).start.AddDate(0, 0, 7*offset)
+		return start, start.AddDate(0, 0, 7), nil
+	case TimetablePeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, offset, 0)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period")
+	}
+}
+
+// ParseTimetableCallback разбирает callback вида prefix:<w|m>:<смещение>
+func ParseTimetableCallback(data string) (string, int, error) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 {
+		return "", 0, fmt.Errorf("invalid callback data")
+	}
+
+	offset, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid offset")
+	}
+
+	return parts[1], offset, nil
+}
+
+// SendTimetablePDF генерирует PDF-расписание и отправляет его документом
+func SendTimetablePDF(ctx context.Context, b *bot.Bot, chatID int64, timetable Timetable) error {
+	data, err := GenerateTimetablePDF(timetable)
+	if err != nil {
+		return err
+	}
+
+	name := timetable.From.Format("2006-01-02")
+	caption := fmt.Sprintf("📄 Расписание на неделю %s – %s", timetable.From.Format("02.01"), timetable.To.AddDate(0, 0, -1).Format("02.01.2006"))
+	if timetable.Period == TimetablePeriodMonth {
+		name = timetable.From.Format("2006-01")
+		caption = fmt.Sprintf("📄 Расписание на %s %d", strings.ToLower(formatting.GetMonthName(timetable.From.Month())), timetable.From.Year())
+	}
+
+	_, err = b.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: chatID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("timetable_%s.pdf", name),
+			Data:     bytes.NewReader(data),
+		},
+		Caption: caption,
+	})
+	return err
+}
+
+// TeacherTimetable собирает расписание учителя. bookings должны содержать Slot, Subject и Student
+func TeacherTimetable(teacher *model.User, bookings []*model.Booking, period string, from, to time.Time) Timetable {
+	timetable := Timetable{
+		Title:      "Расписание занятий: " + userFullName(teacher),
+		From:       from,
+		To:         to,
+		Period:     period,
+		WithHeader: "Студент",
+		Contacts:   []string{"Учитель: " + userContact(teacher)},
+	}
+
+	for _, booking := range bookings {
+		timetable.Lessons = append(timetable.Lessons, timetableLesson(booking, userFullName(booking.Student)))
+	}
+
+	return timetable
+}
+
+// StudentTimetable собирает расписание студента у всех учителей. bookings должны содержать Slot, Subject и Teacher
+func StudentTimetable(student *model.User, bookings []*model.Booking, period string, from, to time.Time) Timetable {
+	timetable := Timetable{
+		Title:      "Расписание занятий: " + userFullName(student),
+		From:       from,
+		To:         to,
+		Period:     period,
+		WithHeader: "Учитель",
+	}
+
+	seenTeachers := make(map[int64]bool)
+	for _, booking := range bookings {
+		timetable.Lessons = append(timetable.Lessons, timetableLesson(booking, userFullName(booking.Teacher)))
+		if !seenTeachers[booking.TeacherID] {
+			seenTeachers[booking.TeacherID] = true
+			timetable.Contacts = append(timetable.Contacts, userContact(booking.Teacher))
+		}
+	}
+	if len(timetable.Contacts) > 0 {
+		timetable.Contacts[0] = "Учителя: " + timetable.Contacts[0]
+	}
+
+	return timetable
+}
+
+// timetableLesson переносит запись в строку расписания; примечание описывает неподтверждённые записи
+func timetableLesson(booking *model.Booking, with string) TimetableLesson {
+	lesson := TimetableLesson{
+		Start:     booking.Slot.StartTime,
+		End:       booking.Slot.EndTime,
+		SubjectID: booking.SubjectID,
+		Subject:   booking.Subject.Name,
+		With:      with,
+		Price:     booking.Price,
+		Currency:  booking.Currency,
+	}
+
+	switch {
+	case booking.CancellationRequested:
+		lesson.Note = "Запрошена отмена"
+	case booking.Status == model.BookingStatusPending:
+		lesson.Note = "Ждёт подтверждения"
+	case booking.Status == model.BookingStatusAwaitingPayment:
+		lesson.Note = "Ждёт оплаты"
+	case booking.Status == model.BookingStatusCompleted:
+		lesson.Note = "Проведено"
+	}
+
+	return lesson
+}
+
+// userFullName имя и фамилия пользователя
+func userFullName(user *model.User) string {
+	if user == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// userContact имя пользователя с Telegram-username, если он есть
+func userContact(user *model.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.Username != "" {
+		return fmt.Sprintf("%s (@%s)", userFullName(user), user.Username)
+	}
+	return userFullName(user)
+}
+
+// timetablePrice форматирует цену с кодом валюты: в шрифте Libertinus нет знаков рубля и тенге
+func timetablePrice(amount int, currency model.Currency) string {
+	if amount == 0 {
+		return "—"
+	}
+	return strings.TrimSuffix(formatting.FormatPriceShort(amount, currency), currency.Symbol()) + string(currency)
+}
+
+// timetableWriter раскладывает расписание по страницам PDF
+type timetableWriter struct {
+	pdf           *fpdf.Fpdf
+	timetable     Timetable
+	columns       []timetableColumn
+	subjectColors map[int64]color.RGBA
+	showColumns   bool // рисовать заголовок таблицы в шапке страницы
+}
+
+// GenerateTimetablePDF генерирует PDF-расписание на неделю или месяц: занятия по дням,
+// легенда предметов с суммами и контакты в колонтитуле. Длинные названия переносятся по строкам,
+// строки занятий не разрываются между страницами
+func GenerateTimetablePDF(timetable Timetable) ([]byte, error) {
+	lessons := append([]TimetableLesson(nil), timetable.Lessons...)
+	sort.SliceStable(lessons, func(i, j int) bool { return lessons[i].Start.Before(lessons[j].Start) })
+	timetable.Lessons = lessons
+
+	subjectNames := make(map[int64]string)
+	for _, lesson := range lessons {
+		subjectNames[lesson.SubjectID] = lesson.Subject
+	}
+	_, subjectColors := assignSubjectColors(subjectNames)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(timetableFontFamily, "", libertinusRegularFontData)
+	pdf.AddUTF8FontFromBytes(timetableFontFamily, "B", libertinusBoldFontData)
+	pdf.AddUTF8FontFromBytes(timetableFontFamily, "I", libertinusItalicFontData)
+	pdf.SetMargins(timetableMargin, timetableMargin, timetableMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetTitle(timetable.Title, true)
+	pdf.AliasNbPages("")
+
+	w := &timetableWriter{
+		pdf:       pdf,
+		timetable: timetable,
+		columns: []timetableColumn{
+			{"Время", 24},
+			{"Предмет", 52},
+			{timetable.WithHeader, 48},
+			{"Цена", 24},
+			{"Примечание", 32},
+		},
+		subjectColors: subjectColors,
+		showColumns:   len(lessons) > 0,
+	}
+	pdf.SetHeaderFunc(w.drawHeader)
+	pdf.SetFooterFunc(w.drawFooter)
+
+	pdf.AddPage()
+	w.drawDays()
+	w.showColumns = false
+	w.drawLegend(subjectNames)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bodyBottom нижняя граница области занятий над колонтитулом
+func (w *timetableWriter) bodyBottom() float64 {
+	return timetablePageHeight - timetableMargin - timetableFooterHeight
+}
+
+// ensureSpace переносит вывод на новую страницу, если height не помещается
+func (w *timetableWriter) ensureSpace(height float64) bool {
+	if w.pdf.GetY()+height <= w.bodyBottom() {
+		return false
+	}
+	w.pdf.AddPage()
+	return true
+}
+
+func (w *timetableWriter) setColor(c color.RGBA) {
+	w.pdf.SetTextColor(int(c.R), int(c.G), int(c.B))
+}
+
+func (w *timetableWriter) setFill(c color.RGBA) {
+	w.pdf.SetFillColor(int(c.R), int(c.G), int(c.B))
+}
+
+// drawHeader рисует заголовок, период и шапку таблицы на каждой странице
+func (w *timetableWriter) drawHeader() {
+	pdf := w.pdf
+	from := w.timetable.From
+	to := w.timetable.To.AddDate(0, 0, -1)
+
+	pdf.SetXY(timetableMargin, timetableMargin)
+	pdf.SetFont(timetableFontFamily, "B", timetableTitleFontSize)
+	w.setColor(timetableTextColor)
+	title := w.fitText(w.timetable.Title, timetableContentWidth)
+	pdf.CellFormat(timetableContentWidth, 8, title, "", 1, "L", false, 0, "")
+
+	period := fmt.Sprintf("Неделя %s – %s", from.Format("02.01.2006"), to.Format("02.01.2006"))
+	if w.timetable.Period == TimetablePeriodMonth {
+		period = fmt.Sprintf("%s %d", formatting.GetMonthName(from.Month()), from.Year())
+	}
+	pdf.SetFont(timetableFontFamily, "I", timetableTextFontSize+1)
+	w.setColor(timetableMutedColor)
+	pdf.CellFormat(timetableContentWidth, 6, period, "", 1, "L", false, 0, "")
+
+	y := timetableHeaderBottom - 6
+	if w.showColumns {
+		pdf.SetFont(timetableFontFamily, "B", timetableSmallFontSize+1)
+		x := timetableMargin
+		for _, column := range w.columns {
+			pdf.SetXY(x, y)
+			pdf.CellFormat(column.width, 5, column.title, "", 0, "L", false, 0, "")
+			x += column.width
+		}
+	}
+
+	pdf.SetDrawColor(int(timetableRuleColor.R), int(timetableRuleColor.G), int(timetableRuleColor.B))
+	pdf.SetLineWidth(0.4)
+	pdf.Line(timetableMargin, timetableHeaderBottom-0.5, timetableMargin+timetableContentWidth, timetableHeaderBottom-0.5)
+	pdf.SetXY(timetableMargin, timetableHeaderBottom+1)
+}
+
+// drawFooter рисует контакты и номер страницы
+func (w *timetableWriter) drawFooter() {
+	pdf := w.pdf
+	pdf.SetFont(timetableFontFamily, "I", timetableSmallFontSize)
+	w.setColor(timetableMutedColor)
+
+	y := timetablePageHeight - timetableMargin - timetableFooterHeight + 4
+	pdf.SetDrawColor(int(timetableRuleColor.R), int(timetableRuleColor.G), int(timetableRuleColor.B))
+	pdf.SetLineWidth(0.2)
+	pdf.Line(timetableMargin, y-2, timetableMargin+timetableContentWidth, y-2)
+
+	lines := w.wrapText(strings.Join(w.timetable.Contacts, " · "), timetableContentWidth)
+	if len(lines) > timetableFooterLines {
+		lines = lines[:timetableFooterLines]
+		lines[timetableFooterLines-1] = w.fitText(lines[timetableFooterLines-1]+"…", timetableContentWidth)
+	}
+	for _, line := range lines {
+		pdf.SetXY(timetableMargin, y)
+		pdf.CellFormat(timetableContentWidth, 3.8, line, "", 0, "L", false, 0, "")
+		y += 3.8
+	}
+
+	pdf.SetXY(timetableMargin, timetablePageHeight-timetableMargin-4)
+	pdf.CellFormat(timetableContentWidth/2, 4, "Сформировано "+time.Now().Format("02.01.2006 15:04"), "", 0, "L", false, 0, "")
+	pdf.CellFormat(timetableContentWidth/2, 4, fmt.Sprintf("Стр. %d из {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+}
+
+// drawDays рисует занятия по дням. На неделе показываются все дни, в месяце — только дни с занятиями
+func (w *timetableWriter) drawDays() {
+	byDay := make(map[string][]TimetableLesson)
+	for _, lesson := range w.timetable.Lessons {
+		key := lesson.Start.Format("2006-01-02")
+		byDay[key] = append(byDay[key], lesson)
+	}
+
+	if len(w.timetable.Lessons) == 0 {
+		w.pdf.SetFont(timetableFontFamily, "I", timetableTextFontSize+1)
+		w.setColor(timetableMutedColor)
+		w.pdf.CellFormat(timetableContentWidth, 10, "За этот период занятий нет", "", 1, "L", false, 0, "")
+		return
+	}
+
+	for day := w.timetable.From; day.Before(w.timetable.To); day = day.AddDate(0, 0, 1) {
+		lessons := byDay[day.Format("2006-01-02")]
+		if len(lessons) == 0 && w.timetable.Period == TimetablePeriodMonth {
+			continue
+		}
+		w.drawDay(day, lessons)
+	}
+}
+
+// drawDay рисует заголовок дня и его занятия; при переносе на новую страницу заголовок дня повторяется
+func (w *timetableWriter) drawDay(day time.Time, lessons []TimetableLesson) {
+	firstRowHeight := timetableLineHeight + 2*timetableCellPadding
+	if len(lessons) > 0 {
+		firstRowHeight = w.rowHeight(lessons[0])
+	}
+	w.ensureSpace(timetableDayHeight + firstRowHeight)
+	w.drawDayHeading(day, len(lessons), false)
+
+	if len(lessons) == 0 {
+		w.pdf.SetFont(timetableFontFamily, "I", timetableTextFontSize)
+		w.setColor(timetableMutedColor)
+		w.pdf.SetX(timetableMargin)
+		w.pdf.CellFormat(timetableContentWidth, firstRowHeight, "Занятий нет", "", 1, "L", false, 0, "")
+		return
+	}
+
+	for _, lesson := range lessons {
+		if w.ensureSpace(w.rowHeight(lesson)) {
+			w.drawDayHeading(day, len(lessons), true)
+		}
+		w.drawLesson(lesson)
+	}
+}
+
+// drawDayHeading рисует полосу с датой и числом занятий
+func (w *timetableWriter) drawDayHeading(day time.Time, count int, continued bool) {
+	pdf := w.pdf
+	y := pdf.GetY() + 1
+	w.setFill(timetableDayFill)
+	pdf.Rect(timetableMargin, y, timetableContentWidth, timetableDayHeight-1.5, "F")
+
+	title := fmt.Sprintf("%s, %s", formatting.GetWeekdayName(int(day.Weekday())), day.Format("02.01"))
+	if continued {
+		title += " (продолжение)"
+	}
+	pdf.SetFont(timetableFontFamily, "B", timetableDayFontSize)
+	w.setColor(timetableTextColor)
+	pdf.SetXY(timetableMargin+2, y)
+	pdf.CellFormat(timetableContentWidth/2, timetableDayHeight-1.5, title, "", 0, "L", false, 0, "")
+
+	if count > 0 {
+		pdf.SetFont(timetableFontFamily, "", timetableSmallFontSize+1)
+		w.setColor(timetableMutedColor)
+		pdf.CellFormat(timetableContentWidth/2-4, timetableDayHeight-1.5, fmt.Sprintf("%d %s", count, formatting.PluralizeLessons(count)), "", 0, "R", false, 0, "")
+	}
+
+	pdf.SetXY(timetableMargin, y+timetableDayHeight)
+}
+
+// lessonCells тексты колонок строки занятия
+func (w *timetableWriter) lessonCells(lesson TimetableLesson) []string {
+	return []string{
+		lesson.Start.Format("15:04") + "–" + lesson.End.Format("15:04"),
+		lesson.Subject,
+		lesson.With,
+		timetablePrice(lesson.Price, lesson.Currency),
+		lesson.Note,
+	}
+}
+
+// cellWidth ширина текста в колонке: в колонке предмета место занимает цветная метка
+func (w *timetableWriter) cellWidth(index int) float64 {
+	if index == 1 {
+		return w.columns[index].width - timetableSwatchSize - 1.5
+	}
+	return w.columns[index].width
+}
+
+// wrapCells разбивает тексты колонок на строки по ширине колонок
+func (w *timetableWriter) wrapCells(lesson TimetableLesson) [][]string {
+	w.pdf.SetFont(timetableFontFamily, "", timetableTextFontSize)
+	cells := w.lessonCells(lesson)
+	wrapped := make([][]string, len(cells))
+	for i, text := range cells {
+		wrapped[i] = w.wrapText(text, w.cellWidth(i))
+	}
+	return wrapped
+}
+
+// rowHeight высота строки занятия по самой длинной колонке
+func (w *timetableWriter) rowHeight(lesson TimetableLesson) float64 {
+	lines := 1
+	for _, cell := range w.wrapCells(lesson) {
+		if len(cell) > lines {
+			lines = len(cell)
+		}
+	}
+	return float64(lines)*timetableLineHeight + 2*timetableCellPadding
+}
+
+// drawLesson рисует строку занятия с переносом длинных текстов
+func (w *timetableWriter) drawLesson(lesson TimetableLesson) {
+	pdf := w.pdf
+	wrapped := w.wrapCells(lesson)
+	height := w.rowHeight(lesson)
+	y := pdf.GetY()
+
+	x := timetableMargin
+	for i, lines := range wrapped {
+		textX := x
+		switch i {
+		case 0:
+			pdf.SetFont(timetableFontFamily, "B", timetableTextFontSize)
+			w.setColor(timetableTextColor)
+		case 1:
+			w.setFill(w.subjectColors[lesson.SubjectID])
+			pdf.Rect(x+0.8, y+timetableCellPadding+0.6, timetableSwatchSize, timetableSwatchSize, "F")
+			textX += timetableSwatchSize + 1.5
+			pdf.SetFont(timetableFontFamily, "", timetableTextFontSize)
+			w.setColor(timetableTextColor)
+		case 4:
+			pdf.SetFont(timetableFontFamily, "I", timetableTextFontSize)
+			w.setColor(timetableMutedColor)
+		default:
+			pdf.SetFont(timetableFontFamily, "", timetableTextFontSize)
+			w.setColor(timetableTextColor)
+		}
+
+		for n, line := range lines {
+			pdf.SetXY(textX, y+timetableCellPadding+float64(n)*timetableLineHeight)
+			pdf.CellFormat(w.cellWidth(i), timetableLineHeight, line, "", 0, "L", false, 0, "")
+		}
+		x += w.columns[i].width
+	}
+
+	pdf.SetDrawColor(int(timetableRuleColor.R), int(timetableRuleColor.G), int(timetableRuleColor.B))
+	pdf.SetLineWidth(0.2)
+	pdf.Line(timetableMargin, y+height, timetableMargin+timetableContentWidth, y+height)
+	pdf.SetXY(timetableMargin, y+height)
+}
+
+// drawLegend рисует предметы с цветом, числом занятий и суммой, затем общий итог
+func (w *timetableWriter) drawLegend(subjectNames map[int64]string) {
+	if len(w.timetable.Lessons) == 0 {
+		return
+	}
+
+	pdf := w.pdf
+	counts := make(map[int64]int)
+	sums := make(map[int64]map[model.Currency]int)
+	totals := make(map[model.Currency]int)
+	for _, lesson := range w.timetable.Lessons {
+		counts[lesson.SubjectID]++
+		if sums[lesson.SubjectID] == nil {
+			sums[lesson.SubjectID] = make(map[model.Currency]int)
+		}
+		sums[lesson.SubjectID][lesson.Currency] += lesson.Price
+		totals[lesson.Currency] += lesson.Price
+	}
+	subjectIDs, _ := assignSubjectColors(subjectNames)
+
+	pdf.SetY(pdf.GetY() + 6)
+	w.ensureSpace(8 + timetableLineHeight + 2*timetableCellPadding)
+	pdf.SetFont(timetableFontFamily, "B", timetableDayFontSize)
+	w.setColor(timetableTextColor)
+	pdf.SetX(timetableMargin)
+	pdf.CellFormat(timetableContentWidth, 8, "Предметы", "", 1, "L", false, 0, "")
+
+	nameWidth := 110.0 - timetableSwatchSize - 1.5
+	for _, id := range subjectIDs {
+		pdf.SetFont(timetableFontFamily, "", timetableTextFontSize)
+		lines := w.wrapText(subjectNames[id], nameWidth)
+		height := float64(len(lines))*timetableLineHeight + 2*timetableCellPadding
+		w.ensureSpace(height)
+
+		y := pdf.GetY()
+		w.setFill(w.subjectColors[id])
+		pdf.Rect(timetableMargin+0.8, y+timetableCellPadding+0.6, timetableSwatchSize, timetableSwatchSize, "F")
+		w.setColor(timetableTextColor)
+		for n, line := range lines {
+			pdf.SetXY(timetableMargin+timetableSwatchSize+2.3, y+timetableCellPadding+float64(n)*timetableLineHeight)
+			pdf.CellFormat(nameWidth, timetableLineHeight, line, "", 0, "L", false, 0, "")
+		}
+
+		summary := fmt.Sprintf("%d %s · %s", counts[id], formatting.PluralizeLessons(counts[id]), formatTimetableSums(sums[id]))
+		pdf.SetXY(timetableMargin+110, y+timetableCellPadding)
+		pdf.CellFormat(timetableContentWidth-110, timetableLineHeight, summary, "", 0, "R", false, 0, "")
+		pdf.SetXY(timetableMargin, y+height)
+	}
+
+	w.ensureSpace(10)
+	count := len(w.timetable.Lessons)
+	pdf.SetY(pdf.GetY() + 2)
+	pdf.SetFont(timetableFontFamily, "B", timetableTextFontSize)
+	pdf.SetX(timetableMargin)
+	pdf.CellFormat(timetableContentWidth, 6, fmt.Sprintf("Итого: %d %s · %s", count, formatting.PluralizeLessons(count), formatTimetableSums(totals)), "", 1, "R", false, 0, "")
+}
+
+// formatTimetableSums форматирует суммы по валютам: "12000 RUB + 50 EUR"
+func formatTimetableSums(sums map[model.Currency]int) string {
+	var parts []string
+	for _, currency := range model.SupportedCurrencies() {
+		if sums[currency] > 0 {
+			parts = append(parts, timetablePrice(sums[currency], currency))
+		}
+	}
+	if len(parts) == 0 {
+		return "без оплаты"
+	}
+	return strings.Join(parts, " + ")
+}
+
+// wrapText разбивает текст текущего шрифта на строки не шире width. Переносит по пробелам и
+// после дефисов, а слово, которое не помещается целиком, режет по символам
+func (w *timetableWriter) wrapText(text string, width float64) []string {
+	var chunks []string
+	for _, word := range strings.Fields(text) {
+		for {
+			hyphen := strings.Index(word, "-")
+			if hyphen <= 0 || hyphen == len(word)-1 {
+				break
+			}
+			chunks = append(chunks, word[:hyphen+1])
+			word = word[hyphen+1:]
+		}
+		chunks = append(chunks, word+" ")
+	}
+
+	var lines []string
+	line := ""
+	for _, chunk := range chunks {
+		if w.pdf.GetStringWidth(strings.TrimSpace(line+chunk)) <= width {
+			line += chunk
+			continue
+		}
+		if line != "" {
+			lines = append(lines, strings.TrimSpace(line))
+			line = ""
+		}
+		for w.pdf.GetStringWidth(strings.TrimSpace(chunk)) > width {
+			runes := []rune(chunk)
+			cut := len(runes) - 1
+			for cut > 1 && w.pdf.GetStringWidth(string(runes[:cut])) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			chunk = string(runes[cut:])
+		}
+		line = chunk
+	}
+	if strings.TrimSpace(line) != "" || len(lines) == 0 {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	return lines
+}
+
+// fitText обрезает строку текущего шрифта до ширины width
+func (w *timetableWriter) fitText(text string, width float64) string {
+	if w.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 1 && w.pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
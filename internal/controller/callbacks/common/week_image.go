@@ -4,6 +4,7 @@ import (
 	"bytes"
 	_ "embed"
 	"image/color"
+	"sort"
 	"strconv"
 	"time"
 
@@ -74,8 +75,54 @@ var (
 
 	legendTextColor = color.RGBA{90, 95, 100, 220}
 	legendItemColor = color.RGBA{70, 74, 78, 220}
+
+	// Изображение недели студента: цвет слота — предмет, оформление — статус записи
+	lessonStatusColor  = color.RGBA{150, 155, 160, 255}
+	cancelRequestColor = color.RGBA{200, 60, 60, 200}
+	subjectPalette     = []color.RGBA{
+		{100, 160, 230, 255},
+		{133, 193, 85, 255},
+		{245, 170, 80, 255},
+		{180, 130, 220, 255},
+		{90, 200, 190, 255},
+		{240, 120, 150, 255},
+		{210, 190, 80, 255},
+		{140, 150, 170, 255},
+	}
+)
+
+// Ограничения подписей
+const (
+	slotLabelMaxLen   = 20
+	legendLabelMaxLen = 14
+)
+
+// slotStyle оформление слота, по которому различаются статусы записей
+type slotStyle int
+
+const (
+	slotStyleSolid   slotStyle = iota // обычная заливка
+	slotStyleDashed                   // бледная заливка и пунктирная рамка: ожидает подтверждения или оплаты
+	slotStyleHatched                  // бледная заливка со штриховкой: запрошена отмена
 )
 
+// weekImageItem занятие или слот, нарисованный на изображении недели
+type weekImageItem struct {
+	start, end time.Time
+	fill       color.RGBA
+	border     color.RGBA
+	text       color.RGBA
+	label      string
+	style      slotStyle
+}
+
+// legendEntry строка легенды
+type legendEntry struct {
+	label string
+	fill  color.RGBA
+	style slotStyle
+}
+
 // weekBounds содержит границы недели
 type weekBounds struct {
 	start time.Time
@@ -171,12 +218,135 @@ func loadFont(dc *gg.Context, size float64, style ...FontStyle) {
 // GenerateWeekImage генерирует изображение недели с отображением слотов
 // studentNames - map studentID -> имя студента для отображения на слотах
 func GenerateWeekImage(startDate, endDate time.Time, slots []*model.ScheduleSlot, subjectID int64, studentNames map[int64]string) ([]byte, error) {
+	var items []weekImageItem
+	for _, slot := range slots {
+		if slot.SubjectID == subjectID {
+			items = append(items, teacherSlotItem(slot, studentNames))
+		}
+	}
+
+	legend := []legendEntry{
+		{label: "Свободно", fill: slotFreeColor},
+		{label: "Забронировано", fill: slotBookedColor},
+		{label: "Отменено", fill: slotCanceledColor},
+	}
+
+	return renderWeekImage(startDate, items, legend)
+}
+
+// GenerateStudentWeekImage генерирует изображение недели студента со всеми его занятиями у разных учителей.
+// Цвет слота обозначает предмет, подпись — учителя; ожидающие подтверждения записи и записи
+// с запрошенной отменой оформлены иначе, чем подтверждённые.
+// bookings должны содержать Slot, Subject и Teacher
+func GenerateStudentWeekImage(startDate time.Time, bookings []*model.Booking) ([]byte, error) {
+	subjectNames := make(map[int64]string)
+	for _, booking := range bookings {
+		subjectNames[booking.SubjectID] = booking.Subject.Name
+	}
+	subjectIDs, subjectColors := assignSubjectColors(subjectNames)
+
+	var legend []legendEntry
+	for _, id := range subjectIDs {
+		legend = append(legend, legendEntry{label: truncateLabel(subjectNames[id], legendLabelMaxLen), fill: subjectColors[id]})
+	}
+	legend = append(legend,
+		legendEntry{label: "Подтверждено", fill: lessonStatusColor},
+		legendEntry{label: "Ожидает", fill: lightenColor(lessonStatusColor, 0.6), style: slotStyleDashed},
+		legendEntry{label: "Отмена?", fill: lightenColor(lessonStatusColor, 0.6), style: slotStyleHatched},
+	)
+
+	items := make([]weekImageItem, 0, len(bookings))
+	for _, booking := range bookings {
+		items = append(items, studentLessonItem(booking, subjectColors[booking.SubjectID]))
+	}
+
+	return renderWeekImage(startDate, items, legend)
+}
+
+// assignSubjectColors раздаёт цвета палитры по возрастанию ID предмета, чтобы на одной картинке
+// они не повторялись. Возвращает отсортированные ID и цвета предметов
+func assignSubjectColors(subjectNames map[int64]string) ([]int64, map[int64]color.RGBA) {
+	subjectIDs := make([]int64, 0, len(subjectNames))
+	for id := range subjectNames {
+		subjectIDs = append(subjectIDs, id)
+	}
+	sort.Slice(subjectIDs, func(i, j int) bool { return subjectIDs[i] < subjectIDs[j] })
+
+	subjectColors := make(map[int64]color.RGBA, len(subjectIDs))
+	for i, id := range subjectIDs {
+		subjectColors[id] = subjectPalette[i%len(subjectPalette)]
+	}
+
+	return subjectIDs, subjectColors
+}
+
+// teacherSlotItem оформляет слот расписания учителя: цвет по статусу слота, подпись — комментарий или студент
+func teacherSlotItem(slot *model.ScheduleSlot, studentNames map[int64]string) weekImageItem {
+	fillColor := getSlotColor(slot.Status)
+
+	// Выбираем цвет текста в зависимости от статуса слота
+	textColor := slotTextColor
+	if slot.Status == model.SlotStatusBooked {
+		textColor = slotBookedTextColor
+	}
+
+	// Добавляем комментарий или имя студента, если есть
+	label := ""
+	if slot.Comment != nil && *slot.Comment != "" {
+		// Приоритет комментарию
+		label = *slot.Comment
+	} else if slot.StudentID != nil && slot.Status == model.SlotStatusBooked {
+		// Если нет комментария, но есть студент
+		label = studentNames[*slot.StudentID]
+	}
+
+	return weekImageItem{
+		start:  slot.StartTime,
+		end:    slot.EndTime,
+		fill:   fillColor,
+		border: darkenColor(fillColor, 0.8),
+		text:   textColor,
+		label:  label,
+	}
+}
+
+// studentLessonItem оформляет занятие студента: цвет предмета, подпись — учитель, оформление — статус записи
+func studentLessonItem(booking *model.Booking, subjectColor color.RGBA) weekImageItem {
+	item := weekImageItem{
+		start:  booking.Slot.StartTime,
+		end:    booking.Slot.EndTime,
+		fill:   subjectColor,
+		border: darkenColor(subjectColor, 0.8),
+		text:   slotTextColor,
+	}
+
+	if booking.Teacher != nil {
+		item.label = booking.Teacher.FirstName
+		if booking.Teacher.LastName != "" {
+			item.label += " " + string([]rune(booking.Teacher.LastName)[0]) + "."
+		}
+	}
+
+	switch {
+	case booking.CancellationRequested:
+		item.fill = lightenColor(subjectColor, 0.6)
+		item.style = slotStyleHatched
+	case booking.Status == model.BookingStatusPending || booking.Status == model.BookingStatusAwaitingPayment:
+		item.fill = lightenColor(subjectColor, 0.6)
+		item.style = slotStyleDashed
+	}
+
+	return item
+}
+
+// renderWeekImage рисует неделю, в которую попадает startDate, с занятиями и легендой
+func renderWeekImage(startDate time.Time, items []weekImageItem, legend []legendEntry) ([]byte, error) {
 	week := normalizeToWeekBounds(startDate)
 	today := normalizeToDay(time.Now())
 	shouldHighlightToday := isTodayInWeek(today, week)
 
-	slotsByDay := groupSlotsByDay(slots, subjectID)
-	hours := calculateHourRange(slots, subjectID)
+	itemsByDay := groupItemsByDay(items)
+	hours := calculateHourRange(items)
 
 	dc := createCanvas()
 	dayWidth := (imageWidth - leftLabelsWidth - legendWidth) / totalDaysInWeek
@@ -185,9 +355,9 @@ func GenerateWeekImage(startDate, endDate time.Time, slots []*model.ScheduleSlot
 
 	drawHeader(dc, week)
 	drawHourLabels(dc, hours, cellHeight)
-	drawDaysAndSlots(dc, week, today, shouldHighlightToday, slotsByDay, hours, dayWidth, dayHeight, cellHeight, studentNames)
+	drawDaysAndSlots(dc, week, today, shouldHighlightToday, itemsByDay, hours, dayWidth, dayHeight, cellHeight)
 	drawCurrentTimeLine(dc, shouldHighlightToday, hours, cellHeight, dayWidth)
-	drawLegend(dc, dayWidth)
+	drawLegend(dc, dayWidth, legend)
 
 	return encodeImage(dc)
 }
@@ -217,36 +387,32 @@ func isTodayInWeek(today time.Time, week weekBounds) bool {
 	return !today.Before(week.start) && !today.After(week.end)
 }
 
-// groupSlotsByDay группирует слоты по дням
-func groupSlotsByDay(slots []*model.ScheduleSlot, subjectID int64) map[string][]*model.ScheduleSlot {
-	slotsByDay := make(map[string][]*model.ScheduleSlot)
-	for _, slot := range slots {
-		if slot.SubjectID == subjectID {
-			dateKey := slot.StartTime.Format("2006-01-02")
-			slotsByDay[dateKey] = append(slotsByDay[dateKey], slot)
-		}
+// groupItemsByDay группирует слоты по дням
+func groupItemsByDay(items []weekImageItem) map[string][]weekImageItem {
+	itemsByDay := make(map[string][]weekImageItem)
+	for _, item := range items {
+		dateKey := item.start.Format("2006-01-02")
+		itemsByDay[dateKey] = append(itemsByDay[dateKey], item)
 	}
-	return slotsByDay
+	return itemsByDay
 }
 
 // calculateHourRange определяет диапазон часов для отображения
-func calculateHourRange(slots []*model.ScheduleSlot, subjectID int64) hourRange {
+func calculateHourRange(items []weekImageItem) hourRange {
 	minHour := 24
 	maxHour := 0
 
-	for _, slot := range slots {
-		if slot.SubjectID == subjectID {
-			startH := slot.StartTime.Hour()
-			endH := slot.EndTime.Hour()
-			if slot.EndTime.Minute() > 0 {
-				endH++
-			}
-			if startH < minHour {
-				minHour = startH
-			}
-			if endH > maxHour {
-				maxHour = endH
-			}
+	for _, item := range items {
+		startH := item.start.Hour()
+		endH := item.end.Hour()
+		if item.end.Minute() > 0 {
+			endH++
+		}
+		if startH < minHour {
+			minHour = startH
+		}
+		if endH > maxHour {
+			maxHour = endH
 		}
 	}
 
@@ -312,7 +478,7 @@ func drawHourLabels(dc *gg.Context, hours hourRange, cellHeight float64) {
 
 // drawDaysAndSlots рисует все дни недели со слотами
 func drawDaysAndSlots(dc *gg.Context, week weekBounds, today time.Time, shouldHighlightToday bool,
-	slotsByDay map[string][]*model.ScheduleSlot, hours hourRange, dayWidth, dayHeight int, cellHeight float64, studentNames map[int64]string) {
+	itemsByDay map[string][]weekImageItem, hours hourRange, dayWidth, dayHeight int, cellHeight float64) {
 
 	currentDate := week.start
 
@@ -325,7 +491,7 @@ func drawDaysAndSlots(dc *gg.Context, week weekBounds, today time.Time, shouldHi
 		drawDayBackground(dc, x, y, dayWidth, dayHeight, dayIndex, isToday)
 		drawDayHeader(dc, currentDate, x, y, dayWidth)
 		drawHourLines(dc, x, y, dayWidth, hours, cellHeight)
-		drawSlotsForDay(dc, currentDate, slotsByDay, x, y, dayWidth, hours, cellHeight, studentNames)
+		drawSlotsForDay(dc, currentDate, itemsByDay, x, y, dayWidth, hours, cellHeight)
 
 		currentDate = currentDate.AddDate(0, 0, 1)
 	}
@@ -375,19 +541,19 @@ func drawHourLines(dc *gg.Context, x, y float64, dayWidth int, hours hourRange,
 }
 
 // drawSlotsForDay рисует все слоты для указанного дня
-func drawSlotsForDay(dc *gg.Context, date time.Time, slotsByDay map[string][]*model.ScheduleSlot,
-	x, y float64, dayWidth int, hours hourRange, cellHeight float64, studentNames map[int64]string) {
+func drawSlotsForDay(dc *gg.Context, date time.Time, itemsByDay map[string][]weekImageItem,
+	x, y float64, dayWidth int, hours hourRange, cellHeight float64) {
 
 	dateKey := date.Format("2006-01-02")
-	for _, slot := range slotsByDay[dateKey] {
-		drawSlot(dc, slot, x, y, dayWidth, hours, cellHeight, studentNames)
+	for _, item := range itemsByDay[dateKey] {
+		drawSlot(dc, item, x, y, dayWidth, hours, cellHeight)
 	}
 }
 
 // drawSlot рисует один слот
-func drawSlot(dc *gg.Context, slot *model.ScheduleSlot, x, y float64, dayWidth int, hours hourRange, cellHeight float64, studentNames map[int64]string) {
-	slotStartHour := float64(slot.StartTime.Hour()) + float64(slot.StartTime.Minute())/60.0
-	slotEndHour := float64(slot.EndTime.Hour()) + float64(slot.EndTime.Minute())/60.0
+func drawSlot(dc *gg.Context, item weekImageItem, x, y float64, dayWidth int, hours hourRange, cellHeight float64) {
+	slotStartHour := float64(item.start.Hour()) + float64(item.start.Minute())/60.0
+	slotEndHour := float64(item.end.Hour()) + float64(item.end.Minute())/60.0
 
 	slotY := y + (slotStartHour-float64(hours.start))*cellHeight
 	slotHeight := (slotEndHour - slotStartHour) * cellHeight
@@ -395,66 +561,85 @@ func drawSlot(dc *gg.Context, slot *model.ScheduleSlot, x, y float64, dayWidth i
 		slotHeight = minSlotHeight
 	}
 
-	fillColor := getSlotColor(slot.Status)
 	slotWidth := float64(dayWidth) - float64(dayPaddingX*2)
+	slotX := x + float64(dayPaddingX)
 
 	// Тень
 	dc.SetColor(slotShadowColor)
-	dc.DrawRoundedRectangle(x+dayPaddingX+shadowOffset, slotY+2+shadowOffset, slotWidth, slotHeight-4, slotBorderRadius)
+	dc.DrawRoundedRectangle(slotX+shadowOffset, slotY+2+shadowOffset, slotWidth, slotHeight-4, slotBorderRadius)
 	dc.Fill()
 
 	// Основной слот
-	dc.SetColor(fillColor)
-	dc.DrawRoundedRectangle(x+float64(dayPaddingX), slotY+2, slotWidth, slotHeight-4, slotBorderRadius)
+	dc.SetColor(item.fill)
+	dc.DrawRoundedRectangle(slotX, slotY+2, slotWidth, slotHeight-4, slotBorderRadius)
 	dc.Fill()
 
-	// Рамка
-	borderColor := darkenColor(fillColor, 0.8)
-	dc.SetColor(borderColor)
-	dc.SetLineWidth(1)
-	dc.DrawRoundedRectangle(x+float64(dayPaddingX), slotY+2, slotWidth, slotHeight-4, slotBorderRadius)
-	dc.Stroke()
-
-	// Выбираем цвет текста в зависимости от статуса слота
-	textColor := slotTextColor
-	if slot.Status == model.SlotStatusBooked {
-		textColor = slotBookedTextColor
+	// Рамка и оформление статуса
+	drawStyleDecoration(dc, item.style, slotX, slotY+2, slotWidth, slotHeight-4, slotBorderRadius, item.border)
+	if item.style == slotStyleSolid {
+		dc.SetColor(item.border)
+		dc.SetLineWidth(1)
+		dc.DrawRoundedRectangle(slotX, slotY+2, slotWidth, slotHeight-4, slotBorderRadius)
+		dc.Stroke()
 	}
 
 	// Текст времени
 	loadFont(dc, slotTimeFontSize, FontStyleMedium)
-	dc.SetColor(textColor)
-	txtX := x + float64(dayPaddingX) + 8
+	dc.SetColor(item.text)
+	txtX := slotX + 8
 	txtY := slotY + 8 + 10
-	timeText := slot.StartTime.Format("15:04")
+	timeText := item.start.Format("15:04")
 	dc.DrawStringAnchored(timeText, txtX, txtY, 0, 0)
 
-	// Добавляем комментарий или имя студента, если есть
-	additionalText := ""
-	if slot.Comment != nil && *slot.Comment != "" {
-		// Приоритет комментарию
-		additionalText = *slot.Comment
-	} else if slot.StudentID != nil && slot.Status == model.SlotStatusBooked {
-		// Если нет комментария, но есть студент
-		if name, ok := studentNames[*slot.StudentID]; ok && name != "" {
-			additionalText = name
-		}
+	// Отображаем подпись (комментарий или имя) если есть место
+	if item.label != "" && slotHeight > 25 {
+		// Используем меньший шрифт для дополнительного текста
+		loadFont(dc, slotTimeFontSize-2, FontStyleMedium)
+		dc.SetColor(item.text)
+		dc.DrawStringAnchored(truncateLabel(item.label, slotLabelMaxLen), txtX, txtY+16, 0, 0)
 	}
+}
 
-	// Отображаем дополнительный текст (комментарий или имя) если есть место
-	if additionalText != "" && slotHeight > 25 {
-		// Ограничиваем длину текста для отображения
-		maxLen := 20
-		if len(additionalText) > maxLen {
-			additionalText = additionalText[:maxLen-3] + "..."
+// drawStyleDecoration дорисовывает оформление статуса поверх залитого прямоугольника:
+// пунктирную рамку или штриховку с рамкой цвета запроса отмены
+func drawStyleDecoration(dc *gg.Context, style slotStyle, x, y, w, h, radius float64, border color.RGBA) {
+	switch style {
+	case slotStyleDashed:
+		dc.SetColor(border)
+		dc.SetLineWidth(2)
+		dc.SetDash(6, 4)
+		dc.DrawRoundedRectangle(x, y, w, h, radius)
+		dc.Stroke()
+		dc.SetDash()
+	case slotStyleHatched:
+		dc.Push()
+		dc.DrawRoundedRectangle(x, y, w, h, radius)
+		dc.Clip()
+		dc.SetColor(cancelRequestColor)
+		dc.SetLineWidth(1.5)
+		for offset := -h; offset < w; offset += 10 {
+			dc.DrawLine(x+offset, y+h, x+offset+h, y)
+			dc.Stroke()
 		}
-		// Используем меньший шрифт для дополнительного текста
-		loadFont(dc, slotTimeFontSize-2, FontStyleMedium)
-		dc.SetColor(textColor)
-		dc.DrawStringAnchored(additionalText, txtX, txtY+16, 0, 0)
+		dc.ResetClip()
+		dc.Pop()
+
+		dc.SetColor(cancelRequestColor)
+		dc.SetLineWidth(1.5)
+		dc.DrawRoundedRectangle(x, y, w, h, radius)
+		dc.Stroke()
 	}
 }
 
+// truncateLabel обрезает подпись до maxLen символов
+func truncateLabel(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
 // getSlotColor возвращает цвет слота по его статусу
 func getSlotColor(status model.SlotStatus) color.RGBA {
 	switch status {
@@ -469,6 +654,16 @@ func getSlotColor(status model.SlotStatus) color.RGBA {
 	}
 }
 
+// lightenColor смешивает цвет с белым: factor 0 — исходный цвет, 1 — белый
+func lightenColor(c color.RGBA, factor float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) + (255-float64(c.R))*factor),
+		G: uint8(float64(c.G) + (255-float64(c.G))*factor),
+		B: uint8(float64(c.B) + (255-float64(c.B))*factor),
+		A: c.A,
+	}
+}
+
 // darkenColor затемняет цвет на указанный множитель
 func darkenColor(c color.RGBA, factor float64) color.RGBA {
 	return color.RGBA{
@@ -499,35 +694,29 @@ func drawCurrentTimeLine(dc *gg.Context, shouldHighlight bool, hours hourRange,
 	dc.Stroke()
 }
 
-// drawLegend рисует легенду справа
-func drawLegend(dc *gg.Context, dayWidth int) {
+// drawLegend рисует легенду справа, прижатую к нижнему краю
+func drawLegend(dc *gg.Context, dayWidth int, legend []legendEntry) {
 	legendX := float64(leftLabelsWidth + totalDaysInWeek*dayWidth + 10)
-	legendY := float64(imageHeight) - 100.0
 
 	dc.SetColor(legendTextColor)
 
-	legendItems := []struct {
-		Label string
-		Clr   color.Color
-	}{
-		{"Свободно", slotFreeColor},
-		{"Забронировано", slotBookedColor},
-		{"Отменено", slotCanceledColor},
-	}
-
 	boxW := 20.0
 	boxH := 14.0
 	liX := legendX
-	liY := legendY + 22
+	liY := float64(imageHeight) - float64(len(legend))*(boxH+14) + 6
+	if liY < float64(headerHeight) {
+		liY = float64(headerHeight)
+	}
 
-	for _, item := range legendItems {
-		dc.SetColor(item.Clr)
+	for _, item := range legend {
+		dc.SetColor(item.fill)
 		dc.DrawRoundedRectangle(liX, liY, boxW, boxH, 3)
 		dc.Fill()
+		drawStyleDecoration(dc, item.style, liX, liY, boxW, boxH, 3, darkenColor(item.fill, 0.8))
 
 		loadFont(dc, legendItemFontSize)
 		dc.SetColor(legendItemColor)
-		dc.DrawStringAnchored(item.Label, liX+boxW+8, liY+boxH/2+1, 0, 0.2)
+		dc.DrawStringAnchored(item.label, liX+boxW+8, liY+boxH/2+1, 0, 0.2)
 		liY += boxH + 14
 	}
 }
@@ -52,8 +52,28 @@ const (
 	EditFieldDuration  = "edit_field_duration:"  // edit_field_duration:123
 	EditDurationCustom = "edit_duration_custom:" // edit_duration_custom:123
 	ToggleApproval     = "toggle_approval:"      // toggle_approval:123
+	TogglePrepayment   = "toggle_prepayment:"    // toggle_prepayment:123
 	SetDuration        = "set_duration:"         // set_duration:123:60 (ID:minutes)
 
+	// Subject currency
+	SubjectCurrency    = "subject_currency:"     // subject_currency:123
+	SetSubjectCurrency = "set_subject_currency:" // set_subject_currency:123:EUR
+
+	// Subject category and tags
+	SubjectTags        = "subject_tags:"         // subject_tags:123
+	SetSubjectCategory = "set_subject_category:" // set_subject_category:123:languages
+	EditFieldTags      = "edit_field_tags:"      // edit_field_tags:123
+
+	// Booking rules
+	BookingRules    = "booking_rules:"     // booking_rules:123
+	BookingRuleMenu = "booking_rule_menu:" // booking_rule_menu:123:notice
+	SetBookingRule  = "set_booking_rule:"  // set_booking_rule:123:notice:60 (ID:rule:value)
+
+	// Lesson packages
+	SubjectPackages = "subject_packages:" // subject_packages:123
+	AddPackage      = "add_package:"      // add_package:123
+	TogglePackage   = "toggle_package:"   // toggle_package:package_id
+
 	ViewSchedule        = "view_schedule"
 	ViewScheduleSubject = "view_schedule_subject:" // view_schedule_subject:subject_id
 	AddSlots            = "add_slots"
@@ -172,10 +192,34 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 		subjects.HandleEditFieldDuration(ctx, b, callback, h)
 	case strings.HasPrefix(data, ToggleApproval):
 		subjects.HandleToggleApproval(ctx, b, callback, h)
+	case strings.HasPrefix(data, TogglePrepayment):
+		subjects.HandleTogglePrepayment(ctx, b, callback, h)
 	case strings.HasPrefix(data, SetDuration):
 		subjects.HandleSetDuration(ctx, b, callback, h)
 	case strings.HasPrefix(data, EditDurationCustom):
 		subjects.HandleEditDurationCustom(ctx, b, callback, h)
+	case strings.HasPrefix(data, SubjectCurrency):
+		subjects.HandleSubjectCurrency(ctx, b, callback, h)
+	case strings.HasPrefix(data, SetSubjectCurrency):
+		subjects.HandleSetSubjectCurrency(ctx, b, callback, h)
+	case strings.HasPrefix(data, SubjectTags):
+		subjects.HandleSubjectTags(ctx, b, callback, h)
+	case strings.HasPrefix(data, SetSubjectCategory):
+		subjects.HandleSetSubjectCategory(ctx, b, callback, h)
+	case strings.HasPrefix(data, EditFieldTags):
+		subjects.HandleEditFieldTags(ctx, b, callback, h)
+	case strings.HasPrefix(data, BookingRules):
+		subjects.HandleBookingRules(ctx, b, callback, h)
+	case strings.HasPrefix(data, BookingRuleMenu):
+		subjects.HandleBookingRuleMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, SetBookingRule):
+		subjects.HandleSetBookingRule(ctx, b, callback, h)
+	case strings.HasPrefix(data, SubjectPackages):
+		subjects.HandleSubjectPackages(ctx, b, callback, h)
+	case strings.HasPrefix(data, AddPackage):
+		subjects.HandleAddPackage(ctx, b, callback, h)
+	case strings.HasPrefix(data, TogglePackage):
+		subjects.HandleTogglePackage(ctx, b, callback, h)
 	case strings.HasPrefix(data, ToggleSubject):
 		subjects.HandleToggleSubject(ctx, b, callback, h)
 	case strings.HasPrefix(data, DeleteSubject):
@@ -194,16 +238,48 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 		schedule.HandleViewScheduleCalendarPage(ctx, b, callback, h)
 	case strings.HasPrefix(data, "view_schedule_day:"):
 		schedule.HandleViewScheduleDay(ctx, b, callback, h)
+	case strings.HasPrefix(data, "schedule_month:"):
+		schedule.HandleScheduleMonth(ctx, b, callback, h)
+	case strings.HasPrefix(data, "schedule_agenda:"):
+		schedule.HandleScheduleAgenda(ctx, b, callback, h)
+	case strings.HasPrefix(data, "teacher_pdf:"):
+		schedule.HandleTeacherTimetablePDF(ctx, b, callback, h)
 	case strings.HasPrefix(data, "view_schedule_weeks:"):
 		schedule.HandleViewScheduleWeeks(ctx, b, callback, h)
 	case strings.HasPrefix(data, "view_schedule_week_day:"):
 		schedule.HandleViewScheduleWeekDay(ctx, b, callback, h)
+	case strings.HasPrefix(data, "copy_week:"):
+		schedule.HandleCopyWeek(ctx, b, callback, h)
+	case strings.HasPrefix(data, "copy_week_subject:"):
+		schedule.HandleCopyWeekSubject(ctx, b, callback, h)
+	case strings.HasPrefix(data, "copy_week_weeks:"):
+		schedule.HandleCopyWeekPreview(ctx, b, callback, h)
+	case strings.HasPrefix(data, "copy_week_confirm:"):
+		schedule.HandleCopyWeekConfirm(ctx, b, callback, h)
+	case strings.HasPrefix(data, "bulk_slots:"):
+		schedule.HandleBulkSlots(ctx, b, callback, h)
+	case strings.HasPrefix(data, "bulk_toggle:"):
+		schedule.HandleBulkToggle(ctx, b, callback, h)
+	case strings.HasPrefix(data, "bulk_select:"):
+		schedule.HandleBulkSelect(ctx, b, callback, h)
+	case strings.HasPrefix(data, "bulk_action:"):
+		schedule.HandleBulkAction(ctx, b, callback, h)
+	case strings.HasPrefix(data, "bulk_confirm:"):
+		schedule.HandleBulkConfirm(ctx, b, callback, h)
 	case strings.HasPrefix(data, "view_slot_details:"):
 		schedule.HandleViewSlotDetails(ctx, b, callback, h)
 	case strings.HasPrefix(data, "cancel_slot:"):
 		schedule.HandleCancelSlot(ctx, b, callback, h)
 	case strings.HasPrefix(data, "restore_slot:"):
 		schedule.HandleRestoreSlot(ctx, b, callback, h)
+	case strings.HasPrefix(data, "mark_no_show:"):
+		schedule.HandleMarkNoShow(ctx, b, callback, h)
+	case strings.HasPrefix(data, "slot_group:"):
+		schedule.HandleSlotGroupMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "set_slot_group:"):
+		schedule.HandleSetSlotGroup(ctx, b, callback, h)
+	case strings.HasPrefix(data, "invite_group_slot:"):
+		schedule.HandleInviteGroupToSlot(ctx, b, callback, h)
 	case strings.HasPrefix(data, "cancel_booking_from_slot:"):
 		schedule.HandleCancelBookingFromSlot(ctx, b, callback, h)
 	case strings.HasPrefix(data, "slot_action:"):
@@ -308,6 +384,10 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 		student.HandleRequestRecurringBooking(ctx, b, callback, h)
 	case strings.HasPrefix(data, "request_recurring_confirm:"):
 		student.HandleRequestRecurringConfirm(ctx, b, callback, h)
+	case strings.HasPrefix(data, "enter_promo:"):
+		student.HandleEnterPromoCode(ctx, b, callback, h)
+	case strings.HasPrefix(data, "remove_promo:"):
+		student.HandleRemovePromoCode(ctx, b, callback, h)
 	case strings.HasPrefix(data, BookLesson):
 		student.HandleBookLesson(ctx, b, callback, h)
 	case strings.HasPrefix(data, CancelBooking):
@@ -330,6 +410,35 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 		recurring.HandleRejectRecurring(ctx, b, callback, h)
 
 	// ===== Student: Teacher Access Management =====
+	case data == "find_time":
+		student.HandleFindTime(ctx, b, callback, h)
+	case strings.HasPrefix(data, "ftw:"):
+		student.HandleFindTimeWhen(ctx, b, callback, h)
+	case strings.HasPrefix(data, "ftr:"):
+		student.HandleFindTimeResults(ctx, b, callback, h)
+	case data == "my_favorites":
+		student.HandleMyFavorites(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_teacher:"):
+		student.HandleFavoriteTeacher(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_subject:"):
+		student.HandleFavoriteSubject(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_view:"):
+		student.HandleViewFavorite(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_alerts:"):
+		student.HandleFavoriteAlerts(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_day:"):
+		student.HandleFavoriteDay(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_period:"):
+		student.HandleFavoritePeriod(ctx, b, callback, h)
+	case strings.HasPrefix(data, "fav_remove:"):
+		student.HandleRemoveFavorite(ctx, b, callback, h)
+	case data == "subject_search":
+		student.HandleSubjectSearch(ctx, b, callback, h)
+	case data == "subject_search_price", data == "subject_search_duration",
+		data == "subject_search_category", data == "subject_search_free":
+		student.HandleSubjectSearchFilter(ctx, b, callback, h)
+	case strings.HasPrefix(data, "subject_search_page:"):
+		student.HandleSubjectSearchPage(ctx, b, callback, h)
 	case data == "subjects_menu":
 		// Back to subjects menu - will be handled in the main command handler
 		common.HandleBackToSubjects(ctx, b, callback, h)
@@ -344,7 +453,15 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
 			return
 		}
-		student.HandlePublicTeachersPage(ctx, b, callback, h, int(page))
+		student.HandlePublicTeachersPage(ctx, b, callback, h, int(page), false)
+	case strings.HasPrefix(data, "public_teachers_rated:"):
+		page, err := common.ParseIDFromCallback(data)
+		if err != nil {
+			h.Logger.Error("Failed to parse page number", zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+			return
+		}
+		student.HandlePublicTeachersPage(ctx, b, callback, h, int(page), true)
 	case strings.HasPrefix(data, "teacher_profile:"):
 		student.HandleTeacherProfile(ctx, b, callback, h)
 	case data == "find_teacher":
@@ -365,18 +482,160 @@ func Route(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *c
 		teacher.HandleManageInviteCodes(ctx, b, callback, h)
 	case data == "create_invite_code":
 		teacher.HandleCreateInviteCode(ctx, b, callback, h)
+	case strings.HasPrefix(data, "create_invite_code:"):
+		teacher.HandleConfirmCreateInviteCode(ctx, b, callback, h)
 	case strings.HasPrefix(data, "deactivate_code:"):
 		teacher.HandleDeactivateInviteCode(ctx, b, callback, h)
+	case data == "teacher_reports":
+		teacher.HandleTeacherReports(ctx, b, callback, h)
+	case strings.HasPrefix(data, "teacher_report_chart:"):
+		teacher.HandleTeacherReportChart(ctx, b, callback, h)
+	case strings.HasPrefix(data, "teacher_report:"):
+		teacher.HandleTeacherReport(ctx, b, callback, h)
+	case data == "export_menu":
+		teacher.HandleExportMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "export_dataset:"):
+		teacher.HandleExportDataset(ctx, b, callback, h)
+	case strings.HasPrefix(data, "export_file:"):
+		teacher.HandleExportFile(ctx, b, callback, h)
+	case data == "default_currency_menu":
+		teacher.HandleDefaultCurrencyMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "set_default_currency:"):
+		teacher.HandleSetDefaultCurrency(ctx, b, callback, h)
+	case data == "manage_promo_codes":
+		teacher.HandleManagePromoCodes(ctx, b, callback, h)
+	case data == "create_promo_code":
+		teacher.HandleCreatePromoCode(ctx, b, callback, h)
+	case strings.HasPrefix(data, "promo_scope:"):
+		teacher.HandlePromoScope(ctx, b, callback, h)
+	case strings.HasPrefix(data, "deactivate_promo:"):
+		teacher.HandleDeactivatePromoCode(ctx, b, callback, h)
 	case data == "view_access_requests":
 		teacher.HandleViewAccessRequests(ctx, b, callback, h)
 	case strings.HasPrefix(data, "approve_request:"):
 		teacher.HandleApproveAccessRequest(ctx, b, callback, h)
+	case strings.HasPrefix(data, "approve_request_for:"):
+		teacher.HandleConfirmApproveAccessRequest(ctx, b, callback, h)
 	case strings.HasPrefix(data, "reject_request:"):
 		teacher.HandleRejectAccessRequest(ctx, b, callback, h)
 	case data == "view_my_students":
 		teacher.HandleViewMyStudents(ctx, b, callback, h)
+	case data == "manage_groups":
+		teacher.HandleManageGroups(ctx, b, callback, h)
+	case data == "create_group":
+		teacher.HandleCreateGroup(ctx, b, callback, h)
+	case strings.HasPrefix(data, "view_group:"):
+		teacher.HandleViewGroup(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_add_members:"):
+		teacher.HandleGroupAddMembers(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_remove_members:"):
+		teacher.HandleGroupRemoveMembers(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_add:"):
+		teacher.HandleGroupAdd(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_remove:"):
+		teacher.HandleGroupRemove(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_broadcast:"):
+		teacher.HandleGroupBroadcast(ctx, b, callback, h)
+	case strings.HasPrefix(data, "group_invite_code:"):
+		teacher.HandleGroupInviteCode(ctx, b, callback, h)
+	case strings.HasPrefix(data, "delete_group:"):
+		teacher.HandleDeleteGroup(ctx, b, callback, h)
+	case strings.HasPrefix(data, "confirm_delete_group:"):
+		teacher.HandleConfirmDeleteGroup(ctx, b, callback, h)
+	case data == "broadcast_menu":
+		teacher.HandleBroadcastMenu(ctx, b, callback, h)
+	case data == "broadcast_subjects":
+		teacher.HandleBroadcastSubjects(ctx, b, callback, h)
+	case data == "broadcast_periods":
+		teacher.HandleBroadcastPeriods(ctx, b, callback, h)
+	case data == "broadcast_groups":
+		teacher.HandleBroadcastGroups(ctx, b, callback, h)
+	case data == "broadcast_pick":
+		teacher.HandleBroadcastPick(ctx, b, callback, h)
+	case strings.HasPrefix(data, "broadcast_pick_toggle:"):
+		teacher.HandleBroadcastPickToggle(ctx, b, callback, h)
+	case strings.HasPrefix(data, "broadcast_audience:"):
+		teacher.HandleBroadcastAudience(ctx, b, callback, h)
+	case data == "broadcast_send":
+		teacher.HandleBroadcastSend(ctx, b, callback, h)
+	case data == "broadcast_cancel":
+		teacher.HandleBroadcastCancel(ctx, b, callback, h)
+
+	// Переписка через бота (студенты и учителя)
+	case data == "my_chats":
+		common.HandleMyChats(ctx, b, callback, h)
+	case strings.HasPrefix(data, "view_chat:"):
+		common.HandleViewChat(ctx, b, callback, h)
+	case strings.HasPrefix(data, "chat_write:"):
+		common.HandleChatWrite(ctx, b, callback, h)
+	case strings.HasPrefix(data, "chat_mute:"):
+		common.HandleChatMute(ctx, b, callback, h)
+	case strings.HasPrefix(data, "chat_booking:"):
+		common.HandleChatBooking(ctx, b, callback, h)
+	case strings.HasPrefix(data, "chat_student:"), strings.HasPrefix(data, "chat_teacher:"):
+		common.HandleChatPeer(ctx, b, callback, h)
+
+	// Заметки к занятиям
+	case strings.HasPrefix(data, "lesson_notes:"):
+		teacher.HandleLessonNotes(ctx, b, callback, h)
+	case strings.HasPrefix(data, "note_add:"):
+		teacher.HandleNoteAdd(ctx, b, callback, h)
+	case strings.HasPrefix(data, "note_due_menu:"):
+		teacher.HandleNoteDueMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "note_due:"):
+		teacher.HandleNoteDue(ctx, b, callback, h)
+	case strings.HasPrefix(data, "note_delete:"):
+		teacher.HandleNoteDelete(ctx, b, callback, h)
+	case strings.HasPrefix(data, "student_notes:"):
+		teacher.HandleStudentNotes(ctx, b, callback, h)
+	case strings.HasPrefix(data, "view_note:"):
+		common.HandleViewNote(ctx, b, callback, h)
+	case strings.HasPrefix(data, "booking_notes:"):
+		student.HandleBookingNotes(ctx, b, callback, h)
+	case data == "my_notes":
+		student.HandleMyNotes(ctx, b, callback, h)
+	case strings.HasPrefix(data, "student_week:"):
+		student.HandleStudentWeek(ctx, b, callback, h)
+	case strings.HasPrefix(data, "student_pdf:"):
+		student.HandleStudentTimetablePDF(ctx, b, callback, h)
+
+	// Отзывы об учителях
+	case strings.HasPrefix(data, "review_rate:"):
+		student.HandleReviewRate(ctx, b, callback, h)
+	case strings.HasPrefix(data, "review_comment:"):
+		student.HandleReviewComment(ctx, b, callback, h)
+	case strings.HasPrefix(data, "review_skip:"):
+		student.HandleReviewSkip(ctx, b, callback, h)
+	case data == "my_reviews":
+		teacher.HandleMyReviews(ctx, b, callback, h)
+	case strings.HasPrefix(data, "review_reply:"):
+		teacher.HandleReviewReply(ctx, b, callback, h)
+	case strings.HasPrefix(data, "review_report:"):
+		teacher.HandleReviewReport(ctx, b, callback, h)
+	case strings.HasPrefix(data, "confirm_review_report:"):
+		teacher.HandleConfirmReviewReport(ctx, b, callback, h)
 	case strings.HasPrefix(data, "revoke_access:"):
 		teacher.HandleRevokeStudentAccess(ctx, b, callback, h)
+	case strings.HasPrefix(data, "student_credits:"):
+		teacher.HandleStudentCredits(ctx, b, callback, h)
+	case strings.HasPrefix(data, "grant_credits_menu:"):
+		teacher.HandleGrantCreditsMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "grant_credits:"):
+		teacher.HandleGrantCredits(ctx, b, callback, h)
+	case strings.HasPrefix(data, "grant_package:"):
+		teacher.HandleGrantPackage(ctx, b, callback, h)
+	case strings.HasPrefix(data, "subscription_menu:"):
+		teacher.HandleSubscriptionMenu(ctx, b, callback, h)
+	case strings.HasPrefix(data, "extend_subscription:"):
+		teacher.HandleExtendSubscription(ctx, b, callback, h)
+	case data == "ledger_balances":
+		teacher.HandleLedgerBalances(ctx, b, callback, h)
+	case strings.HasPrefix(data, "student_ledger:"):
+		teacher.HandleStudentLedger(ctx, b, callback, h)
+	case strings.HasPrefix(data, "record_payment:"):
+		teacher.HandleRecordPayment(ctx, b, callback, h)
+	case strings.HasPrefix(data, "ledger_statement:"):
+		teacher.HandleLedgerStatement(ctx, b, callback, h)
 	case data == "mysubjects":
 		// Back to my subjects - редактируем существующее сообщение
 		if h.HandleMySubjects != nil {
@@ -3,10 +3,13 @@ package student
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -38,6 +41,26 @@ func HandleMyTeachers(ctx context.Context, b *bot.Bot, callback *models.Callback
 		text += fmt.Sprintf("Учителя, к которым у вас есть доступ (%d):\n\n", len(teachers))
 	}
 
+	// Остатки оплаченных занятий по учителям
+	balances, err := h.CreditService.GetStudentBalances(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get credit balances", zap.Error(err))
+	}
+	balancesByTeacher := make(map[int64][]*model.CreditBalance)
+	for _, balance := range balances {
+		balancesByTeacher[balance.TeacherID] = append(balancesByTeacher[balance.TeacherID], balance)
+	}
+
+	// Долги и авансы за занятия, оплачиваемые вне бота
+	ledgerBalances, err := h.LedgerService.GetStudentBalances(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get ledger balances", zap.Error(err))
+	}
+	ledgerByTeacher := make(map[int64]*model.LedgerBalance)
+	for _, balance := range ledgerBalances {
+		ledgerByTeacher[balance.TeacherID] = balance
+	}
+
 	kb := keyboard.NewBuilder()
 
 	// Добавляем кнопки учителей
@@ -46,10 +69,30 @@ func HandleMyTeachers(ctx context.Context, b *bot.Bot, callback *models.Callback
 		if teacher.LastName != "" {
 			name += " " + teacher.LastName
 		}
-		kb.Row(keyboard.Button(
-			fmt.Sprintf("👤 %s", name),
-			fmt.Sprintf("teacher_profile:%d", teacher.ID),
-		))
+
+		label := fmt.Sprintf("👤 %s", name)
+		if teacherBalances := balancesByTeacher[teacher.ID]; len(teacherBalances) > 0 {
+			label += fmt.Sprintf(" · 🎟 %d", formatting.SumCreditBalances(teacherBalances))
+
+			text += fmt.Sprintf("🎟 *%s* — оплаченные занятия:\n", name)
+			for _, balance := range teacherBalances {
+				text += "   • " + formatting.FormatCreditBalance(balance) + "\n"
+			}
+			text += "\n"
+		}
+
+		if access, _ := h.AccessRepo.GetAccessInfo(ctx, user.ID, teacher.ID); access != nil && access.ExpiresAt != nil {
+			text += fmt.Sprintf("⭐ *%s* — подписка до %s\n\n", name, formatting.FormatDate(*access.ExpiresAt))
+		}
+
+		if ledger := ledgerByTeacher[teacher.ID]; ledger != nil && ledger.Debt() != 0 {
+			text += fmt.Sprintf("💰 *%s* — %s\n\n", name, formatting.FormatLedgerBalance(ledger))
+		}
+
+		kb.AddRow([]models.InlineKeyboardButton{
+			keyboard.Button(label, fmt.Sprintf("teacher_profile:%d", teacher.ID)),
+			keyboard.Button("💬", fmt.Sprintf("chat_teacher:%d", teacher.ID)),
+		})
 	}
 
 	// Навигация
@@ -160,6 +203,14 @@ func ProcessInviteCode(ctx context.Context, b *bot.Bot, message *models.Message,
 
 	code := message.Text
 
+	// Код группы можно ввести и с уже открытым доступом — тогда студент только вступает в группу
+	hadAccess := false
+	if inviteCode, _ := h.InviteCodeRepo.GetByCode(ctx, code); inviteCode != nil {
+		if access, _ := h.AccessRepo.GetAccessInfo(ctx, user.ID, inviteCode.TeacherID); access != nil {
+			hadAccess = true
+		}
+	}
+
 	// Используем код
 	err = h.AccessService.UseInviteCode(ctx, user.ID, code)
 	if err != nil {
@@ -202,12 +253,46 @@ func ProcessInviteCode(ctx context.Context, b *bot.Bot, message *models.Message,
 	// Очищаем состояние
 	h.StateManager.ClearState(telegramID)
 
+	groupName := ""
+	if inviteCode != nil && inviteCode.GroupID != nil {
+		if group, err := h.GroupService.GetGroup(ctx, inviteCode.TeacherID, *inviteCode.GroupID); err == nil {
+			groupName = group.Name
+		}
+	}
+
+	if hadAccess {
+		text := "✅ *Код принят!*\n\n"
+		if groupName != "" {
+			text += fmt.Sprintf("Вы вступили в группу *%s*", groupName)
+		} else {
+			text += "Вы вступили в группу"
+		}
+		if teacherName != "" {
+			text += fmt.Sprintf(" учителя *%s*", teacherName)
+		}
+		text += "."
+
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    message.Chat.ID,
+			Text:      text,
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+
 	text := "✅ *Доступ получен!*\n\n"
 	if teacherName != "" {
 		text += fmt.Sprintf("Учитель *%s* добавлен в 'Мои учителя'.\n\n", teacherName)
 	} else {
 		text += "Учитель добавлен в 'Мои учителя'.\n\n"
 	}
+	if groupName != "" {
+		text += fmt.Sprintf("👥 Вы в группе *%s*.\n\n", groupName)
+	}
+	if inviteCode != nil && inviteCode.AccessDays != nil {
+		text += fmt.Sprintf("⏳ Доступ действует до %s.\n\n",
+			inviteCode.AccessExpiresAt(time.Now()).Format("02.01.2006"))
+	}
 	text += "Теперь вы можете просматривать предметы и записываться на занятия."
 
 	kb := keyboard.NewBuilder()
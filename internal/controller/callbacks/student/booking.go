@@ -3,10 +3,12 @@ package student
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -52,8 +54,25 @@ func HandleBookLesson(ctx context.Context, b *bot.Bot, callback *models.Callback
 			errorMsg = "❌ Этот слот уже занят. Выберите другое время."
 		} else if err.Error() == "slot is in the past" {
 			errorMsg = "❌ Этот слот в прошлом. Выберите другое время."
+		} else if err.Error() == "slot conflicts with teacher buffer" {
+			errorMsg = "❌ Это время пересекается с перерывом учителя между занятиями. Выберите другое время."
+		} else if err.Error() == "slot is reserved for group" {
+			errorMsg = "❌ Это занятие только для участников группы учителя. Выберите другое время."
 		} else if err.Error() == "subject is not active" {
 			errorMsg = "❌ Этот предмет больше не доступен для записи."
+		} else if strings.HasPrefix(err.Error(), "failed to send invoice") {
+			errorMsg = "❌ Не удалось выставить счёт на оплату. Попробуйте позже."
+		} else if subject := getSlotSubject(ctx, h, slotID); subject != nil {
+			switch err.Error() {
+			case "minimum notice not met":
+				errorMsg = fmt.Sprintf("❌ Записаться на этот предмет можно не позднее чем %s до начала занятия.", formatting.FormatMinNotice(subject.MinNoticeMinutes))
+			case "booking window not open yet":
+				errorMsg = fmt.Sprintf("❌ Запись на это время ещё не открыта. Записаться можно %s.", formatting.FormatMaxAdvance(subject.MaxAdvanceDays))
+			case "active bookings limit reached":
+				errorMsg = fmt.Sprintf("❌ У вас уже %d %s на этот предмет — это максимум. Дождитесь занятия или отмените одну из записей.", subject.MaxActiveBookings, formatting.PluralizeBookings(subject.MaxActiveBookings))
+			case "weekly bookings limit reached":
+				errorMsg = fmt.Sprintf("❌ На этой неделе у вас уже %d %s на этот предмет — это максимум. Выберите время на другой неделе.", subject.MaxBookingsPerWeek, formatting.PluralizeBookings(subject.MaxBookingsPerWeek))
+			}
 		}
 
 		common.AnswerCallbackAlert(ctx, b, callback.ID, errorMsg)
@@ -62,71 +81,30 @@ func HandleBookLesson(ctx context.Context, b *bot.Bot, callback *models.Callback
 
 	b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: msg.Chat.ID, MessageID: msg.ID})
 
+	// Запись ждёт оплаты: счёт уже отправлен, учителя уведомим после оплаты
+	if booking.Status == model.BookingStatusAwaitingPayment {
+		text, keyboard := common.BuildAwaitingPaymentScreen(booking.ID, booking.Payment)
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: msg.Chat.ID, Text: text, ReplyMarkup: keyboard})
+		common.AnswerCallback(ctx, b, callback.ID, "💳 Счёт отправлен")
+		return
+	}
+
 	// Используем билдер экрана
 	isPending := booking.Status == model.BookingStatusPending
 	text, keyboard := common.BuildBookingSuccessScreen(booking.ID, slotID, isPending)
+	if booking.CreditDebited {
+		text += "\n\n🎟 Списано 1 занятие из пакета"
+	} else if booking.PromoCode != nil {
+		text += fmt.Sprintf("\n\n🏷 Промокод %s: стоимость занятия %s", booking.PromoCode.Code, formatting.FormatPrice(booking.Price, booking.Currency))
+	}
 
 	b.SendMessage(ctx, &bot.SendMessageParams{ChatID: msg.Chat.ID, Text: text, ReplyMarkup: keyboard})
 	common.AnswerCallback(ctx, b, callback.ID, "✅ Запись создана")
 
 	// Отправляем уведомление учителю
 	teacher, err := h.UserService.GetByID(ctx, booking.TeacherID)
-	if err == nil && teacher != nil && booking.Subject != nil && booking.Slot != nil {
-		var notificationText string
-
-		if booking.Status == model.BookingStatusPending {
-			// Для pending - запрос на одобрение с кнопками
-			notificationText = fmt.Sprintf(
-				"⏳ **Новый запрос на запись**\n\n"+
-					"👤 Студент: %s\n"+
-					"📚 Предмет: %s\n"+
-					"📅 Дата: %s\n"+
-					"🕐 Время: %s - %s\n\n"+
-					"Требуется ваше одобрение:",
-				user.FirstName,
-				booking.Subject.Name,
-				booking.Slot.StartTime.Format("02.01.2006"),
-				booking.Slot.StartTime.Format("15:04"),
-				booking.Slot.EndTime.Format("15:04"),
-			)
-
-			keyboard := &models.InlineKeyboardMarkup{
-				InlineKeyboard: [][]models.InlineKeyboardButton{
-					{
-						{Text: "✅ Одобрить", CallbackData: fmt.Sprintf("approve_booking:%d", booking.ID)},
-						{Text: "❌ Отклонить", CallbackData: fmt.Sprintf("reject_booking:%d", booking.ID)},
-					},
-				},
-			}
-
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID:      teacher.TelegramID,
-				Text:        notificationText,
-				ParseMode:   models.ParseModeMarkdown,
-				ReplyMarkup: keyboard,
-			})
-		} else {
-			// Для confirmed - просто уведомление
-			notificationText = fmt.Sprintf(
-				"✅ **Новая запись**\n\n"+
-					"👤 Студент: %s\n"+
-					"📚 Предмет: %s\n"+
-					"📅 Дата: %s\n"+
-					"🕐 Время: %s - %s\n\n"+
-					"Запись подтверждена автоматически.",
-				user.FirstName,
-				booking.Subject.Name,
-				booking.Slot.StartTime.Format("02.01.2006"),
-				booking.Slot.StartTime.Format("15:04"),
-				booking.Slot.EndTime.Format("15:04"),
-			)
-
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID:    teacher.TelegramID,
-				Text:      notificationText,
-				ParseMode: models.ParseModeMarkdown,
-			})
-		}
+	if err == nil && teacher != nil {
+		common.SendNewBookingNotification(ctx, b, teacher.TelegramID, user.FirstName, booking)
 	}
 }
 
@@ -153,10 +131,21 @@ func HandleCancelBooking(ctx context.Context, b *bot.Bot, callback *models.Callb
 		},
 	}
 
+	text := fmt.Sprintf("❓ Вы уверены, что хотите отменить запись #%d?\n\nУчитель получит уведомление об отмене.", bookingID)
+
+	// Предупреждаем, что поздняя отмена сжигает занятие из пакета
+	forfeits, err := h.BookingService.LateCancelForfeitsCredit(ctx, bookingID)
+	if err != nil {
+		h.Logger.Warn("Failed to check credit forfeit", zap.Int64("booking_id", bookingID), zap.Error(err))
+	}
+	if forfeits {
+		text += "\n\n⚠️ До занятия осталось меньше, чем допускает политика отмены: занятие из пакета не вернётся."
+	}
+
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:      msg.Chat.ID,
 		MessageID:   msg.ID,
-		Text:        fmt.Sprintf("❓ Вы уверены, что хотите отменить запись #%d?\n\nУчитель получит уведомление об отмене.", bookingID),
+		Text:        text,
 		ReplyMarkup: keyboard,
 	})
 
@@ -217,3 +206,18 @@ func HandleConfirmCancel(ctx context.Context, b *bot.Bot, callback *models.Callb
 
 	common.AnswerCallback(ctx, b, callback.ID, "✅ Запись отменена")
 }
+
+// getSlotSubject возвращает предмет слота или nil, если его не удалось получить
+func getSlotSubject(ctx context.Context, h *callbacktypes.Handler, slotID int64) *model.Subject {
+	slot, err := h.TeacherService.GetSlotByID(ctx, slotID)
+	if err != nil || slot == nil {
+		return nil
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, slot.SubjectID)
+	if err != nil {
+		return nil
+	}
+
+	return subject
+}
@@ -0,0 +1,317 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleMyFavorites показывает избранных учителей и предметы студента
+func HandleMyFavorites(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	showFavorites(ctx, b, callback, h, "")
+}
+
+// showFavorites перерисовывает список избранного; notice показывается во всплывающем ответе
+func showFavorites(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, notice string) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	favorites, err := h.FavoriteService.GetFavorites(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get favorites", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке избранного")
+		return
+	}
+
+	text, kb := common.BuildFavoritesScreen(favorites)
+	editFavoriteMessage(ctx, b, callback, text, kb)
+	common.AnswerCallback(ctx, b, callback.ID, notice)
+}
+
+// HandleFavoriteTeacher добавляет учителя в избранное или убирает из него прямо из профиля
+func HandleFavoriteTeacher(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	teacherID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	added, err := h.FavoriteService.ToggleTeacher(ctx, user.ID, teacherID)
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showTeacherProfile(ctx, b, callback, h, teacherID, favoriteToggleNotice(added))
+}
+
+// HandleFavoriteSubject добавляет предмет в избранное или убирает из него прямо из карточки предмета
+func HandleFavoriteSubject(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	added, err := h.FavoriteService.ToggleSubject(ctx, user.ID, subjectID)
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showStudentSubjectDetails(ctx, b, callback, h, subjectID, favoriteToggleNotice(added))
+}
+
+// HandleViewFavorite показывает запись избранного с настройками уведомлений
+func HandleViewFavorite(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	favoriteID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	favorite, err := h.FavoriteService.GetFavorite(ctx, user.ID, favoriteID)
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showFavorite(ctx, b, callback, favorite)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleFavoriteAlerts включает или выключает уведомления о свободных слотах
+func HandleFavoriteAlerts(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	favoriteID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	favorite, err := h.FavoriteService.ToggleAlerts(ctx, user.ID, favoriteID)
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showFavorite(ctx, b, callback, favorite)
+	if favorite.AlertsEnabled {
+		common.AnswerCallback(ctx, b, callback.ID, "🔔 Уведомления включены")
+	} else {
+		common.AnswerCallback(ctx, b, callback.ID, "🔕 Уведомления выключены")
+	}
+}
+
+// HandleFavoriteDay отмечает удобный день недели для уведомлений
+func HandleFavoriteDay(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: fav_day:<ID избранного>:<день недели>
+	ids := common.ParseMultiIDFromCallback(callback.Data, "fav_day:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	favorite, err := h.FavoriteService.ToggleWeekday(ctx, user.ID, ids[0], time.Weekday(ids[1]))
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showFavorite(ctx, b, callback, favorite)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleFavoritePeriod отмечает удобную часть дня для уведомлений
+func HandleFavoritePeriod(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: fav_period:<ID избранного>:<индекс части дня>
+	ids := common.ParseMultiIDFromCallback(callback.Data, "fav_period:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	favorite, err := h.FavoriteService.ToggleDayPeriod(ctx, user.ID, ids[0], int(ids[1]))
+	if err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showFavorite(ctx, b, callback, favorite)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleRemoveFavorite убирает запись из избранного и возвращает к списку
+func HandleRemoveFavorite(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	favoriteID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	if err := h.FavoriteService.Remove(ctx, user.ID, favoriteID); err != nil {
+		answerFavoriteError(ctx, b, callback, h, err)
+		return
+	}
+
+	showFavorites(ctx, b, callback, h, "💔 Убрано из избранного")
+}
+
+// showFavorite перерисовывает экран записи избранного
+func showFavorite(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, favorite *model.Favorite) {
+	text := "❤️ <b>Избранное</b>\n\n" +
+		fmt.Sprintf("👤 Учитель: %s\n", html.EscapeString(favorite.TeacherName))
+	if favorite.IsSubject() {
+		text += fmt.Sprintf("📚 Предмет: %s\n", html.EscapeString(favorite.SubjectName))
+	} else {
+		text += "📚 Предмет: все предметы учителя\n"
+	}
+
+	if favorite.AlertsEnabled {
+		text += "\n🔔 Уведомления о свободном времени включены"
+	} else {
+		text += "\n🔕 Уведомления о свободном времени выключены"
+	}
+	text += fmt.Sprintf("\n🕐 Удобное время: %s\n\n", formatting.FormatTimeWish(favorite.Weekdays, favorite.DayPeriods)) +
+		"Отметьте удобные дни и время — бот сообщит только о подходящих слотах."
+
+	kb := keyboard.NewBuilder()
+
+	// Дни недели с понедельника
+	var days []models.InlineKeyboardButton
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		label := formatting.GetWeekdayShort(int(day))
+		if favorite.HasWeekday(day) {
+			label = "✅" + label
+		}
+		days = append(days, keyboard.Button(label, fmt.Sprintf("fav_day:%d:%d", favorite.ID, day)))
+	}
+	kb.AddRow(days)
+
+	var periods []models.InlineKeyboardButton
+	for i, period := range model.DayPeriods {
+		label := fmt.Sprintf("%s %s", period.Emoji, period.Title)
+		if favorite.HasDayPeriod(i) {
+			label = "✅ " + period.Title
+		}
+		periods = append(periods, keyboard.Button(label, fmt.Sprintf("fav_period:%d:%d", favorite.ID, i)))
+	}
+	kb.AddRow(periods)
+
+	if favorite.AlertsEnabled {
+		kb.Row(keyboard.Button("🔕 Выключить уведомления", fmt.Sprintf("fav_alerts:%d", favorite.ID)))
+	} else {
+		kb.Row(keyboard.Button("🔔 Включить уведомления", fmt.Sprintf("fav_alerts:%d", favorite.ID)))
+	}
+
+	if favorite.IsSubject() {
+		kb.Row(keyboard.Button("📅 Расписание", fmt.Sprintf("view_schedule_subject:%d", *favorite.SubjectID)))
+	} else {
+		kb.Row(keyboard.Button("👤 Профиль учителя", fmt.Sprintf("teacher_profile:%d", favorite.TeacherID)))
+	}
+
+	kb.Row(keyboard.Button("💔 Убрать из избранного", fmt.Sprintf("fav_remove:%d", favorite.ID)))
+	kb.Row(keyboard.BackButton("my_favorites"))
+
+	editFavoriteMessage(ctx, b, callback, text, kb.Build())
+}
+
+// favoriteToggleNotice текст всплывающего ответа после добавления или удаления из избранного
+func favoriteToggleNotice(added bool) string {
+	if added {
+		return "❤️ Добавлено в избранное"
+	}
+	return "💔 Убрано из избранного"
+}
+
+// answerFavoriteError отвечает на ошибку сервиса избранного
+func answerFavoriteError(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, err error) {
+	switch err.Error() {
+	case "favorite not found":
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Запись не найдена в избранном")
+	case "subject not found":
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+	case "teacher not available":
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Нет доступа к этому учителю")
+	case "too many favorites":
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ В избранном уже слишком много записей. Уберите ненужные через /favorites")
+	case "already favorite":
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❤️ Уже в избранном")
+	default:
+		h.Logger.Error("Favorite action failed",
+			zap.String("callback_data", callback.Data),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка")
+	}
+}
+
+// editFavoriteMessage заменяет экран избранного в сообщении callback
+func editFavoriteMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
@@ -0,0 +1,359 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Find Me a Time Handlers
+// ========================
+// Студент выбирает предмет или категорию, удобные дни и часть дня, а бот подбирает свободные
+// слоты у всех доступных ему учителей. Выбор хранится в callback data:
+//   ftw:<выбор>:<дни>:<части дня>          — экран выбора дней и времени
+//   ftr:<выбор>:<дни>:<части дня>:<страница> — найденные слоты
+// где выбор — all, c.<код категории> или s.<ID предмета>, дни и части дня — битовые маски
+
+const (
+	// findTimePerPage сколько слотов показывать на странице
+	findTimePerPage = 8
+	// findTimeMaxNames сколько названий предметов предлагать на выбор
+	findTimeMaxNames = 20
+)
+
+// findTimeQuery пожелания студента, закодированные в callback data
+type findTimeQuery struct {
+	Selector string // all, c.<код категории> или s.<ID предмета>
+	Days     int    // бит i — time.Weekday(i)
+	Periods  int    // бит i — model.DayPeriods[i]
+}
+
+func (q findTimeQuery) String() string {
+	return fmt.Sprintf("%s:%d:%d", q.Selector, q.Days, q.Periods)
+}
+
+// filter переводит пожелания в фильтр сервиса
+func (q findTimeQuery) filter() model.FreeTimeFilter {
+	filter := model.FreeTimeFilterFromMasks(q.Days, q.Periods)
+	if kind, value, ok := strings.Cut(q.Selector, "."); ok {
+		switch kind {
+		case "c":
+			filter.Category = value
+		case "s":
+			filter.SubjectID, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+
+	return filter
+}
+
+// parseFindTimeQuery разбирает пожелания из callback data после префикса; rest — оставшиеся части
+func parseFindTimeQuery(data, prefix string) (findTimeQuery, []string, bool) {
+	parts := strings.Split(strings.TrimPrefix(data, prefix), ":")
+	if len(parts) < 3 {
+		return findTimeQuery{}, nil, false
+	}
+
+	days, errDays := strconv.Atoi(parts[1])
+	periods, errPeriods := strconv.Atoi(parts[2])
+	if errDays != nil || errPeriods != nil {
+		return findTimeQuery{}, nil, false
+	}
+
+	return findTimeQuery{Selector: parts[0], Days: days, Periods: periods}, parts[3:], true
+}
+
+// HandleFindTime показывает выбор предмета или категории для подбора времени
+func HandleFindTime(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subjects, err := h.AccessService.GetAccessibleSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get accessible subjects", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке предметов")
+		return
+	}
+
+	kb := keyboard.NewBuilder()
+	text := "🕐 <b>Подобрать время</b>\n\n"
+
+	if len(subjects) == 0 {
+		text += "Пока нет доступных предметов. Найдите учителя в разделе «Публичные учителя» или по коду приглашения."
+		kb.Row(keyboard.BackButton("subjects_menu"))
+		editFindTimeMessage(ctx, b, callback, text, kb.Build())
+		common.AnswerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	text += "Покажу свободные окна сразу у всех доступных вам учителей — не нужно открывать расписание каждого предмета.\n\n" +
+		"Что хотите изучать?"
+
+	// Категории, в которых есть доступные предметы
+	categoryCounts := make(map[string]int)
+	for _, subject := range subjects {
+		categoryCounts[subject.Category]++
+	}
+	var row []models.InlineKeyboardButton
+	for _, category := range model.SubjectCategories {
+		if count := categoryCounts[category.Code]; count > 0 {
+			row = append(row, keyboard.Button(
+				fmt.Sprintf("%s %s (%d)", category.Emoji, category.Title, count),
+				fmt.Sprintf("ftw:%s", findTimeQuery{Selector: "c." + category.Code}),
+			))
+			if len(row) == 2 {
+				kb.AddRow(row)
+				row = nil
+			}
+		}
+	}
+	if len(row) > 0 {
+		kb.AddRow(row)
+	}
+
+	// Предметы с одинаковым названием у разных учителей объединяются в одну кнопку
+	type subjectName struct {
+		Key   string
+		Title string
+		ID    int64
+		Count int
+	}
+	names := make(map[string]*subjectName)
+	for _, subject := range subjects {
+		key := service.SubjectNameKey(subject.Name)
+		if existing, ok := names[key]; ok {
+			existing.Count++
+			if subject.ID < existing.ID {
+				existing.ID = subject.ID
+			}
+			continue
+		}
+		names[key] = &subjectName{Key: key, Title: subject.Name, ID: subject.ID, Count: 1}
+	}
+	sorted := make([]*subjectName, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	if len(sorted) > findTimeMaxNames {
+		sorted = sorted[:findTimeMaxNames]
+	}
+
+	for _, name := range sorted {
+		label := "📚 " + name.Title
+		if name.Count > 1 {
+			label += fmt.Sprintf(" (%d %s)", name.Count, formatting.PluralizeTeachers(name.Count))
+		}
+		kb.Row(keyboard.Button(label, fmt.Sprintf("ftw:%s", findTimeQuery{Selector: fmt.Sprintf("s.%d", name.ID)})))
+	}
+
+	kb.Row(keyboard.Button("🎲 Любой предмет", fmt.Sprintf("ftw:%s", findTimeQuery{Selector: "all"})))
+	kb.Row(keyboard.BackButton("subjects_menu"))
+
+	editFindTimeMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleFindTimeWhen показывает выбор удобных дней недели и части дня
+func HandleFindTimeWhen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: ftw:<выбор>:<дни>:<части дня>
+	query, _, ok := parseFindTimeQuery(callback.Data, "ftw:")
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	text := "🕐 <b>Подобрать время</b>\n\n" +
+		fmt.Sprintf("Предмет: %s\n\n", findTimeSelectorTitle(ctx, h, query.Selector)) +
+		"Отметьте удобные дни и время. Если ничего не отмечать, подойдёт любой день или любое время."
+
+	kb := keyboard.NewBuilder()
+
+	// Дни недели с понедельника
+	var days []models.InlineKeyboardButton
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		label := formatting.GetWeekdayShort(int(day))
+		if query.Days&(1<<day) != 0 {
+			label = "✅" + label
+		}
+		toggled := query
+		toggled.Days ^= 1 << day
+		days = append(days, keyboard.Button(label, fmt.Sprintf("ftw:%s", toggled)))
+	}
+	kb.AddRow(days)
+
+	var periods []models.InlineKeyboardButton
+	for i, period := range model.DayPeriods {
+		label := fmt.Sprintf("%s %s", period.Emoji, period.Title)
+		if query.Periods&(1<<i) != 0 {
+			label = "✅ " + period.Title
+		}
+		toggled := query
+		toggled.Periods ^= 1 << i
+		periods = append(periods, keyboard.Button(label, fmt.Sprintf("ftw:%s", toggled)))
+	}
+	kb.AddRow(periods)
+
+	kb.Row(keyboard.Button("🔍 Показать свободное время", fmt.Sprintf("ftr:%s:1", query)))
+	kb.Row(keyboard.BackButton("find_time"))
+
+	editFindTimeMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleFindTimeResults показывает подходящие свободные слоты; нажатие на слот сразу записывает на него
+func HandleFindTimeResults(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: ftr:<выбор>:<дни>:<части дня>:<страница>
+	query, rest, ok := parseFindTimeQuery(callback.Data, "ftr:")
+	if !ok || len(rest) != 1 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+	page, err := strconv.Atoi(rest[0])
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	slots, err := h.AccessService.FindFreeTime(ctx, user.ID, query.filter())
+	if err != nil {
+		if err.Error() == "no subjects" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет больше недоступен, выберите другой")
+			return
+		}
+		h.Logger.Error("Failed to find free time", zap.Int64("user_id", user.ID), zap.String("query", query.String()), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при подборе времени")
+		return
+	}
+
+	text := "🕐 <b>Свободное время</b>\n\n" +
+		fmt.Sprintf("Предмет: %s\n", findTimeSelectorTitle(ctx, h, query.Selector)) +
+		fmt.Sprintf("Когда: %s\n\n", findTimeWhenTitle(query))
+
+	kb := keyboard.NewBuilder()
+
+	if len(slots) == 0 {
+		text += "В ближайшие 2 недели подходящих окон нет. Попробуйте отметить больше дней или другое время."
+	} else {
+		totalPages := (len(slots) + findTimePerPage - 1) / findTimePerPage
+		if page < 1 {
+			page = 1
+		}
+		if page > totalPages {
+			page = totalPages
+		}
+
+		text += fmt.Sprintf("Нашлось окон: %d. Нажмите на время, чтобы записаться.\n\n", len(slots))
+
+		start := (page - 1) * findTimePerPage
+		end := start + findTimePerPage
+		if end > len(slots) {
+			end = len(slots)
+		}
+
+		for i, found := range slots[start:end] {
+			slot := found.Slot
+			text += fmt.Sprintf("<b>%d.</b> %s %s %s — %s\n   👤 %s · %s · %s\n",
+				start+i+1,
+				formatting.GetWeekdayShort(int(slot.StartTime.Weekday())),
+				slot.StartTime.Format("02.01"),
+				formatting.FormatTimeRange(slot.StartTime, slot.EndTime),
+				html.EscapeString(found.Subject.Name),
+				html.EscapeString(found.TeacherName),
+				formatting.FormatPriceShort(found.Subject.Price, found.Subject.Currency),
+				formatting.FormatDuration(found.Subject.Duration),
+			)
+			if found.Subject.RequiresBookingApproval {
+				text += "   ⏳ Нужно одобрение учителя\n"
+			}
+
+			kb.Row(keyboard.Button(
+				fmt.Sprintf("✅ %d. %s %s %s", start+i+1,
+					formatting.GetWeekdayShort(int(slot.StartTime.Weekday())),
+					slot.StartTime.Format("02.01 15:04"),
+					found.TeacherName),
+				fmt.Sprintf("book_lesson:%d", slot.ID),
+			))
+		}
+
+		if totalPages > 1 {
+			var paginationRow []models.InlineKeyboardButton
+			if page > 1 {
+				paginationRow = append(paginationRow, keyboard.Button("◀️ Назад", fmt.Sprintf("ftr:%s:%d", query, page-1)))
+			}
+			paginationRow = append(paginationRow, keyboard.Button(fmt.Sprintf("%d/%d", page, totalPages), "noop"))
+			if page < totalPages {
+				paginationRow = append(paginationRow, keyboard.Button("Вперёд ▶️", fmt.Sprintf("ftr:%s:%d", query, page+1)))
+			}
+			kb.AddRow(paginationRow)
+		}
+	}
+
+	kb.Row(keyboard.Button("✏️ Изменить дни и время", fmt.Sprintf("ftw:%s", query)))
+	kb.Row(keyboard.Button("📚 Другой предмет", "find_time"))
+
+	editFindTimeMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// findTimeSelectorTitle описывает выбранный предмет или категорию
+func findTimeSelectorTitle(ctx context.Context, h *callbacktypes.Handler, selector string) string {
+	kind, value, _ := strings.Cut(selector, ".")
+	switch kind {
+	case "c":
+		return formatting.FormatSubjectCategory(value)
+	case "s":
+		if subjectID, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID); err == nil && subject != nil {
+				return "📚 " + html.EscapeString(subject.Name)
+			}
+		}
+	}
+	return "🎲 любой"
+}
+
+// findTimeWhenTitle описывает выбранные дни и части дня
+func findTimeWhenTitle(query findTimeQuery) string {
+	return formatting.FormatTimeWish(query.Days, query.Periods)
+}
+
+// editFindTimeMessage заменяет экран подбора времени в сообщении callback
+func editFindTimeMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
@@ -0,0 +1,104 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Student Lesson Notes Handlers
+// ========================
+
+// HandleBookingNotes присылает студенту заметки учителя к записи.
+// Список отправляется новым сообщением, чтобы не затирать карточку записи из /mybookings
+func HandleBookingNotes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: booking_notes:bookingID
+	bookingID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	_, notes, err := h.LessonNoteService.GetBookingNotes(ctx, user.ID, bookingID)
+	if err != nil {
+		if err.Error() != "booking not found" {
+			h.Logger.Error("Failed to get booking notes", zap.Int64("booking_id", bookingID), zap.Error(err))
+		}
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Запись не найдена")
+		return
+	}
+
+	if len(notes) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Учитель пока не оставил заметок к этому занятию")
+		return
+	}
+
+	text := fmt.Sprintf(
+		"📝 <b>Заметки к занятию</b>\n📚 %s, %s\n\n",
+		html.EscapeString(notes[0].SubjectName),
+		notes[0].LessonStart.Format("02.01.2006 15:04"),
+	)
+
+	sendNotesList(ctx, b, callback, text, notes, false)
+}
+
+// HandleMyNotes присылает студенту последние заметки всех его учителей
+func HandleMyNotes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	notes, err := h.LessonNoteService.GetMyHistory(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get student notes", zap.Int64("student_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке заметок")
+		return
+	}
+
+	if len(notes) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Заметок к занятиям пока нет")
+		return
+	}
+
+	sendNotesList(ctx, b, callback, "📚 <b>Заметки и домашние задания</b>\n\n", notes, true)
+}
+
+// sendNotesList отправляет список заметок с кнопками полного просмотра
+func sendNotesList(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, header string, notes []*model.LessonNote, withLesson bool) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	list, shown := common.BuildLessonNotesList(notes, withLesson)
+
+	kb := keyboard.NewBuilder()
+	common.LessonNoteViewButtons(kb, notes, shown)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      msg.Chat.ID,
+		Text:        header + list,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
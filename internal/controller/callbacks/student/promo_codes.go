@@ -0,0 +1,72 @@
+package student
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleEnterPromoCode запрашивает у студента промокод для предмета
+func HandleEnterPromoCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	telegramID := callback.From.ID
+	h.StateManager.SetState(telegramID, callbacktypes.UserState(state.StateEnteringPromoCode))
+	h.StateManager.SetData(telegramID, "subject_id", subjectID)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text: "🏷 Отправьте промокод от преподавателя.\n\n" +
+			"Скидка сработает при следующей записи на этот предмет.\n\n" +
+			"Для отмены используйте /cancel",
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: fmt.Sprintf("view_subject:%d", subjectID)}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleRemovePromoCode убирает применённый промокод и возвращает к предмету
+func HandleRemovePromoCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	if err := h.PromoCodeService.RemoveAppliedPromoCode(ctx, user.ID, subjectID); err != nil {
+		h.Logger.Error("Failed to remove applied promo code",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось убрать промокод")
+		return
+	}
+
+	HandleViewSubjectDetails(ctx, b, callback, h)
+}
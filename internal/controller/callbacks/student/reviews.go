@@ -0,0 +1,138 @@
+package student
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Student Review Handlers
+// ========================
+
+// HandleReviewRate сохраняет оценку занятия из просьбы об отзыве и предлагает добавить комментарий
+func HandleReviewRate(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: review_rate:bookingID:rating
+	ids := common.ParseMultiIDFromCallback(callback.Data, "review_rate:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	review, err := h.ReviewService.Rate(ctx, user.ID, ids[0], int(ids[1]))
+	if err != nil {
+		switch err.Error() {
+		case "already reviewed":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "Вы уже оценили это занятие")
+		case "lesson not completed":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Оценить можно только состоявшееся занятие")
+		case "booking not found", "invalid rating":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Запись не найдена")
+		default:
+			h.Logger.Error("Failed to rate lesson", zap.Int64("booking_id", ids[0]), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сохранить оценку")
+		}
+		return
+	}
+
+	text := fmt.Sprintf(
+		"✅ <b>Спасибо за оценку!</b> %s\n\n"+
+			"Расскажите парой слов, что понравилось или что можно улучшить — комментарий увидят другие студенты в профиле учителя.",
+		formatting.FormatStars(review.Rating),
+	)
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.Button("✍️ Добавить комментарий", fmt.Sprintf("review_comment:%d", review.ID)))
+	kb.Row(keyboard.Button("Без комментария", fmt.Sprintf("review_skip:%d", review.ID)))
+
+	editReviewMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleReviewComment просит студента написать комментарий к отзыву
+func HandleReviewComment(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: review_comment:reviewID
+	reviewID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	review, err := h.ReviewService.GetReview(ctx, user.ID, reviewID)
+	if err != nil || review.StudentID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Отзыв не найден")
+		return
+	}
+
+	if review.Comment != "" {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Комментарий к этому отзыву уже добавлен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateReviewComment))
+	h.StateManager.SetData(callback.From.ID, "review_id", review.ID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text: fmt.Sprintf("✍️ <b>Комментарий к отзыву</b>\n\n"+
+			"Напишите, как прошло занятие (до %d символов).\n\n"+
+			"Для отмены используйте /cancel", service.MaxReviewCommentLength),
+		ParseMode: models.ParseModeHTML,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleReviewSkip завершает отзыв без комментария
+func HandleReviewSkip(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	editReviewMessage(ctx, b, callback, "✅ <b>Спасибо за оценку!</b>\n\nОна уже учтена в рейтинге учителя.", nil)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// editReviewMessage заменяет сообщение с просьбой об отзыве
+func editReviewMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	params := &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	}
+	if kb != nil {
+		params.ReplyMarkup = kb
+	}
+
+	b.EditMessageText(ctx, params)
+}
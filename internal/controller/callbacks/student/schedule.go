@@ -7,6 +7,7 @@ import (
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -58,7 +59,13 @@ func HandleViewScheduleSubject(ctx context.Context, b *bot.Bot, callback *models
 		zap.Time("from", now),
 		zap.Time("to", endDate))
 
-	slots, err := h.BookingService.GetAvailableSlots(ctx, subjectID, now, endDate)
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	slots, err := h.BookingService.GetAvailableSlots(ctx, user.ID, subjectID, now, endDate)
 	if err != nil {
 		h.Logger.Error("Failed to get available slots",
 			zap.Int64("subject_id", subjectID),
@@ -104,11 +111,11 @@ func HandleViewScheduleSubject(ctx context.Context, b *bot.Bot, callback *models
 
 	// Формируем текст и кнопки
 	text := fmt.Sprintf("📅 <b>Расписание: %s</b>\n\n"+
-		"💰 Цена: %.2f ₽\n"+
+		"💰 Цена: %s\n"+
 		"⏱ Длительность: %d мин\n\n"+
 		"Доступные слоты на ближайшие 2 недели:\n\n",
 		subject.Name,
-		float64(subject.Price)/100,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration)
 
 	var buttons [][]models.InlineKeyboardButton
@@ -206,7 +213,13 @@ func HandleViewExtendedSlots(ctx context.Context, b *bot.Bot, callback *models.C
 		zap.Time("from", startDate),
 		zap.Time("to", endDate))
 
-	slots, err := h.BookingService.GetAvailableSlots(ctx, subjectID, startDate, endDate)
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	slots, err := h.BookingService.GetAvailableSlots(ctx, user.ID, subjectID, startDate, endDate)
 	if err != nil {
 		h.Logger.Error("Failed to get extended slots", zap.Error(err))
 		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось загрузить слоты")
@@ -215,11 +228,11 @@ func HandleViewExtendedSlots(ctx context.Context, b *bot.Bot, callback *models.C
 
 	text := fmt.Sprintf("🔮 <b>Расширенное расписание: %s</b>\n\n"+
 		"📅 Период: с %s по %s\n"+
-		"💰 Цена: %.2f ₽ | ⏱ %d мин\n\n",
+		"💰 Цена: %s | ⏱ %d мин\n\n",
 		subject.Name,
 		startDate.Format("02.01"),
 		endDate.Format("02.01"),
-		float64(subject.Price)/100,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration)
 
 	if len(slots) == 0 {
@@ -325,9 +338,9 @@ func HandleRequestRecurringBooking(ctx context.Context, b *bot.Bot, callback *mo
 	}
 
 	text := fmt.Sprintf("🔄 <b>Постоянная запись: %s</b>\n\n"+
-		"💰 Цена: %.2f ₽ | ⏱ %d мин\n\n",
+		"💰 Цена: %s | ⏱ %d мин\n\n",
 		subject.Name,
-		float64(subject.Price)/100,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration)
 
 	if len(recurringSchedules) == 0 {
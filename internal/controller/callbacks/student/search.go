@@ -0,0 +1,111 @@
+package student
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Subject Search Handlers
+// ========================
+
+// HandleSubjectSearch включает режим поиска: следующее текстовое сообщение студента — поисковый запрос
+func HandleSubjectSearch(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Фильтры прошлого поиска сохраняются для нового запроса
+	filter, _ := common.SubjectSearchFromState(h, callback.From.ID)
+	filter.Query = ""
+
+	h.StateManager.ClearState(callback.From.ID)
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateSearchingTeacher))
+	h.StateManager.SetData(callback.From.ID, common.SubjectSearchFilterKey, filter)
+
+	text, kb := common.BuildSubjectSearchPrompt()
+	editSearchMessage(ctx, b, callback, text, kb)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleSubjectSearchFilter переключает фильтр поиска и обновляет результаты
+func HandleSubjectSearchFilter(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	filter, ok := common.SubjectSearchFromState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Поиск завершён. Начните новый поиск из /subjects")
+		return
+	}
+
+	switch callback.Data {
+	case "subject_search_price":
+		filter = common.NextSubjectSearchPrice(filter)
+	case "subject_search_duration":
+		filter = common.NextSubjectSearchDuration(filter)
+	case "subject_search_category":
+		filter = common.NextSubjectSearchCategory(filter)
+	case "subject_search_free":
+		filter.HasFreeSlots = !filter.HasFreeSlots
+	}
+
+	h.StateManager.SetData(callback.From.ID, common.SubjectSearchFilterKey, filter)
+	showSearchResults(ctx, b, callback, h, filter, 1)
+}
+
+// HandleSubjectSearchPage показывает страницу результатов поиска
+func HandleSubjectSearchPage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: subject_search_page:2
+	page, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "subject_search_page:"))
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	filter, ok := common.SubjectSearchFromState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Поиск завершён. Начните новый поиск из /subjects")
+		return
+	}
+
+	showSearchResults(ctx, b, callback, h, filter, page)
+}
+
+// showSearchResults повторяет поиск с фильтром и перерисовывает результаты в сообщении callback
+func showSearchResults(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, filter model.SubjectSearchFilter, page int) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	results, ratings, err := common.SearchSubjectsForStudent(ctx, h.AccessService, h.ReviewService, h.Logger, user.ID, filter)
+	if err != nil {
+		h.Logger.Error("Failed to search subjects", zap.String("query", filter.Query), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при поиске")
+		return
+	}
+
+	text, kb := common.BuildSubjectSearchScreen(filter, results, ratings, page)
+	editSearchMessage(ctx, b, callback, text, kb)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// editSearchMessage заменяет экран поиска в сообщении callback
+func editSearchMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
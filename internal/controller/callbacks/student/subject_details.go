@@ -5,6 +5,7 @@ import (
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -23,6 +24,11 @@ func HandleViewSubjectDetails(ctx context.Context, b *bot.Bot, callback *models.
 		return
 	}
 
+	showStudentSubjectDetails(ctx, b, callback, h, subjectID, "")
+}
+
+// showStudentSubjectDetails перерисовывает карточку предмета для студента; notice показывается во всплывающем ответе
+func showStudentSubjectDetails(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, subjectID int64, notice string) {
 	msg := common.GetMessageFromCallback(callback)
 	if msg == nil {
 		h.Logger.Error("Failed to get message from callback")
@@ -56,8 +62,43 @@ func HandleViewSubjectDetails(ctx context.Context, b *bot.Bot, callback *models.
 		}
 	}
 
+	// Пакеты занятий и остаток оплаченных занятий студента
+	packages, err := h.CreditService.GetActiveSubjectPackages(ctx, subjectID)
+	if err != nil {
+		h.Logger.Error("Failed to get lesson packages",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+	}
+
+	credits := 0
+	var promo *model.PromoCode
+	isFavorite := false
+	if user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID); err == nil && user != nil {
+		credits, err = h.CreditService.GetSubjectBalance(ctx, user.ID, subjectID)
+		if err != nil {
+			h.Logger.Error("Failed to get credit balance",
+				zap.Int64("subject_id", subjectID),
+				zap.Error(err))
+		}
+
+		// Применённый студентом промокод
+		promo, err = h.PromoCodeService.GetAppliedPromoCode(ctx, user.ID, subject)
+		if err != nil {
+			h.Logger.Error("Failed to get applied promo code",
+				zap.Int64("subject_id", subjectID),
+				zap.Error(err))
+		}
+
+		isFavorite, err = h.FavoriteService.IsSubjectFavorite(ctx, user.ID, subjectID)
+		if err != nil {
+			h.Logger.Error("Failed to check favorite subject",
+				zap.Int64("subject_id", subjectID),
+				zap.Error(err))
+		}
+	}
+
 	// Используем билдер экрана
-	text, keyboard := common.BuildStudentSubjectDetailsScreen(subject, teacherName)
+	text, keyboard := common.BuildStudentSubjectDetailsScreen(subject, teacherName, packages, credits, promo, isFavorite)
 
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:      msg.Chat.ID,
@@ -67,5 +108,5 @@ func HandleViewSubjectDetails(ctx context.Context, b *bot.Bot, callback *models.
 		ReplyMarkup: keyboard,
 	})
 
-	common.AnswerCallback(ctx, b, callback.ID, "")
+	common.AnswerCallback(ctx, b, callback.ID, notice)
 }
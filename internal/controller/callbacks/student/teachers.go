@@ -6,8 +6,10 @@ import (
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -17,11 +19,11 @@ const itemsPerPage = 5
 
 // HandlePublicTeachers показывает список публичных учителей с пагинацией
 func HandlePublicTeachers(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
-	HandlePublicTeachersPage(ctx, b, callback, h, 1)
+	HandlePublicTeachersPage(ctx, b, callback, h, 1, false)
 }
 
-// HandlePublicTeachersPage показывает страницу публичных учителей
-func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, page int) {
+// HandlePublicTeachersPage показывает страницу публичных учителей; sortByRating ставит первыми учителей с высокой оценкой
+func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, page int, sortByRating bool) {
 	// Получаем публичных учителей
 	teachers, err := h.AccessService.GetPublicTeachers(ctx)
 	if err != nil {
@@ -30,6 +32,21 @@ func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.
 		return
 	}
 
+	ratings, err := h.ReviewService.GetRatings(ctx, teachers)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher ratings", zap.Error(err))
+	}
+
+	if sortByRating {
+		service.SortTeachersByRating(teachers, ratings)
+	}
+
+	// Пагинация сохраняет выбранный порядок
+	pagePrefix := "public_teachers_page"
+	if sortByRating {
+		pagePrefix = "public_teachers_rated"
+	}
+
 	totalTeachers := len(teachers)
 	totalPages := (totalTeachers + itemsPerPage - 1) / itemsPerPage
 
@@ -81,6 +98,9 @@ func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.
 			}
 
 			text += fmt.Sprintf("%d. *%s*\n", start+i+1, name)
+			if rating := ratings[teacher.ID]; rating != nil {
+				text += fmt.Sprintf("   %s\n", formatting.FormatRating(rating))
+			}
 			if subjectNames != "" {
 				text += fmt.Sprintf("   📚 %s\n", subjectNames)
 			}
@@ -90,6 +110,14 @@ func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.
 	// Формируем клавиатуру
 	kb := keyboard.NewBuilder()
 
+	if totalTeachers > 1 {
+		if sortByRating {
+			kb.Row(keyboard.Button("🔤 Обычный порядок", "public_teachers_page:1"))
+		} else {
+			kb.Row(keyboard.Button("⭐ Сначала с высоким рейтингом", "public_teachers_rated:1"))
+		}
+	}
+
 	// Добавляем кнопки учителей на текущей странице
 	if totalTeachers > 0 {
 		start := (page - 1) * itemsPerPage
@@ -114,20 +142,21 @@ func HandlePublicTeachersPage(ctx context.Context, b *bot.Bot, callback *models.
 		if totalPages > 1 {
 			paginationRow := []models.InlineKeyboardButton{}
 			if page > 1 {
-				paginationRow = append(paginationRow, keyboard.Button("◀️ Назад", fmt.Sprintf("public_teachers_page:%d", page-1)))
+				paginationRow = append(paginationRow, keyboard.Button("◀️ Назад", fmt.Sprintf("%s:%d", pagePrefix, page-1)))
 			}
 			paginationRow = append(paginationRow, keyboard.Button(
 				fmt.Sprintf("%d/%d", page, totalPages),
 				"noop",
 			))
 			if page < totalPages {
-				paginationRow = append(paginationRow, keyboard.Button("Вперёд ▶️", fmt.Sprintf("public_teachers_page:%d", page+1)))
+				paginationRow = append(paginationRow, keyboard.Button("Вперёд ▶️", fmt.Sprintf("%s:%d", pagePrefix, page+1)))
 			}
 			kb.AddRow(paginationRow)
 		}
 	}
 
 	// Навигация
+	kb.Row(keyboard.Button("🔎 Поиск предметов", "subject_search"))
 	kb.Row(keyboard.BackButton("subjects_menu"))
 
 	common.AnswerCallback(ctx, b, callback.ID, "")
@@ -151,6 +180,11 @@ func HandleTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.Call
 		return
 	}
 
+	showTeacherProfile(ctx, b, callback, h, teacherID, "")
+}
+
+// showTeacherProfile перерисовывает профиль учителя; notice показывается во всплывающем ответе
+func showTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, teacherID int64, notice string) {
 	telegramID := callback.From.ID
 	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
 	if err != nil || user == nil {
@@ -188,11 +222,17 @@ func HandleTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.Call
 	text := fmt.Sprintf("👤 *%s*\n\n", teacherName)
 
 	if teacher.IsPublic {
-		text += "🌍 Публичный учитель\n\n"
+		text += "🌍 Публичный учитель\n"
 	} else {
-		text += "🔒 Приватный учитель\n\n"
+		text += "🔒 Приватный учитель\n"
 	}
 
+	rating, err := h.ReviewService.GetTeacherRating(ctx, teacherID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher rating", zap.Int64("teacher_id", teacherID), zap.Error(err))
+	}
+	text += formatting.FormatRating(rating) + "\n\n"
+
 	// Активные предметы
 	activeSubjects := 0
 	for _, subj := range subjects {
@@ -212,11 +252,20 @@ func HandleTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.Call
 				if subj.Description != "" {
 					text += fmt.Sprintf("  %s\n", subj.Description)
 				}
-				text += fmt.Sprintf("  💰 %d₽/занятие • ⏱ %d мин\n\n", subj.Price, subj.Duration)
+				text += fmt.Sprintf("  💰 %s/занятие • ⏱ %d мин\n\n", formatting.FormatPriceShort(subj.Price, subj.Currency), subj.Duration)
 			}
 		}
 	}
 
+	// Последние отзывы студентов
+	if rating != nil {
+		reviews, err := h.ReviewService.GetPublicReviews(ctx, teacherID)
+		if err != nil {
+			h.Logger.Error("Failed to get teacher reviews", zap.Int64("teacher_id", teacherID), zap.Error(err))
+		}
+		text += formatProfileReviews(reviews)
+	}
+
 	// Формируем клавиатуру
 	kb := keyboard.NewBuilder()
 
@@ -230,10 +279,20 @@ func HandleTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.Call
 		}
 	}
 
+	isFavorite, err := h.FavoriteService.IsTeacherFavorite(ctx, user.ID, teacherID)
+	if err != nil {
+		h.Logger.Error("Failed to check favorite teacher", zap.Int64("teacher_id", teacherID), zap.Error(err))
+	}
+	if isFavorite {
+		kb.Row(keyboard.Button("💔 Убрать из избранного", fmt.Sprintf("fav_teacher:%d", teacherID)))
+	} else {
+		kb.Row(keyboard.Button("❤️ В избранное", fmt.Sprintf("fav_teacher:%d", teacherID)))
+	}
+
 	// Навигация
 	kb.Row(keyboard.BackButton("public_teachers"))
 
-	common.AnswerCallback(ctx, b, callback.ID, "")
+	common.AnswerCallback(ctx, b, callback.ID, notice)
 	msg := common.GetMessageFromCallback(callback)
 	if msg != nil {
 		b.EditMessageText(ctx, &bot.EditMessageTextParams{
@@ -245,3 +304,36 @@ func HandleTeacherProfile(ctx context.Context, b *bot.Bot, callback *models.Call
 		})
 	}
 }
+
+// formatProfileReviews форматирует последние отзывы для профиля учителя (Markdown)
+func formatProfileReviews(reviews []*model.Review) string {
+	text := ""
+	for _, review := range reviews {
+		if review.Comment == "" {
+			continue
+		}
+
+		comment := []rune(review.Comment)
+		if len(comment) > 300 {
+			comment = append(comment[:300], '…')
+		}
+
+		text += fmt.Sprintf("%s _%s_\n%s\n",
+			formatting.FormatStars(review.Rating),
+			formatting.EscapeMarkdown(review.StudentName),
+			formatting.EscapeMarkdown(string(comment)),
+		)
+		if review.TeacherReply != "" {
+			text += fmt.Sprintf("↩️ %s\n", formatting.EscapeMarkdown(review.TeacherReply))
+		}
+		if review.IsReported() {
+			text += "⚠️ Учитель оспаривает этот отзыв\n"
+		}
+		text += "\n"
+	}
+
+	if text == "" {
+		return ""
+	}
+	return "💬 *Отзывы студентов:*\n\n" + text
+}
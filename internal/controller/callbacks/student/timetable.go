@@ -0,0 +1,50 @@
+package student
+
+import (
+	"context"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleStudentTimetablePDF отправляет PDF-расписание занятий студента у всех учителей на неделю или месяц
+func HandleStudentTimetablePDF(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: student_pdf:<w|m>:<смещение в неделях или месяцах от текущих>
+	period, offset, err := common.ParseTimetableCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	from, to, err := common.TimetableRange(period, offset, time.Now())
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	bookings, err := h.BookingService.GetStudentLessons(ctx, user.ID, from, to)
+	if err != nil {
+		h.Logger.Error("Failed to get student lessons", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке занятий")
+		return
+	}
+
+	timetable := common.StudentTimetable(user, bookings, period, from, to)
+	if err := common.SendTimetablePDF(ctx, b, callback.From.ID, timetable); err != nil {
+		h.Logger.Error("Failed to send student timetable", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сформировать PDF")
+		return
+	}
+
+	common.AnswerCallback(ctx, b, callback.ID, "📄 Расписание отправлено")
+}
@@ -0,0 +1,134 @@
+package student
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// studentWeekMaxOffset на сколько недель назад и вперёд можно листать неделю студента
+	studentWeekMaxOffset = 12
+)
+
+// HandleStudentWeek показывает картинку недели со всеми занятиями студента у разных учителей
+func HandleStudentWeek(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: student_week:<смещение в неделях от текущей>
+	offset, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil || offset < -studentWeekMaxOffset || offset > studentWeekMaxOffset {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	bookings, weekStart, err := h.BookingService.GetStudentWeekLessons(ctx, user.ID, time.Now().AddDate(0, 0, int(offset)*7))
+	if err != nil {
+		h.Logger.Error("Failed to get student week lessons", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке занятий")
+		return
+	}
+
+	text := buildStudentWeekCaption(weekStart, bookings)
+	now := time.Now()
+	monthOffset := (weekStart.Year()-now.Year())*12 + int(weekStart.Month()-now.Month())
+	kb := buildStudentWeekKeyboard(int(offset), monthOffset)
+
+	imageData, err := common.GenerateStudentWeekImage(weekStart, bookings)
+	if err != nil {
+		h.Logger.Error("Failed to generate student week image", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось построить картинку недели")
+		return
+	}
+
+	// Отправляем изображение с подписью и удаляем старое сообщение
+	b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:      msg.Chat.ID,
+		Photo:       &models.InputFileUpload{Filename: "week.png", Data: bytes.NewReader(imageData)},
+		Caption:     text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// buildStudentWeekCaption формирует подпись к картинке недели студента
+func buildStudentWeekCaption(weekStart time.Time, bookings []*model.Booking) string {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	text := fmt.Sprintf("🗓 <b>Мои занятия</b>\n\n📍 %s – %s\n\n", weekStart.Format("02.01"), weekEnd.Format("02.01.2006"))
+
+	if len(bookings) == 0 {
+		return text + "На этой неделе занятий нет."
+	}
+
+	pending, cancelRequested := 0, 0
+	for _, booking := range bookings {
+		switch {
+		case booking.CancellationRequested:
+			cancelRequested++
+		case booking.Status == model.BookingStatusPending || booking.Status == model.BookingStatusAwaitingPayment:
+			pending++
+		}
+	}
+
+	text += fmt.Sprintf("📚 %d %s", len(bookings), formatting.PluralizeLessons(len(bookings)))
+	if pending > 0 {
+		text += fmt.Sprintf("\n⏳ Ожидают подтверждения или оплаты: %d", pending)
+	}
+	if cancelRequested > 0 {
+		text += fmt.Sprintf("\n🚫 Запрошена отмена: %d", cancelRequested)
+	}
+
+	return text + "\n\nЦвет занятия — предмет, подпись — учитель. Пунктир — запись ещё не подтверждена, штриховка — запрошена отмена."
+}
+
+// buildStudentWeekKeyboard формирует кнопки листания недель и выгрузки PDF;
+// monthOffset — смещение месяца, в который попадает неделя
+func buildStudentWeekKeyboard(offset, monthOffset int) *models.InlineKeyboardMarkup {
+	kb := keyboard.NewBuilder()
+
+	var nav []models.InlineKeyboardButton
+	if offset > -studentWeekMaxOffset {
+		nav = append(nav, keyboard.Button("⬅️ Пред. неделя", fmt.Sprintf("student_week:%d", offset-1)))
+	}
+	if offset < studentWeekMaxOffset {
+		nav = append(nav, keyboard.Button("След. неделя ➡️", fmt.Sprintf("student_week:%d", offset+1)))
+	}
+	kb.AddRow(nav)
+
+	if offset != 0 {
+		kb.Row(keyboard.Button("📍 Текущая неделя", "student_week:0"))
+	}
+	kb.Row(
+		keyboard.Button("📄 PDF недели", fmt.Sprintf("student_pdf:%s:%d", common.TimetablePeriodWeek, offset)),
+		keyboard.Button("📄 PDF месяца", fmt.Sprintf("student_pdf:%s:%d", common.TimetablePeriodMonth, monthOffset)),
+	)
+	kb.Row(keyboard.Button("➕ Записаться на занятие", "book_another"))
+
+	return kb.Build()
+}
@@ -54,6 +54,11 @@ func HandleTeacherSettings(ctx context.Context, b *bot.Bot, callback *models.Cal
 	kb.Row(keyboard.Button(fmt.Sprintf("📩 Заявки (%d)", pendingRequests), "view_access_requests"))
 	kb.Row(keyboard.Button(fmt.Sprintf("🎟️ Коды приглашения (%d)", activeCodes), "manage_invite_codes"))
 	kb.Row(keyboard.Button(fmt.Sprintf("👥 Мои студенты (%d)", studentsCount), "view_my_students"))
+	kb.Row(keyboard.Button("🏷 Промокоды", "manage_promo_codes"))
+	kb.Row(keyboard.Button("📈 Отчёты", "teacher_reports"))
+	kb.Row(keyboard.Button("⭐ Отзывы", "my_reviews"))
+	kb.Row(keyboard.Button("📤 Выгрузка данных", "export_menu"))
+	kb.Row(keyboard.Button("💱 Валюта: "+string(user.DefaultCurrency), "default_currency_menu"))
 	kb.Row(keyboard.BackButton("mysubjects"))
 
 	msg := common.GetMessageFromCallback(callback)
@@ -121,6 +126,14 @@ func HandleManageInviteCodes(ctx context.Context, b *bot.Bot, callback *models.C
 		return
 	}
 
+	// Названия групп для кодов приглашения в группу
+	groupNames := make(map[int64]string)
+	if groups, err := h.GroupService.GetTeacherGroups(ctx, user.ID); err == nil {
+		for _, group := range groups {
+			groupNames[group.ID] = group.Name
+		}
+	}
+
 	// Группируем коды
 	var activeCodes, inactiveCodes int
 	for _, code := range codes {
@@ -164,6 +177,17 @@ func HandleManageInviteCodes(ctx context.Context, b *bot.Bot, callback *models.C
 					text += "   Срок: бессрочный\n"
 				}
 
+				// Срок выдаваемого доступа
+				if code.AccessDays != nil {
+					text += fmt.Sprintf("   Доступ по коду: %d дн.\n", *code.AccessDays)
+				}
+
+				if code.GroupID != nil {
+					if name, ok := groupNames[*code.GroupID]; ok {
+						text += fmt.Sprintf("   Группа: %s\n", name)
+					}
+				}
+
 				text += "\n"
 			}
 		}
@@ -206,7 +230,26 @@ func HandleManageInviteCodes(ctx context.Context, b *bot.Bot, callback *models.C
 	}
 }
 
-// HandleCreateInviteCode создает новый код приглашения
+// accessDurationOptions сроки доступа на выбор при создании кода и одобрении заявки, 0 - бессрочно
+var accessDurationOptions = []int{0, 30, 90, 180}
+
+// accessDurationLabel возвращает подпись кнопки срока доступа
+func accessDurationLabel(days int) string {
+	if days == 0 {
+		return "♾ Бессрочно"
+	}
+	return fmt.Sprintf("%d дней", days)
+}
+
+// accessDaysFromOption переводит выбранный срок в параметр сервиса: 0 - бессрочно (nil)
+func accessDaysFromOption(days int) *int {
+	if days == 0 {
+		return nil
+	}
+	return &days
+}
+
+// HandleCreateInviteCode предлагает выбрать срок доступа для нового кода приглашения
 func HandleCreateInviteCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
 	telegramID := callback.From.ID
 	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
@@ -215,11 +258,66 @@ func HandleCreateInviteCode(ctx context.Context, b *bot.Bot, callback *models.Ca
 		return
 	}
 
-	// Создаем код с настройками по умолчанию (без ограничений)
-	inviteCode, err := h.AccessService.CreateInviteCode(ctx, user.ID, nil, nil)
+	text := "🎟️ *Новый код приглашения*\n\n"
+	text += "На какой срок студенты получат доступ по этому коду?\n\n"
+	text += "_Срок отсчитывается с момента ввода кода. Когда он истечёт, доступ отзывается автоматически, " +
+		"а будущие записи студента отменяются._"
+
+	kb := keyboard.NewBuilder()
+	for _, days := range accessDurationOptions {
+		kb.Row(keyboard.Button(accessDurationLabel(days), fmt.Sprintf("create_invite_code:%d", days)))
+	}
+	kb.Row(keyboard.BackButton("manage_invite_codes"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeMarkdown,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleConfirmCreateInviteCode создает новый код приглашения с выбранным сроком доступа
+func HandleConfirmCreateInviteCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	telegramID := callback.From.ID
+	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	// Формат: create_invite_code:days[:groupID]
+	ids := common.ParseMultiIDFromCallback(callback.Data, "create_invite_code:")
+	if len(ids) < 1 || len(ids) > 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+	days := ids[0]
+
+	var groupID *int64
+	backCallback := "manage_invite_codes"
+	if len(ids) == 2 {
+		groupID = &ids[1]
+		backCallback = fmt.Sprintf("view_group:%d", ids[1])
+	}
+
+	// Код без ограничений по количеству и сроку действия самого кода
+	inviteCode, err := h.AccessService.CreateInviteCode(ctx, user.ID, nil, nil, accessDaysFromOption(int(days)), groupID)
 	if err != nil {
 		h.Logger.Error("Failed to create invite code", zap.Error(err))
-		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось создать код")
+		switch err.Error() {
+		case "invalid access period":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Недопустимый срок доступа")
+		case "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		default:
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось создать код")
+		}
 		return
 	}
 
@@ -228,10 +326,22 @@ func HandleCreateInviteCode(ctx context.Context, b *bot.Bot, callback *models.Ca
 	text += "Отправьте этот код студентам для предоставления доступа.\n\n"
 	text += "⚙️ Код создан с настройками:\n"
 	text += "• Без ограничений по количеству\n"
-	text += "• Бессрочный"
+	text += "• Сам код бессрочный\n"
+	if inviteCode.AccessDays != nil {
+		text += fmt.Sprintf("• Доступ по коду действует %d дн.", *inviteCode.AccessDays)
+	} else {
+		text += "• Доступ по коду бессрочный"
+	}
+	if groupID != nil {
+		text += "\n• Студенты с этим кодом попадут в группу; те, у кого уже есть доступ, тоже могут ввести его, чтобы вступить"
+	}
 
 	kb := keyboard.NewBuilder()
-	kb.Row(keyboard.Button("🔙 К кодам", "manage_invite_codes"))
+	if groupID != nil {
+		kb.Row(keyboard.Button("🔙 К группе", backCallback))
+	} else {
+		kb.Row(keyboard.Button("🔙 К кодам", backCallback))
+	}
 
 	common.AnswerCallbackAlert(ctx, b, callback.ID, "✅ Код создан")
 
@@ -0,0 +1,432 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// maxBroadcastPickButtons сколько студентов показывать кнопками при ручном выборе получателей рассылки
+const maxBroadcastPickButtons = 30
+
+// broadcastPeriods периоды записей, по которым можно выбрать получателей рассылки
+var broadcastPeriods = []struct {
+	key   string
+	label string
+}{
+	{"today", "Сегодня"},
+	{"tomorrow", "Завтра"},
+	{"next_week", "Ближайшие 7 дней"},
+	{"last_week", "Прошедшие 7 дней"},
+}
+
+// HandleBroadcastMenu показывает выбор получателей рассылки
+func HandleBroadcastMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	// Новая рассылка начинается с чистого листа
+	h.StateManager.ClearState(callback.From.ID)
+
+	text, kb := common.BuildBroadcastMenuScreen()
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastSubjects показывает предметы учителя для рассылки их студентам
+func HandleBroadcastSubjects(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher subjects", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке предметов")
+		return
+	}
+
+	if len(subjects) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "У вас пока нет предметов")
+		return
+	}
+
+	text := "📚 <b>Рассылка студентам предмета</b>\n\n" +
+		"Сообщение получат студенты, которые записывались на выбранный предмет:"
+
+	kb := keyboard.NewBuilder()
+	for _, subject := range subjects {
+		kb.Row(keyboard.Button(subject.Name, fmt.Sprintf("broadcast_audience:%s:%d", model.BroadcastAudienceSubject, subject.ID)))
+	}
+	kb.Row(keyboard.BackButton("broadcast_menu"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastPeriods показывает периоды, записанным на которые студентам можно отправить рассылку
+func HandleBroadcastPeriods(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	text := "📅 <b>Рассылка записанным студентам</b>\n\n" +
+		"Сообщение получат студенты с занятиями в выбранный период — " +
+		"например, чтобы предупредить о переносе или напомнить взять материалы:"
+
+	kb := keyboard.NewBuilder()
+	for _, period := range broadcastPeriods {
+		kb.Row(keyboard.Button(period.label, fmt.Sprintf("broadcast_audience:%s:%s", model.BroadcastAudienceBooked, period.key)))
+	}
+	kb.Row(keyboard.BackButton("broadcast_menu"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastGroups показывает группы учителя для рассылки
+func HandleBroadcastGroups(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	groups, err := h.GroupService.GetTeacherGroups(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher groups", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке групп")
+		return
+	}
+
+	if len(groups) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "У вас пока нет групп. Создайте их в разделе «Мои студенты» → «Группы студентов»")
+		return
+	}
+
+	text := "🧑‍🤝‍🧑 <b>Рассылка группе</b>\n\nВыберите группу:"
+
+	kb := keyboard.NewBuilder()
+	for _, group := range groups {
+		kb.Row(keyboard.Button(
+			fmt.Sprintf("%s (%d)", group.Name, group.MembersCount),
+			fmt.Sprintf("broadcast_audience:%s:%d", model.BroadcastAudienceGroup, group.ID),
+		))
+	}
+	kb.Row(keyboard.BackButton("broadcast_menu"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastPick показывает студентов для ручного выбора получателей рассылки
+func HandleBroadcastPick(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	showBroadcastPicker(ctx, b, callback, h)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastPickToggle добавляет студента в список получателей или убирает из него
+func HandleBroadcastPickToggle(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: broadcast_pick_toggle:studentID
+	studentID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	picked := getBroadcastPicked(h, callback.From.ID)
+
+	var updated []int64
+	removed := false
+	for _, id := range picked {
+		if id == studentID {
+			removed = true
+			continue
+		}
+		updated = append(updated, id)
+	}
+	if !removed {
+		updated = append(updated, studentID)
+	}
+
+	h.StateManager.SetData(callback.From.ID, "broadcast_picked", updated)
+
+	showBroadcastPicker(ctx, b, callback, h)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showBroadcastPicker перерисовывает список студентов с отметками выбранных
+func showBroadcastPicker(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	students, err := h.AccessService.GetMyStudents(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher students", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке студентов")
+		return
+	}
+
+	if len(students) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "У вас пока нет студентов")
+		return
+	}
+
+	selected := make(map[int64]bool)
+	for _, id := range getBroadcastPicked(h, callback.From.ID) {
+		selected[id] = true
+	}
+
+	text := fmt.Sprintf("✅ <b>Выбор получателей</b>\n\nОтмечено: %d\n\nНажмите на студента, чтобы отметить его или снять отметку:", len(selected))
+	if len(students) > maxBroadcastPickButtons {
+		text += fmt.Sprintf("\n\n<i>Показаны первые %d из %d. Для остальных воспользуйтесь группами.</i>", maxBroadcastPickButtons, len(students))
+		students = students[:maxBroadcastPickButtons]
+	}
+
+	kb := keyboard.NewBuilder()
+	for _, student := range students {
+		mark := "⬜"
+		if selected[student.ID] {
+			mark = "✅"
+		}
+		kb.Row(keyboard.Button(mark+" "+groupMemberName(student), fmt.Sprintf("broadcast_pick_toggle:%d", student.ID)))
+	}
+	if len(selected) > 0 {
+		kb.Row(keyboard.Button(fmt.Sprintf("➡️ Далее (%d)", len(selected)), "broadcast_audience:"+string(model.BroadcastAudiencePicked)))
+	}
+	kb.Row(keyboard.BackButton("broadcast_menu"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+}
+
+// HandleBroadcastAudience запоминает получателей рассылки и просит прислать сообщение
+func HandleBroadcastAudience(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: broadcast_audience:type[:subjectID|groupID|period]
+	parts := strings.Split(strings.TrimPrefix(callback.Data, "broadcast_audience:"), ":")
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	audience, label, ok := parseBroadcastAudience(parts, time.Now())
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	if audience.Type == model.BroadcastAudiencePicked {
+		audience.StudentIDs = getBroadcastPicked(h, callback.From.ID)
+	}
+
+	recipients, err := h.BroadcastService.GetRecipients(ctx, user.ID, audience)
+	if err != nil {
+		switch err.Error() {
+		case "subject not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		case "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		default:
+			h.Logger.Error("Failed to get broadcast recipients", zap.Int64("teacher_id", user.ID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось подобрать получателей")
+		}
+		return
+	}
+
+	if len(recipients) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "Нет студентов, которым можно отправить рассылку")
+		return
+	}
+
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateBroadcastCompose))
+	h.StateManager.SetData(callback.From.ID, "broadcast_audience", audience)
+	h.StateManager.SetData(callback.From.ID, "broadcast_audience_label", label)
+
+	text := fmt.Sprintf(
+		"📣 <b>Рассылка</b>\n\n"+
+			"Получатели: %s (%d)\n\n"+
+			"Отправьте текст сообщения. Можно прислать фото или документ — текст тогда укажите в подписи.\n\n"+
+			"Для отмены используйте /cancel",
+		html.EscapeString(label),
+		len(recipients),
+	)
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.Button("❌ Отмена", "broadcast_cancel"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBroadcastSend запускает рассылку подготовленного сообщения
+func HandleBroadcastSend(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	audienceRaw, _ := h.StateManager.GetData(callback.From.ID, "broadcast_audience")
+	audience, okAudience := audienceRaw.(model.BroadcastAudience)
+	draftRaw, _ := h.StateManager.GetData(callback.From.ID, "broadcast_draft")
+	draft, okDraft := draftRaw.(*model.BroadcastMessage)
+	if !okAudience || !okDraft {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Черновик рассылки не найден. Начните заново: /broadcast")
+		return
+	}
+
+	total, err := h.BroadcastService.Start(ctx, user.ID, audience, draft)
+	if err != nil {
+		switch err.Error() {
+		case "broadcast in progress":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "⏳ Предыдущая рассылка ещё отправляется. Дождитесь отчёта о ней")
+		case "no recipients":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "Нет студентов, которым можно отправить рассылку")
+		case "subject not found", "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Получатели больше недоступны. Начните заново: /broadcast")
+		default:
+			h.Logger.Error("Failed to start broadcast", zap.Int64("teacher_id", user.ID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось запустить рассылку")
+		}
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+
+	text := fmt.Sprintf(
+		"📣 <b>Рассылка запущена</b>\n\n"+
+			"Получателей: %d\n\n"+
+			"Сообщения отправляются постепенно, чтобы не превысить ограничения Telegram. "+
+			"Когда всё будет доставлено, придёт отчёт.",
+		total,
+	)
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	editBroadcastMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "📣 Рассылка запущена")
+}
+
+// HandleBroadcastCancel отменяет подготовку рассылки
+func HandleBroadcastCancel(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	h.StateManager.ClearState(callback.From.ID)
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	editBroadcastMessage(ctx, b, callback, "❌ Рассылка отменена", kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// parseBroadcastAudience разбирает получателей рассылки из callback и возвращает их описание для учителя
+func parseBroadcastAudience(parts []string, now time.Time) (model.BroadcastAudience, string, bool) {
+	audience := model.BroadcastAudience{Type: model.BroadcastAudienceType(parts[0])}
+
+	switch audience.Type {
+	case model.BroadcastAudienceAll:
+		return audience, "все студенты", len(parts) == 1
+	case model.BroadcastAudiencePicked:
+		return audience, "выбранные студенты", len(parts) == 1
+	case model.BroadcastAudienceSubject, model.BroadcastAudienceGroup:
+		if len(parts) != 2 {
+			return audience, "", false
+		}
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return audience, "", false
+		}
+		if audience.Type == model.BroadcastAudienceSubject {
+			audience.SubjectID = id
+			return audience, "студенты предмета", true
+		}
+		audience.GroupID = id
+		return audience, "участники группы", true
+	case model.BroadcastAudienceBooked:
+		if len(parts) != 2 {
+			return audience, "", false
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		switch parts[1] {
+		case "today":
+			audience.From, audience.To = today, today.AddDate(0, 0, 1)
+		case "tomorrow":
+			audience.From, audience.To = today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)
+		case "next_week":
+			audience.From, audience.To = now, today.AddDate(0, 0, 8)
+		case "last_week":
+			audience.From, audience.To = today.AddDate(0, 0, -7), now
+		default:
+			return audience, "", false
+		}
+		for _, period := range broadcastPeriods {
+			if period.key == parts[1] {
+				return audience, "записанные на период «" + period.label + "»", true
+			}
+		}
+	}
+
+	return audience, "", false
+}
+
+// getBroadcastPicked возвращает студентов, отмеченных при ручном выборе получателей
+func getBroadcastPicked(h *callbacktypes.Handler, telegramID int64) []int64 {
+	raw, ok := h.StateManager.GetData(telegramID, "broadcast_picked")
+	if !ok {
+		return nil
+	}
+	picked, _ := raw.([]int64)
+	return picked
+}
+
+// editBroadcastMessage заменяет экран рассылки в сообщении callback
+func editBroadcastMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
@@ -0,0 +1,308 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// grantCreditPresets варианты ручного начисления занятий
+var grantCreditPresets = []int{1, 5, 10}
+
+// HandleStudentCredits показывает остаток оплаченных занятий студента у учителя
+func HandleStudentCredits(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	studentID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	showStudentCreditsScreen(ctx, b, callback, h, user, studentID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleGrantCreditsMenu показывает варианты начисления занятий по предмету
+func HandleGrantCreditsMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: grant_credits_menu:studentID:subjectID
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	studentID, err1 := strconv.ParseInt(parts[1], 10, 64)
+	subjectID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	packages, err := h.CreditService.GetActiveSubjectPackages(ctx, subjectID)
+	if err != nil {
+		h.Logger.Error("Failed to get lesson packages", zap.Int64("subject_id", subjectID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке пакетов")
+		return
+	}
+
+	text := fmt.Sprintf("🎟 <b>Начислить занятия: %s</b>\n\n", subject.Name)
+	if len(packages) > 0 {
+		text += "Пакет — если студент оплатил пакет, занятия получат срок действия пакета.\n"
+	}
+	text += "Отдельные занятия начисляются бессрочно."
+
+	kb := keyboard.NewBuilder()
+	for _, pkg := range packages {
+		kb.Row(keyboard.Button(
+			fmt.Sprintf("🎁 %d %s — %s", pkg.LessonCount, formatting.PluralizeLessons(pkg.LessonCount), formatting.FormatPriceShort(pkg.Price, pkg.Currency)),
+			fmt.Sprintf("grant_package:%d:%d", studentID, pkg.ID),
+		))
+	}
+
+	presetRow := make([]models.InlineKeyboardButton, 0, len(grantCreditPresets))
+	for _, count := range grantCreditPresets {
+		presetRow = append(presetRow, keyboard.Button(
+			fmt.Sprintf("+%d", count),
+			fmt.Sprintf("grant_credits:%d:%d:%d", studentID, subjectID, count),
+		))
+	}
+	kb.AddRow(presetRow)
+	kb.Row(keyboard.BackButton(fmt.Sprintf("student_credits:%d", studentID)))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleGrantCredits вручную начисляет студенту занятия по предмету
+func HandleGrantCredits(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: grant_credits:studentID:subjectID:count
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 4 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	studentID, err1 := strconv.ParseInt(parts[1], 10, 64)
+	subjectID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	count, err3 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	lot, err := h.CreditService.GrantCredits(ctx, user.ID, studentID, subjectID, count)
+	if err != nil {
+		h.Logger.Error("Failed to grant credits",
+			zap.Int64("student_id", studentID),
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось начислить занятия")
+		return
+	}
+
+	notifyCreditsGranted(ctx, b, h, user, lot)
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("✅ Начислено %d %s", lot.Total, formatting.PluralizeLessons(lot.Total)))
+	showStudentCreditsScreen(ctx, b, callback, h, user, studentID)
+}
+
+// HandleGrantPackage начисляет студенту занятия пакета
+func HandleGrantPackage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: grant_package:studentID:packageID
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	studentID, err1 := strconv.ParseInt(parts[1], 10, 64)
+	packageID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	lot, err := h.CreditService.GrantPackage(ctx, user.ID, studentID, packageID)
+	if err != nil {
+		h.Logger.Error("Failed to grant lesson package",
+			zap.Int64("student_id", studentID),
+			zap.Int64("package_id", packageID),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось начислить пакет")
+		return
+	}
+
+	// Стоимость пакета попадает во взаиморасчёты со студентом
+	chargePackage(ctx, h, user.ID, studentID, packageID)
+
+	notifyCreditsGranted(ctx, b, h, user, lot)
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("✅ Пакет начислен: %d %s", lot.Total, formatting.PluralizeLessons(lot.Total)))
+	showStudentCreditsScreen(ctx, b, callback, h, user, studentID)
+}
+
+// showStudentCreditsScreen отображает остатки занятий студента и предметы для начисления
+func showStudentCreditsScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, teacher *model.User, studentID int64) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	student, err := h.UserService.GetByID(ctx, studentID)
+	if err != nil || student == nil {
+		h.Logger.Error("Student not found for credits", zap.Int64("student_id", studentID), zap.Error(err))
+		return
+	}
+
+	balances, err := h.CreditService.GetStudentBalancesWithTeacher(ctx, studentID, teacher.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get credit balances", zap.Int64("student_id", studentID), zap.Error(err))
+		return
+	}
+
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, teacher.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher subjects", zap.Error(err))
+		return
+	}
+
+	studentName := student.FirstName
+	if student.LastName != "" {
+		studentName += " " + student.LastName
+	}
+
+	text := fmt.Sprintf("🎟 <b>Оплаченные занятия: %s</b>\n\n", studentName)
+	if len(balances) == 0 {
+		text += "Оплаченных занятий нет.\n"
+	} else {
+		for _, balance := range balances {
+			text += "• " + formatting.FormatCreditBalance(balance) + "\n"
+		}
+	}
+	text += "\nВыберите предмет, чтобы начислить занятия или пакет:"
+
+	kb := keyboard.NewBuilder()
+	for _, subject := range subjects {
+		if !subject.IsActive {
+			continue
+		}
+		kb.Row(keyboard.Button(
+			fmt.Sprintf("➕ %s", subject.Name),
+			fmt.Sprintf("grant_credits_menu:%d:%d", studentID, subject.ID),
+		))
+	}
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// notifyCreditsGranted уведомляет студента о начисленных занятиях
+func notifyCreditsGranted(ctx context.Context, b *bot.Bot, h *callbacktypes.Handler, teacher *model.User, lot *model.CreditLot) {
+	student, err := h.UserService.GetByID(ctx, lot.StudentID)
+	if err != nil || student == nil {
+		return
+	}
+
+	subjectName := ""
+	if subject, err := h.TeacherService.GetSubjectByID(ctx, lot.SubjectID); err == nil && subject != nil {
+		subjectName = subject.Name
+	}
+
+	teacherName := teacher.FirstName
+	if teacher.LastName != "" {
+		teacherName += " " + teacher.LastName
+	}
+
+	validity := "бессрочно"
+	if lot.ExpiresAt != nil {
+		validity = "до " + formatting.FormatDate(*lot.ExpiresAt)
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: student.TelegramID,
+		Text: fmt.Sprintf(
+			"🎟 <b>Начислены занятия</b>\n\n"+
+				"Учитель <b>%s</b> начислил вам %d %s по предмету «%s».\n"+
+				"Срок действия: %s\n\n"+
+				"При записи на этот предмет занятие будет списываться автоматически.",
+			teacherName,
+			lot.Total,
+			formatting.PluralizeLessons(lot.Total),
+			subjectName,
+			validity,
+		),
+		ParseMode: models.ParseModeHTML,
+	})
+}
+
+// chargePackage начисляет студенту стоимость выданного пакета
+func chargePackage(ctx context.Context, h *callbacktypes.Handler, teacherID, studentID, packageID int64) {
+	pkg, err := h.CreditService.GetPackageByID(ctx, packageID)
+	if err != nil || pkg == nil {
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, pkg.SubjectID)
+	if err != nil || subject == nil {
+		return
+	}
+
+	if err := h.LedgerService.ChargePackage(ctx, teacherID, studentID, pkg, subject); err != nil {
+		h.Logger.Error("Failed to charge lesson package",
+			zap.Int64("student_id", studentID),
+			zap.Int64("package_id", packageID),
+			zap.Error(err))
+	}
+}
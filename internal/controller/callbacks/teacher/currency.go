@@ -0,0 +1,79 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleDefaultCurrencyMenu показывает выбор валюты учителя по умолчанию
+func HandleDefaultCurrencyMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	text := "💱 <b>Валюта по умолчанию</b>\n\n" +
+		fmt.Sprintf("Сейчас: %s\n\n", formatting.FormatCurrency(user.DefaultCurrency)) +
+		"В этой валюте создаются новые предметы, промокоды на все предметы и отмечаются оплаты без указания валюты. " +
+		"Валюту существующего предмета можно сменить в его настройках."
+
+	kb := keyboard.NewBuilder()
+	for _, currency := range model.SupportedCurrencies() {
+		label := formatting.FormatCurrency(currency)
+		if currency == user.DefaultCurrency {
+			label = "✅ " + label
+		}
+		kb.Row(keyboard.Button(label, "set_default_currency:"+string(currency)))
+	}
+	kb.Row(keyboard.BackButton("teacher_settings"))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleSetDefaultCurrency сохраняет валюту учителя по умолчанию
+func HandleSetDefaultCurrency(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	currency := model.Currency(strings.TrimPrefix(callback.Data, "set_default_currency:"))
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	if err := h.UserService.SetDefaultCurrency(ctx, user.ID, currency); err != nil {
+		if err.Error() == "invalid currency" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестная валюта")
+			return
+		}
+		h.Logger.Error("Failed to set default currency", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось обновить")
+		return
+	}
+
+	HandleTeacherSettings(ctx, b, callback, h)
+}
@@ -0,0 +1,130 @@
+package teacher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleExportMenu показывает выбор данных для выгрузки
+func HandleExportMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	text, keyboard := common.BuildExportMenuScreen()
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleExportDataset показывает выбор периода и формата для набора данных
+func HandleExportDataset(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: export_dataset:dataset
+	dataset := model.ExportDataset(strings.TrimPrefix(callback.Data, "export_dataset:"))
+	if !dataset.IsValid() {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный тип выгрузки")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	text, keyboard := common.BuildExportOptionsScreen(dataset)
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleExportFile формирует файл выгрузки и отправляет его документом
+func HandleExportFile(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: export_file:dataset:format:period
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 4 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	dataset := model.ExportDataset(parts[1])
+	format := model.ExportFormat(parts[2])
+	period := parts[3]
+
+	var from, to time.Time
+	if dataset.HasPeriod() {
+		var ok bool
+		from, to, ok = reportPeriodBounds(period, time.Now())
+		if !ok {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный период")
+			return
+		}
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	file, err := h.ExportService.Export(ctx, user.ID, dataset, format, from, to)
+	if err != nil {
+		switch err.Error() {
+		case "unknown export dataset", "unknown export format":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный тип выгрузки")
+		default:
+			h.Logger.Error("Failed to export teacher data",
+				zap.Int64("teacher_id", user.ID),
+				zap.String("dataset", string(dataset)),
+				zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сформировать файл")
+		}
+		return
+	}
+
+	caption := fmt.Sprintf("📤 Выгрузка: %d строк", file.Rows)
+	if dataset.HasPeriod() {
+		caption += fmt.Sprintf(" за %s — %s", from.Format("02.01.2006"), to.AddDate(0, 0, -1).Format("02.01.2006"))
+	}
+
+	b.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: callback.From.ID,
+		Document: &models.InputFileUpload{
+			Filename: file.Filename,
+			Data:     bytes.NewReader(file.Data),
+		},
+		Caption: caption,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "📤 Файл отправлен")
+}
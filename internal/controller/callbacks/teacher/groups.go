@@ -0,0 +1,519 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// maxGroupMemberButtons сколько студентов показывать кнопками при добавлении и удалении участников
+const maxGroupMemberButtons = 20
+
+// HandleManageGroups показывает группы студентов учителя
+func HandleManageGroups(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	groups, err := h.GroupService.GetTeacherGroups(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher groups", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке групп")
+		return
+	}
+
+	text := fmt.Sprintf("🧑‍🤝‍🧑 <b>Группы студентов</b> (%d)\n\n", len(groups))
+	if len(groups) == 0 {
+		text += "Объединяйте студентов в группы — классы, кружки, потоки. " +
+			"Группе можно отправить сообщение, выдать код приглашения и закрепить за ней слоты расписания."
+	} else {
+		text += "Выберите группу:"
+	}
+
+	kb := keyboard.NewBuilder()
+	for _, group := range groups {
+		kb.Row(keyboard.Button(fmt.Sprintf("👥 %s (%d)", group.Name, group.MembersCount), fmt.Sprintf("view_group:%d", group.ID)))
+	}
+	kb.Row(keyboard.Button("➕ Создать группу", "create_group"))
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleCreateGroup запрашивает название новой группы
+func HandleCreateGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateCreateGroup))
+
+	text := "🧑‍🤝‍🧑 <b>Новая группа</b>\n\n" +
+		"Отправьте название группы, например <code>10Б</code> или <code>Олимпиадники</code>.\n\n" +
+		"Для отмены используйте /cancel"
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: "manage_groups"}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleViewGroup показывает участников группы и действия с ней
+func HandleViewGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	showGroup(ctx, b, callback, h, groupID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showGroup перерисовывает экран группы в сообщении callback
+func showGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, groupID int64) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	group, err := h.GroupService.GetGroup(ctx, user.ID, groupID)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		return
+	}
+
+	members, err := h.GroupService.GetMembers(ctx, user.ID, groupID)
+	if err != nil {
+		h.Logger.Error("Failed to get group members", zap.Int64("group_id", groupID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке участников")
+		return
+	}
+
+	text := fmt.Sprintf("👥 <b>%s</b>\n\n", html.EscapeString(group.Name))
+	if len(members) == 0 {
+		text += "В группе пока нет студентов. Добавьте их из списка своих студентов " +
+			"или отправьте код приглашения в группу."
+	} else {
+		text += fmt.Sprintf("<b>Участники (%d):</b>\n", len(members))
+		for i, member := range members {
+			text += fmt.Sprintf("%d. %s", i+1, html.EscapeString(groupMemberName(member)))
+			if member.Username != "" {
+				text += fmt.Sprintf(" (@%s)", member.Username)
+			}
+			text += "\n"
+		}
+	}
+
+	kb := keyboard.NewBuilder()
+	kb.Row(
+		keyboard.Button("➕ Добавить", fmt.Sprintf("group_add_members:%d", groupID)),
+		keyboard.Button("➖ Убрать", fmt.Sprintf("group_remove_members:%d", groupID)),
+	)
+	kb.Row(keyboard.Button("📣 Сообщение группе", fmt.Sprintf("group_broadcast:%d", groupID)))
+	kb.Row(keyboard.Button("🎟️ Код приглашения в группу", fmt.Sprintf("group_invite_code:%d", groupID)))
+	kb.Row(keyboard.Button("🗑 Удалить группу", fmt.Sprintf("delete_group:%d", groupID)))
+	kb.Row(keyboard.BackButton("manage_groups"))
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleGroupAddMembers показывает студентов учителя, которых можно добавить в группу
+func HandleGroupAddMembers(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	showGroupMemberPicker(ctx, b, callback, h, groupID, true)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleGroupRemoveMembers показывает участников группы, которых можно убрать
+func HandleGroupRemoveMembers(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	showGroupMemberPicker(ctx, b, callback, h, groupID, false)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showGroupMemberPicker показывает кнопки студентов для добавления в группу (adding) или удаления из неё
+func showGroupMemberPicker(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, groupID int64, adding bool) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	group, err := h.GroupService.GetGroup(ctx, user.ID, groupID)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		return
+	}
+
+	members, err := h.GroupService.GetMembers(ctx, user.ID, groupID)
+	if err != nil {
+		h.Logger.Error("Failed to get group members", zap.Int64("group_id", groupID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке участников")
+		return
+	}
+
+	candidates := members
+	if adding {
+		students, err := h.AccessService.GetMyStudents(ctx, user.ID)
+		if err != nil {
+			h.Logger.Error("Failed to get teacher students", zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке студентов")
+			return
+		}
+
+		inGroup := make(map[int64]bool, len(members))
+		for _, member := range members {
+			inGroup[member.ID] = true
+		}
+
+		candidates = nil
+		for _, student := range students {
+			if !inGroup[student.ID] {
+				candidates = append(candidates, student)
+			}
+		}
+	}
+
+	var text string
+	if adding {
+		text = fmt.Sprintf("➕ <b>Добавить в группу «%s»</b>\n\n", html.EscapeString(group.Name))
+		if len(candidates) == 0 {
+			text += "Все ваши студенты уже в этой группе."
+		} else {
+			text += "Нажмите на студента, чтобы добавить его:"
+		}
+	} else {
+		text = fmt.Sprintf("➖ <b>Убрать из группы «%s»</b>\n\n", html.EscapeString(group.Name))
+		if len(candidates) == 0 {
+			text += "В группе нет участников."
+		} else {
+			text += "Нажмите на студента, чтобы убрать его из группы:"
+		}
+	}
+
+	if len(candidates) > maxGroupMemberButtons {
+		text += fmt.Sprintf("\n\n<i>Показаны первые %d из %d.</i>", maxGroupMemberButtons, len(candidates))
+		candidates = candidates[:maxGroupMemberButtons]
+	}
+
+	kb := keyboard.NewBuilder()
+	for _, student := range candidates {
+		if adding {
+			kb.Row(keyboard.Button("➕ "+groupMemberName(student), fmt.Sprintf("group_add:%d:%d", groupID, student.ID)))
+		} else {
+			kb.Row(keyboard.Button("❌ "+groupMemberName(student), fmt.Sprintf("group_remove:%d:%d", groupID, student.ID)))
+		}
+	}
+	kb.Row(keyboard.BackButton(fmt.Sprintf("view_group:%d", groupID)))
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleGroupAdd добавляет студента в группу
+func HandleGroupAdd(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: group_add:groupID:studentID
+	ids := common.ParseMultiIDFromCallback(callback.Data, "group_add:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	if err := h.GroupService.AddMember(ctx, user.ID, ids[0], ids[1]); err != nil {
+		switch err.Error() {
+		case "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		case "student has no access":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ У студента нет доступа к вашему расписанию")
+		default:
+			h.Logger.Error("Failed to add group member", zap.Int64("group_id", ids[0]), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось добавить студента")
+		}
+		return
+	}
+
+	showGroupMemberPicker(ctx, b, callback, h, ids[0], true)
+	common.AnswerCallback(ctx, b, callback.ID, "✅ Студент добавлен")
+}
+
+// HandleGroupRemove убирает студента из группы
+func HandleGroupRemove(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: group_remove:groupID:studentID
+	ids := common.ParseMultiIDFromCallback(callback.Data, "group_remove:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	if err := h.GroupService.RemoveMember(ctx, user.ID, ids[0], ids[1]); err != nil {
+		switch err.Error() {
+		case "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		case "member not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Студент уже не в группе")
+		default:
+			h.Logger.Error("Failed to remove group member", zap.Int64("group_id", ids[0]), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось убрать студента")
+		}
+		return
+	}
+
+	showGroupMemberPicker(ctx, b, callback, h, ids[0], false)
+	common.AnswerCallback(ctx, b, callback.ID, "✅ Студент убран из группы")
+}
+
+// HandleGroupBroadcast запрашивает текст сообщения для участников группы
+func HandleGroupBroadcast(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	group, err := h.GroupService.GetGroup(ctx, user.ID, groupID)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		return
+	}
+
+	if group.MembersCount == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "В группе нет участников")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateGroupBroadcast))
+	h.StateManager.SetData(callback.From.ID, "group_id", groupID)
+
+	text := fmt.Sprintf("📣 <b>Сообщение группе «%s»</b>\n\n", html.EscapeString(group.Name)) +
+		fmt.Sprintf("Отправьте текст — бот перешлёт его всем участникам (%d).\n\n", group.MembersCount) +
+		"Для отмены используйте /cancel"
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: fmt.Sprintf("view_group:%d", groupID)}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleGroupInviteCode предлагает выбрать срок доступа для кода приглашения в группу
+func HandleGroupInviteCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	group, err := h.GroupService.GetGroup(ctx, user.ID, groupID)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		return
+	}
+
+	text := fmt.Sprintf("🎟️ <b>Код приглашения в группу «%s»</b>\n\n", html.EscapeString(group.Name))
+	text += "Студент, который введёт код, получит доступ к вашему расписанию и попадёт в группу. " +
+		"Студенты, у которых доступ уже есть, могут ввести код, чтобы вступить в группу.\n\n"
+	text += "На какой срок выдавать доступ по этому коду?"
+
+	kb := keyboard.NewBuilder()
+	for _, days := range accessDurationOptions {
+		kb.Row(keyboard.Button(accessDurationLabel(days), fmt.Sprintf("create_invite_code:%d:%d", days, groupID)))
+	}
+	kb.Row(keyboard.BackButton(fmt.Sprintf("view_group:%d", groupID)))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleDeleteGroup просит подтвердить удаление группы
+func HandleDeleteGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	group, err := h.GroupService.GetGroup(ctx, user.ID, groupID)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		return
+	}
+
+	text := fmt.Sprintf("🗑 <b>Удалить группу «%s»?</b>\n\n", html.EscapeString(group.Name)) +
+		"Студенты сохранят доступ к вашему расписанию. Слоты группы станут доступны всем, " +
+		"коды приглашения продолжат работать без добавления в группу."
+
+	kb := keyboard.NewBuilder()
+	kb.Row(
+		keyboard.Button("✅ Да, удалить", fmt.Sprintf("confirm_delete_group:%d", groupID)),
+		keyboard.Button("❌ Нет", fmt.Sprintf("view_group:%d", groupID)),
+	)
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleConfirmDeleteGroup удаляет группу
+func HandleConfirmDeleteGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	groupID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	if err := h.GroupService.DeleteGroup(ctx, user.ID, groupID); err != nil {
+		if err.Error() == "group not found" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+			return
+		}
+		h.Logger.Error("Failed to delete group", zap.Int64("group_id", groupID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось удалить группу")
+		return
+	}
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, "🗑 Группа удалена")
+	HandleManageGroups(ctx, b, callback, h)
+}
+
+// groupMemberName возвращает имя студента для списков группы
+func groupMemberName(student *model.User) string {
+	name := student.FirstName
+	if student.LastName != "" {
+		name += " " + student.LastName
+	}
+	return name
+}
@@ -0,0 +1,250 @@
+package teacher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ledgerRecentEntries сколько последних операций показывать на экране студента
+const ledgerRecentEntries = 10
+
+// HandleLedgerBalances показывает долги и авансы всех студентов учителя
+func HandleLedgerBalances(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	// Выходим из диалога ввода оплаты, если вернулись к списку
+	h.StateManager.ClearState(callback.From.ID)
+
+	balances, err := h.LedgerService.GetTeacherBalances(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get ledger balances", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке взаиморасчётов")
+		return
+	}
+
+	text := "💰 <b>Взаиморасчёты со студентами</b>\n\n"
+	// Долги в разных валютах не складываются
+	totalDebt := make(map[model.Currency]int)
+	if len(balances) == 0 {
+		text += "Операций пока нет. Проведённые занятия начисляются автоматически по цене предмета, " +
+			"а полученные оплаты можно отметить на экране студента.\n"
+	} else {
+		for _, balance := range balances {
+			text += fmt.Sprintf("• <b>%s</b> — %s\n", balance.Name, formatting.FormatLedgerBalance(balance))
+			if debt := balance.Debt(); debt > 0 {
+				totalDebt[balance.Currency] += debt
+			}
+		}
+
+		var totals []string
+		for _, currency := range model.SupportedCurrencies() {
+			if debt := totalDebt[currency]; debt > 0 {
+				totals = append(totals, formatting.FormatPriceShort(debt, currency))
+			}
+		}
+		if len(totals) == 0 {
+			totals = append(totals, "0")
+		}
+		text += fmt.Sprintf("\nВсего вам должны: <b>%s</b>\n", strings.Join(totals, " + "))
+	}
+
+	kb := keyboard.NewBuilder()
+	seen := make(map[int64]bool)
+	for _, balance := range balances {
+		if seen[balance.StudentID] {
+			continue
+		}
+		seen[balance.StudentID] = true
+		kb.Row(keyboard.Button(
+			fmt.Sprintf("👤 %s", balance.Name),
+			fmt.Sprintf("student_ledger:%d", balance.StudentID),
+		))
+	}
+
+	// Выписки за текущий и прошлый месяц
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	prevMonth := thisMonth.AddDate(0, -1, 0)
+	kb.AddRow([]models.InlineKeyboardButton{
+		keyboard.Button("📄 "+formatting.GetMonthName(prevMonth.Month()), "ledger_statement:"+prevMonth.Format("2006-01")),
+		keyboard.Button("📄 "+formatting.GetMonthName(thisMonth.Month()), "ledger_statement:"+thisMonth.Format("2006-01")),
+	})
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleStudentLedger показывает взаиморасчёты с одним студентом
+func HandleStudentLedger(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	studentID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	student, err := h.UserService.GetByID(ctx, studentID)
+	if err != nil || student == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Студент не найден")
+		return
+	}
+
+	balances, err := h.LedgerService.GetBalances(ctx, user.ID, studentID)
+	if err != nil {
+		h.Logger.Error("Failed to get ledger balance", zap.Int64("student_id", studentID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке взаиморасчётов")
+		return
+	}
+
+	entries, err := h.LedgerService.GetRecentEntries(ctx, user.ID, studentID, ledgerRecentEntries)
+	if err != nil {
+		h.Logger.Error("Failed to get ledger entries", zap.Int64("student_id", studentID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке взаиморасчётов")
+		return
+	}
+
+	studentName := student.FirstName
+	if student.LastName != "" {
+		studentName += " " + student.LastName
+	}
+
+	text, kb := common.BuildStudentLedgerScreen(studentID, studentName, balances, entries)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleRecordPayment запрашивает сумму полученной оплаты
+func HandleRecordPayment(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: record_payment:studentID:method
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	studentID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	method := model.LedgerPaymentMethod(parts[2])
+	if method != model.LedgerPaymentCash && method != model.LedgerPaymentTransfer {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный способ оплаты")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	telegramID := callback.From.ID
+	h.StateManager.SetState(telegramID, callbacktypes.UserState(state.StateRecordPayment))
+	h.StateManager.SetData(telegramID, "student_id", studentID)
+	h.StateManager.SetData(telegramID, "payment_method", string(method))
+
+	text := fmt.Sprintf("💰 <b>Оплата: %s</b>\n\n", formatting.FormatLedgerMethod(method)) +
+		"Отправьте сумму. Если оплата не в валюте по умолчанию из настроек, укажите код валюты после суммы. " +
+		"Затем можно добавить комментарий.\n\n" +
+		"Например: <code>3000 за октябрь</code> или <code>50 EUR за октябрь</code>\n\n" +
+		"Для отмены используйте /cancel"
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: fmt.Sprintf("student_ledger:%d", studentID)}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleLedgerStatement отправляет CSV-выписку взаиморасчётов за месяц
+func HandleLedgerStatement(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: ledger_statement:YYYY-MM
+	month, err := time.ParseInLocation("2006-01", strings.TrimPrefix(callback.Data, "ledger_statement:"), time.Local)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	data, err := h.LedgerService.BuildMonthlyStatement(ctx, user.ID, month)
+	if err != nil {
+		h.Logger.Error("Failed to build ledger statement", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сформировать выписку")
+		return
+	}
+
+	b.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: callback.From.ID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("statement_%s.csv", month.Format("2006-01")),
+			Data:     bytes.NewReader(data),
+		},
+		Caption: fmt.Sprintf("📄 Выписка за %s %d", strings.ToLower(formatting.GetMonthName(month.Month())), month.Year()),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "📄 Выписка отправлена")
+}
@@ -0,0 +1,284 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Lesson Notes Handlers
+// ========================
+// Учитель оставляет к занятию заметки, материалы и домашние задания
+
+// HandleLessonNotes показывает заметки к записи на занятие
+func HandleLessonNotes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: lesson_notes:bookingID:weekOffset
+	ids := common.ParseMultiIDFromCallback(callback.Data, "lesson_notes:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	showLessonNotes(ctx, b, callback, h, user.ID, ids[0], int(ids[1]))
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showLessonNotes перерисовывает экран заметок к записи в сообщении callback
+func showLessonNotes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, teacherID, bookingID int64, weekOffset int) {
+	booking, notes, err := h.LessonNoteService.GetBookingNotes(ctx, teacherID, bookingID)
+	if err != nil {
+		if err.Error() != "booking not found" {
+			h.Logger.Error("Failed to get lesson notes", zap.Int64("booking_id", bookingID), zap.Error(err))
+		}
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Запись не найдена")
+		return
+	}
+
+	studentName := "Студент"
+	if student, err := h.UserService.GetByID(ctx, booking.StudentID); err == nil && student != nil {
+		studentName = student.FirstName
+		if student.LastName != "" {
+			studentName += " " + student.LastName
+		}
+	}
+
+	text := fmt.Sprintf("📝 <b>Заметки к занятию</b>\n👤 %s\n\n", html.EscapeString(studentName))
+
+	kb := keyboard.NewBuilder()
+	if len(notes) == 0 {
+		text += "Заметок пока нет. Добавьте материалы, итоги занятия или домашнее задание — студент получит уведомление."
+	} else {
+		list, shown := common.BuildLessonNotesList(notes, false)
+		text += list
+
+		common.LessonNoteViewButtons(kb, notes, shown)
+		for i := 0; i < shown; i++ {
+			kb.Row(
+				keyboard.Button(fmt.Sprintf("📅 Срок ДЗ %d", i+1), fmt.Sprintf("note_due_menu:%d:%d", notes[i].ID, weekOffset)),
+				keyboard.Button(fmt.Sprintf("🗑 Удалить %d", i+1), fmt.Sprintf("note_delete:%d:%d", notes[i].ID, weekOffset)),
+			)
+		}
+	}
+
+	kb.Row(keyboard.Button("➕ Добавить заметку", fmt.Sprintf("note_add:%d:%d", bookingID, weekOffset)))
+	kb.Row(keyboard.Button("📚 Все заметки студента", fmt.Sprintf("student_notes:%d", booking.StudentID)))
+	kb.Row(keyboard.BackButton(fmt.Sprintf("view_slot_details:%d:%d", booking.SlotID, weekOffset)))
+
+	editLessonNotesMessage(ctx, b, callback, text, kb.Build())
+}
+
+// HandleNoteAdd просит учителя прислать заметку к занятию
+func HandleNoteAdd(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: note_add:bookingID:weekOffset
+	ids := common.ParseMultiIDFromCallback(callback.Data, "note_add:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateLessonNote))
+	h.StateManager.SetData(callback.From.ID, "lesson_note_booking_id", ids[0])
+	h.StateManager.SetData(callback.From.ID, "lesson_note_week_offset", int(ids[1]))
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: msg.Chat.ID,
+		Text: "📝 <b>Новая заметка к занятию</b>\n\n" +
+			"Отправьте текст, фото или документ с подписью: материалы, итоги занятия или домашнее задание. " +
+			"Срок сдачи можно будет указать после сохранения.\n\n" +
+			"Для отмены используйте /cancel",
+		ParseMode: models.ParseModeHTML,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleNoteDueMenu предлагает срок домашнего задания по заметке
+func HandleNoteDueMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: note_due_menu:noteID:weekOffset
+	ids := common.ParseMultiIDFromCallback(callback.Data, "note_due_menu:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	note, err := h.LessonNoteService.GetNote(ctx, user.ID, ids[0])
+	if err != nil || note.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заметка не найдена")
+		return
+	}
+
+	text := "📅 <b>Срок домашнего задания</b>\n\n" +
+		"Срок — конец выбранного дня. За сутки до него студент получит напоминание."
+	if note.HomeworkDueAt != nil {
+		text += fmt.Sprintf("\n\nСейчас: до %s", note.HomeworkDueAt.Format("02.01.2006"))
+	}
+
+	editLessonNotesMessage(ctx, b, callback, text, common.BuildHomeworkDueKeyboard(note.ID, note.BookingID, int(ids[1])))
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleNoteDue устанавливает или снимает срок домашнего задания
+func HandleNoteDue(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: note_due:noteID:days:weekOffset
+	ids := common.ParseMultiIDFromCallback(callback.Data, "note_due:")
+	if len(ids) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	note, err := h.LessonNoteService.GetNote(ctx, user.ID, ids[0])
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заметка не найдена")
+		return
+	}
+
+	dueAt, err := h.LessonNoteService.SetHomeworkDue(ctx, user.ID, note.ID, int(ids[1]))
+	if err != nil {
+		if err.Error() == "note not found" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заметка не найдена")
+			return
+		}
+		h.Logger.Error("Failed to set homework due", zap.Int64("note_id", note.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сохранить срок")
+		return
+	}
+
+	showLessonNotes(ctx, b, callback, h, user.ID, note.BookingID, int(ids[2]))
+	if dueAt != nil {
+		common.AnswerCallback(ctx, b, callback.ID, fmt.Sprintf("📅 Срок: до %s", dueAt.Format("02.01.2006")))
+	} else {
+		common.AnswerCallback(ctx, b, callback.ID, "Срок снят")
+	}
+}
+
+// HandleNoteDelete удаляет заметку к занятию
+func HandleNoteDelete(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: note_delete:noteID:weekOffset
+	ids := common.ParseMultiIDFromCallback(callback.Data, "note_delete:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	note, err := h.LessonNoteService.DeleteNote(ctx, user.ID, ids[0])
+	if err != nil {
+		if err.Error() == "note not found" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заметка не найдена")
+			return
+		}
+		h.Logger.Error("Failed to delete lesson note", zap.Int64("note_id", ids[0]), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось удалить заметку")
+		return
+	}
+
+	showLessonNotes(ctx, b, callback, h, user.ID, note.BookingID, int(ids[1]))
+	common.AnswerCallback(ctx, b, callback.ID, "🗑 Заметка удалена")
+}
+
+// HandleStudentNotes показывает историю заметок учителя по студенту
+func HandleStudentNotes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: student_notes:studentID
+	studentID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	notes, err := h.LessonNoteService.GetStudentHistory(ctx, user.ID, studentID)
+	if err != nil {
+		h.Logger.Error("Failed to get student notes", zap.Int64("student_id", studentID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке заметок")
+		return
+	}
+
+	studentName := "студента"
+	if student, err := h.UserService.GetByID(ctx, studentID); err == nil && student != nil {
+		studentName = student.FirstName
+		if student.LastName != "" {
+			studentName += " " + student.LastName
+		}
+	}
+
+	text := fmt.Sprintf("📚 <b>Заметки: %s</b>\n\n", html.EscapeString(studentName))
+
+	kb := keyboard.NewBuilder()
+	if len(notes) == 0 {
+		text += "Заметок пока нет. Добавить заметку можно в деталях занятия в расписании."
+	} else {
+		list, shown := common.BuildLessonNotesList(notes, true)
+		text += list
+		common.LessonNoteViewButtons(kb, notes, shown)
+	}
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	editLessonNotesMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// editLessonNotesMessage заменяет экран заметок в сообщении callback
+func editLessonNotesMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
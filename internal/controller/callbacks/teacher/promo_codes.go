@@ -0,0 +1,199 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleManagePromoCodes показывает промокоды учителя
+func HandleManagePromoCodes(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	// Выходим из диалога создания, если вернулись к списку
+	h.StateManager.ClearState(callback.From.ID)
+
+	promos, err := h.PromoCodeService.GetTeacherPromoCodes(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get promo codes", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке промокодов")
+		return
+	}
+
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher subjects", zap.Error(err))
+	}
+	subjectNames := make(map[int64]string, len(subjects))
+	for _, subject := range subjects {
+		subjectNames[subject.ID] = subject.Name
+	}
+
+	text := "🏷 <b>Промокоды</b>\n\n" +
+		"Студент вводит промокод на странице предмета, и скидка применяется к его следующей записи.\n\n"
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.Button("➕ Создать промокод", "create_promo_code"))
+
+	inactive := 0
+	for _, promo := range promos {
+		if !promo.IsValid() {
+			inactive++
+			continue
+		}
+
+		scope := "все предметы"
+		if promo.SubjectID != nil {
+			scope = html.EscapeString(subjectNames[*promo.SubjectID])
+		}
+
+		text += fmt.Sprintf("• <code>%s</code> %s — %s\n   %s\n",
+			promo.Code,
+			formatting.FormatPromoDiscount(promo),
+			scope,
+			formatting.FormatPromoLimits(promo))
+
+		kb.Row(
+			keyboard.Button(fmt.Sprintf("🏷 %s", promo.Code), "noop"),
+			keyboard.Button("❌", fmt.Sprintf("deactivate_promo:%d", promo.ID)),
+		)
+	}
+
+	if len(promos) == inactive {
+		text += "Активных промокодов нет.\n"
+	}
+	if inactive > 0 {
+		text += fmt.Sprintf("\n<i>Неактивных промокодов: %d</i>\n", inactive)
+	}
+
+	kb.Row(keyboard.BackButton("teacher_settings"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleCreatePromoCode предлагает выбрать, на какие предметы действует новый промокод
+func HandleCreatePromoCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher subjects", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке предметов")
+		return
+	}
+
+	kb := keyboard.NewBuilder()
+	kb.Row(keyboard.Button("📚 Все предметы", "promo_scope:0"))
+	for _, subject := range subjects {
+		kb.Row(keyboard.Button("📖 "+subject.Name, fmt.Sprintf("promo_scope:%d", subject.ID)))
+	}
+	kb.Row(keyboard.BackButton("manage_promo_codes"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        "🏷 <b>Новый промокод</b>\n\nНа какие предметы будет действовать скидка?",
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandlePromoScope запоминает предмет промокода и запрашивает код и размер скидки
+func HandlePromoScope(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: promo_scope:subjectID (0 — все предметы)
+	subjectID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, "promo_scope:"), 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	telegramID := callback.From.ID
+	h.StateManager.SetState(telegramID, callbacktypes.UserState(state.StateCreatePromoCode))
+	h.StateManager.SetData(telegramID, "promo_subject_id", subjectID)
+
+	text := "🏷 <b>Новый промокод</b>\n\n" +
+		"Отправьте код и скидку. Скидка в процентах — с «%», иначе сумма в валюте предмета " +
+		"(для всех предметов — в валюте по умолчанию). " +
+		"Дальше можно указать лимит использований и срок действия в днях (0 — без ограничений).\n\n" +
+		"Например:\n" +
+		"<code>SUMMER 20%</code> — скидка 20% без ограничений\n" +
+		"<code>FIRST 500 10 30</code> — скидка 500, 10 использований, 30 дней\n\n" +
+		"Для отмены используйте /cancel"
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: "manage_promo_codes"}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleDeactivatePromoCode отключает промокод
+func HandleDeactivatePromoCode(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	promoID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	if err := h.PromoCodeService.DeactivatePromoCode(ctx, user.ID, promoID); err != nil {
+		h.Logger.Error("Failed to deactivate promo code", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось отключить промокод")
+		return
+	}
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, "✅ Промокод отключён")
+	HandleManagePromoCodes(ctx, b, callback, h)
+}
@@ -570,7 +570,7 @@ func HandleRecurringIntervalEnd(ctx context.Context, b *bot.Bot, callback *model
 	startTime := time.Date(2000, 1, 1, startHour, startMinute, 0, 0, time.UTC)
 	endTime := time.Date(2000, 1, 1, endHour, endMinute, 0, 0, time.UTC)
 
-	// Генерируем слоты по времени с шагом = длительность занятия
+	// Генерируем слоты по времени с шагом = длительность занятия + перерывы
 	currentTime := startTime
 	var timeSlots []struct{ Hour, Minute int }
 
@@ -579,7 +579,7 @@ func HandleRecurringIntervalEnd(ctx context.Context, b *bot.Bot, callback *model
 			Hour:   currentTime.Hour(),
 			Minute: currentTime.Minute(),
 		})
-		currentTime = currentTime.Add(time.Duration(subject.Duration) * time.Minute)
+		currentTime = currentTime.Add(time.Duration(subject.SlotStep()) * time.Minute)
 	}
 
 	// Собираем выбранные дни недели
@@ -591,7 +591,7 @@ func HandleRecurringIntervalEnd(ctx context.Context, b *bot.Bot, callback *model
 	}
 
 	// Создаём группу расписаний одним вызовом
-	groupID, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
+	groupID, conflicts, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
 	if err != nil {
 		h.Logger.Error("Failed to create recurring schedule group", zap.Error(err))
 		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка создания расписания")
@@ -626,6 +626,10 @@ func HandleRecurringIntervalEnd(ctx context.Context, b *bot.Bot, callback *model
 			strings.Join(selectedDaysList, ", "),
 			totalCreated)
 
+		if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+			text += "\n\n" + conflictsText
+		}
+
 		b.EditMessageText(ctx, &bot.EditMessageTextParams{
 			ChatID:    msg.Chat.ID,
 			MessageID: msg.ID,
@@ -695,7 +699,7 @@ func showRecurringSpecificSlotsSelection(ctx context.Context, b *bot.Bot, callba
 			row = []models.InlineKeyboardButton{}
 		}
 
-		currentTime = currentTime.Add(time.Duration(subject.Duration) * time.Minute)
+		currentTime = currentTime.Add(time.Duration(subject.SlotStep()) * time.Minute)
 		slotCount++
 	}
 
@@ -870,7 +874,7 @@ func HandleCreateRecurringSpecificConfirm(ctx context.Context, b *bot.Bot, callb
 	}
 
 	// Создаём группу расписаний одним вызовом
-	groupID, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
+	groupID, conflicts, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
 	if err != nil {
 		h.Logger.Error("Failed to create recurring schedule group", zap.Error(err))
 		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка создания расписания")
@@ -905,6 +909,10 @@ func HandleCreateRecurringSpecificConfirm(ctx context.Context, b *bot.Bot, callb
 			strings.Join(selectedDaysList, ", "),
 			totalCreated)
 
+		if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+			text += "\n\n" + conflictsText
+		}
+
 		b.EditMessageText(ctx, &bot.EditMessageTextParams{
 			ChatID:    msg.Chat.ID,
 			MessageID: msg.ID,
@@ -455,7 +455,7 @@ func HandleSaveRecurringDays(ctx context.Context, b *bot.Bot, callback *models.C
 	}
 
 	// Создаём новую группу с новыми днями
-	newGroupID, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlotsSlice, subject.Duration)
+	newGroupID, conflicts, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlotsSlice, subject.Duration)
 	if err != nil {
 		h.Logger.Error("Failed to create new group", zap.Error(err))
 		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка создания нового расписания")
@@ -474,6 +474,10 @@ func HandleSaveRecurringDays(ctx context.Context, b *bot.Bot, callback *models.C
 			subject.Name,
 			len(weekdays)*len(timeSlotsSlice))
 
+		if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+			text += "\n\n" + conflictsText
+		}
+
 		keyboard := &models.InlineKeyboardMarkup{
 			InlineKeyboard: [][]models.InlineKeyboardButton{
 				{
@@ -883,7 +887,7 @@ func HandleRecurringEditIntervalEnd(ctx context.Context, b *bot.Bot, callback *m
 			Hour:   currentTime.Hour(),
 			Minute: currentTime.Minute(),
 		})
-		currentTime = currentTime.Add(time.Duration(subject.Duration) * time.Minute)
+		currentTime = currentTime.Add(time.Duration(subject.SlotStep()) * time.Minute)
 	}
 
 	// Деактивируем старую группу
@@ -895,7 +899,7 @@ func HandleRecurringEditIntervalEnd(ctx context.Context, b *bot.Bot, callback *m
 	}
 
 	// Создаём новую группу
-	newGroupID, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
+	newGroupID, conflicts, err := h.TeacherService.CreateWeeklySlotsGroup(ctx, user.ID, subjectID, weekdays, timeSlots, subject.Duration)
 	if err != nil {
 		h.Logger.Error("Failed to create new group", zap.Error(err))
 		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка создания")
@@ -915,6 +919,10 @@ func HandleRecurringEditIntervalEnd(ctx context.Context, b *bot.Bot, callback *m
 			startHour, startMinute, endHour, endMinute,
 			len(weekdays)*len(timeSlots))
 
+		if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+			text += "\n\n" + conflictsText
+		}
+
 		keyboard := &models.InlineKeyboardMarkup{
 			InlineKeyboard: [][]models.InlineKeyboardButton{
 				{
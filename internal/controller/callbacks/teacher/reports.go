@@ -0,0 +1,166 @@
+package teacher
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// Периоды отчёта в callback data
+const (
+	reportPeriodWeek      = "week"
+	reportPeriodMonth     = "month"
+	reportPeriodPrevMonth = "prev_month"
+	reportPeriodQuarter   = "quarter"
+	reportPeriodNextMonth = "next_month"
+)
+
+// reportPeriodButtons кнопки выбора периода отчёта
+var reportPeriodButtons = []struct {
+	period string
+	label  string
+}{
+	{reportPeriodWeek, "Неделя"},
+	{reportPeriodMonth, "Месяц"},
+	{reportPeriodPrevMonth, "Прошлый месяц"},
+	{reportPeriodQuarter, "3 месяца"},
+}
+
+// reportPeriodBounds возвращает границы периода отчёта [from, to)
+func reportPeriodBounds(period string, now time.Time) (time.Time, time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case reportPeriodWeek:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		from := today.AddDate(0, 0, -daysSinceMonday)
+		return from, from.AddDate(0, 0, 7), true
+	case reportPeriodMonth:
+		return monthStart, monthStart.AddDate(0, 1, 0), true
+	case reportPeriodPrevMonth:
+		return monthStart.AddDate(0, -1, 0), monthStart, true
+	case reportPeriodQuarter:
+		return monthStart.AddDate(0, -2, 0), monthStart.AddDate(0, 1, 0), true
+	case reportPeriodNextMonth:
+		return monthStart.AddDate(0, 1, 0), monthStart.AddDate(0, 2, 0), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// HandleTeacherReports показывает отчёт учителя за текущий месяц
+func HandleTeacherReports(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	showTeacherReport(ctx, b, callback, h, reportPeriodMonth)
+}
+
+// HandleTeacherReport показывает отчёт учителя за выбранный период
+func HandleTeacherReport(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: teacher_report:period
+	showTeacherReport(ctx, b, callback, h, strings.TrimPrefix(callback.Data, "teacher_report:"))
+}
+
+// showTeacherReport отображает отчёт за период с кнопками выбора периода и графика
+func showTeacherReport(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, period string) {
+	from, to, ok := reportPeriodBounds(period, time.Now())
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный период")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	report, err := h.ReportService.BuildTeacherReport(ctx, user.ID, from, to)
+	if err != nil {
+		h.Logger.Error("Failed to build teacher report",
+			zap.Int64("teacher_id", user.ID),
+			zap.String("period", period),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось построить отчёт")
+		return
+	}
+
+	periodRow := make([]models.InlineKeyboardButton, 0, len(reportPeriodButtons))
+	for _, button := range reportPeriodButtons {
+		label := button.label
+		if button.period == period {
+			label = "• " + label + " •"
+		}
+		periodRow = append(periodRow, keyboard.Button(label, "teacher_report:"+button.period))
+	}
+
+	kb := keyboard.NewBuilder()
+	kb.AddRow(periodRow[:2])
+	kb.AddRow(periodRow[2:])
+	kb.Row(keyboard.Button("📊 График загрузки", "teacher_report_chart:"+period))
+	kb.Row(keyboard.BackButton("teacher_settings"))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        formatting.FormatTeacherReport(report),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleTeacherReportChart отправляет график загрузки за период отдельным изображением
+func HandleTeacherReportChart(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: teacher_report_chart:period
+	period := strings.TrimPrefix(callback.Data, "teacher_report_chart:")
+	from, to, ok := reportPeriodBounds(period, time.Now())
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестный период")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	report, err := h.ReportService.BuildTeacherReport(ctx, user.ID, from, to)
+	if err != nil {
+		h.Logger.Error("Failed to build teacher report", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось построить отчёт")
+		return
+	}
+
+	imageData, err := common.GenerateReportChart(report)
+	if err != nil {
+		h.Logger.Error("Failed to generate report chart", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось нарисовать график")
+		return
+	}
+
+	b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID: callback.From.ID,
+		Photo:  &models.InputFileUpload{Filename: "report.png", Data: bytes.NewReader(imageData)},
+		Caption: "📊 Загрузка расписания за " + from.Format("02.01.2006") + " — " +
+			to.AddDate(0, 0, -1).Format("02.01.2006"),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
@@ -0,0 +1,231 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Review Handlers
+// ========================
+// Учитель видит отзывы студентов, отвечает на них и может пожаловаться на отзыв
+
+// HandleMyReviews показывает учителю рейтинг и последние отзывы
+func HandleMyReviews(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	showMyReviews(ctx, b, callback, h, user.ID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showMyReviews перерисовывает экран отзывов учителя в сообщении callback
+func showMyReviews(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, teacherID int64) {
+	rating, err := h.ReviewService.GetTeacherRating(ctx, teacherID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher rating", zap.Int64("teacher_id", teacherID), zap.Error(err))
+	}
+
+	reviews, err := h.ReviewService.GetTeacherReviews(ctx, teacherID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher reviews", zap.Int64("teacher_id", teacherID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке отзывов")
+		return
+	}
+
+	text := fmt.Sprintf("⭐ <b>Отзывы студентов</b>\n\n%s\n\n", formatting.FormatRating(rating))
+
+	kb := keyboard.NewBuilder()
+	if len(reviews) == 0 {
+		text += "Студенты получают просьбу оценить занятие после его завершения. Отзывов пока нет."
+	} else {
+		for i, review := range reviews {
+			text += fmt.Sprintf("<b>%d.</b> %s — %s, %s\n",
+				i+1,
+				formatting.FormatStars(review.Rating),
+				html.EscapeString(review.StudentName),
+				html.EscapeString(review.SubjectName),
+			)
+			if review.Comment != "" {
+				text += fmt.Sprintf("<i>%s</i>\n", html.EscapeString(truncateReviewText(review.Comment)))
+			}
+			if review.TeacherReply != "" {
+				text += fmt.Sprintf("↩️ %s\n", html.EscapeString(truncateReviewText(review.TeacherReply)))
+			}
+			if review.IsReported() {
+				text += "🚩 Жалоба отправлена, в профиле отзыв отмечен как оспоренный\n"
+			}
+			text += "\n"
+
+			var row []models.InlineKeyboardButton
+			if review.Comment != "" {
+				row = append(row, keyboard.Button(fmt.Sprintf("↩️ Ответить %d", i+1), fmt.Sprintf("review_reply:%d", review.ID)))
+			}
+			if review.Comment != "" && !review.IsReported() {
+				row = append(row, keyboard.Button(fmt.Sprintf("🚩 Пожаловаться %d", i+1), fmt.Sprintf("review_report:%d", review.ID)))
+			}
+			if len(row) > 0 {
+				kb.AddRow(row)
+			}
+		}
+	}
+	kb.Row(keyboard.BackButton("teacher_settings"))
+
+	editReviewsMessage(ctx, b, callback, text, kb.Build())
+}
+
+// HandleReviewReply просит учителя написать ответ на отзыв
+func HandleReviewReply(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: review_reply:reviewID
+	reviewID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	review, err := h.ReviewService.GetReview(ctx, user.ID, reviewID)
+	if err != nil || review.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Отзыв не найден")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.ClearState(callback.From.ID)
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateReviewReply))
+	h.StateManager.SetData(callback.From.ID, "review_id", review.ID)
+
+	text := fmt.Sprintf("↩️ <b>Ответ на отзыв</b> %s\n\n<i>%s</i>\n\n", formatting.FormatStars(review.Rating), html.EscapeString(review.Comment))
+	if review.TeacherReply != "" {
+		text += "Новый ответ заменит прежний.\n"
+	}
+	text += fmt.Sprintf("Напишите ответ (до %d символов) — его увидят студент и посетители вашего профиля.\n\n"+
+		"Для отмены используйте /cancel", service.MaxReviewCommentLength)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    msg.Chat.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleReviewReport просит подтвердить жалобу на отзыв
+func HandleReviewReport(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: review_report:reviewID
+	reviewID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	review, err := h.ReviewService.GetReview(ctx, user.ID, reviewID)
+	if err != nil || review.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Отзыв не найден")
+		return
+	}
+
+	text := fmt.Sprintf(
+		"🚩 <b>Пожаловаться на отзыв?</b>\n\n%s — %s\n<i>%s</i>\n\n"+
+			"Пожалуйтесь, если отзыв оскорбительный или не относится к занятию. "+
+			"Отзыв останется в профиле с пометкой, что вы его оспариваете, оценка останется в рейтинге.",
+		formatting.FormatStars(review.Rating),
+		html.EscapeString(review.StudentName),
+		html.EscapeString(review.Comment),
+	)
+
+	kb := keyboard.NewBuilder()
+	kb.AddRows(keyboard.ConfirmCancelButtons(fmt.Sprintf("confirm_review_report:%d", review.ID), "my_reviews"))
+
+	editReviewsMessage(ctx, b, callback, text, kb.Build())
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleConfirmReviewReport отправляет жалобу на отзыв
+func HandleConfirmReviewReport(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: confirm_review_report:reviewID
+	reviewID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	if err := h.ReviewService.Report(ctx, user.ID, reviewID); err != nil {
+		switch err.Error() {
+		case "review not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Отзыв не найден")
+			return
+		case "already reported":
+			// Жалоба уже отправлена — просто обновляем экран
+		default:
+			h.Logger.Error("Failed to report review", zap.Int64("review_id", reviewID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось отправить жалобу")
+			return
+		}
+	}
+
+	showMyReviews(ctx, b, callback, h, user.ID)
+	common.AnswerCallback(ctx, b, callback.ID, "🚩 Жалоба отправлена")
+}
+
+// truncateReviewText сокращает отзыв для списка
+func truncateReviewText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= 200 {
+		return text
+	}
+	return string(runes[:200]) + "…"
+}
+
+// editReviewsMessage заменяет экран отзывов в сообщении callback
+func editReviewsMessage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, text string, kb *models.InlineKeyboardMarkup) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
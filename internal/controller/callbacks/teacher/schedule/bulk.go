@@ -0,0 +1,527 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Bulk Slot Handlers
+// ========================
+
+// Ключи state для режима множественного выбора
+const (
+	bulkScopeKey    = "bulk_slots_scope"
+	bulkSelectedKey = "bulk_selected_slots"
+)
+
+// bulkScope описывает экран, из которого открыт режим выбора
+// Формат: d:subjectID:date (день предмета) или w:weekOffset:date (день недели, все предметы)
+type bulkScope struct {
+	fromWeek   bool
+	subjectID  int64
+	weekOffset int
+	date       time.Time
+}
+
+// parseBulkScope разбирает область выбора из строки формата d:123:2024-01-15 / w:0:2024-01-15
+func parseBulkScope(raw string) (*bulkScope, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid scope format")
+	}
+
+	date, err := time.Parse("2006-01-02", parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %w", err)
+	}
+
+	scope := &bulkScope{date: date}
+	switch parts[0] {
+	case "d":
+		scope.subjectID, err = strconv.ParseInt(parts[1], 10, 64)
+	case "w":
+		scope.fromWeek = true
+		scope.weekOffset, err = strconv.Atoi(parts[1])
+	default:
+		return nil, fmt.Errorf("unknown scope type")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid scope id: %w", err)
+	}
+
+	return scope, nil
+}
+
+// backCallback возвращает callback для возврата к экрану дня
+func (s *bulkScope) backCallback() string {
+	dateStr := s.date.Format("2006-01-02")
+	if s.fromWeek {
+		return fmt.Sprintf("view_schedule_week_day:%d:%s", s.weekOffset, dateStr)
+	}
+	return fmt.Sprintf("view_schedule_day:%d:%s:%s", s.subjectID, dateStr, formatting.GetWeekdayName(int(s.date.Weekday())))
+}
+
+// bulkActionLabels названия действий для кнопок и сообщений
+var bulkActionLabels = map[model.SlotBulkAction]string{
+	model.SlotBulkCancel:  "❌ Отменить",
+	model.SlotBulkRestore: "♻️ Восстановить",
+	model.SlotBulkBusy:    "📌 Пометить занятыми",
+	model.SlotBulkDelete:  "🗑 Удалить",
+}
+
+// HandleBulkSlots включает режим множественного выбора слотов на экране дня
+func HandleBulkSlots(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	h.Logger.Info("HandleBulkSlots called",
+		zap.String("callback_data", callback.Data),
+		zap.Int64("user_id", callback.From.ID))
+
+	// Формат: bulk_slots:d:123:2024-01-15 или bulk_slots:w:0:2024-01-15
+	raw := strings.TrimPrefix(callback.Data, "bulk_slots:")
+	scope, err := parseBulkScope(raw)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	telegramID := callback.From.ID
+	h.StateManager.SetData(telegramID, bulkScopeKey, raw)
+	h.StateManager.SetData(telegramID, bulkSelectedKey, make(map[int64]bool))
+
+	showBulkSlotsScreen(ctx, b, callback, h, scope)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBulkToggle переключает выбор слота
+func HandleBulkToggle(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: bulk_toggle:123
+	slotID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID слота")
+		return
+	}
+
+	scope, selected, ok := getBulkState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Режим выбора устарел, откройте день заново")
+		return
+	}
+
+	if selected[slotID] {
+		delete(selected, slotID)
+	} else {
+		selected[slotID] = true
+	}
+	h.StateManager.SetData(callback.From.ID, bulkSelectedKey, selected)
+
+	showBulkSlotsScreen(ctx, b, callback, h, scope)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBulkSelect выбирает все слоты дня, снимает выбор или возвращает к экрану выбора
+func HandleBulkSelect(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: bulk_select:all / bulk_select:none / bulk_select:keep (вернуться к выбору)
+	scope, selected, ok := getBulkState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Режим выбора устарел, откройте день заново")
+		return
+	}
+
+	mode := strings.TrimPrefix(callback.Data, "bulk_select:")
+	if mode != "keep" {
+		selected = make(map[int64]bool)
+	}
+	if mode == "all" {
+		user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+		if err != nil || user == nil {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+			return
+		}
+
+		for _, slot := range loadBulkSlots(ctx, h, user.ID, scope) {
+			selected[slot.ID] = true
+		}
+	}
+	h.StateManager.SetData(callback.From.ID, bulkSelectedKey, selected)
+
+	showBulkSlotsScreen(ctx, b, callback, h, scope)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBulkAction показывает подтверждение действия над выбранными слотами
+func HandleBulkAction(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	h.Logger.Info("HandleBulkAction called",
+		zap.String("callback_data", callback.Data),
+		zap.Int64("user_id", callback.From.ID))
+
+	// Формат: bulk_action:cancel
+	action := model.SlotBulkAction(strings.TrimPrefix(callback.Data, "bulk_action:"))
+	if !action.IsValid() {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестное действие")
+		return
+	}
+
+	_, selected, ok := getBulkState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Режим выбора устарел, откройте день заново")
+		return
+	}
+
+	if len(selected) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "⚠️ Сначала выберите слоты")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	// Считаем, сколько бронирований студентов затронет действие
+	bookedCount := 0
+	if action == model.SlotBulkCancel || action == model.SlotBulkDelete {
+		for slotID := range selected {
+			slot, err := h.TeacherService.GetSlotByID(ctx, slotID)
+			if err == nil && slot != nil && slot.Status == model.SlotStatusBooked && slot.StudentID != nil {
+				bookedCount++
+			}
+		}
+	}
+
+	text := fmt.Sprintf("⚠️ <b>Подтверждение</b>\n\n"+
+		"Действие: %s\n"+
+		"Выбрано: %d %s\n",
+		bulkActionLabels[action],
+		len(selected), formatting.PluralizeSlots(len(selected)))
+
+	if bookedCount > 0 {
+		text += fmt.Sprintf("\n👥 Бронирований студентов: %d — они будут отменены, студенты получат уведомление.\n", bookedCount)
+	}
+	text += "\nНеподходящие слоты (уже прошедшие или с другим статусом) будут пропущены."
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "✅ Подтвердить", CallbackData: fmt.Sprintf("bulk_confirm:%s", action)}},
+			{{Text: "⬅️ Назад к выбору", CallbackData: "bulk_select:keep"}},
+		},
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBulkConfirm применяет действие к выбранным слотам и уведомляет студентов
+func HandleBulkConfirm(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	h.Logger.Info("HandleBulkConfirm called",
+		zap.String("callback_data", callback.Data),
+		zap.Int64("user_id", callback.From.ID))
+
+	// Формат: bulk_confirm:cancel
+	action := model.SlotBulkAction(strings.TrimPrefix(callback.Data, "bulk_confirm:"))
+	if !action.IsValid() {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестное действие")
+		return
+	}
+
+	scope, selected, ok := getBulkState(h, callback.From.ID)
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Режим выбора устарел, откройте день заново")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	slotIDs := make([]int64, 0, len(selected))
+	for slotID := range selected {
+		slotIDs = append(slotIDs, slotID)
+	}
+
+	result, err := h.TeacherService.BulkSlotAction(ctx, user.ID, slotIDs, action)
+	if err != nil {
+		if err.Error() == "no slots selected" {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "⚠️ Сначала выберите слоты")
+			return
+		}
+		h.Logger.Error("Failed to apply bulk slot action",
+			zap.Error(err),
+			zap.String("action", string(action)))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось выполнить действие, изменения не применены")
+		return
+	}
+
+	h.StateManager.SetData(callback.From.ID, bulkSelectedKey, make(map[int64]bool))
+
+	notified := notifyBulkCanceledBookings(ctx, b, h, result.CanceledBookings)
+
+	text := fmt.Sprintf("✅ <b>Готово</b>\n\n"+
+		"Действие: %s\n"+
+		"✅ Выполнено: %d %s\n"+
+		"⏭ Пропущено: %d\n",
+		bulkActionLabels[action],
+		len(result.Processed), formatting.PluralizeSlots(len(result.Processed)),
+		len(result.Skipped))
+
+	if len(result.CanceledBookings) > 0 {
+		text += fmt.Sprintf("👥 Отменено бронирований: %d (уведомлено студентов: %d)\n", len(result.CanceledBookings), notified)
+	}
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "☑️ Продолжить выбор", CallbackData: "bulk_select:none"}},
+			{{Text: "⬅️ К расписанию дня", CallbackData: scope.backCallback()}},
+		},
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "✅ Готово")
+}
+
+// getBulkState возвращает область и выбранные слоты из state
+func getBulkState(h *callbacktypes.Handler, telegramID int64) (*bulkScope, map[int64]bool, bool) {
+	rawScope, ok := h.StateManager.GetData(telegramID, bulkScopeKey)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scopeStr, ok := rawScope.(string)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scope, err := parseBulkScope(scopeStr)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	selected := make(map[int64]bool)
+	if rawSelected, ok := h.StateManager.GetData(telegramID, bulkSelectedKey); ok {
+		if m, ok := rawSelected.(map[int64]bool); ok {
+			selected = m
+		}
+	}
+
+	return scope, selected, true
+}
+
+// loadBulkSlots загружает слоты дня, отсортированные по времени
+func loadBulkSlots(ctx context.Context, h *callbacktypes.Handler, teacherID int64, scope *bulkScope) []*model.ScheduleSlot {
+	startOfDay := time.Date(scope.date.Year(), scope.date.Month(), scope.date.Day(), 0, 0, 0, 0, scope.date.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	allSlots, err := h.TeacherService.GetTeacherSchedule(ctx, teacherID, startOfDay, endOfDay)
+	if err != nil {
+		h.Logger.Error("Failed to get schedule", zap.Error(err))
+		return nil
+	}
+
+	var slots []*model.ScheduleSlot
+	for _, slot := range allSlots {
+		if scope.fromWeek || slot.SubjectID == scope.subjectID {
+			slots = append(slots, slot)
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].StartTime.Before(slots[j].StartTime)
+	})
+
+	return slots
+}
+
+// showBulkSlotsScreen отображает экран множественного выбора слотов
+func showBulkSlotsScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, scope *bulkScope) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		return
+	}
+
+	_, selected, _ := getBulkState(h, callback.From.ID)
+	slots := loadBulkSlots(ctx, h, user.ID, scope)
+
+	subjectNames := make(map[int64]string)
+	var buttons [][]models.InlineKeyboardButton
+	selectedCount := 0
+
+	for _, slot := range slots {
+		checkbox := "⬜️"
+		if selected[slot.ID] {
+			checkbox = "☑️"
+			selectedCount++
+		}
+
+		statusEmoji := "🟢"
+		switch slot.Status {
+		case model.SlotStatusBooked:
+			statusEmoji = "🔴"
+		case model.SlotStatusCanceled:
+			statusEmoji = "⚫️"
+		}
+
+		buttonText := fmt.Sprintf("%s %s %s-%s", checkbox, statusEmoji, slot.StartTime.Format("15:04"), slot.EndTime.Format("15:04"))
+
+		// На экране недели показываем предмет, так как там слоты всех предметов
+		if scope.fromWeek {
+			name, exists := subjectNames[slot.SubjectID]
+			if !exists {
+				subject, err := h.TeacherService.GetSubjectByID(ctx, slot.SubjectID)
+				if err == nil && subject != nil {
+					name = subject.Name
+				}
+				subjectNames[slot.SubjectID] = name
+			}
+			if name != "" {
+				buttonText += " · " + name
+			}
+		}
+
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: buttonText, CallbackData: fmt.Sprintf("bulk_toggle:%d", slot.ID)},
+		})
+	}
+
+	text := fmt.Sprintf("☑️ <b>Выбор слотов на %s</b>\n\n", scope.date.Format("02.01.2006"))
+	if len(slots) == 0 {
+		text += "📭 На этот день нет слотов"
+	} else {
+		text += "Отметьте слоты и выберите действие.\n"
+		text += "🟢 свободен  🔴 занят  ⚫️ отменён\n\n"
+		text += fmt.Sprintf("Выбрано: <b>%d</b> из %d", selectedCount, len(slots))
+
+		buttons = append(buttons,
+			[]models.InlineKeyboardButton{
+				{Text: "✅ Выбрать все", CallbackData: "bulk_select:all"},
+				{Text: "✖️ Снять выбор", CallbackData: "bulk_select:none"},
+			},
+			[]models.InlineKeyboardButton{
+				{Text: bulkActionLabels[model.SlotBulkCancel], CallbackData: fmt.Sprintf("bulk_action:%s", model.SlotBulkCancel)},
+				{Text: bulkActionLabels[model.SlotBulkRestore], CallbackData: fmt.Sprintf("bulk_action:%s", model.SlotBulkRestore)},
+			},
+			[]models.InlineKeyboardButton{
+				{Text: bulkActionLabels[model.SlotBulkBusy], CallbackData: fmt.Sprintf("bulk_action:%s", model.SlotBulkBusy)},
+				{Text: bulkActionLabels[model.SlotBulkDelete], CallbackData: fmt.Sprintf("bulk_action:%s", model.SlotBulkDelete)},
+			},
+		)
+	}
+
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "⬅️ Назад", CallbackData: scope.backCallback()},
+	})
+
+	keyboard := &models.InlineKeyboardMarkup{InlineKeyboard: buttons}
+
+	// Экран дня по предмету отправляется как фото — заменяем его текстовым сообщением
+	if len(msg.Photo) > 0 {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+		})
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      msg.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// notifyBulkCanceledBookings уведомляет студентов об отмене их занятий.
+// Возвращает количество отправленных уведомлений
+func notifyBulkCanceledBookings(ctx context.Context, b *bot.Bot, h *callbacktypes.Handler, bookings []*model.Booking) int {
+	subjectNames := make(map[int64]string)
+	notified := 0
+
+	for _, booking := range bookings {
+		student, err := h.UserService.GetByID(ctx, booking.StudentID)
+		if err != nil || student == nil {
+			h.Logger.Warn("Student not found for bulk cancel notification",
+				zap.Int64("booking_id", booking.ID),
+				zap.Error(err))
+			continue
+		}
+
+		name, exists := subjectNames[booking.SubjectID]
+		if !exists {
+			subject, err := h.TeacherService.GetSubjectByID(ctx, booking.SubjectID)
+			if err == nil && subject != nil {
+				name = subject.Name
+			}
+			subjectNames[booking.SubjectID] = name
+		}
+
+		text := fmt.Sprintf(
+			"❌ <b>Занятие отменено преподавателем</b>\n\n"+
+				"📚 Предмет: %s\n"+
+				"📆 Дата: %s\n"+
+				"🕐 Время: %s - %s",
+			name,
+			booking.Slot.StartTime.Format("02.01.2006"),
+			booking.Slot.StartTime.Format("15:04"),
+			booking.Slot.EndTime.Format("15:04"))
+
+		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    student.TelegramID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			h.Logger.Warn("Failed to notify student about canceled booking",
+				zap.Int64("booking_id", booking.ID),
+				zap.Error(err))
+			continue
+		}
+		notified++
+	}
+
+	return notified
+}
@@ -145,6 +145,12 @@ func showScheduleCalendar(ctx context.Context, b *bot.Bot, callback *models.Call
 		buttons = append(buttons, navButtons)
 	}
 
+	// Обзор месяца с загрузкой по дням и PDF-расписание недели по всем предметам
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "🗓 Обзор месяца", CallbackData: fmt.Sprintf("schedule_month:%d:0", subjectID)},
+		{Text: "📄 PDF недели", CallbackData: fmt.Sprintf("teacher_pdf:%s:%d", common.TimetablePeriodWeek, offset/7)},
+	})
+
 	// Кнопка "Назад к предмету"
 	buttons = append(buttons, []models.InlineKeyboardButton{
 		{Text: "⬅️ Назад к предмету", CallbackData: fmt.Sprintf("view_subject:%d", subjectID)},
@@ -390,6 +396,20 @@ func HandleViewScheduleDay(ctx context.Context, b *bot.Bot, callback *models.Cal
 		}
 	}
 
+	// Режим множественного выбора для преподавателя
+	if user.IsTeacher && len(slots) > 0 {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "☑️ Выбрать несколько", CallbackData: fmt.Sprintf("bulk_slots:d:%d:%s", subjectID, dateStr)},
+		})
+	}
+
+	// Печатное расписание дня по всем предметам
+	if user.IsTeacher {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "🖨 Печать дня", CallbackData: fmt.Sprintf("schedule_agenda:%s", dateStr)},
+		})
+	}
+
 	// Кнопка назад
 	buttons = append(buttons, []models.InlineKeyboardButton{
 		{Text: "⬅️ Назад к календарю", CallbackData: fmt.Sprintf("view_schedule_calendar:%d", subjectID)},
@@ -0,0 +1,296 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Copy Week Handlers
+// ========================
+
+// copyWeekOptions варианты количества недель для копирования
+var copyWeekOptions = []int{1, 2, 3, 4, service.MaxCopyWeeks}
+
+// isCopyWeekOption проверяет, что количество недель — один из предложенных вариантов
+func isCopyWeekOption(weeks int) bool {
+	for _, option := range copyWeekOptions {
+		if option == weeks {
+			return true
+		}
+	}
+	return false
+}
+
+// weekStartByOffset возвращает понедельник 00:00 недели со смещением weekOffset от текущей
+func weekStartByOffset(weekOffset int) time.Time {
+	now := time.Now()
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday+weekOffset*7, 0, 0, 0, 0, now.Location())
+}
+
+// HandleCopyWeek показывает выбор предмета для копирования недели
+func HandleCopyWeek(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	h.Logger.Info("HandleCopyWeek called",
+		zap.String("callback_data", callback.Data),
+		zap.Int64("user_id", callback.From.ID))
+
+	// Формат: copy_week:0 (weekOffset)
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	weekOffset, err := strconv.Atoi(parts[1])
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверное смещение")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher subjects", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка загрузки предметов")
+		return
+	}
+
+	buttons := [][]models.InlineKeyboardButton{
+		{{Text: "📚 Все предметы", CallbackData: fmt.Sprintf("copy_week_subject:%d:0", weekOffset)}},
+	}
+	for _, subject := range subjects {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: subject.Name, CallbackData: fmt.Sprintf("copy_week_subject:%d:%d", weekOffset, subject.ID)},
+		})
+	}
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("view_schedule_weeks:%d", weekOffset)},
+	})
+
+	weekStart := weekStartByOffset(weekOffset)
+	text := fmt.Sprintf("📋 <b>Копирование недели</b>\n\n"+
+		"📍 Неделя %s-%s\n\n"+
+		"Все неотменённые слоты этой недели будут скопированы на следующие недели.\n"+
+		"Какие слоты копировать?",
+		weekStart.Format("02.01"),
+		weekStart.AddDate(0, 0, 6).Format("02.01"))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: buttons},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleCopyWeekSubject показывает выбор количества недель для копирования
+func HandleCopyWeekSubject(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: copy_week_subject:0:123 (weekOffset:subjectID, 0 = все предметы)
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	weekOffset, err1 := strconv.Atoi(parts[1])
+	subjectID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	var buttons [][]models.InlineKeyboardButton
+	var row []models.InlineKeyboardButton
+	for _, weeks := range copyWeekOptions {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d %s", weeks, formatting.PluralizeWeeks(weeks)),
+			CallbackData: fmt.Sprintf("copy_week_weeks:%d:%d:%d", weekOffset, subjectID, weeks),
+		})
+		if len(row) == 3 {
+			buttons = append(buttons, row)
+			row = []models.InlineKeyboardButton{}
+		}
+	}
+	if len(row) > 0 {
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("copy_week:%d", weekOffset)},
+	})
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        "📋 <b>Копирование недели</b>\n\nНа сколько следующих недель скопировать слоты?",
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: buttons},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleCopyWeekPreview показывает итоги копирования перед подтверждением
+func HandleCopyWeekPreview(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	handleCopyWeekStep(ctx, b, callback, h, false)
+}
+
+// HandleCopyWeekConfirm выполняет копирование недели
+func HandleCopyWeekConfirm(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	handleCopyWeekStep(ctx, b, callback, h, true)
+}
+
+// handleCopyWeekStep рассчитывает (execute = false) или выполняет (execute = true) копирование
+// Формат: copy_week_weeks:0:123:4 / copy_week_confirm:0:123:4 (weekOffset:subjectID:weeks)
+func handleCopyWeekStep(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, execute bool) {
+	h.Logger.Info("Copy week step",
+		zap.String("callback_data", callback.Data),
+		zap.Int64("user_id", callback.From.ID),
+		zap.Bool("execute", execute))
+
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 4 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	weekOffset, err1 := strconv.Atoi(parts[1])
+	subjectID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	weeks, err3 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil || !isCopyWeekOption(weeks) {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subjectName := "все предметы"
+	if subjectID != 0 {
+		subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+		if err != nil || subject == nil || subject.TeacherID != user.ID {
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+			return
+		}
+		subjectName = subject.Name
+	}
+
+	weekStart := weekStartByOffset(weekOffset)
+
+	var plan *model.SlotCopyPlan
+	if execute {
+		plan, err = h.TeacherService.CopyWeek(ctx, user.ID, weekStart, subjectID, weeks)
+	} else {
+		plan, err = h.TeacherService.PlanWeekCopy(ctx, user.ID, weekStart, subjectID, weeks)
+	}
+	if err != nil {
+		h.Logger.Error("Failed to copy week", zap.Error(err), zap.Bool("execute", execute))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось скопировать неделю")
+		return
+	}
+
+	header := "📋 <b>Копирование недели — проверка</b>"
+	createdLabel := "✅ Будет создано"
+	if execute {
+		header = "✅ <b>Неделя скопирована!</b>"
+		createdLabel = "✅ Создано"
+	}
+
+	text := fmt.Sprintf("%s\n\n"+
+		"📍 Неделя %s-%s\n"+
+		"📚 Предмет: %s\n"+
+		"🔁 Копий: на %d %s вперёд (до %s)\n\n"+
+		"%s: %d %s\n"+
+		"⏭ Пропущено (уже есть или в прошлом): %d\n",
+		header,
+		weekStart.Format("02.01"),
+		weekStart.AddDate(0, 0, 6).Format("02.01"),
+		subjectName,
+		weeks, formatting.PluralizeWeeks(weeks),
+		weekStart.AddDate(0, 0, 7*weeks+6).Format("02.01"),
+		createdLabel, len(plan.Slots), formatting.PluralizeSlots(len(plan.Slots)),
+		len(plan.Skipped))
+
+	if conflictsText := formatting.FormatSlotConflicts(plan.Conflicts); conflictsText != "" {
+		text += "\n" + conflictsText
+	}
+
+	if len(plan.Failed) > 0 {
+		text += fmt.Sprintf("\n❌ Не удалось создать: %d %s — попробуйте скопировать ещё раз\n",
+			len(plan.Failed), formatting.PluralizeSlots(len(plan.Failed)))
+	}
+
+	var buttons [][]models.InlineKeyboardButton
+	if !execute {
+		if len(plan.Slots) > 0 {
+			text += "\nСлоты с пересечениями будут пропущены."
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "✅ Скопировать", CallbackData: fmt.Sprintf("copy_week_confirm:%d:%d:%d", weekOffset, subjectID, weeks)},
+			})
+		} else {
+			text += "\nНечего копировать."
+		}
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("copy_week_subject:%d:%d", weekOffset, subjectID)},
+		})
+	} else {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "📅 К расписанию недели", CallbackData: fmt.Sprintf("view_schedule_weeks:%d", weekOffset)},
+		})
+	}
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: buttons},
+	})
+
+	if execute {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("✅ Создано %d %s", len(plan.Slots), formatting.PluralizeSlots(len(plan.Slots))))
+		return
+	}
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
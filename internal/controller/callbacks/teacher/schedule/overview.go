@@ -0,0 +1,256 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// scheduleMonthMaxOffset на сколько месяцев назад и вперёд можно листать обзор месяца
+	scheduleMonthMaxOffset = 12
+)
+
+// HandleScheduleMonth показывает обзор месяца по предмету: занятые и свободные слоты по дням
+func HandleScheduleMonth(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: schedule_month:subjectID:offset
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	subjectID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	offset, err := strconv.Atoi(parts[2])
+	if err != nil || offset < -scheduleMonthMaxOffset || offset > scheduleMonthMaxOffset {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверное смещение")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil || subject.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, offset, 0)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	allSlots, err := h.TeacherService.GetTeacherSchedule(ctx, user.ID, monthStart, monthEnd)
+	if err != nil {
+		h.Logger.Error("Failed to get month schedule", zap.Int64("subject_id", subjectID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке расписания")
+		return
+	}
+
+	var slots []*model.ScheduleSlot
+	var booked, free int
+	for _, slot := range allSlots {
+		if slot.SubjectID != subjectID {
+			continue
+		}
+		slots = append(slots, slot)
+		switch slot.Status {
+		case model.SlotStatusBooked:
+			booked++
+		case model.SlotStatusFree:
+			free++
+		}
+	}
+
+	imageData, err := common.GenerateMonthImage(monthStart, slots)
+	if err != nil {
+		h.Logger.Error("Failed to generate month image", zap.Int64("subject_id", subjectID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось построить обзор месяца")
+		return
+	}
+
+	text := fmt.Sprintf("🗓 <b>%s: %s %d</b>\n\n", subject.Name, formatting.GetMonthName(monthStart.Month()), monthStart.Year())
+	if booked+free == 0 {
+		text += "В этом месяце слотов нет."
+	} else {
+		text += fmt.Sprintf("🔴 Занято: %d\n🟢 Свободно: %d\n\nЧем насыщеннее цвет дня, тем выше загрузка.", booked, free)
+	}
+
+	var navButtons []models.InlineKeyboardButton
+	if offset > -scheduleMonthMaxOffset {
+		navButtons = append(navButtons, models.InlineKeyboardButton{
+			Text:         "⬅️ Пред. месяц",
+			CallbackData: fmt.Sprintf("schedule_month:%d:%d", subjectID, offset-1),
+		})
+	}
+	if offset < scheduleMonthMaxOffset {
+		navButtons = append(navButtons, models.InlineKeyboardButton{
+			Text:         "След. месяц ➡️",
+			CallbackData: fmt.Sprintf("schedule_month:%d:%d", subjectID, offset+1),
+		})
+	}
+
+	buttons := [][]models.InlineKeyboardButton{navButtons}
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "📄 PDF за месяц", CallbackData: fmt.Sprintf("teacher_pdf:%s:%d", common.TimetablePeriodMonth, offset)},
+	})
+	if offset != 0 {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "📍 Текущий месяц", CallbackData: fmt.Sprintf("schedule_month:%d:0", subjectID)},
+		})
+	}
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "⬅️ Назад к календарю", CallbackData: fmt.Sprintf("view_schedule_calendar:%d", subjectID)},
+	})
+
+	// Отправляем изображение с подписью и удаляем старое сообщение
+	b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:      msg.Chat.ID,
+		Photo:       &models.InputFileUpload{Filename: "month.png", Data: bytes.NewReader(imageData)},
+		Caption:     text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: buttons},
+	})
+	b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleScheduleAgenda отправляет печатное расписание дня по всем предметам учителя
+func HandleScheduleAgenda(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: schedule_agenda:2024-01-15
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimPrefix(callback.Data, "schedule_agenda:"), time.Local)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверная дата")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	slots, err := h.TeacherService.GetTeacherSchedule(ctx, user.ID, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		h.Logger.Error("Failed to get day schedule", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке расписания")
+		return
+	}
+
+	subjectNames := make(map[int64]string)
+	subjects, err := h.TeacherService.GetTeacherSubjects(ctx, user.ID)
+	if err != nil {
+		h.Logger.Warn("Failed to get teacher subjects", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+	for _, subject := range subjects {
+		subjectNames[subject.ID] = subject.Name
+	}
+
+	studentIDsMap := make(map[int64]bool)
+	for _, slot := range slots {
+		if slot.StudentID != nil {
+			studentIDsMap[*slot.StudentID] = true
+		}
+	}
+	studentIDs := make([]int64, 0, len(studentIDsMap))
+	for id := range studentIDsMap {
+		studentIDs = append(studentIDs, id)
+	}
+	studentNames := make(map[int64]string)
+	if len(studentIDs) > 0 {
+		students, _ := h.UserService.GetByIDs(ctx, studentIDs)
+		for _, student := range students {
+			name := student.FirstName
+			if student.LastName != "" {
+				name += " " + student.LastName
+			}
+			studentNames[student.ID] = name
+		}
+	}
+
+	imageData, err := common.GenerateAgendaImage(day, slots, subjectNames, studentNames)
+	if err != nil {
+		h.Logger.Error("Failed to generate agenda image", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сформировать расписание")
+		return
+	}
+
+	// Отправляем файлом, чтобы картинка не сжималась и подходила для печати
+	b.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: callback.From.ID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("agenda_%s.png", day.Format("2006-01-02")),
+			Data:     bytes.NewReader(imageData),
+		},
+		Caption: fmt.Sprintf("🖨 Расписание на %s, %s", strings.ToLower(formatting.GetWeekdayName(int(day.Weekday()))), day.Format("02.01.2006")),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "🖨 Расписание отправлено")
+}
+
+// HandleTeacherTimetablePDF отправляет PDF-расписание занятий учителя по всем предметам на неделю или месяц
+func HandleTeacherTimetablePDF(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: teacher_pdf:<w|m>:<смещение в неделях или месяцах от текущих>
+	period, offset, err := common.ParseTimetableCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	from, to, err := common.TimetableRange(period, offset, time.Now())
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	bookings, err := h.BookingService.GetTeacherLessons(ctx, user.ID, from, to)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher lessons", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Ошибка при загрузке занятий")
+		return
+	}
+
+	timetable := common.TeacherTimetable(user, bookings, period, from, to)
+	if err := common.SendTimetablePDF(ctx, b, callback.From.ID, timetable); err != nil {
+		h.Logger.Error("Failed to send teacher timetable", zap.Int64("user_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось сформировать PDF")
+		return
+	}
+
+	common.AnswerCallback(ctx, b, callback.ID, "📄 Расписание отправлено")
+}
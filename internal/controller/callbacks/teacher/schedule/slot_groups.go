@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// ========================
+// Slot Group Handlers
+// ========================
+
+// HandleSlotGroupMenu показывает выбор группы, за которой закрепляется свободный слот
+func HandleSlotGroupMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: slot_group:slot_id:weekOffset
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	slotID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID слота")
+		return
+	}
+	weekOffset, _ := strconv.Atoi(parts[2])
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	groups, err := h.GroupService.GetTeacherGroups(ctx, user.ID)
+	if err != nil {
+		h.Logger.Error("Failed to get teacher groups", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось загрузить группы")
+		return
+	}
+
+	if len(groups) == 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "У вас пока нет групп. Создайте их в разделе «Мои студенты» → «Группы студентов»")
+		return
+	}
+
+	text := "👥 <b>Слот для группы</b>\n\n" +
+		"Записаться на слот смогут только участники выбранной группы, остальные студенты его не увидят."
+
+	kb := keyboard.NewBuilder()
+	for _, group := range groups {
+		kb.Row(keyboard.Button(
+			fmt.Sprintf("%s (%d)", group.Name, group.MembersCount),
+			fmt.Sprintf("set_slot_group:%d:%d:%d", slotID, weekOffset, group.ID),
+		))
+	}
+	kb.Row(keyboard.BackButton(fmt.Sprintf("view_slot_details:%d:%d", slotID, weekOffset)))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleSetSlotGroup закрепляет слот за группой или открывает его для всех студентов (группа 0)
+func HandleSetSlotGroup(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: set_slot_group:slot_id:weekOffset:group_id
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 4 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	slotID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID слота")
+		return
+	}
+
+	groupID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	var target *int64
+	if groupID != 0 {
+		target = &groupID
+	}
+
+	if err := h.GroupService.SetSlotGroup(ctx, user.ID, slotID, target); err != nil {
+		switch err.Error() {
+		case "slot not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Слот не найден")
+		case "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Группа не найдена")
+		case "slot is not free":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Закрепить за группой можно только свободный слот")
+		default:
+			h.Logger.Error("Failed to set slot group", zap.Int64("slot_id", slotID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось изменить слот")
+		}
+		return
+	}
+
+	if target != nil {
+		common.AnswerCallback(ctx, b, callback.ID, "👥 Слот закреплён за группой")
+	} else {
+		common.AnswerCallback(ctx, b, callback.ID, "🔓 Слот открыт для всех")
+	}
+
+	HandleViewSlotDetails(ctx, b, callback, h)
+}
+
+// HandleInviteGroupToSlot рассылает участникам группы приглашение записаться на закреплённый за ней слот
+func HandleInviteGroupToSlot(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: invite_group_slot:slot_id:weekOffset
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	slotID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID слота")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	sent, err := h.GroupService.InviteGroupToSlot(ctx, user.ID, slotID)
+	if err != nil {
+		switch err.Error() {
+		case "slot not found", "group not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Слот или группа не найдены")
+		case "slot has no group":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Слот не закреплён за группой")
+		case "slot is not free":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пригласить можно только на свободный будущий слот")
+		default:
+			h.Logger.Error("Failed to invite group to slot", zap.Int64("slot_id", slotID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось отправить приглашения")
+		}
+		return
+	}
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("📣 Приглашение отправлено: %d", sent))
+}
@@ -3,8 +3,10 @@ package schedule
 import (
 	"context"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
@@ -115,6 +117,12 @@ func HandleViewSlotDetails(ctx context.Context, b *bot.Bot, callback *models.Cal
 		statusEmoji,
 		statusText)
 
+	if slot.GroupID != nil {
+		if group, err := h.GroupService.GetGroup(ctx, user.ID, *slot.GroupID); err == nil {
+			text += fmt.Sprintf("👥 <b>Только для группы:</b> %s\n", html.EscapeString(group.Name))
+		}
+	}
+
 	var buttons [][]models.InlineKeyboardButton
 
 	// Если слот забронирован, показываем информацию о студенте
@@ -135,11 +143,39 @@ func HandleViewSlotDetails(ctx context.Context, b *bot.Bot, callback *models.Cal
 		buttons = append(buttons, []models.InlineKeyboardButton{
 			{Text: "❌ Отменить запись студента", CallbackData: fmt.Sprintf("cancel_booking_from_slot:%d:%d", slotID, weekOffset)},
 		})
+
+		if booking, err := h.BookingService.GetSlotBooking(ctx, slotID); err == nil && booking != nil {
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "💬 Написать студенту", CallbackData: fmt.Sprintf("chat_booking:%d", booking.ID)},
+			})
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "📝 Заметки и ДЗ", CallbackData: fmt.Sprintf("lesson_notes:%d:%d", booking.ID, weekOffset)},
+			})
+		}
+
+		// После занятия можно отметить неявку — она попадёт в отчёты
+		if slot.EndTime.Before(time.Now()) {
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "🚷 Студент не пришёл", CallbackData: fmt.Sprintf("mark_no_show:%d:%d", slotID, weekOffset)},
+			})
+		}
 	} else if slot.Status == model.SlotStatusFree {
 		// Кнопки для свободного слота
 		buttons = append(buttons, []models.InlineKeyboardButton{
 			{Text: "🗑 Отменить слот", CallbackData: fmt.Sprintf("cancel_slot:%d:%d", slotID, weekOffset)},
 		})
+
+		// Слот можно закрепить за группой и пригласить её участников записаться
+		if slot.GroupID != nil {
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "📣 Пригласить группу", CallbackData: fmt.Sprintf("invite_group_slot:%d:%d", slotID, weekOffset)},
+				{Text: "🔓 Открыть для всех", CallbackData: fmt.Sprintf("set_slot_group:%d:%d:0", slotID, weekOffset)},
+			})
+		} else {
+			buttons = append(buttons, []models.InlineKeyboardButton{
+				{Text: "👥 Только для группы", CallbackData: fmt.Sprintf("slot_group:%d:%d", slotID, weekOffset)},
+			})
+		}
 	} else if slot.Status == model.SlotStatusCanceled {
 		// Кнопки для отменённого слота
 		buttons = append(buttons, []models.InlineKeyboardButton{
@@ -276,7 +312,11 @@ func HandleRestoreSlot(ctx context.Context, b *bot.Bot, callback *models.Callbac
 	err = h.TeacherService.RestoreSlot(ctx, slotID)
 	if err != nil {
 		h.Logger.Error("Failed to restore slot", zap.Error(err))
-		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось восстановить слот")
+		errorMsg := "❌ Не удалось восстановить слот"
+		if err.Error() == "slot overlaps existing slot" {
+			errorMsg = "❌ На это время уже есть другой слот в вашем расписании. Отмените его, чтобы восстановить этот."
+		}
+		common.AnswerCallbackAlert(ctx, b, callback.ID, errorMsg)
 		return
 	}
 
@@ -345,6 +385,51 @@ func HandleCancelBookingFromSlot(ctx context.Context, b *bot.Bot, callback *mode
 	HandleViewSlotDetails(ctx, b, callback, h)
 }
 
+// HandleMarkNoShow отмечает неявку студента на прошедшее занятие
+func HandleMarkNoShow(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: mark_no_show:slot_id:weekOffset
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) < 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	slotID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID слота")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	err = h.TeacherService.MarkNoShow(ctx, slotID, user.ID)
+	if err != nil {
+		switch err.Error() {
+		case "slot not found":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Слот не найден")
+		case "slot does not belong to teacher":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ У вас нет доступа к этому слоту")
+		case "lesson has not ended":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Занятие ещё не закончилось")
+		case "no held booking found for this slot":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неявка уже отмечена или запись на занятие отменена")
+		default:
+			h.Logger.Error("Failed to mark no-show", zap.Int64("slot_id", slotID), zap.Error(err))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось отметить неявку")
+		}
+		return
+	}
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, "✅ Неявка отмечена. Начисление за занятие сохраняется")
+
+	// Обновляем экран с деталями
+	HandleViewSlotDetails(ctx, b, callback, h)
+}
+
 // HandleAddSlots начинает процесс добавления слотов
 func HandleAddSlots(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
 	msg := common.GetMessageFromCallback(callback)
@@ -127,6 +127,11 @@ func HandleViewScheduleWeeks(ctx context.Context, b *bot.Bot, callback *models.C
 		buttons = append(buttons, navButtons)
 	}
 
+	// Кнопка копирования недели
+	buttons = append(buttons, []models.InlineKeyboardButton{
+		{Text: "📋 Копировать неделю", CallbackData: fmt.Sprintf("copy_week:%d", weekOffset)},
+	})
+
 	// Кнопка "Назад"
 	buttons = append(buttons, []models.InlineKeyboardButton{
 		{Text: "⬅️ Назад", CallbackData: "back_to_myschedule"},
@@ -298,6 +303,12 @@ func HandleViewScheduleWeekDay(ctx context.Context, b *bot.Bot, callback *models
 		}
 	}
 
+	if len(allSlots) > 0 {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "☑️ Выбрать несколько", CallbackData: fmt.Sprintf("bulk_slots:w:%d:%s", weekOffset, dateStr)},
+		})
+	}
+
 	// Кнопка "Назад"
 	buttons = append(buttons, []models.InlineKeyboardButton{
 		{Text: "⬅️ Назад к неделе", CallbackData: fmt.Sprintf("view_schedule_weeks:%d", weekOffset)},
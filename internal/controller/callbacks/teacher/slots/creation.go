@@ -142,7 +142,7 @@ func HandleSetWeekday(ctx context.Context, b *bot.Bot, callback *models.Callback
 	duration := subject.Duration // в минутах
 	var buttons [][]models.InlineKeyboardButton
 
-	// Генерируем слоты с 00:00 до 23:59 с шагом в длительность занятия
+	// Генерируем слоты с 00:00 до 23:59 с шагом в длительность занятия с учётом перерывов
 	now := time.Now()
 	currentTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, now.Location())
@@ -164,7 +164,7 @@ func HandleSetWeekday(ctx context.Context, b *bot.Bot, callback *models.Callback
 			row = []models.InlineKeyboardButton{}
 		}
 
-		currentTime = currentTime.Add(time.Duration(duration) * time.Minute)
+		currentTime = currentTime.Add(time.Duration(subject.SlotStep()) * time.Minute)
 	}
 
 	// Добавляем оставшиеся кнопки
@@ -292,7 +292,7 @@ func HandleSetTime(ctx context.Context, b *bot.Bot, callback *models.CallbackQue
 
 	// Создаем слоты на 4 недели
 	weekday := time.Weekday(weekdayNum)
-	err = h.TeacherService.CreateWeeklySlots(ctx, user.ID, subjectID, weekday, hour, minute, subject.Duration)
+	conflicts, err := h.TeacherService.CreateWeeklySlots(ctx, user.ID, subjectID, weekday, hour, minute, subject.Duration)
 	if err != nil {
 		h.Logger.Error("Failed to create weekly slots",
 			zap.Int64("teacher_id", user.ID),
@@ -322,6 +322,10 @@ func HandleSetTime(ctx context.Context, b *bot.Bot, callback *models.CallbackQue
 			minute,
 			subject.Duration)
 
+		if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+			text += "\n\n" + conflictsText
+		}
+
 		b.EditMessageText(ctx, &bot.EditMessageTextParams{
 			ChatID:    msg.Chat.ID,
 			MessageID: msg.ID,
@@ -317,7 +317,7 @@ func HandleSingleDayDate(ctx context.Context, b *bot.Bot, callback *models.Callb
 	var buttons [][]models.InlineKeyboardButton
 	duration := subject.Duration // в минутах
 
-	// Генерируем слоты с 00:00 до 23:59 с шагом в длительность занятия
+	// Генерируем слоты с 00:00 до 23:59 с шагом в длительность занятия с учётом перерывов
 	currentTime := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, targetDate.Location())
 	endOfDay := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 23, 59, 0, 0, targetDate.Location())
 
@@ -335,7 +335,7 @@ func HandleSingleDayDate(ctx context.Context, b *bot.Bot, callback *models.Callb
 			row = []models.InlineKeyboardButton{}
 		}
 
-		currentTime = currentTime.Add(time.Duration(duration) * time.Minute)
+		currentTime = currentTime.Add(time.Duration(subject.SlotStep()) * time.Minute)
 	}
 
 	// Добавляем оставшиеся кнопки
@@ -10,6 +10,7 @@ import (
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -99,6 +100,7 @@ func HandlePeriodTime(ctx context.Context, b *bot.Bot, callback *models.Callback
 	weekday := time.Weekday(weekdayNum)
 
 	count := 0
+	var conflicts []model.SlotConflict
 	daysToCheck := weeks * 7
 
 	for i := 0; i < daysToCheck; i++ {
@@ -114,6 +116,10 @@ func HandlePeriodTime(ctx context.Context, b *bot.Bot, callback *models.Callback
 			}
 
 			_, err = h.TeacherService.CreateSlot(ctx, user.ID, subjectID, startTime, endTime)
+			if err != nil && err.Error() == "slot overlaps existing slot" {
+				conflicts = append(conflicts, model.SlotConflict{StartTime: startTime, EndTime: endTime})
+				continue
+			}
 			if err != nil {
 				h.Logger.Warn("Failed to create slot",
 					zap.Error(err),
@@ -150,6 +156,10 @@ func HandlePeriodTime(ctx context.Context, b *bot.Bot, callback *models.Callback
 		weeks, formatting.PluralizeWeeks(weeks),
 		count, formatting.PluralizeSlots(count))
 
+	if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+		text += "\n\n" + conflictsText
+	}
+
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
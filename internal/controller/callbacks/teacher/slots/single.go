@@ -68,7 +68,11 @@ func HandleSingleTimeAuto(ctx context.Context, b *bot.Bot, callback *models.Call
 	slot, err := h.TeacherService.CreateSlot(ctx, user.ID, subjectID, startTime, endTime)
 	if err != nil {
 		h.Logger.Error("Failed to create slot", zap.Error(err))
-		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось создать слот")
+		errorMsg := "❌ Не удалось создать слот"
+		if err.Error() == "slot overlaps existing slot" {
+			errorMsg = "❌ Это время пересекается с другим слотом в вашем расписании (возможно, по другому предмету). Выберите другое время."
+		}
+		common.AnswerCallbackAlert(ctx, b, callback.ID, errorMsg)
 		return
 	}
 
@@ -214,9 +218,13 @@ func HandleCustomTimeInput(ctx context.Context, b *bot.Bot, update *models.Updat
 	slot, err := h.TeacherService.CreateSlot(ctx, user.ID, subjectID, startTime, endTime)
 	if err != nil {
 		h.Logger.Error("Failed to create slot", zap.Error(err))
+		errorText := fmt.Sprintf("❌ Не удалось создать слот: %v", err)
+		if err.Error() == "slot overlaps existing slot" {
+			errorText = "❌ Это время пересекается с другим слотом в вашем расписании (возможно, по другому предмету). Выберите другое время."
+		}
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   fmt.Sprintf("❌ Не удалось создать слот: %v", err),
+			Text:   errorText,
 		})
 		return
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -275,26 +276,18 @@ func HandleWorkdayEnd(ctx context.Context, b *bot.Bot, callback *models.Callback
 	}
 	targetDate := now.AddDate(0, 0, daysUntilTarget)
 
-	// Рассчитываем сколько слотов помещается в рабочий день
-	workdayMinutes := (endHour - startHour) * 60
-	slotsCount := workdayMinutes / subject.Duration
+	// Раскладываем слоты с учётом перерывов до и после занятия:
+	// первый слот начинается после перерыва "до", следующий — через длительность и оба перерыва
+	workdayEndMinutes := (endHour - startHour) * 60
+	step := subject.SlotStep()
 
 	count := 0
+	var conflicts []model.SlotConflict
 
-	for i := 0; i < slotsCount; i++ {
-		// Вычисляем время начала слота
-		minutesFromStart := i * subject.Duration
+	for minutesFromStart := subject.BufferBeforeMinutes; minutesFromStart+subject.Duration <= workdayEndMinutes; minutesFromStart += step {
 		slotStartHour := startHour + (minutesFromStart / 60)
 		slotStartMinute := minutesFromStart % 60
 
-		// Проверяем, что слот заканчивается до endHour
-		slotEndMinutes := minutesFromStart + subject.Duration
-		slotEndHour := startHour + (slotEndMinutes / 60)
-
-		if slotEndHour > endHour {
-			break // Слот выходит за пределы рабочего дня
-		}
-
 		startTime := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
 			slotStartHour, slotStartMinute, 0, 0, location)
 		endTime := startTime.Add(time.Duration(subject.Duration) * time.Minute)
@@ -305,6 +298,10 @@ func HandleWorkdayEnd(ctx context.Context, b *bot.Bot, callback *models.Callback
 		}
 
 		_, err = h.TeacherService.CreateSlot(ctx, user.ID, subjectID, startTime, endTime)
+		if err != nil && err.Error() == "slot overlaps existing slot" {
+			conflicts = append(conflicts, model.SlotConflict{StartTime: startTime, EndTime: endTime})
+			continue
+		}
 		if err != nil {
 			h.Logger.Warn("Failed to create slot",
 				zap.Error(err),
@@ -336,7 +333,8 @@ func HandleWorkdayEnd(ctx context.Context, b *bot.Bot, callback *models.Callback
 		"📚 Предмет: %s\n"+
 		"📅 День: %s\n"+
 		"🕐 Рабочее время: %02d:00 - %02d:00\n"+
-		"⏱ Длительность занятия: %d мин\n\n"+
+		"⏱ Длительность занятия: %d мин\n"+
+		"%s\n"+
 		"Создано %d %s\n\n"+
 		"Посмотреть расписание: /myschedule",
 		subject.Name,
@@ -344,8 +342,13 @@ func HandleWorkdayEnd(ctx context.Context, b *bot.Bot, callback *models.Callback
 		startHour,
 		endHour,
 		subject.Duration,
+		formatting.FormatBuffer(subject.BufferBeforeMinutes, subject.BufferAfterMinutes),
 		count, slotsWord)
 
+	if conflictsText := formatting.FormatSlotConflicts(conflicts); conflictsText != "" {
+		text += "\n\n" + conflictsText
+	}
+
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
@@ -3,9 +3,11 @@ package teacher
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -101,7 +103,7 @@ func HandleViewAccessRequests(ctx context.Context, b *bot.Bot, callback *models.
 	}
 }
 
-// HandleApproveAccessRequest одобряет заявку на доступ
+// HandleApproveAccessRequest предлагает выбрать срок доступа перед одобрением заявки
 func HandleApproveAccessRequest(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
 	telegramID := callback.From.ID
 	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
@@ -116,11 +118,66 @@ func HandleApproveAccessRequest(ctx context.Context, b *bot.Bot, callback *model
 		return
 	}
 
+	request, err := h.AccessRequestRepo.GetByID(ctx, requestID)
+	if err != nil || request == nil || request.TeacherID != user.ID || !request.IsPending() {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Заявка не найдена")
+		return
+	}
+
+	studentName := "студента"
+	if student, _ := h.UserService.GetByID(ctx, request.StudentID); student != nil {
+		studentName = student.FirstName
+		if student.LastName != "" {
+			studentName += " " + student.LastName
+		}
+	}
+
+	text := "✅ *Одобрение заявки*\n\n"
+	text += fmt.Sprintf("На какой срок открыть доступ для *%s*?\n\n", studentName)
+	text += "_Когда срок истечёт, доступ отзывается автоматически, а будущие записи студента отменяются._"
+
+	kb := keyboard.NewBuilder()
+	for _, days := range accessDurationOptions {
+		kb.Row(keyboard.Button(accessDurationLabel(days), fmt.Sprintf("approve_request_for:%d:%d", requestID, days)))
+	}
+	kb.Row(keyboard.BackButton("view_access_requests"))
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+	msg := common.GetMessageFromCallback(callback)
+	if msg != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeMarkdown,
+			ReplyMarkup: kb.Build(),
+		})
+	}
+}
+
+// HandleConfirmApproveAccessRequest одобряет заявку на доступ с выбранным сроком
+func HandleConfirmApproveAccessRequest(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	telegramID := callback.From.ID
+	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	// Формат: approve_request_for:requestID:days
+	ids := common.ParseMultiIDFromCallback(callback.Data, "approve_request_for:")
+	if len(ids) != 2 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+	requestID := ids[0]
+	accessDays := accessDaysFromOption(int(ids[1]))
+
 	// Получаем заявку для уведомления студента
 	request, _ := h.AccessRequestRepo.GetByID(ctx, requestID)
 
 	// Одобряем заявку
-	err = h.AccessService.ApproveAccessRequest(ctx, user.ID, requestID, "Добро пожаловать!")
+	err = h.AccessService.ApproveAccessRequest(ctx, user.ID, requestID, "Добро пожаловать!", accessDays)
 	if err != nil {
 		h.Logger.Error("Failed to approve access request",
 			zap.Int64("request_id", requestID),
@@ -138,14 +195,22 @@ func HandleApproveAccessRequest(ctx context.Context, b *bot.Bot, callback *model
 				teacherName += " " + user.LastName
 			}
 
+			accessNote := ""
+			if accessDays != nil {
+				accessNote = fmt.Sprintf("⏳ Доступ действует до %s.\n\n",
+					time.Now().AddDate(0, 0, *accessDays).Format("02.01.2006"))
+			}
+
 			b.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID: student.TelegramID,
 				Text: fmt.Sprintf(
 					"✅ *Заявка одобрена!*\n\n"+
 						"Учитель *%s* одобрил вашу заявку на доступ.\n\n"+
 						"💬 _Добро пожаловать!_\n\n"+
+						"%s"+
 						"Теперь вы можете просматривать предметы и записываться на занятия.",
 					teacherName,
+					accessNote,
 				),
 				ParseMode: models.ParseModeMarkdown,
 			})
@@ -266,6 +331,22 @@ func HandleViewMyStudents(ctx context.Context, b *bot.Bot, callback *models.Call
 				}
 				text += fmt.Sprintf("   Доступ: %s\n", accessTypeText)
 				text += fmt.Sprintf("   Дата: %s\n", accessInfo.GrantedAt.Format("02.01.2006"))
+				text += formatAccessExpiry(accessInfo)
+			}
+
+			// Остаток оплаченных занятий
+			balances, _ := h.CreditService.GetStudentBalancesWithTeacher(ctx, student.ID, user.ID)
+			if credits := formatting.SumCreditBalances(balances); credits > 0 {
+				text += fmt.Sprintf("   Оплачено: %d %s\n", credits, formatting.PluralizeLessons(credits))
+			}
+
+			// Долг по занятиям, оплачиваемым вне бота
+			if ledgerBalances, err := h.LedgerService.GetBalances(ctx, user.ID, student.ID); err == nil {
+				for _, balance := range ledgerBalances {
+					if debt := balance.Debt(); debt > 0 {
+						text += fmt.Sprintf("   Долг: %s\n", formatting.FormatPriceShort(debt, balance.Currency))
+					}
+				}
 			}
 
 			text += "\n"
@@ -288,10 +369,18 @@ func HandleViewMyStudents(ctx context.Context, b *bot.Bot, callback *models.Call
 
 		kb.AddRow([]models.InlineKeyboardButton{
 			keyboard.Button(fmt.Sprintf("%d. %s", i+1, studentName), "noop"),
+			keyboard.Button("🎟", fmt.Sprintf("student_credits:%d", student.ID)),
+			keyboard.Button("💰", fmt.Sprintf("student_ledger:%d", student.ID)),
+			keyboard.Button("⭐", fmt.Sprintf("subscription_menu:%d", student.ID)),
+			keyboard.Button("💬", fmt.Sprintf("chat_student:%d", student.ID)),
+			keyboard.Button("📝", fmt.Sprintf("student_notes:%d", student.ID)),
 			keyboard.Button("❌ Отозвать", fmt.Sprintf("revoke_access:%d", student.ID)),
 		})
 	}
 
+	kb.Row(keyboard.Button("💰 Взаиморасчёты", "ledger_balances"))
+	kb.Row(keyboard.Button("🧑‍🤝‍🧑 Группы студентов", "manage_groups"))
+	kb.Row(keyboard.Button("📣 Рассылка", "broadcast_menu"), keyboard.Button("💬 Переписки", "my_chats"))
 	kb.Row(keyboard.BackButton("teacher_settings"))
 
 	common.AnswerCallback(ctx, b, callback.ID, "")
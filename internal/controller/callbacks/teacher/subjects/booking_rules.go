@@ -0,0 +1,295 @@
+package subjects
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// Типы правил записи в callback data
+const (
+	bookingRuleNotice  = "notice"
+	bookingRuleAdvance = "advance"
+	bookingRuleActive  = "active"
+	bookingRuleWeek    = "week"
+	bookingRuleBefore  = "before"
+	bookingRuleAfter   = "after"
+	bookingRuleCancel  = "cancel"
+)
+
+// bookingRulePreset вариант значения правила для кнопки
+type bookingRulePreset struct {
+	label string
+	value int
+}
+
+// bufferPresets варианты перерыва до и после занятия
+var bufferPresets = []bookingRulePreset{
+	{"Без перерыва", 0},
+	{"5 минут", 5},
+	{"10 минут", 10},
+	{"15 минут", 15},
+	{"30 минут", 30},
+	{"1 час", 60},
+}
+
+// bookingRulePresets возвращает заголовок меню и варианты значений для правила
+func bookingRulePresets(rule string) (string, []bookingRulePreset, bool) {
+	switch rule {
+	case bookingRuleNotice:
+		return "⏰ За сколько минимум до начала можно записаться?", []bookingRulePreset{
+			{"Без ограничения", 0},
+			{"1 час", 60},
+			{"3 часа", 180},
+			{"12 часов", 720},
+			{"1 день", 1440},
+			{"2 дня", 2880},
+		}, true
+	case bookingRuleAdvance:
+		return "📅 На сколько дней вперёд открыта запись?", []bookingRulePreset{
+			{"Без ограничения", 0},
+			{"7 дней", 7},
+			{"14 дней", 14},
+			{"30 дней", 30},
+			{"60 дней", 60},
+		}, true
+	case bookingRuleActive:
+		return "🎟 Сколько активных записей может быть у студента одновременно?", []bookingRulePreset{
+			{"Без ограничения", 0},
+			{"1", 1},
+			{"2", 2},
+			{"3", 3},
+			{"5", 5},
+		}, true
+	case bookingRuleWeek:
+		return "🗓 Сколько записей в неделю может сделать студент?", []bookingRulePreset{
+			{"Без ограничения", 0},
+			{"1", 1},
+			{"2", 2},
+			{"3", 3},
+		}, true
+	case bookingRuleBefore:
+		return "☕️ Сколько минут перерыва нужно перед занятием?", bufferPresets, true
+	case bookingRuleAfter:
+		return "☕️ Сколько минут перерыва нужно после занятия?", bufferPresets, true
+	case bookingRuleCancel:
+		return "↩️ До какого момента отмена студентом возвращает занятие в пакет?", []bookingRulePreset{
+			{"В любое время", 0},
+			{"За 2 часа", 2},
+			{"За 12 часов", 12},
+			{"За 1 день", 24},
+			{"За 2 дня", 48},
+		}, true
+	}
+	return "", nil, false
+}
+
+// HandleBookingRules показывает правила записи предмета
+func HandleBookingRules(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	showBookingRulesScreen(ctx, b, callback, h, subjectID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleBookingRuleMenu показывает варианты значений для правила записи
+func HandleBookingRuleMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: booking_rule_menu:123:notice
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	subjectID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	title, presets, ok := bookingRulePresets(parts[2])
+	if !ok {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестное правило")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for i := 0; i < len(presets); i += 2 {
+		row := []models.InlineKeyboardButton{
+			{Text: presets[i].label, CallbackData: fmt.Sprintf("set_booking_rule:%d:%s:%d", subjectID, parts[2], presets[i].value)},
+		}
+		if i+1 < len(presets) {
+			row = append(row, models.InlineKeyboardButton{
+				Text:         presets[i+1].label,
+				CallbackData: fmt.Sprintf("set_booking_rule:%d:%s:%d", subjectID, parts[2], presets[i+1].value),
+			})
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("booking_rules:%d", subjectID)},
+	})
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        title,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: rows},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleSetBookingRule устанавливает выбранное значение правила записи
+func HandleSetBookingRule(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: set_booking_rule:123:notice:60
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 4 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	subjectID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	value, err := strconv.Atoi(parts[3])
+	if err != nil || value < 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверное значение")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	switch parts[2] {
+	case bookingRuleNotice:
+		subject.MinNoticeMinutes = value
+	case bookingRuleAdvance:
+		subject.MaxAdvanceDays = value
+	case bookingRuleActive:
+		subject.MaxActiveBookings = value
+	case bookingRuleWeek:
+		subject.MaxBookingsPerWeek = value
+	case bookingRuleBefore:
+		subject.BufferBeforeMinutes = value
+	case bookingRuleAfter:
+		subject.BufferAfterMinutes = value
+	case bookingRuleCancel:
+		subject.FreeCancelHours = value
+	default:
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестное правило")
+		return
+	}
+
+	err = h.TeacherService.UpdateSubject(ctx, user.ID, subject)
+	if err != nil {
+		h.Logger.Error("Failed to update subject booking rules",
+			zap.Error(err),
+			zap.Int64("subject_id", subjectID),
+			zap.String("rule", parts[2]))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось обновить")
+		return
+	}
+
+	showBookingRulesScreen(ctx, b, callback, h, subjectID)
+	common.AnswerCallback(ctx, b, callback.ID, "✅ Правило обновлено")
+}
+
+// showBookingRulesScreen отображает экран правил записи предмета
+func showBookingRulesScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, subjectID int64) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		h.Logger.Error("Subject not found for booking rules",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+		return
+	}
+
+	text, keyboard := buildBookingRulesScreen(subject)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// buildBookingRulesScreen формирует экран правил записи предмета
+func buildBookingRulesScreen(subject *model.Subject) (string, *models.InlineKeyboardMarkup) {
+	text := fmt.Sprintf(
+		"📏 <b>Правила записи: %s</b>\n\n"+
+			"⏰ Минимум до начала: %s\n"+
+			"📅 Горизонт записи: %s\n"+
+			"🎟 Активных записей у студента: %s\n"+
+			"🗓 Записей в неделю: %s\n"+
+			"☕️ Перерыв до занятия: %d мин\n"+
+			"☕️ Перерыв после занятия: %d мин\n"+
+			"↩️ Отмена с возвратом занятия: %s\n\n"+
+			"Студенты не увидят слоты вне окна записи, а при превышении лимитов получат понятное сообщение.\n"+
+			"Перерывы учитываются при раскладке новых слотов и закрывают время вокруг занятых слотов для других предметов.\n"+
+			"Занятие из пакета возвращается студенту, если он отменил запись не позже срока отмены. При отмене учителем занятие возвращается всегда.",
+		subject.Name,
+		formatting.FormatMinNotice(subject.MinNoticeMinutes),
+		formatting.FormatMaxAdvance(subject.MaxAdvanceDays),
+		formatting.FormatBookingLimit(subject.MaxActiveBookings),
+		formatting.FormatBookingLimit(subject.MaxBookingsPerWeek),
+		subject.BufferBeforeMinutes,
+		subject.BufferAfterMinutes,
+		formatting.FormatFreeCancel(subject.FreeCancelHours),
+	)
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "⏰ Минимум до начала", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleNotice)}},
+			{{Text: "📅 Горизонт записи", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleAdvance)}},
+			{{Text: "🎟 Лимит активных записей", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleActive)}},
+			{{Text: "🗓 Лимит в неделю", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleWeek)}},
+			{
+				{Text: "☕️ Перерыв до", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleBefore)},
+				{Text: "☕️ Перерыв после", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleAfter)},
+			},
+			{{Text: "↩️ Отмена с возвратом занятия", CallbackData: fmt.Sprintf("booking_rule_menu:%d:%s", subject.ID, bookingRuleCancel)}},
+			{{Text: "⬅️ Назад", CallbackData: fmt.Sprintf("edit_subject:%d", subject.ID)}},
+		},
+	}
+
+	return text, keyboard
+}
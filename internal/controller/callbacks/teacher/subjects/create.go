@@ -8,7 +8,9 @@ import (
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -73,6 +75,7 @@ func HandleCreateSubjectSetDuration(ctx context.Context, b *bot.Bot, callback *m
 	name, _ := allData["name"].(string)
 	description, _ := allData["description"].(string)
 	price, _ := allData["price"].(int)
+	currency, _ := allData["currency"].(model.Currency)
 
 	// Сохраняем длительность и переходим к одобрению
 	h.StateManager.SetData(telegramID, "duration", duration)
@@ -89,19 +92,17 @@ func HandleCreateSubjectSetDuration(ctx context.Context, b *bot.Bot, callback *m
 		},
 	}
 
-	priceInRubles := float64(price) / 100
-
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
 		Text: fmt.Sprintf("✅ Название: %s\n"+
 			"✅ Описание: %s\n"+
-			"✅ Цена: %.2f ₽\n"+
+			"✅ Цена: %s\n"+
 			"✅ Длительность: %d минут\n\n"+
 			"Шаг 5 из 5: Требуется ли ваше одобрение для записи?\n\n"+
 			"• 🟢 Да - студенты отправляют запрос, вы одобряете\n"+
 			"• 🔵 Нет - студенты записываются автоматически",
-			name, description, priceInRubles, duration),
+			name, description, formatting.FormatPrice(price, currency), duration),
 		ReplyMarkup: keyboard,
 	})
 
@@ -0,0 +1,128 @@
+package subjects
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// HandleSubjectCurrency показывает выбор валюты предмета
+func HandleSubjectCurrency(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	text := fmt.Sprintf("💱 <b>Валюта предмета: %s</b>\n\n", subject.Name) +
+		fmt.Sprintf("Сейчас: %s\n\n", formatting.FormatCurrency(subject.Currency)) +
+		"После выбора новой валюты введите цену занятия в ней. " +
+		"Пакеты занятий предмета будут сняты с продажи — создайте их заново в новой валюте. " +
+		"Уже созданные записи и начисления остаются в прежней валюте."
+
+	kb := keyboard.NewBuilder()
+	for _, currency := range model.SupportedCurrencies() {
+		label := formatting.FormatCurrency(currency)
+		if currency == subject.Currency {
+			label = "✅ " + label
+		}
+		kb.Row(keyboard.Button(label, fmt.Sprintf("set_subject_currency:%d:%s", subject.ID, currency)))
+	}
+	kb.Row(keyboard.BackButton(fmt.Sprintf("edit_subject:%d", subject.ID)))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleSetSubjectCurrency запрашивает цену занятия в выбранной валюте; валюта меняется после ввода цены
+func HandleSetSubjectCurrency(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: set_subject_currency:123:EUR
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	subjectID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	currency := model.Currency(parts[2])
+	if !currency.IsValid() {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестная валюта")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil || subject.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	if subject.Currency == currency {
+		showEditSubjectScreen(ctx, b, callback, h, subjectID)
+		common.AnswerCallback(ctx, b, callback.ID, fmt.Sprintf("✅ Валюта: %s", currency))
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	// Сумма в минимальных единицах не переносится между валютами — цену нужно ввести заново
+	h.StateManager.SetState(callback.From.ID, callbacktypes.UserState(state.StateEditSubjectCurrency))
+	h.StateManager.SetData(callback.From.ID, "subject_id", subjectID)
+	h.StateManager.SetData(callback.From.ID, "currency", string(currency))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text: fmt.Sprintf("💱 Новая валюта: %s\n\n"+
+			"Сейчас цена занятия: %s. Введите цену в %s (например: 1500 или 1500,50):\n\n"+
+			"Для отмены используйте /cancel",
+			formatting.FormatCurrency(currency),
+			formatting.FormatPrice(subject.Price, subject.Currency),
+			currency),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
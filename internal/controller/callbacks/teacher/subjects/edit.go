@@ -94,13 +94,20 @@ func HandleEditFieldPrice(ctx context.Context, b *bot.Bot, callback *models.Call
 		return
 	}
 
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
 	h.StateManager.SetState(telegramID, "edit_subject_price")
 	h.StateManager.SetData(telegramID, "subject_id", subjectID)
 
 	b.EditMessageText(ctx, &bot.EditMessageTextParams{
 		ChatID:    msg.Chat.ID,
 		MessageID: msg.ID,
-		Text:      "💰 Введите новую цену в рублях (например: 1500):\n\nДля отмены используйте /cancel",
+		Text: fmt.Sprintf("💰 Введите новую цену в %s (например: 1500 или 1500,50):\n\nДля отмены используйте /cancel",
+			subject.Currency),
 	})
 
 	common.AnswerCallback(ctx, b, callback.ID, "")
@@ -270,3 +277,48 @@ func HandleToggleApproval(ctx context.Context, b *bot.Bot, callback *models.Call
 	// Обновляем меню
 	HandleEditSubject(ctx, b, callback, h)
 }
+
+// HandleTogglePrepayment переключает предоплату занятий через Telegram Payments
+func HandleTogglePrepayment(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	telegramID := callback.From.ID
+	user, err := h.UserService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	// Бесплатное занятие оплачивать нечем
+	if !subject.RequiresPrepayment && subject.Price <= 0 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Сначала укажите цену занятия")
+		return
+	}
+
+	subject.RequiresPrepayment = !subject.RequiresPrepayment
+	err = h.TeacherService.UpdateSubject(ctx, user.ID, subject)
+	if err != nil {
+		h.Logger.Error("Failed to toggle prepayment", zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось обновить")
+		return
+	}
+
+	statusText := "включена: студент оплачивает занятие сразу при записи"
+	if !subject.RequiresPrepayment {
+		statusText = "выключена"
+	}
+	common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("✅ Предоплата %s", statusText))
+
+	// Обновляем меню
+	HandleEditSubject(ctx, b, callback, h)
+}
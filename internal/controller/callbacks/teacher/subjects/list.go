@@ -9,6 +9,7 @@ import (
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -38,6 +39,7 @@ func HandleCreateFirstSubject(ctx context.Context, b *bot.Bot, callback *models.
 
 	h.StateManager.SetState(telegramID, "create_subject_name")
 	h.StateManager.SetData(telegramID, "teacher_id", user.ID)
+	h.StateManager.SetData(telegramID, "currency", user.DefaultCurrency)
 
 	keyboard := &models.InlineKeyboardMarkup{
 		InlineKeyboard: [][]models.InlineKeyboardButton{{
@@ -160,7 +162,6 @@ func finalizeSubjectCreation(ctx context.Context, b *bot.Bot, callback *models.C
 	// Очищаем состояние
 	h.StateManager.ClearState(telegramID)
 
-	priceInRubles := float64(price) / 100
 	approvalText := "❌ Нет"
 	if requiresApproval {
 		approvalText = "✅ Да"
@@ -184,14 +185,14 @@ func finalizeSubjectCreation(ctx context.Context, b *bot.Bot, callback *models.C
 		Text: fmt.Sprintf("🎉 Предмет успешно создан!\n\n"+
 			"📚 %s\n"+
 			"📝 %s\n"+
-			"💰 %.2f ₽\n"+
+			"💰 %s\n"+
 			"⏱ %d минут\n"+
 			"⏳ Требуется одобрение: %s\n"+
 			"ID: %d\n\n"+
 			"Теперь вы можете:\n"+
 			"• Добавить временные слоты: /addslots\n"+
 			"• Управлять предметами: /mysubjects",
-			name, description, priceInRubles, duration, approvalText, subject.ID),
+			name, description, formatting.FormatPrice(subject.Price, subject.Currency), duration, approvalText, subject.ID),
 	})
 
 	common.AnswerCallback(ctx, b, callback.ID, "✅ Предмет создан!")
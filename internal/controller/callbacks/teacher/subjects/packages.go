@@ -0,0 +1,139 @@
+package subjects
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleSubjectPackages показывает пакеты занятий предмета
+func HandleSubjectPackages(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	// Выходим из диалога создания пакета, если вернулись к списку
+	h.StateManager.ClearState(callback.From.ID)
+
+	showSubjectPackagesScreen(ctx, b, callback, h, subjectID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleAddPackage запрашивает параметры нового пакета занятий
+func HandleAddPackage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	telegramID := callback.From.ID
+	h.StateManager.SetState(telegramID, callbacktypes.UserState(state.StateCreatePackage))
+	h.StateManager.SetData(telegramID, "subject_id", subjectID)
+
+	text := "🎁 <b>Новый пакет занятий</b>\n\n" +
+		"Отправьте одним сообщением через пробел:\n" +
+		"• количество занятий\n" +
+		"• цену всего пакета в валюте предмета\n" +
+		"• срок действия в днях (0 — бессрочно)\n\n" +
+		"Например: <code>10 9000 90</code> — 10 занятий за 9000 на 90 дней\n\n" +
+		"Для отмены используйте /cancel"
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "❌ Отмена", CallbackData: fmt.Sprintf("subject_packages:%d", subjectID)}},
+			},
+		},
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleTogglePackage скрывает пакет из продажи или возвращает его
+func HandleTogglePackage(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	packageID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	pkg, err := h.CreditService.TogglePackageActive(ctx, user.ID, packageID)
+	if err != nil && err.Error() == "package currency differs from subject" {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Цена пакета указана в прежней валюте предмета. Создайте новый пакет")
+		return
+	}
+	if err != nil {
+		h.Logger.Error("Failed to toggle lesson package",
+			zap.Int64("package_id", packageID),
+			zap.Error(err))
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось обновить пакет")
+		return
+	}
+
+	showSubjectPackagesScreen(ctx, b, callback, h, pkg.SubjectID)
+
+	if pkg.IsActive {
+		common.AnswerCallback(ctx, b, callback.ID, "✅ Пакет снова доступен")
+	} else {
+		common.AnswerCallback(ctx, b, callback.ID, "⏸ Пакет скрыт")
+	}
+}
+
+// showSubjectPackagesScreen отображает экран пакетов занятий предмета
+func showSubjectPackagesScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, subjectID int64) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		h.Logger.Error("Subject not found for packages",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+		return
+	}
+
+	packages, err := h.CreditService.GetSubjectPackages(ctx, subjectID)
+	if err != nil {
+		h.Logger.Error("Failed to get lesson packages",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+		return
+	}
+
+	text, keyboard := common.BuildSubjectPackagesScreen(subject, packages)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
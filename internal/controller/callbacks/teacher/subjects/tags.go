@@ -0,0 +1,168 @@
+package subjects
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// noCategory код кнопки, убирающей категорию предмета
+const noCategory = "none"
+
+// HandleSubjectTags показывает категорию и теги предмета, по которым его находят студенты
+func HandleSubjectTags(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil || subject.TeacherID != user.ID {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		return
+	}
+
+	showSubjectTagsScreen(ctx, b, callback, subject)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// showSubjectTagsScreen перерисовывает экран категории и тегов в сообщении callback
+func showSubjectTagsScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, subject *model.Subject) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	text := fmt.Sprintf("🏷 <b>Категория и теги: %s</b>\n\n", html.EscapeString(subject.Name)) +
+		fmt.Sprintf("Категория: %s\n", formatting.FormatSubjectCategory(subject.Category)) +
+		fmt.Sprintf("Теги: %s\n\n", html.EscapeString(formatting.FormatSubjectTags(subject.Tags))) +
+		"Студенты находят предметы поиском по названию, категории, тегам и описанию. " +
+		"Теги помогают уточнить, чему вы учите: например «егэ, разговорный, для детей»."
+
+	kb := keyboard.NewBuilder()
+	var row []models.InlineKeyboardButton
+	for _, category := range model.SubjectCategories {
+		label := category.Emoji + " " + category.Title
+		if category.Code == subject.Category {
+			label = "✅ " + label
+		}
+		row = append(row, keyboard.Button(label, fmt.Sprintf("set_subject_category:%d:%s", subject.ID, category.Code)))
+		if len(row) == 2 {
+			kb.AddRow(row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		kb.AddRow(row)
+	}
+	if subject.Category != "" {
+		kb.Row(keyboard.Button("🚫 Без категории", fmt.Sprintf("set_subject_category:%d:%s", subject.ID, noCategory)))
+	}
+	kb.Row(keyboard.Button("🔖 Изменить теги", fmt.Sprintf("edit_field_tags:%d", subject.ID)))
+	kb.Row(keyboard.BackButton(fmt.Sprintf("edit_subject:%d", subject.ID)))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// HandleSetSubjectCategory меняет категорию предмета
+func HandleSetSubjectCategory(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: set_subject_category:123:languages
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	subjectID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный ID")
+		return
+	}
+
+	category := parts[2]
+	if category == noCategory {
+		category = ""
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Пользователь не найден")
+		return
+	}
+
+	subject, err := h.TeacherService.SetSubjectCategory(ctx, user.ID, subjectID, category)
+	if err != nil {
+		switch err.Error() {
+		case "unknown category":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неизвестная категория")
+		case "subject not found", "subject does not belong to teacher":
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Предмет не найден")
+		default:
+			h.Logger.Error("Failed to update subject category",
+				zap.Error(err),
+				zap.Int64("subject_id", subjectID),
+				zap.String("category", category))
+			common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Не удалось обновить")
+		}
+		return
+	}
+
+	showSubjectTagsScreen(ctx, b, callback, subject)
+	common.AnswerCallback(ctx, b, callback.ID, "✅ Категория сохранена")
+}
+
+// HandleEditFieldTags устанавливает state для ввода тегов предмета
+func HandleEditFieldTags(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	subjectID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	telegramID := callback.From.ID
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		common.AnswerCallback(ctx, b, callback.ID, "❌ Ошибка")
+		return
+	}
+
+	h.StateManager.SetState(telegramID, callbacktypes.UserState(state.StateEditSubjectTags))
+	h.StateManager.SetData(telegramID, "subject_id", subjectID)
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text: fmt.Sprintf("🔖 Введите теги предмета через запятую, например:\n"+
+			"егэ, разговорный, для детей\n\n"+
+			"До %d тегов, каждый до %d символов. Новые теги заменят прежние, «-» удалит все.\n\n"+
+			"Для отмены используйте /cancel", service.MaxSubjectTags, service.MaxSubjectTagLength),
+	})
+
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
@@ -0,0 +1,175 @@
+package teacher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/callbacktypes"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/keyboard"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// subscriptionPeriods варианты продления подписки: подпись кнопки и количество дней
+var subscriptionPeriods = []struct {
+	label string
+	days  int
+}{
+	{"+1 мес", 30},
+	{"+3 мес", 90},
+	{"+6 мес", 180},
+	{"+1 год", 365},
+}
+
+// HandleSubscriptionMenu показывает подписку студента и варианты продления
+func HandleSubscriptionMenu(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	studentID, err := common.ParseIDFromCallback(callback.Data)
+	if err != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	showSubscriptionScreen(ctx, b, callback, h, user.ID, studentID)
+	common.AnswerCallback(ctx, b, callback.ID, "")
+}
+
+// HandleExtendSubscription оформляет или продлевает подписку студента
+func HandleExtendSubscription(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler) {
+	// Формат: extend_subscription:studentID:days
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	studentID, err1 := strconv.ParseInt(parts[1], 10, 64)
+	days, err2 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Неверный формат")
+		return
+	}
+
+	user, err := h.UserService.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil || !user.IsTeacher {
+		common.AnswerCallbackAlert(ctx, b, callback.ID, "❌ Доступ запрещен")
+		return
+	}
+
+	access, err := h.AccessService.ExtendSubscription(ctx, user.ID, studentID, days)
+	if err != nil {
+		h.Logger.Error("Failed to extend subscription",
+			zap.Int64("student_id", studentID),
+			zap.Int("days", days),
+			zap.Error(err))
+
+		errorMsg := "❌ Не удалось продлить подписку"
+		if err.Error() == "access not found" {
+			errorMsg = "❌ У студента нет доступа к вашему расписанию"
+		}
+		common.AnswerCallbackAlert(ctx, b, callback.ID, errorMsg)
+		return
+	}
+
+	// Уведомляем студента
+	student, err := h.UserService.GetByID(ctx, studentID)
+	if err == nil && student != nil {
+		teacherName := user.FirstName
+		if user.LastName != "" {
+			teacherName += " " + user.LastName
+		}
+
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: student.TelegramID,
+			Text: fmt.Sprintf(
+				"⭐ <b>Подписка продлена</b>\n\n"+
+					"Учитель <b>%s</b> продлил ваш доступ к расписанию до %s.",
+				teacherName,
+				formatting.FormatDateTime(*access.ExpiresAt),
+			),
+			ParseMode: models.ParseModeHTML,
+		})
+	}
+
+	common.AnswerCallbackAlert(ctx, b, callback.ID, fmt.Sprintf("✅ Подписка действует до %s", formatting.FormatDate(*access.ExpiresAt)))
+	showSubscriptionScreen(ctx, b, callback, h, user.ID, studentID)
+}
+
+// showSubscriptionScreen отображает экран подписки студента
+func showSubscriptionScreen(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, h *callbacktypes.Handler, teacherID, studentID int64) {
+	msg := common.GetMessageFromCallback(callback)
+	if msg == nil {
+		return
+	}
+
+	student, err := h.UserService.GetByID(ctx, studentID)
+	if err != nil || student == nil {
+		h.Logger.Error("Student not found for subscription", zap.Int64("student_id", studentID), zap.Error(err))
+		return
+	}
+
+	access, err := h.AccessRepo.GetAccessInfo(ctx, studentID, teacherID)
+	if err != nil || access == nil {
+		h.Logger.Error("Access not found for subscription", zap.Int64("student_id", studentID), zap.Error(err))
+		return
+	}
+
+	studentName := student.FirstName
+	if student.LastName != "" {
+		studentName += " " + student.LastName
+	}
+
+	text := fmt.Sprintf("⭐ <b>Подписка: %s</b>\n\n", studentName)
+	switch {
+	case access.ExpiresAt == nil:
+		text += "Сейчас доступ бессрочный. Оформление подписки ограничит его выбранным сроком.\n\n"
+	case access.IsExpired(time.Now()):
+		text += fmt.Sprintf("⚠️ Доступ истёк %s и скоро будет отозван.\n\n", formatting.FormatDateTime(*access.ExpiresAt))
+	default:
+		text += fmt.Sprintf("Доступ действует до <b>%s</b>.\n\n", formatting.FormatDateTime(*access.ExpiresAt))
+	}
+	warnDays := int(service.SubscriptionWarnBefore / (24 * time.Hour))
+	text += fmt.Sprintf("За %d %s до окончания студент получит напоминание, после окончания доступ будет отозван автоматически.",
+		warnDays, formatting.PluralizeDays(warnDays))
+
+	var row []models.InlineKeyboardButton
+	for _, period := range subscriptionPeriods {
+		row = append(row, keyboard.Button(period.label, fmt.Sprintf("extend_subscription:%d:%d", studentID, period.days)))
+	}
+
+	kb := keyboard.NewBuilder()
+	kb.AddRow(row)
+	kb.Row(keyboard.BackButton("view_my_students"))
+
+	b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// formatAccessExpiry форматирует срок доступа для списка студентов
+func formatAccessExpiry(access *model.StudentTeacherAccess) string {
+	if access.ExpiresAt == nil {
+		return ""
+	}
+	if access.IsExpired(time.Now()) {
+		return fmt.Sprintf("   ⚠️ Истёк: %s\n", formatting.FormatDate(*access.ExpiresAt))
+	}
+	return fmt.Sprintf("   Действует до: %s\n", formatting.FormatDate(*access.ExpiresAt))
+}
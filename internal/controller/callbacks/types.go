@@ -40,6 +40,17 @@ func NewHandler(
 	bookingService *service.BookingService,
 	teacherService *service.TeacherService,
 	accessService *service.StudentAccessService,
+	creditService *service.CreditService,
+	ledgerService *service.LedgerService,
+	promoCodeService *service.PromoCodeService,
+	reportService *service.ReportService,
+	exportService *service.ExportService,
+	groupService *service.GroupService,
+	broadcastService *service.BroadcastService,
+	conversationService *service.ConversationService,
+	lessonNoteService *service.LessonNoteService,
+	reviewService *service.ReviewService,
+	favoriteService *service.FavoriteService,
 	userRepo interface {
 		GetByID(ctx context.Context, id int64) (*model.User, error)
 		UpdatePublicStatus(ctx context.Context, userID int64, isPublic bool) error
@@ -61,19 +72,30 @@ func NewHandler(
 	handleMySubjects func(ctx context.Context, b *bot.Bot, update *models.Update, messageID ...int),
 ) *Handler {
 	inner := &callbacktypes.Handler{
-		UserService:       userService,
-		BookingService:    bookingService,
-		TeacherService:    teacherService,
-		AccessService:     accessService,
-		UserRepo:          userRepo,
-		InviteCodeRepo:    inviteCodeRepo,
-		AccessRepo:        accessRepo,
-		AccessRequestRepo: accessRequestRepo,
-		StateManager:      stateManager,
-		Logger:            logger,
-		HandleSubjects:    handleSubjects,
-		HandleMySchedule:  handleMySchedule,
-		HandleMySubjects:  handleMySubjects,
+		UserService:         userService,
+		BookingService:      bookingService,
+		TeacherService:      teacherService,
+		AccessService:       accessService,
+		CreditService:       creditService,
+		LedgerService:       ledgerService,
+		PromoCodeService:    promoCodeService,
+		ReportService:       reportService,
+		ExportService:       exportService,
+		GroupService:        groupService,
+		BroadcastService:    broadcastService,
+		ConversationService: conversationService,
+		LessonNoteService:   lessonNoteService,
+		ReviewService:       reviewService,
+		FavoriteService:     favoriteService,
+		UserRepo:            userRepo,
+		InviteCodeRepo:      inviteCodeRepo,
+		AccessRepo:          accessRepo,
+		AccessRequestRepo:   accessRequestRepo,
+		StateManager:        stateManager,
+		Logger:              logger,
+		HandleSubjects:      handleSubjects,
+		HandleMySchedule:    handleMySchedule,
+		HandleMySubjects:    handleMySubjects,
 	}
 	return &Handler{Handler: inner}
 }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleBroadcast обрабатывает команду /broadcast - рассылка учителя своим студентам
+func (h *Handlers) HandleBroadcast(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if _, ok := h.requireTeacher(ctx, b, update); !ok {
+		return
+	}
+
+	// Новая рассылка начинается с чистого листа
+	h.stateManager.ClearState(update.Message.From.ID)
+
+	text, keyboard := common.BuildBroadcastMenuScreen()
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// IsBroadcastMedia проверяет, что update — фото или документ для рассылки, которую готовит учитель
+func (h *Handlers) IsBroadcastMedia(update *models.Update) bool {
+	if update.Message == nil || update.Message.From == nil {
+		return false
+	}
+
+	if len(update.Message.Photo) == 0 && update.Message.Document == nil {
+		return false
+	}
+
+	return h.stateManager.GetState(update.Message.From.ID) == state.StateBroadcastCompose
+}
+
+// HandleBroadcastMedia принимает фото или документ с подписью как сообщение рассылки
+func (h *Handlers) HandleBroadcastMedia(ctx context.Context, b *bot.Bot, update *models.Update) {
+	draft := &model.BroadcastMessage{Text: update.Message.Caption}
+
+	if len(update.Message.Photo) > 0 {
+		// Telegram присылает несколько размеров фото, последний — самый крупный
+		draft.PhotoFileID = update.Message.Photo[len(update.Message.Photo)-1].FileID
+	} else {
+		draft.DocumentFileID = update.Message.Document.FileID
+	}
+
+	h.handleBroadcastDraft(ctx, b, update, draft)
+}
+
+// handleBroadcastDraft показывает учителю, как сообщение увидят студенты, и просит подтвердить отправку.
+// Новое сообщение в том же диалоге заменяет черновик
+func (h *Handlers) handleBroadcastDraft(ctx context.Context, b *bot.Bot, update *models.Update, draft *model.BroadcastMessage) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	audienceRaw, _ := h.stateManager.GetData(telegramID, "broadcast_audience")
+	audience, ok := audienceRaw.(model.BroadcastAudience)
+	if !ok {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: начните рассылку заново командой /broadcast",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	if err := h.broadcastService.SendPreview(ctx, user.ID, chatID, draft); err != nil {
+		switch err.Error() {
+		case "empty message":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Сообщение пустое. Отправьте текст, фото или документ:"})
+		case "message too long":
+			limit := service.MaxBroadcastTextLength
+			if draft.HasMedia() {
+				limit = service.MaxBroadcastCaptionLength
+			}
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Текст длиннее %d символов. Сократите его и отправьте ещё раз:", limit),
+			})
+		default:
+			h.logger.Error("Failed to send broadcast preview", zap.Int64("teacher_id", user.ID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось подготовить рассылку"})
+			h.stateManager.ClearState(telegramID)
+		}
+		return
+	}
+
+	recipients, err := h.broadcastService.GetRecipients(ctx, user.ID, audience)
+	if err != nil {
+		h.logger.Error("Failed to get broadcast recipients", zap.Int64("teacher_id", user.ID), zap.Error(err))
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось подобрать получателей"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	h.stateManager.SetData(telegramID, "broadcast_draft", draft)
+
+	label, _ := h.stateManager.GetData(telegramID, "broadcast_audience_label")
+	labelText, _ := label.(string)
+
+	text := fmt.Sprintf(
+		"👆 <b>Так сообщение увидят студенты</b>\n\n"+
+			"Получатели: %s (%d)\n\n"+
+			"Отправить? Чтобы исправить сообщение, просто пришлите новое.",
+		html.EscapeString(labelText),
+		len(recipients),
+	)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: "✅ Отправить", CallbackData: "broadcast_send"},
+					{Text: "❌ Отмена", CallbackData: "broadcast_cancel"},
+				},
+			},
+		},
+	})
+}
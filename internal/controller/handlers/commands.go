@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -71,12 +72,18 @@ func (h *Handlers) HandleHelp(ctx context.Context, b *bot.Bot, update *models.Up
 		"Для студентов:\n" +
 		"/start - Начать работу с ботом\n" +
 		"/subjects - Список всех предметов\n" +
-		"/mybookings - Мои записи на занятия\n" +
+		"/mybookings - Мои записи на занятия, заметки и домашние задания\n" +
+		"/chats - Переписки с учителями\n" +
+		"/favorites - Избранные учителя и уведомления о свободном времени\n" +
 		"/help - Показать эту справку\n\n" +
 		"Для учителей:\n" +
 		"/becometeacher - Зарегистрироваться как учитель\n" +
 		"/mysubjects - Управление своими предметами\n" +
-		"/myschedule - Посмотреть расписание\n\n" +
+		"/myschedule - Посмотреть расписание\n" +
+		"/export - Выгрузить расписание, записи и студентов в CSV/XLSX\n" +
+		"/broadcast - Отправить сообщение студентам\n" +
+		"/chats - Переписки со студентами\n" +
+		"Заметки и ДЗ к занятию - в деталях слота в /myschedule\n\n" +
 		"Для записи на занятие выберите предмет из списка /subjects"
 
 	b.SendMessage(ctx, &bot.SendMessageParams{
@@ -161,12 +168,40 @@ func (h *Handlers) HandleTextMessage(ctx context.Context, b *bot.Bot, update *mo
 		h.handleEditSubjectDescription(ctx, b, update)
 	case state.StateEditSubjectPrice:
 		h.handleEditSubjectPrice(ctx, b, update)
+	case state.StateEditSubjectCurrency:
+		h.handleEditSubjectCurrency(ctx, b, update)
 	case state.StateEditSubjectDuration:
 		h.handleEditSubjectDuration(ctx, b, update)
+	case state.StateEditSubjectTags:
+		h.handleEditSubjectTags(ctx, b, update)
+	case state.StateSearchingTeacher:
+		h.handleSubjectSearch(ctx, b, update)
 	case state.StateEnteringInviteCode:
 		h.handleEnteringInviteCode(ctx, b, update)
 	case state.StateMarkSlotBusyComment:
 		h.handleMarkSlotBusyComment(ctx, b, update)
+	case state.StateCreatePackage:
+		h.handleCreatePackage(ctx, b, update)
+	case state.StateRecordPayment:
+		h.handleRecordPayment(ctx, b, update)
+	case state.StateCreatePromoCode:
+		h.handleCreatePromoCode(ctx, b, update)
+	case state.StateEnteringPromoCode:
+		h.handleEnteringPromoCode(ctx, b, update)
+	case state.StateCreateGroup:
+		h.handleCreateGroup(ctx, b, update)
+	case state.StateGroupBroadcast:
+		h.handleGroupBroadcast(ctx, b, update)
+	case state.StateBroadcastCompose:
+		h.handleBroadcastDraft(ctx, b, update, &model.BroadcastMessage{Text: update.Message.Text})
+	case state.StateConversationMessage:
+		h.handleConversationMessage(ctx, b, update)
+	case state.StateLessonNote:
+		h.handleLessonNote(ctx, b, update)
+	case state.StateReviewComment:
+		h.handleReviewComment(ctx, b, update)
+	case state.StateReviewReply:
+		h.handleReviewReply(ctx, b, update)
 	case "custom_slot_time":
 		h.handleCustomSlotTime(ctx, b, update)
 	default:
@@ -286,9 +321,13 @@ func (h *Handlers) processCustomSlotTime(ctx context.Context, b *bot.Bot, update
 	slot, err := h.teacherService.CreateSlot(ctx, user.ID, subjectID, startTime, endTime)
 	if err != nil {
 		h.logger.Error("Failed to create slot", zap.Error(err))
+		errorText := fmt.Sprintf("❌ Не удалось создать слот: %v", err)
+		if err.Error() == "slot overlaps existing slot" {
+			errorText = "❌ Это время пересекается с другим слотом в вашем расписании (возможно, по другому предмету). Выберите другое время."
+		}
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   fmt.Sprintf("❌ Не удалось создать слот: %v", err),
+			Text:   errorText,
 		})
 		h.stateManager.ClearState(telegramID)
 		return
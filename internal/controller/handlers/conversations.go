@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleChats обрабатывает команду /chats - переписки с учителями и студентами
+func (h *Handlers) HandleChats(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, update.Message.From.ID)
+	if err != nil || user == nil {
+		h.sendError(ctx, b, update.Message.Chat.ID, "❌ Пользователь не найден. Используйте /start для регистрации.")
+		return
+	}
+
+	conversations, err := h.conversationService.GetUserConversations(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("Failed to get conversations", zap.Int64("user_id", user.ID), zap.Error(err))
+		h.sendError(ctx, b, update.Message.Chat.ID, "❌ Не удалось загрузить переписки.")
+		return
+	}
+
+	text, keyboard := common.BuildConversationsScreen(user.ID, conversations)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// handleConversationMessage пересылает введённое сообщение собеседнику по открытой переписке
+func (h *Handlers) handleConversationMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	conversationIDRaw, ok := h.stateManager.GetData(telegramID, "conversation_id")
+	conversationID, okType := conversationIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: откройте переписку заново через /chats",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	var bookingID *int64
+	if raw, ok := h.stateManager.GetData(telegramID, "conversation_booking_id"); ok {
+		if id, ok := raw.(int64); ok {
+			bookingID = &id
+		}
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	if !h.sendConversationMessage(ctx, b, chatID, user.ID, conversationID, bookingID, update.Message.Text) {
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+}
+
+// IsConversationReply проверяет, что update — текстовый ответ на сообщение бота вне диалога.
+// Такой ответ может быть ответом на пересланное сообщение переписки
+func (h *Handlers) IsConversationReply(update *models.Update) bool {
+	if update.Message == nil || update.Message.From == nil || update.Message.ReplyToMessage == nil {
+		return false
+	}
+
+	if update.Message.Text == "" || strings.HasPrefix(update.Message.Text, "/") {
+		return false
+	}
+
+	return h.stateManager.GetState(update.Message.From.ID) == state.StateNone
+}
+
+// HandleConversationReply пересылает ответ на сообщение переписки собеседнику
+func (h *Handlers) HandleConversationReply(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	target, err := h.conversationService.FindReplyTarget(ctx, chatID, update.Message.ReplyToMessage.ID)
+	if err != nil {
+		h.logger.Error("Failed to find reply target", zap.Error(err))
+		return
+	}
+
+	// Ответ не на пересланное сообщение — обрабатываем как обычный текст
+	if target == nil {
+		h.HandleTextMessage(ctx, b, update)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, update.Message.From.ID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		return
+	}
+
+	h.sendConversationMessage(ctx, b, chatID, user.ID, target.ConversationID, target.BookingID, update.Message.Text)
+}
+
+// sendConversationMessage отправляет сообщение в переписку и сообщает результат. Возвращает false,
+// если сообщение нужно ввести заново
+func (h *Handlers) sendConversationMessage(ctx context.Context, b *bot.Bot, chatID, userID, conversationID int64, bookingID *int64, text string) bool {
+	err := h.conversationService.SendMessage(ctx, userID, conversationID, bookingID, text)
+	if err != nil {
+		switch err.Error() {
+		case "empty message":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Сообщение пустое. Отправьте текст:"})
+			return false
+		case "message too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Сообщение длиннее %d символов. Сократите его и отправьте ещё раз:", service.MaxConversationMessageLength),
+			})
+			return false
+		case "conversation not found":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Переписка не найдена"})
+		case "delivery failed":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "⚠️ Сообщение сохранено в переписке, но доставить его не удалось — возможно, собеседник заблокировал бота.",
+			})
+		default:
+			h.logger.Error("Failed to send conversation message", zap.Int64("conversation_id", conversationID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось отправить сообщение"})
+		}
+		return true
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   "✅ Сообщение отправлено",
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "💬 К переписке", CallbackData: fmt.Sprintf("view_chat:%d", conversationID)}},
+			},
+		},
+	})
+
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -28,6 +29,7 @@ func (h *Handlers) HandleCreateSubjectStart(ctx context.Context, b *bot.Bot, upd
 	// Сохраняем teacher_id в данных
 	h.stateManager.SetState(telegramID, state.StateCreateSubjectName)
 	h.stateManager.SetData(telegramID, "teacher_id", user.ID)
+	h.stateManager.SetData(telegramID, "currency", user.DefaultCurrency)
 
 	h.logger.Info("Set initial state and data",
 		zap.Int64("telegram_id", telegramID),
@@ -117,6 +119,7 @@ func (h *Handlers) handleCreateSubjectDescriptionStep(ctx context.Context, b *bo
 	h.stateManager.SetState(telegramID, state.StateCreateSubjectPrice)
 
 	name, _ := h.stateManager.GetData(telegramID, "name")
+	currency := h.subjectDraftCurrency(telegramID)
 
 	h.logger.Info("Description saved, moving to price step",
 		zap.Int64("telegram_id", telegramID),
@@ -126,9 +129,10 @@ func (h *Handlers) handleCreateSubjectDescriptionStep(ctx context.Context, b *bo
 		ChatID: update.Message.Chat.ID,
 		Text: fmt.Sprintf("✅ Название: %s\n"+
 			"✅ Описание: %s\n\n"+
-			"Шаг 3 из 4: Укажите стоимость занятия в рублях\n\n"+
-			"Например: 1500, 2000, 500\n\n"+
-			"Для отмены используйте /cancel", name, description),
+			"Шаг 3 из 4: Укажите стоимость занятия в %s (%s)\n\n"+
+			"Например: 1500, 2000, 500\n"+
+			"Валюту по умолчанию можно сменить в настройках учителя\n\n"+
+			"Для отмены используйте /cancel", name, description, currency, currency.Symbol()),
 	})
 }
 
@@ -141,28 +145,27 @@ func (h *Handlers) handleCreateSubjectPriceStep(ctx context.Context, b *bot.Bot,
 		zap.Int64("telegram_id", telegramID),
 		zap.String("price_input", priceStr))
 
-	price, err := strconv.Atoi(priceStr)
-	if err != nil || price < 0 {
+	// Цена хранится в минимальных единицах валюты (копейках, центах)
+	currency := h.subjectDraftCurrency(telegramID)
+	priceInCents, err := currency.ParseAmount(priceStr)
+	if err != nil {
 		h.logger.Warn("Invalid price format",
 			zap.Error(err),
 			zap.String("input", priceStr))
 		h.sendError(ctx, b, update.Message.Chat.ID,
-			"❌ Неверный формат цены. Введите целое число (например: 1500).\n\nПопробуйте ещё раз:")
+			fmt.Sprintf("❌ Неверный формат цены. Введите сумму в %s (например: 1500 или 1500,50).\n\nПопробуйте ещё раз:", currency))
 		return
 	}
 
-	if price > SubjectMaxPrice {
+	if priceInCents > currency.MaxPrice() {
 		h.logger.Warn("Price too high",
-			zap.Int("price", price),
-			zap.Int("max", SubjectMaxPrice))
+			zap.Int("price", priceInCents),
+			zap.Int("max", currency.MaxPrice()))
 		h.sendError(ctx, b, update.Message.Chat.ID,
-			fmt.Sprintf("❌ Цена слишком большая. Максимум %s.\n\nПопробуйте ещё раз:", FormatPrice(SubjectMaxPrice*100)))
+			fmt.Sprintf("❌ Цена слишком большая. Максимум %s.\n\nПопробуйте ещё раз:", FormatPrice(currency.MaxPrice(), currency)))
 		return
 	}
 
-	// Конвертируем в копейки для хранения в БД
-	priceInCents := price * 100
-
 	// Сохраняем цену и переходим к выбору длительности (кнопками)
 	h.stateManager.SetData(telegramID, "price", priceInCents)
 	h.stateManager.SetState(telegramID, state.StateCreateSubjectDuration)
@@ -202,9 +205,9 @@ func (h *Handlers) handleCreateSubjectPriceStep(ctx context.Context, b *bot.Bot,
 		ChatID: update.Message.Chat.ID,
 		Text: fmt.Sprintf("✅ Название: %s\n"+
 			"✅ Описание: %s\n"+
-			"✅ Цена: %d ₽\n\n"+
+			"✅ Цена: %s\n\n"+
 			"Шаг 4 из 5: Выберите длительность занятия:",
-			name, description, price),
+			name, description, FormatPrice(priceInCents, currency)),
 		ReplyMarkup: keyboard,
 	})
 }
@@ -243,6 +246,7 @@ func (h *Handlers) handleCreateSubjectDurationStep(ctx context.Context, b *bot.B
 	name, _ := allData["name"].(string)
 	description, _ := allData["description"].(string)
 	price, _ := allData["price"].(int)
+	currency := h.subjectDraftCurrency(telegramID)
 
 	h.logger.Info("Retrieved subject data from state",
 		zap.String("name", name),
@@ -277,7 +281,7 @@ func (h *Handlers) handleCreateSubjectDurationStep(ctx context.Context, b *bot.B
 			"• 🟢 Да - студенты отправляют запрос, вы одобряете\n"+
 			"• 🔵 Нет - студенты записываются автоматически\n\n"+
 			"Для отмены используйте /cancel",
-			name, description, FormatPrice(price), duration),
+			name, description, FormatPrice(price, currency), duration),
 		ReplyMarkup: keyboard,
 	})
 
@@ -290,3 +294,12 @@ func (h *Handlers) handleCreateSubjectDurationStep(ctx context.Context, b *bot.B
 
 	h.logger.Info("Successfully sent approval step message", zap.Int64("telegram_id", telegramID))
 }
+
+// subjectDraftCurrency возвращает валюту создаваемого предмета — валюту учителя по умолчанию
+func (h *Handlers) subjectDraftCurrency(telegramID int64) model.Currency {
+	currencyRaw, _ := h.stateManager.GetData(telegramID, "currency")
+	if currency, ok := currencyRaw.(model.Currency); ok && currency.IsValid() {
+		return currency
+	}
+	return model.DefaultCurrency
+}
@@ -3,10 +3,13 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
 
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
@@ -40,7 +43,6 @@ func (h *Handlers) showEditSubjectScreen(ctx context.Context, b *bot.Bot, chatID
 // buildEditSubjectScreen - локальная обёртка для билдера из common
 // (можно было бы импортировать напрямую, но для избежания циклических импортов дублируем)
 func buildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeyboardMarkup) {
-	price := float64(subject.Price) / 100
 	statusText := "Активен ✅"
 	if !subject.IsActive {
 		statusText = "Неактивен ⏸"
@@ -49,21 +51,31 @@ func buildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 	if subject.RequiresBookingApproval {
 		approvalText = "Да ✅"
 	}
+	prepaymentText := "Нет ❌"
+	if subject.RequiresPrepayment {
+		prepaymentText = "Да ✅"
+	}
 
 	text := fmt.Sprintf(
 		"🛠 <b>Редактирование предмета</b>\n\n"+
 			"📚 Название: %s\n"+
 			"📝 Описание: %s\n"+
-			"💰 Цена: %.2f ₽\n"+
+			"💰 Цена: %s\n"+
 			"⏱ Длительность: %d мин\n"+
+			"🏷 Категория: %s\n"+
+			"🔖 Теги: %s\n"+
 			"⏳ Требуется одобрение: %s\n"+
+			"💳 Предоплата: %s\n"+
 			"📊 Статус: %s\n\n"+
 			"Выберите, что хотите изменить:",
 		subject.Name,
 		subject.Description,
-		price,
+		formatting.FormatPrice(subject.Price, subject.Currency),
 		subject.Duration,
+		formatting.FormatSubjectCategory(subject.Category),
+		html.EscapeString(formatting.FormatSubjectTags(subject.Tags)),
 		approvalText,
+		prepaymentText,
 		statusText,
 	)
 
@@ -73,6 +85,11 @@ func buildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 		approvalButtonText = "⏳ Требуется одобрение: да"
 	}
 
+	prepaymentButtonText := "💳 Предоплата: нет"
+	if subject.RequiresPrepayment {
+		prepaymentButtonText = "💳 Предоплата: да"
+	}
+
 	statusButtonText := "📊 Статус: активен"
 	if !subject.IsActive {
 		statusButtonText = "📊 Статус: неактивен"
@@ -88,9 +105,22 @@ func buildEditSubjectScreen(subject *model.Subject) (string, *models.InlineKeybo
 				{Text: "💰 Цена", CallbackData: fmt.Sprintf("edit_field_price:%d", subject.ID)},
 				{Text: "⏱ Длительность", CallbackData: fmt.Sprintf("edit_field_duration:%d", subject.ID)},
 			},
+			{
+				{Text: "💱 Валюта: " + string(subject.Currency), CallbackData: fmt.Sprintf("subject_currency:%d", subject.ID)},
+			},
+			{
+				{Text: "🏷 Категория и теги", CallbackData: fmt.Sprintf("subject_tags:%d", subject.ID)},
+			},
 			{
 				{Text: approvalButtonText, CallbackData: fmt.Sprintf("toggle_approval:%d", subject.ID)},
 			},
+			{
+				{Text: prepaymentButtonText, CallbackData: fmt.Sprintf("toggle_prepayment:%d", subject.ID)},
+			},
+			{
+				{Text: "📏 Правила записи", CallbackData: fmt.Sprintf("booking_rules:%d", subject.ID)},
+				{Text: "🎁 Пакеты занятий", CallbackData: fmt.Sprintf("subject_packages:%d", subject.ID)},
+			},
 			{
 				{Text: statusButtonText, CallbackData: fmt.Sprintf("toggle_subject:%d", subject.ID)},
 			},
@@ -262,18 +292,6 @@ func (h *Handlers) handleEditSubjectPrice(ctx context.Context, b *bot.Bot, updat
 	telegramID := update.Message.From.ID
 	priceText := strings.TrimSpace(update.Message.Text)
 
-	// Парсим цену
-	price, err := strconv.ParseFloat(priceText, 64)
-	if err != nil || price < 0 || price > 1000000 {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "❌ Неверная цена. Введите число от 0 до 1000000:",
-		})
-		return
-	}
-
-	priceInCents := int(price * 100)
-
 	subjectIDRaw, ok := h.stateManager.GetData(telegramID, "subject_id")
 	if !ok {
 		b.SendMessage(ctx, &bot.SendMessageParams{
@@ -314,7 +332,18 @@ func (h *Handlers) handleEditSubjectPrice(ctx context.Context, b *bot.Bot, updat
 		return
 	}
 
-	subject.Price = priceInCents
+	// Цена вводится в валюте предмета с точностью до её минимальной единицы
+	priceInMinor, err := subject.Currency.ParseAmount(priceText)
+	if err != nil || priceInMinor > subject.Currency.MaxPrice() {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text: fmt.Sprintf("❌ Неверная цена. Введите сумму в %s от 0 до %s:",
+				subject.Currency, formatting.FormatPriceShort(subject.Currency.MaxPrice(), subject.Currency)),
+		})
+		return
+	}
+
+	subject.Price = priceInMinor
 	err = h.teacherService.UpdateSubject(ctx, user.ID, subject)
 	if err != nil {
 		h.logger.Error("Failed to update subject price", zap.Error(err))
@@ -331,6 +360,81 @@ func (h *Handlers) handleEditSubjectPrice(ctx context.Context, b *bot.Bot, updat
 	h.showEditSubjectScreen(ctx, b, update.Message.Chat.ID, subjectID)
 }
 
+// handleEditSubjectCurrency обрабатывает ввод цены занятия в новой валюте предмета и меняет валюту
+func (h *Handlers) handleEditSubjectCurrency(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	priceText := strings.TrimSpace(update.Message.Text)
+
+	subjectIDRaw, okSubject := h.stateManager.GetData(telegramID, "subject_id")
+	currencyRaw, okCurrency := h.stateManager.GetData(telegramID, "currency")
+	subjectID, okID := subjectIDRaw.(int64)
+	currencyCode, okCode := currencyRaw.(string)
+	currency := model.Currency(currencyCode)
+	if !okSubject || !okCurrency || !okID || !okCode || !currency.IsValid() {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: предмет не найден",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка авторизации",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	// Цена вводится в новой валюте с точностью до её минимальной единицы
+	priceInMinor, err := currency.ParseAmount(priceText)
+	if err != nil || priceInMinor > currency.MaxPrice() {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text: fmt.Sprintf("❌ Неверная цена. Введите сумму в %s от 0 до %s:",
+				currency, formatting.FormatPriceShort(currency.MaxPrice(), currency)),
+		})
+		return
+	}
+
+	deactivated, err := h.teacherService.ChangeSubjectCurrency(ctx, user.ID, subjectID, currency, priceInMinor)
+	if err != nil {
+		h.logger.Error("Failed to change subject currency",
+			zap.Int64("subject_id", subjectID),
+			zap.String("currency", string(currency)),
+			zap.Error(err))
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Не удалось сменить валюту",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	text := fmt.Sprintf("✅ Валюта предмета: %s, цена занятия: %s",
+		formatting.FormatCurrency(currency), formatting.FormatPrice(priceInMinor, currency))
+	if deactivated > 0 {
+		text += fmt.Sprintf("\n\n⏸ Снято с продажи пакетов: %d — создайте их заново в новой валюте", deactivated)
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	})
+
+	// Показываем экран редактирования предмета
+	h.showEditSubjectScreen(ctx, b, chatID, subjectID)
+}
+
 // handleEditSubjectDuration обрабатывает ввод новой длительности предмета
 func (h *Handlers) handleEditSubjectDuration(ctx context.Context, b *bot.Bot, update *models.Update) {
 	if update.Message == nil {
@@ -405,3 +509,75 @@ func (h *Handlers) handleEditSubjectDuration(ctx context.Context, b *bot.Bot, up
 	// Показываем экран редактирования предмета
 	h.showEditSubjectScreen(ctx, b, update.Message.Chat.ID, subjectID)
 }
+
+// handleEditSubjectTags обрабатывает ввод тегов предмета
+func (h *Handlers) handleEditSubjectTags(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	subjectIDRaw, ok := h.stateManager.GetData(telegramID, "subject_id")
+	subjectID, okType := subjectIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: предмет не найден",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка авторизации",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	if _, err := h.teacherService.SetSubjectTags(ctx, user.ID, subjectID, update.Message.Text); err != nil {
+		switch err.Error() {
+		case "empty tags":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "❌ Не нашёл ни одного тега. Перечислите теги через запятую или отправьте «-», чтобы удалить все:",
+			})
+			return
+		case "too many tags":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Не больше %d тегов. Попробуйте ещё раз:", service.MaxSubjectTags),
+			})
+			return
+		case "tag too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Тег длиннее %d символов. Попробуйте ещё раз:", service.MaxSubjectTagLength),
+			})
+			return
+		case "subject not found", "subject does not belong to teacher":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "❌ Предмет не найден",
+			})
+		default:
+			h.logger.Error("Failed to update subject tags", zap.Int64("subject_id", subjectID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "❌ Не удалось обновить теги",
+			})
+		}
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	// Показываем экран редактирования предмета
+	h.showEditSubjectScreen(ctx, b, chatID, subjectID)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// HandleExport обрабатывает команду /export - выгрузка данных учителя в CSV/XLSX
+func (h *Handlers) HandleExport(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if _, ok := h.requireTeacher(ctx, b, update); !ok {
+		return
+	}
+
+	text, keyboard := common.BuildExportMenuScreen()
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
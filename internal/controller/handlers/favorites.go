@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// HandleFavorites обрабатывает команду /favorites - избранные учителя и предметы
+func (h *Handlers) HandleFavorites(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, update.Message.From.ID)
+	if err != nil || user == nil {
+		h.sendError(ctx, b, update.Message.Chat.ID, "❌ Пользователь не найден. Используйте /start для регистрации.")
+		return
+	}
+
+	favorites, err := h.favoriteService.GetFavorites(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("Failed to get favorites", zap.Int64("user_id", user.ID), zap.Error(err))
+		h.sendError(ctx, b, update.Message.Chat.ID, "❌ Не удалось загрузить избранное.")
+		return
+	}
+
+	text, keyboard := common.BuildFavoritesScreen(favorites)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
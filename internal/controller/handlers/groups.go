@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleCreateGroup обрабатывает ввод названия новой группы студентов
+func (h *Handlers) handleCreateGroup(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(ctx, user.ID, update.Message.Text)
+	if err != nil {
+		switch err.Error() {
+		case "invalid group name":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Название должно быть от 1 до %d символов. Попробуйте ещё раз:", service.MaxGroupNameLength),
+			})
+		case "group already exists":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   "❌ Группа с таким названием уже есть. Введите другое название:",
+			})
+		default:
+			h.logger.Error("Failed to create group", zap.Int64("teacher_id", user.ID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось создать группу"})
+			h.stateManager.ClearState(telegramID)
+		}
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text: fmt.Sprintf("✅ Группа <b>%s</b> создана.\n\n"+
+			"Добавьте в неё студентов или отправьте им код приглашения в группу.",
+			html.EscapeString(group.Name)),
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "👥 Открыть группу", CallbackData: fmt.Sprintf("view_group:%d", group.ID)}},
+			},
+		},
+	})
+}
+
+// handleGroupBroadcast рассылает введённое сообщение участникам группы
+func (h *Handlers) handleGroupBroadcast(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	groupIDRaw, ok := h.stateManager.GetData(telegramID, "group_id")
+	groupID, okType := groupIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: откройте группу заново и нажмите «Сообщение группе»",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	sent, err := h.groupService.Broadcast(ctx, user.ID, groupID, update.Message.Text)
+	if err != nil {
+		switch err.Error() {
+		case "empty message":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Сообщение пустое. Отправьте текст:"})
+		case "message too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Сообщение длиннее %d символов. Сократите его и отправьте ещё раз:", service.MaxGroupBroadcastLength),
+			})
+		default:
+			h.logger.Error("Failed to broadcast to group", zap.Int64("group_id", groupID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось отправить сообщение группе"})
+			h.stateManager.ClearState(telegramID)
+		}
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   fmt.Sprintf("📣 Сообщение отправлено участникам группы: %d", sent),
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "🔙 К группе", CallbackData: fmt.Sprintf("view_group:%d", groupID)}},
+			},
+		},
+	})
+}
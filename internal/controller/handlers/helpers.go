@@ -38,8 +38,8 @@ func FormatBooking(booking *model.Booking) string {
 	)
 }
 
-// FormatPrice форматирует цену из копеек в рубли
+// FormatPrice форматирует цену из минимальных единиц валюты
 // Deprecated: используйте cmdfmt.FormatPrice
-func FormatPrice(priceInCents int) string {
-	return cmdfmt.FormatPrice(priceInCents)
+func FormatPrice(amount int, currency model.Currency) string {
+	return cmdfmt.FormatPrice(amount, currency)
 }
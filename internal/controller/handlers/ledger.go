@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleRecordPayment обрабатывает ввод суммы полученной оплаты: "сумма [валюта] [комментарий]".
+// Без кода валюты оплата записывается в валюте учителя по умолчанию
+func (h *Handlers) handleRecordPayment(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	studentIDRaw, okStudent := h.stateManager.GetData(telegramID, "student_id")
+	methodRaw, okMethod := h.stateManager.GetData(telegramID, "payment_method")
+	studentID, okStudentType := studentIDRaw.(int64)
+	method, okMethodType := methodRaw.(string)
+	if !okStudent || !okMethod || !okStudentType || !okMethodType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: студент не найден. Начните заново из раздела «Мои студенты»",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка авторизации",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	text := strings.TrimSpace(update.Message.Text)
+	amountStr, description, _ := strings.Cut(text, " ")
+
+	currency := user.DefaultCurrency
+	if code, rest, _ := strings.Cut(strings.TrimSpace(description), " "); model.Currency(strings.ToUpper(code)).IsValid() {
+		currency = model.Currency(strings.ToUpper(code))
+		description = rest
+	}
+
+	amount, err := currency.ParseAmount(amountStr)
+	if err != nil || amount <= 0 {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      "❌ Не удалось разобрать сумму. Пример: <code>3000 за октябрь</code> или <code>50 EUR</code>\n\nПопробуйте ещё раз:",
+			ParseMode: models.ParseModeHTML,
+		})
+		return
+	}
+
+	entry, err := h.ledgerService.RecordPayment(ctx, user.ID, studentID, amount, currency, model.LedgerPaymentMethod(method), description)
+	if err != nil {
+		h.logger.Error("Failed to record payment",
+			zap.Int64("student_id", studentID),
+			zap.Error(err))
+
+		errorText := "❌ Не удалось записать оплату"
+		switch err.Error() {
+		case "invalid payment amount":
+			errorText = "❌ Сумма слишком большая. Попробуйте ещё раз:"
+		case "invalid currency":
+			errorText = "❌ Неизвестная валюта. Попробуйте ещё раз:"
+		case "student not found":
+			errorText = "❌ Студент не найден"
+			h.stateManager.ClearState(telegramID)
+		}
+
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: errorText})
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	student, err := h.userService.GetByID(ctx, studentID)
+	if err != nil || student == nil {
+		return
+	}
+
+	balances, err := h.ledgerService.GetBalances(ctx, user.ID, studentID)
+	if err != nil {
+		h.logger.Error("Failed to get ledger balance", zap.Error(err))
+		return
+	}
+
+	entries, err := h.ledgerService.GetRecentEntries(ctx, user.ID, studentID, 10)
+	if err != nil {
+		h.logger.Error("Failed to get ledger entries", zap.Error(err))
+		return
+	}
+
+	studentName := student.FirstName
+	if student.LastName != "" {
+		studentName += " " + student.LastName
+	}
+
+	screenText, keyboard := common.BuildStudentLedgerScreen(studentID, studentName, balances, entries)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        "✅ Оплата записана\n\n" + screenText,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	// Баланс в валюте оплаты
+	balanceText := "⚪️ расчёты закрыты"
+	for _, balance := range balances {
+		if balance.Currency == currency {
+			balanceText = formatting.FormatLedgerBalance(balance)
+		}
+	}
+
+	// Уведомляем студента
+	teacherName := user.FirstName
+	if user.LastName != "" {
+		teacherName += " " + user.LastName
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: student.TelegramID,
+		Text: fmt.Sprintf(
+			"💰 <b>Оплата получена</b>\n\n"+
+				"Учитель <b>%s</b> отметил вашу оплату %s (%s).\n"+
+				"Баланс: %s",
+			teacherName,
+			formatting.FormatPriceShort(entry.Amount, entry.Currency),
+			formatting.FormatLedgerMethod(model.LedgerPaymentMethod(method)),
+			balanceText,
+		),
+		ParseMode: models.ParseModeHTML,
+	})
+}
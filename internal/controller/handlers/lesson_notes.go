@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/state"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// IsLessonNoteMedia проверяет, что update — фото или документ для заметки, которую добавляет учитель
+func (h *Handlers) IsLessonNoteMedia(update *models.Update) bool {
+	if update.Message == nil || update.Message.From == nil {
+		return false
+	}
+
+	if len(update.Message.Photo) == 0 && update.Message.Document == nil {
+		return false
+	}
+
+	return h.stateManager.GetState(update.Message.From.ID) == state.StateLessonNote
+}
+
+// HandleLessonNoteMedia сохраняет фото или документ с подписью как заметку к занятию
+func (h *Handlers) HandleLessonNoteMedia(ctx context.Context, b *bot.Bot, update *models.Update) {
+	var photoFileID, documentFileID string
+	if len(update.Message.Photo) > 0 {
+		// Telegram присылает несколько размеров фото, последний — самый крупный
+		photoFileID = update.Message.Photo[len(update.Message.Photo)-1].FileID
+	} else {
+		documentFileID = update.Message.Document.FileID
+	}
+
+	h.saveLessonNote(ctx, b, update, update.Message.Caption, photoFileID, documentFileID)
+}
+
+// handleLessonNote сохраняет текстовую заметку к занятию
+func (h *Handlers) handleLessonNote(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.saveLessonNote(ctx, b, update, update.Message.Text, "", "")
+}
+
+// saveLessonNote сохраняет заметку и предлагает указать срок домашнего задания
+func (h *Handlers) saveLessonNote(ctx context.Context, b *bot.Bot, update *models.Update, text, photoFileID, documentFileID string) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	bookingIDRaw, ok := h.stateManager.GetData(telegramID, "lesson_note_booking_id")
+	bookingID, okType := bookingIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: откройте заметки занятия заново из расписания",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	weekOffsetRaw, _ := h.stateManager.GetData(telegramID, "lesson_note_week_offset")
+	weekOffset, _ := weekOffsetRaw.(int)
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	note, err := h.lessonNoteService.AddNote(ctx, user.ID, bookingID, text, photoFileID, documentFileID)
+	if err != nil {
+		switch err.Error() {
+		case "empty note":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Заметка пустая. Отправьте текст, фото или документ:"})
+		case "note too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Заметка длиннее %d символов. Сократите её и отправьте ещё раз:", service.MaxLessonNoteLength),
+			})
+		case "booking not found":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Запись не найдена"})
+			h.stateManager.ClearState(telegramID)
+		default:
+			h.logger.Error("Failed to add lesson note", zap.Int64("booking_id", bookingID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось сохранить заметку"})
+			h.stateManager.ClearState(telegramID)
+		}
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text: "✅ Заметка сохранена, студент получил уведомление.\n\n" +
+			"Если это домашнее задание, укажите срок — за сутки до него студенту придёт напоминание:",
+		ReplyMarkup: common.BuildHomeworkDueKeyboard(note.ID, bookingID, weekOffset),
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleCreatePackage обрабатывает ввод параметров пакета занятий: "количество цена срок"
+func (h *Handlers) handleCreatePackage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	subjectIDRaw, ok := h.stateManager.GetData(telegramID, "subject_id")
+	subjectID, okType := subjectIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: предмет не найден. Начните заново через /mysubjects",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) != 3 {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      "❌ Нужно три числа через пробел: количество занятий, цена пакета и срок в днях.\n\nНапример: <code>10 9000 90</code>",
+			ParseMode: models.ParseModeHTML,
+		})
+		return
+	}
+
+	subject, err := h.teacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Предмет не найден",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	// Цена пакета указывается в валюте предмета
+	lessonCount, errCount := strconv.Atoi(fields[0])
+	price, errPrice := subject.Currency.ParseAmount(fields[1])
+	validityDays, errDays := strconv.Atoi(fields[2])
+	if errCount != nil || errPrice != nil || errDays != nil || price > subject.Currency.MaxPrice() {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      "❌ Не удалось разобрать числа. Пример: <code>10 9000 90</code>",
+			ParseMode: models.ParseModeHTML,
+		})
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка авторизации",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	_, err = h.creditService.CreatePackage(ctx, user.ID, subjectID, lessonCount, price, validityDays)
+	if err != nil {
+		h.logger.Error("Failed to create lesson package",
+			zap.Int64("subject_id", subjectID),
+			zap.Error(err))
+
+		errorText := "❌ Не удалось создать пакет"
+		switch err.Error() {
+		case "invalid lesson count":
+			errorText = fmt.Sprintf("❌ Количество занятий должно быть от 1 до %d. Попробуйте ещё раз:", service.MaxPackageLessons)
+		case "invalid validity period":
+			errorText = fmt.Sprintf("❌ Срок действия должен быть от 0 до %d дней. Попробуйте ещё раз:", service.MaxPackageValidityDays)
+		case "subject not found", "subject does not belong to teacher":
+			errorText = "❌ Предмет не найден"
+			h.stateManager.ClearState(telegramID)
+		}
+
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: errorText})
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	packages, err := h.creditService.GetSubjectPackages(ctx, subjectID)
+	if err != nil {
+		h.logger.Error("Failed to get lesson packages", zap.Error(err))
+		return
+	}
+
+	text, keyboard := common.BuildSubjectPackagesScreen(subject, packages)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        "✅ Пакет создан\n\n" + text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
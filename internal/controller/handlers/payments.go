@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// IsPreCheckoutQuery проверяет, что update — запрос подтверждения оплаты
+func IsPreCheckoutQuery(update *models.Update) bool {
+	return update.PreCheckoutQuery != nil
+}
+
+// IsSuccessfulPayment проверяет, что update — сообщение об успешной оплате
+func IsSuccessfulPayment(update *models.Update) bool {
+	return update.Message != nil && update.Message.SuccessfulPayment != nil
+}
+
+// HandlePreCheckoutQuery подтверждает или отклоняет оплату счёта перед списанием
+func (h *Handlers) HandlePreCheckoutQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.PreCheckoutQuery
+
+	h.logger.Info("HandlePreCheckoutQuery called",
+		zap.Int64("user_id", query.From.ID),
+		zap.String("payload", query.InvoicePayload),
+		zap.Int("amount", query.TotalAmount))
+
+	errorMessage := ""
+	if !h.paymentService.Enabled() {
+		errorMessage = "Оплата сейчас недоступна"
+	} else if err := h.paymentService.ValidatePreCheckout(ctx, query.InvoicePayload, query.Currency, query.TotalAmount); err != nil {
+		h.logger.Warn("Pre-checkout rejected",
+			zap.String("payload", query.InvoicePayload),
+			zap.Error(err))
+
+		switch err.Error() {
+		case "payment expired":
+			errorMessage = "Время на оплату истекло, запись отменена. Запишитесь заново."
+		case "payment amount mismatch":
+			errorMessage = "Сумма счёта изменилась. Запишитесь заново."
+		default:
+			errorMessage = "Не удалось проверить счёт. Попробуйте позже."
+		}
+	}
+
+	_, err := b.AnswerPreCheckoutQuery(ctx, &bot.AnswerPreCheckoutQueryParams{
+		PreCheckoutQueryID: query.ID,
+		OK:                 errorMessage == "",
+		ErrorMessage:       errorMessage,
+	})
+	if err != nil {
+		h.logger.Error("Failed to answer pre-checkout query", zap.Error(err))
+	}
+}
+
+// HandleSuccessfulPayment подтверждает запись после успешной оплаты и уведомляет учителя
+func (h *Handlers) HandleSuccessfulPayment(ctx context.Context, b *bot.Bot, update *models.Update) {
+	payment := update.Message.SuccessfulPayment
+	chatID := update.Message.Chat.ID
+
+	h.logger.Info("HandleSuccessfulPayment called",
+		zap.Int64("chat_id", chatID),
+		zap.String("payload", payment.InvoicePayload),
+		zap.Int("amount", payment.TotalAmount),
+		zap.String("telegram_charge_id", payment.TelegramPaymentChargeID))
+
+	if !h.paymentService.Enabled() {
+		h.logger.Error("Successful payment received but payments are not configured",
+			zap.String("payload", payment.InvoicePayload))
+		return
+	}
+
+	booking, err := h.paymentService.ConfirmPayment(ctx,
+		payment.InvoicePayload,
+		payment.Currency,
+		payment.TotalAmount,
+		payment.TelegramPaymentChargeID,
+		payment.ProviderPaymentChargeID)
+	if err != nil {
+		h.logger.Error("Failed to confirm payment", zap.Error(err))
+
+		text := "⚠️ Оплата получена, но подтвердить запись не удалось. Свяжитесь с преподавателем — он поможет с записью или возвратом."
+		if err.Error() == "booking is no longer awaiting payment" {
+			text = "⚠️ Оплата получена, но время на оплату уже истекло и слот был освобождён. Свяжитесь с преподавателем для возврата или переноса."
+		}
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: text})
+		return
+	}
+
+	// Дозаполняем слот для уведомления учителя
+	booking.Slot, _ = h.teacherService.GetSlotByID(ctx, booking.SlotID)
+
+	statusText := "Запись подтверждена ✅"
+	if booking.Status == model.BookingStatusPending {
+		statusText = "Запись ожидает одобрения учителя ⏳"
+	}
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text: fmt.Sprintf("✅ Оплата получена!\n\n📝 Запись #%d\n📅 %s\n\nДетали занятия доступны в /mybookings",
+			booking.ID, statusText),
+	})
+
+	teacher, err := h.userService.GetByID(ctx, booking.TeacherID)
+	if err != nil || teacher == nil {
+		h.logger.Error("Teacher not found for paid booking",
+			zap.Int64("booking_id", booking.ID),
+			zap.Error(err))
+		return
+	}
+
+	studentName := update.Message.From.FirstName
+	if student, err := h.userService.GetByID(ctx, booking.StudentID); err == nil && student != nil {
+		studentName = student.FirstName
+	}
+
+	common.SendNewBookingNotification(ctx, b, teacher.TelegramID, studentName, booking)
+}
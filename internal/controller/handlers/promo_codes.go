@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleCreatePromoCode обрабатывает ввод нового промокода: "КОД СКИДКА [лимит] [дней]"
+func (h *Handlers) handleCreatePromoCode(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	subjectIDRaw, ok := h.stateManager.GetData(telegramID, "promo_subject_id")
+	scopeID, okType := subjectIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: начните заново из раздела «Промокоды»",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	var subjectID *int64
+	if scopeID > 0 {
+		subjectID = &scopeID
+	}
+
+	retry := func(text string) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      text + "\n\nПример: <code>FIRST 500 10 30</code>\n\nПопробуйте ещё раз:",
+			ParseMode: models.ParseModeHTML,
+		})
+	}
+
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) < 2 || len(fields) > 4 {
+		retry("❌ Укажите код и скидку через пробел.")
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка авторизации",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	// Фиксированная скидка указывается в валюте предмета, для всех предметов — в валюте по умолчанию
+	currency := user.DefaultCurrency
+	if subjectID != nil {
+		if subject, err := h.teacherService.GetSubjectByID(ctx, *subjectID); err == nil && subject != nil {
+			currency = subject.Currency
+		}
+	}
+
+	// Скидка: "20%" — проценты, иначе сумма в валюте
+	discountType := model.PromoDiscountFixed
+	discountStr := fields[1]
+	if strings.HasSuffix(discountStr, "%") {
+		discountType = model.PromoDiscountPercent
+		discountStr = strings.TrimSuffix(discountStr, "%")
+	}
+
+	var discountValue int
+	if discountType == model.PromoDiscountPercent {
+		discount, err := strconv.ParseFloat(strings.ReplaceAll(discountStr, ",", "."), 64)
+		if err != nil || discount <= 0 {
+			retry("❌ Не удалось разобрать скидку.")
+			return
+		}
+		discountValue = int(math.Round(discount))
+	} else {
+		discountValue, err = currency.ParseAmount(discountStr)
+		if err != nil || discountValue <= 0 {
+			retry(fmt.Sprintf("❌ Не удалось разобрать скидку в %s.", currency))
+			return
+		}
+	}
+
+	// Необязательные лимит использований и срок действия
+	var maxUses *int
+	if len(fields) > 2 {
+		uses, err := strconv.Atoi(fields[2])
+		if err != nil || uses < 0 {
+			retry("❌ Лимит использований должен быть числом.")
+			return
+		}
+		if uses > 0 {
+			maxUses = &uses
+		}
+	}
+
+	var expiresAt *time.Time
+	if len(fields) > 3 {
+		days, err := strconv.Atoi(fields[3])
+		if err != nil || days < 0 || days > 3650 {
+			retry("❌ Срок действия должен быть числом дней.")
+			return
+		}
+		if days > 0 {
+			t := time.Now().AddDate(0, 0, days)
+			expiresAt = &t
+		}
+	}
+
+	promo, err := h.promoService.CreatePromoCode(ctx, user.ID, fields[0], discountType, discountValue, subjectID, maxUses, expiresAt)
+	if err != nil {
+		h.logger.Error("Failed to create promo code",
+			zap.Int64("teacher_id", user.ID),
+			zap.Error(err))
+
+		switch err.Error() {
+		case "invalid promo code":
+			retry("❌ Код может содержать 3–20 латинских букв, цифр, «-» и «_».")
+		case "invalid discount":
+			retry(fmt.Sprintf("❌ Скидка в процентах должна быть от 1 до 100, фиксированная — не больше %s.",
+				formatting.FormatPriceShort(currency.MaxPrice(), currency)))
+		case "promo code already exists":
+			retry("❌ Такой промокод уже существует, придумайте другой.")
+		default:
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось создать промокод"})
+			h.stateManager.ClearState(telegramID)
+		}
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	scope := "все предметы"
+	if subjectID != nil {
+		if subject, err := h.teacherService.GetSubjectByID(ctx, *subjectID); err == nil && subject != nil {
+			scope = html.EscapeString(subject.Name)
+		}
+	}
+
+	text := fmt.Sprintf(
+		"✅ <b>Промокод создан</b>\n\n"+
+			"Код: <code>%s</code>\n"+
+			"Скидка: %s\n"+
+			"Предметы: %s\n"+
+			"Ограничения: %s\n\n"+
+			"Отправьте код студентам — они введут его на странице предмета.",
+		promo.Code,
+		formatting.FormatPromoDiscount(promo),
+		scope,
+		formatting.FormatPromoLimits(promo),
+	)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "🔙 К промокодам", CallbackData: "manage_promo_codes"}},
+			},
+		},
+	})
+}
+
+// handleEnteringPromoCode обрабатывает ввод промокода студентом на странице предмета
+func (h *Handlers) handleEnteringPromoCode(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	subjectIDRaw, ok := h.stateManager.GetData(telegramID, "subject_id")
+	subjectID, okType := subjectIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: откройте предмет заново и нажмите «Ввести промокод»",
+		})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Пользователь не найден"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	backKeyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "⬅️ К предмету", CallbackData: fmt.Sprintf("view_subject:%d", subjectID)}},
+		},
+	}
+
+	promo, err := h.promoService.ApplyPromoCode(ctx, user.ID, subjectID, update.Message.Text)
+	if err != nil {
+		errorText := "❌ Не удалось применить промокод"
+		switch err.Error() {
+		case "promo code not found":
+			errorText = "❌ Такого промокода нет. Проверьте написание и попробуйте ещё раз:"
+		case "promo code is not valid":
+			errorText = "❌ Промокод больше не действует: истёк срок или закончились использования."
+			h.stateManager.ClearState(telegramID)
+		case "promo code does not apply to subject":
+			errorText = "❌ Этот промокод не действует на выбранный предмет."
+			h.stateManager.ClearState(telegramID)
+		default:
+			h.stateManager.ClearState(telegramID)
+			h.logger.Error("Failed to apply promo code",
+				zap.Int64("subject_id", subjectID),
+				zap.Error(err))
+		}
+
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: errorText, ReplyMarkup: backKeyboard})
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	subject, err := h.teacherService.GetSubjectByID(ctx, subjectID)
+	if err != nil || subject == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Предмет не найден"})
+		return
+	}
+
+	text := fmt.Sprintf(
+		"✅ <b>Промокод %s применён</b>\n\n"+
+			"📚 %s\n"+
+			"💰 Цена: %s → <b>%s</b> (%s)\n\n"+
+			"Скидка сработает при следующей записи на этот предмет.",
+		promo.Code,
+		html.EscapeString(subject.Name),
+		formatting.FormatPrice(subject.Price, subject.Currency),
+		formatting.FormatPrice(promo.Apply(subject.Price), subject.Currency),
+		formatting.FormatPromoDiscount(promo),
+	)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "📅 Посмотреть расписание", CallbackData: fmt.Sprintf("view_schedule_subject:%d", subjectID)}},
+				{{Text: "⬅️ К предмету", CallbackData: fmt.Sprintf("view_subject:%d", subjectID)}},
+			},
+		},
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleReviewComment сохраняет комментарий студента к отзыву
+func (h *Handlers) handleReviewComment(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	reviewID, ok := h.reviewIDFromState(ctx, b, telegramID, chatID)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	if _, err := h.reviewService.SetComment(ctx, user.ID, reviewID, update.Message.Text); err != nil {
+		switch err.Error() {
+		case "empty comment":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Комментарий пустой. Напишите отзыв:"})
+			return
+		case "comment too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Комментарий длиннее %d символов. Сократите его и отправьте ещё раз:", service.MaxReviewCommentLength),
+			})
+			return
+		case "review not found":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Отзыв не найден"})
+		case "comment already set":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "Комментарий к этому отзыву уже добавлен"})
+		default:
+			h.logger.Error("Failed to save review comment", zap.Int64("review_id", reviewID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось сохранить комментарий"})
+		}
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   "✅ Спасибо! Отзыв опубликован в профиле учителя.",
+	})
+}
+
+// handleReviewReply сохраняет ответ учителя на отзыв
+func (h *Handlers) handleReviewReply(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	reviewID, ok := h.reviewIDFromState(ctx, b, telegramID, chatID)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.IsTeacher {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	if _, err := h.reviewService.Reply(ctx, user.ID, reviewID, update.Message.Text); err != nil {
+		switch err.Error() {
+		case "empty reply":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ответ пустой. Напишите ответ:"})
+			return
+		case "reply too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Ответ длиннее %d символов. Сократите его и отправьте ещё раз:", service.MaxReviewCommentLength),
+			})
+			return
+		case "review not found":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Отзыв не найден"})
+		default:
+			h.logger.Error("Failed to save review reply", zap.Int64("review_id", reviewID), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Не удалось сохранить ответ"})
+		}
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	h.stateManager.ClearState(telegramID)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   "✅ Ответ сохранён, студент получил уведомление.",
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "⭐ К отзывам", CallbackData: "my_reviews"}},
+			},
+		},
+	})
+}
+
+// reviewIDFromState получает отзыв, к которому пользователь пишет комментарий или ответ
+func (h *Handlers) reviewIDFromState(ctx context.Context, b *bot.Bot, telegramID, chatID int64) (int64, bool) {
+	reviewIDRaw, ok := h.stateManager.GetData(telegramID, "review_id")
+	reviewID, okType := reviewIDRaw.(int64)
+	if !ok || !okType {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "❌ Ошибка: откройте отзыв заново",
+		})
+		h.stateManager.ClearState(telegramID)
+		return 0, false
+	}
+
+	return reviewID, true
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks"
 	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common/formatting"
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -52,6 +53,11 @@ func (h *Handlers) HandleFindTeachers(ctx context.Context, b *bot.Bot, update *m
 		return
 	}
 
+	ratings, err := h.reviewService.GetRatings(ctx, teachers)
+	if err != nil {
+		h.logger.Error("Failed to get teacher ratings", zap.Error(err))
+	}
+
 	const itemsPerPage = 5
 	page := 1
 	totalTeachers := len(teachers)
@@ -99,6 +105,9 @@ func (h *Handlers) HandleFindTeachers(ctx context.Context, b *bot.Bot, update *m
 			}
 
 			text += fmt.Sprintf("%d. *%s*\n", start+i+1, name)
+			if rating := ratings[teacher.ID]; rating != nil {
+				text += fmt.Sprintf("   %s\n", formatting.FormatRating(rating))
+			}
 			if subjectNames != "" {
 				text += fmt.Sprintf("   📚 %s\n", subjectNames)
 			}
@@ -108,6 +117,12 @@ func (h *Handlers) HandleFindTeachers(ctx context.Context, b *bot.Bot, update *m
 	// Формируем клавиатуру
 	var buttons [][]models.InlineKeyboardButton
 
+	if totalTeachers > 1 {
+		buttons = append(buttons, []models.InlineKeyboardButton{
+			{Text: "⭐ Сначала с высоким рейтингом", CallbackData: "public_teachers_rated:1"},
+		})
+	}
+
 	// Добавляем кнопки учителей на текущей странице
 	if totalTeachers > 0 {
 		start := (page - 1) * itemsPerPage
@@ -232,41 +247,32 @@ func (h *Handlers) handleStudentBookings(ctx context.Context, b *bot.Bot, update
 		return
 	}
 
+	noteCounts := h.bookingNoteCounts(ctx, user.ID)
+
 	// Отправляем каждую запись отдельным сообщением с кнопками
 	for _, booking := range bookings {
-		text := FormatBooking(booking)
-
-		// Добавляем кнопку отмены только для активных записей
-		if booking.Status == model.BookingStatusConfirmed || booking.Status == model.BookingStatusPending {
-			keyboard := &models.InlineKeyboardMarkup{
-				InlineKeyboard: [][]models.InlineKeyboardButton{
-					{
-						{Text: fmt.Sprintf("❌ Отменить запись #%d", booking.ID), CallbackData: fmt.Sprintf("%s%d", callbacks.CancelBooking, booking.ID)},
-					},
-				},
-			}
-
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID:      update.Message.Chat.ID,
-				Text:        text,
-				ReplyMarkup: keyboard,
-			})
-		} else {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: update.Message.Chat.ID,
-				Text:   text,
-			})
-		}
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      update.Message.Chat.ID,
+			Text:        FormatBooking(booking),
+			ReplyMarkup: studentBookingKeyboard(booking, noteCounts[booking.ID]),
+		})
 	}
 
 	// В конце добавляем кнопку для новой записи
-	keyboard := &models.InlineKeyboardMarkup{
-		InlineKeyboard: [][]models.InlineKeyboardButton{
-			{
-				{Text: "➕ Записаться на занятие", CallbackData: callbacks.BookAnother},
-			},
+	rows := [][]models.InlineKeyboardButton{
+		{
+			{Text: "➕ Записаться на занятие", CallbackData: callbacks.BookAnother},
+		},
+		{
+			{Text: "🗓 Неделя на картинке", CallbackData: "student_week:0"},
 		},
 	}
+	if len(noteCounts) > 0 {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: "📚 Все заметки и ДЗ", CallbackData: "my_notes"},
+		})
+	}
+	keyboard := &models.InlineKeyboardMarkup{InlineKeyboard: rows}
 
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:      update.Message.Chat.ID,
@@ -358,6 +364,9 @@ func (h *Handlers) handleTeacherBookings(ctx context.Context, b *bot.Bot, update
 						{Text: "✅ Одобрить", CallbackData: fmt.Sprintf("%s%d", callbacks.ApproveBooking, booking.ID)},
 						{Text: "❌ Отклонить", CallbackData: fmt.Sprintf("%s%d", callbacks.RejectBooking, booking.ID)},
 					},
+					{
+						{Text: "💬 Написать студенту", CallbackData: fmt.Sprintf("chat_booking:%d", booking.ID)},
+					},
 				},
 			}
 
@@ -377,30 +386,45 @@ func (h *Handlers) handleTeacherBookings(ctx context.Context, b *bot.Bot, update
 			ParseMode: models.ParseModeMarkdown,
 		})
 
+		noteCounts := h.bookingNoteCounts(ctx, user.ID)
 		for _, booking := range studentBookings {
-			text := FormatBooking(booking)
-
-			// Добавляем кнопку отмены только для активных записей
-			if booking.Status == model.BookingStatusConfirmed || booking.Status == model.BookingStatusPending {
-				keyboard := &models.InlineKeyboardMarkup{
-					InlineKeyboard: [][]models.InlineKeyboardButton{
-						{
-							{Text: fmt.Sprintf("❌ Отменить запись #%d", booking.ID), CallbackData: fmt.Sprintf("%s%d", callbacks.CancelBooking, booking.ID)},
-						},
-					},
-				}
-
-				b.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID:      update.Message.Chat.ID,
-					Text:        text,
-					ReplyMarkup: keyboard,
-				})
-			} else {
-				b.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID: update.Message.Chat.ID,
-					Text:   text,
-				})
-			}
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:      update.Message.Chat.ID,
+				Text:        FormatBooking(booking),
+				ReplyMarkup: studentBookingKeyboard(booking, noteCounts[booking.ID]),
+			})
 		}
 	}
 }
+
+// studentBookingKeyboard формирует кнопки записи студента: отмена для активных записей, переписка с учителем
+// и заметки учителя к занятию, если они есть
+func studentBookingKeyboard(booking *model.Booking, noteCount int) *models.InlineKeyboardMarkup {
+	var rows [][]models.InlineKeyboardButton
+	if booking.Status.IsActive() {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: fmt.Sprintf("❌ Отменить запись #%d", booking.ID), CallbackData: fmt.Sprintf("%s%d", callbacks.CancelBooking, booking.ID)},
+		})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: "💬 Написать учителю", CallbackData: fmt.Sprintf("chat_booking:%d", booking.ID)},
+	})
+	if noteCount > 0 {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: fmt.Sprintf("📝 Заметки учителя (%d)", noteCount), CallbackData: fmt.Sprintf("booking_notes:%d", booking.ID)},
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// bookingNoteCounts считает заметки учителей по записям студента; при ошибке кнопки заметок не показываются
+func (h *Handlers) bookingNoteCounts(ctx context.Context, studentID int64) map[int64]int {
+	counts, err := h.lessonNoteService.CountByBookings(ctx, studentID)
+	if err != nil {
+		h.logger.Error("Failed to count lesson notes", zap.Int64("student_id", studentID), zap.Error(err))
+		return nil
+	}
+
+	return counts
+}
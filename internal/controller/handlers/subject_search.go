@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/controller/callbacks/common"
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.uber.org/zap"
+)
+
+// handleSubjectSearch ищет предметы по запросу студента. Режим поиска остаётся включённым:
+// следующее сообщение — новый запрос с теми же фильтрами
+func (h *Handlers) handleSubjectSearch(ctx context.Context, b *bot.Bot, update *models.Update) {
+	telegramID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	user, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка авторизации"})
+		h.stateManager.ClearState(telegramID)
+		return
+	}
+
+	var filter model.SubjectSearchFilter
+	if raw, ok := h.stateManager.GetData(telegramID, common.SubjectSearchFilterKey); ok {
+		if saved, ok := raw.(model.SubjectSearchFilter); ok {
+			filter = saved
+		}
+	}
+	filter.Query = strings.TrimSpace(update.Message.Text)
+
+	results, ratings, err := common.SearchSubjectsForStudent(ctx, h.accessService, h.reviewService, h.logger, user.ID, filter)
+	if err != nil {
+		switch err.Error() {
+		case "empty query":
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Запрос пустой. Напишите, что хотите изучать:"})
+		case "query too long":
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: chatID,
+				Text:   fmt.Sprintf("❌ Запрос длиннее %d символов. Сократите его:", service.MaxSubjectSearchQueryLength),
+			})
+		default:
+			h.logger.Error("Failed to search subjects", zap.String("query", filter.Query), zap.Error(err))
+			b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: "❌ Ошибка при поиске. Попробуйте ещё раз"})
+		}
+		return
+	}
+
+	h.stateManager.SetData(telegramID, common.SubjectSearchFilterKey, filter)
+
+	text, kb := common.BuildSubjectSearchScreen(filter, results, ratings, 1)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+}
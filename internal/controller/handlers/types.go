@@ -8,12 +8,22 @@ import (
 
 // Handlers содержит все зависимости для обработки команд
 type Handlers struct {
-	userService    *service.UserService
-	bookingService *service.BookingService
-	teacherService *service.TeacherService
-	accessService  *service.StudentAccessService
-	stateManager   *state.Manager
-	logger         *zap.Logger
+	userService         *service.UserService
+	bookingService      *service.BookingService
+	teacherService      *service.TeacherService
+	accessService       *service.StudentAccessService
+	paymentService      *service.PaymentService
+	creditService       *service.CreditService
+	ledgerService       *service.LedgerService
+	promoService        *service.PromoCodeService
+	groupService        *service.GroupService
+	broadcastService    *service.BroadcastService
+	conversationService *service.ConversationService
+	lessonNoteService   *service.LessonNoteService
+	reviewService       *service.ReviewService
+	favoriteService     *service.FavoriteService
+	stateManager        *state.Manager
+	logger              *zap.Logger
 }
 
 // NewHandlers создаёт новый обработчик команд
@@ -22,15 +32,35 @@ func NewHandlers(
 	bookingService *service.BookingService,
 	teacherService *service.TeacherService,
 	accessService *service.StudentAccessService,
+	paymentService *service.PaymentService,
+	creditService *service.CreditService,
+	ledgerService *service.LedgerService,
+	promoService *service.PromoCodeService,
+	groupService *service.GroupService,
+	broadcastService *service.BroadcastService,
+	conversationService *service.ConversationService,
+	lessonNoteService *service.LessonNoteService,
+	reviewService *service.ReviewService,
+	favoriteService *service.FavoriteService,
 	stateManager *state.Manager,
 	logger *zap.Logger,
 ) *Handlers {
 	return &Handlers{
-		userService:    userService,
-		bookingService: bookingService,
-		teacherService: teacherService,
-		accessService:  accessService,
-		stateManager:   stateManager,
-		logger:         logger,
+		userService:         userService,
+		bookingService:      bookingService,
+		teacherService:      teacherService,
+		accessService:       accessService,
+		paymentService:      paymentService,
+		creditService:       creditService,
+		ledgerService:       ledgerService,
+		promoService:        promoService,
+		groupService:        groupService,
+		broadcastService:    broadcastService,
+		conversationService: conversationService,
+		lessonNoteService:   lessonNoteService,
+		reviewService:       reviewService,
+		favoriteService:     favoriteService,
+		stateManager:        stateManager,
+		logger:              logger,
 	}
 }
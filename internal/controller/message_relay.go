@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TelegramMessageRelay пересылает сообщения переписки сообщениями бота
+type TelegramMessageRelay struct {
+	bot *bot.Bot
+}
+
+// NewTelegramMessageRelay создаёт пересыльщика сообщений переписки
+func NewTelegramMessageRelay(botInstance *bot.Bot) *TelegramMessageRelay {
+	return &TelegramMessageRelay{bot: botInstance}
+}
+
+// Relay отправляет HTML-сообщение в чат получателя и возвращает ID отправленного сообщения
+func (r *TelegramMessageRelay) Relay(ctx context.Context, chatID int64, text string, silent bool) (int, error) {
+	msg, err := r.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:              chatID,
+		Text:                text,
+		ParseMode:           models.ParseModeHTML,
+		DisableNotification: silent,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TelegramNotifier отправляет уведомления сообщениями бота
+type TelegramNotifier struct {
+	bot *bot.Bot
+}
+
+// NewTelegramNotifier создаёт отправителя уведомлений
+func NewTelegramNotifier(botInstance *bot.Bot) *TelegramNotifier {
+	return &TelegramNotifier{bot: botInstance}
+}
+
+// Notify отправляет HTML-сообщение в чат пользователя
+func (n *TelegramNotifier) Notify(ctx context.Context, chatID int64, text string) error {
+	_, err := n.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+	return err
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TelegramPaymentProvider выставляет счета через Telegram Payments
+type TelegramPaymentProvider struct {
+	bot           *bot.Bot
+	providerToken string
+}
+
+// NewTelegramPaymentProvider создаёт провайдер платежей Telegram
+func NewTelegramPaymentProvider(botInstance *bot.Bot, providerToken string) *TelegramPaymentProvider {
+	return &TelegramPaymentProvider{
+		bot:           botInstance,
+		providerToken: providerToken,
+	}
+}
+
+// Name возвращает название провайдера
+func (p *TelegramPaymentProvider) Name() string {
+	return "telegram"
+}
+
+// SendInvoice отправляет счёт в чат студента
+func (p *TelegramPaymentProvider) SendInvoice(ctx context.Context, invoice *model.Invoice) error {
+	_, err := p.bot.SendInvoice(ctx, &bot.SendInvoiceParams{
+		ChatID:        invoice.ChatID,
+		Title:         invoice.Title,
+		Description:   invoice.Description,
+		Payload:       invoice.Payload,
+		ProviderToken: p.providerToken,
+		Currency:      invoice.Currency,
+		Prices: []models.LabeledPrice{
+			{Label: invoice.Label, Amount: invoice.Amount},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("send telegram invoice: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TelegramReviewPrompter предлагает оценить занятие сообщением бота с кнопками оценок
+type TelegramReviewPrompter struct {
+	bot *bot.Bot
+}
+
+// NewTelegramReviewPrompter создаёт отправителя просьб об отзыве
+func NewTelegramReviewPrompter(botInstance *bot.Bot) *TelegramReviewPrompter {
+	return &TelegramReviewPrompter{bot: botInstance}
+}
+
+// PromptReview отправляет HTML-сообщение с кнопками оценки от 1 до 5
+func (p *TelegramReviewPrompter) PromptReview(ctx context.Context, chatID, bookingID int64, text string) error {
+	var row []models.InlineKeyboardButton
+	for rating := model.MinReviewRating; rating <= model.MaxReviewRating; rating++ {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d ⭐", rating),
+			CallbackData: fmt.Sprintf("review_rate:%d:%d", bookingID, rating),
+		})
+	}
+
+	_, err := p.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}},
+	})
+	return err
+}
@@ -17,7 +17,9 @@ const (
 	StateEditSubjectName        UserState = "edit_subject_name"
 	StateEditSubjectDescription UserState = "edit_subject_description"
 	StateEditSubjectPrice       UserState = "edit_subject_price"
+	StateEditSubjectCurrency    UserState = "edit_subject_currency"
 	StateEditSubjectDuration    UserState = "edit_subject_duration"
+	StateEditSubjectTags        UserState = "edit_subject_tags"
 
 	// Состояния для добавления слотов
 	StateAddSlotsSubjectID UserState = "add_slots_subject_id"
@@ -36,6 +38,33 @@ const (
 
 	// Состояния для пометки слотов занятыми
 	StateMarkSlotBusyComment UserState = "mark_slot_busy_comment"
+
+	// Состояния для пакетов занятий
+	StateCreatePackage UserState = "create_package"
+
+	// Состояния для взаиморасчётов
+	StateRecordPayment UserState = "record_payment"
+
+	// Состояния для промокодов
+	StateCreatePromoCode   UserState = "create_promo_code"
+	StateEnteringPromoCode UserState = "entering_promo_code"
+
+	// Состояния для групп студентов
+	StateCreateGroup    UserState = "create_group"
+	StateGroupBroadcast UserState = "group_broadcast"
+
+	// Состояния для рассылок учителя
+	StateBroadcastCompose UserState = "broadcast_compose"
+
+	// Состояния для переписки через бота
+	StateConversationMessage UserState = "conversation_message"
+
+	// Состояния для заметок к занятиям
+	StateLessonNote UserState = "lesson_note"
+
+	// Состояния для отзывов
+	StateReviewComment UserState = "review_comment"
+	StateReviewReply   UserState = "review_reply"
 )
 
 // UserData хранит временные данные пользователя во время диалога
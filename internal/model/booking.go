@@ -5,13 +5,20 @@ import "time"
 type BookingStatus string
 
 const (
-	BookingStatusPending   BookingStatus = "pending"   // Ожидает одобрения учителя
-	BookingStatusConfirmed BookingStatus = "confirmed" // Подтверждено
-	BookingStatusCompleted BookingStatus = "completed" // Завершено
-	BookingStatusCanceled  BookingStatus = "canceled"  // Отменено
-	BookingStatusRejected  BookingStatus = "rejected"  // Отклонено учителем
+	BookingStatusPending         BookingStatus = "pending"          // Ожидает одобрения учителя
+	BookingStatusConfirmed       BookingStatus = "confirmed"        // Подтверждено
+	BookingStatusCompleted       BookingStatus = "completed"        // Завершено
+	BookingStatusCanceled        BookingStatus = "canceled"         // Отменено
+	BookingStatusRejected        BookingStatus = "rejected"         // Отклонено учителем
+	BookingStatusAwaitingPayment BookingStatus = "awaiting_payment" // Ожидает оплаты счёта
+	BookingStatusNoShow          BookingStatus = "no_show"          // Студент не пришёл на занятие
 )
 
+// IsActive проверяет, занимает ли бронирование слот (ожидает одобрения, оплаты или подтверждено)
+func (s BookingStatus) IsActive() bool {
+	return s == BookingStatusPending || s == BookingStatusConfirmed || s == BookingStatusAwaitingPayment
+}
+
 type Booking struct {
 	ID                      int64         `json:"id"`
 	StudentID               int64         `json:"student_id"`
@@ -19,6 +26,9 @@ type Booking struct {
 	SubjectID               int64         `json:"subject_id"`
 	SlotID                  int64         `json:"slot_id"`
 	Status                  BookingStatus `json:"status"`
+	Price                   int           `json:"price"`                     // цена на момент записи с учётом скидки
+	Currency                Currency      `json:"currency"`                  // валюта цены
+	PromoCodeID             *int64        `json:"promo_code_id"`             // применённый промокод
 	CancellationRequested   bool          `json:"cancellation_requested"`    // Запрос на отмену
 	CancellationRequestedAt *time.Time    `json:"cancellation_requested_at"` // Когда запрошена отмену
 	CreatedAt               time.Time     `json:"created_at"`
@@ -29,4 +39,9 @@ type Booking struct {
 	Slot    *ScheduleSlot `json:"slot,omitempty"`
 	Student *User         `json:"student,omitempty"`
 	Teacher *User         `json:"teacher,omitempty"`
+	Payment *Payment      `json:"payment,omitempty"` // счёт, если запись ждёт оплаты
+
+	PromoCode *PromoCode `json:"promo_code,omitempty"` // промокод, по которому дана скидка
+
+	CreditDebited bool `json:"credit_debited,omitempty"` // за запись списано занятие из пакета
 }
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// BroadcastAudienceType кому учитель отправляет рассылку
+type BroadcastAudienceType string
+
+const (
+	BroadcastAudienceAll     BroadcastAudienceType = "all"     // все студенты с доступом
+	BroadcastAudienceSubject BroadcastAudienceType = "subject" // студенты, записывавшиеся на предмет
+	BroadcastAudienceBooked  BroadcastAudienceType = "booked"  // студенты с записями за период
+	BroadcastAudienceGroup   BroadcastAudienceType = "group"   // участники группы
+	BroadcastAudiencePicked  BroadcastAudienceType = "picked"  // студенты, выбранные вручную
+)
+
+// BroadcastAudience получатели рассылки. Заполняются только поля, нужные выбранному типу
+type BroadcastAudience struct {
+	Type       BroadcastAudienceType
+	SubjectID  int64
+	GroupID    int64
+	From       time.Time // начало периода записей, включительно
+	To         time.Time // конец периода записей, не включительно
+	StudentIDs []int64
+}
+
+// BroadcastMessage сообщение рассылки: текст и необязательное фото или документ (file_id Telegram).
+// С вложением текст отправляется подписью к нему
+type BroadcastMessage struct {
+	Text           string
+	PhotoFileID    string
+	DocumentFileID string
+}
+
+// HasMedia проверяет, есть ли у сообщения вложение
+func (m *BroadcastMessage) HasMedia() bool {
+	return m.PhotoFileID != "" || m.DocumentFileID != ""
+}
+
+// BroadcastResult итоги доставки рассылки
+type BroadcastResult struct {
+	Total     int
+	Delivered int
+	Failed    int
+	Blocked   int // студент заблокировал бота или удалил аккаунт
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Conversation переписка студента и учителя через бота. У пары студент-учитель одна переписка
+type Conversation struct {
+	ID            int64      `json:"id"`
+	TeacherID     int64      `json:"teacher_id"`
+	StudentID     int64      `json:"student_id"`
+	TeacherMuted  bool       `json:"teacher_muted"` // учитель получает сообщения без звука
+	StudentMuted  bool       `json:"student_muted"` // студент получает сообщения без звука
+	LastMessageAt *time.Time `json:"last_message_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Дополнительные поля для удобства (не из БД)
+	Teacher *User `json:"teacher,omitempty"`
+	Student *User `json:"student,omitempty"`
+}
+
+// IsParticipant проверяет, участвует ли пользователь в переписке
+func (c *Conversation) IsParticipant(userID int64) bool {
+	return c.TeacherID == userID || c.StudentID == userID
+}
+
+// PeerID возвращает ID собеседника пользователя
+func (c *Conversation) PeerID(userID int64) int64 {
+	if c.TeacherID == userID {
+		return c.StudentID
+	}
+	return c.TeacherID
+}
+
+// IsMutedFor проверяет, отключил ли пользователь звук переписки
+func (c *Conversation) IsMutedFor(userID int64) bool {
+	if c.TeacherID == userID {
+		return c.TeacherMuted
+	}
+	return c.StudentMuted
+}
+
+// ConversationMessage сообщение переписки
+type ConversationMessage struct {
+	ID               int64     `json:"id"`
+	ConversationID   int64     `json:"conversation_id"`
+	SenderID         int64     `json:"sender_id"`
+	BookingID        *int64    `json:"booking_id"` // занятие, о котором сообщение
+	Text             string    `json:"text"`
+	RelayedChatID    *int64    `json:"relayed_chat_id"`    // чат получателя
+	RelayedMessageID *int      `json:"relayed_message_id"` // сообщение бота у получателя
+	CreatedAt        time.Time `json:"created_at"`
+}
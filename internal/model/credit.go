@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// CreditSource источник начисления занятий
+type CreditSource string
+
+const (
+	CreditSourcePackage CreditSource = "package" // Пакет занятий
+	CreditSourceGrant   CreditSource = "grant"   // Ручное начисление учителем
+)
+
+// CreditTransactionKind тип движения занятий в журнале
+type CreditTransactionKind string
+
+const (
+	CreditTransactionGrant  CreditTransactionKind = "grant"  // Начисление
+	CreditTransactionDebit  CreditTransactionKind = "debit"  // Списание за запись
+	CreditTransactionRefund CreditTransactionKind = "refund" // Возврат при отмене
+)
+
+// CreditLot начисленные студенту занятия по предмету
+type CreditLot struct {
+	ID        int64        `json:"id"`
+	StudentID int64        `json:"student_id"`
+	TeacherID int64        `json:"teacher_id"`
+	SubjectID int64        `json:"subject_id"`
+	PackageID *int64       `json:"package_id"`
+	Source    CreditSource `json:"source"`
+	Total     int          `json:"total"`
+	Remaining int          `json:"remaining"`
+	ExpiresAt *time.Time   `json:"expires_at"` // nil = бессрочно
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// CreditTransaction запись журнала движения занятий
+type CreditTransaction struct {
+	ID        int64                 `json:"id"`
+	LotID     int64                 `json:"lot_id"`
+	StudentID int64                 `json:"student_id"`
+	TeacherID int64                 `json:"teacher_id"`
+	BookingID *int64                `json:"booking_id"`
+	Kind      CreditTransactionKind `json:"kind"`
+	Amount    int                   `json:"amount"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// CreditBalance остаток занятий студента по предмету
+type CreditBalance struct {
+	StudentID     int64      `json:"student_id"`
+	TeacherID     int64      `json:"teacher_id"`
+	SubjectID     int64      `json:"subject_id"`
+	SubjectName   string     `json:"subject_name"`
+	Remaining     int        `json:"remaining"`
+	NextExpiresAt *time.Time `json:"next_expires_at"` // ближайшая дата сгорания занятий (nil = бессрочно)
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency код валюты ISO 4217
+type Currency string
+
+const (
+	CurrencyRUB Currency = "RUB"
+	CurrencyKZT Currency = "KZT"
+	CurrencyEUR Currency = "EUR"
+	CurrencyUSD Currency = "USD"
+
+	// DefaultCurrency валюта предметов и учителей по умолчанию
+	DefaultCurrency = CurrencyRUB
+)
+
+// currencyInfo описывает отображение и ограничения цен в валюте
+type currencyInfo struct {
+	symbol     string
+	name       string
+	minorUnits int // знаков после запятой (копейки, тиыны, центы)
+	maxPrice   int // максимальная цена занятия в основных единицах
+}
+
+var currencies = map[Currency]currencyInfo{
+	CurrencyRUB: {symbol: "₽", name: "Рубль", minorUnits: 2, maxPrice: 1_000_000},
+	CurrencyKZT: {symbol: "₸", name: "Тенге", minorUnits: 2, maxPrice: 5_000_000},
+	CurrencyEUR: {symbol: "€", name: "Евро", minorUnits: 2, maxPrice: 10_000},
+	CurrencyUSD: {symbol: "$", name: "Доллар США", minorUnits: 2, maxPrice: 10_000},
+}
+
+// SupportedCurrencies возвращает валюты, в которых учитель может назначать цены
+func SupportedCurrencies() []Currency {
+	return []Currency{CurrencyRUB, CurrencyKZT, CurrencyEUR, CurrencyUSD}
+}
+
+// IsValid проверяет, поддерживается ли валюта
+func (c Currency) IsValid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+func (c Currency) info() currencyInfo {
+	if info, ok := currencies[c]; ok {
+		return info
+	}
+	return currencies[DefaultCurrency]
+}
+
+// Symbol возвращает символ валюты
+func (c Currency) Symbol() string {
+	if !c.IsValid() {
+		return string(c)
+	}
+	return c.info().symbol
+}
+
+// Name возвращает название валюты
+func (c Currency) Name() string {
+	return c.info().name
+}
+
+// MinorUnits возвращает количество знаков после запятой
+func (c Currency) MinorUnits() int {
+	return c.info().minorUnits
+}
+
+// MinorPerMajor возвращает количество минимальных единиц в основной (100 копеек в рубле)
+func (c Currency) MinorPerMajor() int {
+	return int(math.Pow10(c.MinorUnits()))
+}
+
+// MaxPrice возвращает максимальную цену занятия в минимальных единицах
+func (c Currency) MaxPrice() int {
+	return c.info().maxPrice * c.MinorPerMajor()
+}
+
+// ParseAmount разбирает сумму в основных единицах ("1500", "12,50") в минимальные единицы.
+// Дробная часть не может быть точнее минимальной единицы валюты
+func (c Currency) ParseAmount(s string) (int, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	whole, frac, hasFrac := strings.Cut(s, ".")
+
+	if whole == "" || (hasFrac && (frac == "" || len(frac) > c.MinorUnits())) {
+		return 0, fmt.Errorf("invalid amount")
+	}
+
+	major, err := strconv.Atoi(whole)
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("invalid amount")
+	}
+
+	minor := 0
+	if hasFrac {
+		minor, err = strconv.Atoi(frac + strings.Repeat("0", c.MinorUnits()-len(frac)))
+		if err != nil || minor < 0 {
+			return 0, fmt.Errorf("invalid amount")
+		}
+	}
+
+	if major > math.MaxInt32/c.MinorPerMajor() {
+		return 0, fmt.Errorf("amount too large")
+	}
+
+	return major*c.MinorPerMajor() + minor, nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import "time"
+
+// ExportDataset набор данных для выгрузки учителя
+type ExportDataset string
+
+const (
+	ExportDatasetSlots       ExportDataset = "slots"        // слоты расписания за период
+	ExportDatasetBookings    ExportDataset = "bookings"     // записи студентов за период
+	ExportDatasetStudents    ExportDataset = "students"     // список студентов с доступом
+	ExportDatasetInviteCodes ExportDataset = "invite_codes" // коды приглашения и их использование
+)
+
+// IsValid проверяет, что набор данных поддерживается
+func (d ExportDataset) IsValid() bool {
+	switch d {
+	case ExportDatasetSlots, ExportDatasetBookings, ExportDatasetStudents, ExportDatasetInviteCodes:
+		return true
+	}
+	return false
+}
+
+// HasPeriod проверяет, выгружается ли набор данных за период
+func (d ExportDataset) HasPeriod() bool {
+	return d == ExportDatasetSlots || d == ExportDatasetBookings
+}
+
+// ExportFormat формат файла выгрузки
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// IsValid проверяет, что формат поддерживается
+func (f ExportFormat) IsValid() bool {
+	return f == ExportFormatCSV || f == ExportFormatXLSX
+}
+
+// ExportColumn колонка выгрузки. Key не меняется между версиями бота, Title — заголовок в файле
+type ExportColumn struct {
+	Key     string
+	Title   string
+	Numeric bool // значение записывается в XLSX числом
+}
+
+// ExportTable таблица выгрузки: колонки и строки значений в том же порядке
+type ExportTable struct {
+	Name    string
+	Columns []ExportColumn
+	Rows    [][]string
+}
+
+// ExportFile готовый файл выгрузки
+type ExportFile struct {
+	Filename string
+	Data     []byte
+	Rows     int
+}
+
+// SlotExportRow строка выгрузки слотов
+type SlotExportRow struct {
+	SlotID      int64
+	StartTime   time.Time
+	EndTime     time.Time
+	SubjectName string
+	Status      SlotStatus
+	StudentName *string
+	Comment     *string
+}
+
+// BookingExportRow строка выгрузки записей
+type BookingExportRow struct {
+	BookingID       int64
+	StartTime       time.Time
+	EndTime         time.Time
+	SubjectName     string
+	StudentName     string
+	StudentUsername string
+	Status          BookingStatus
+	Price           int
+	Currency        Currency
+	PromoCode       *string
+	CreatedAt       time.Time
+}
+
+// StudentExportRow строка выгрузки студентов
+type StudentExportRow struct {
+	StudentID  int64
+	Name       string
+	Username   string
+	AccessType string
+	GrantedAt  time.Time
+	ExpiresAt  *time.Time
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Favorite избранный учитель или отдельный предмет студента.
+// По избранному студент получает уведомления о появившихся свободных слотах
+type Favorite struct {
+	ID              int64     `json:"id"`
+	StudentID       int64     `json:"student_id"`
+	TeacherID       int64     `json:"teacher_id"`
+	SubjectID       *int64    `json:"subject_id"` // nil — все предметы учителя
+	AlertsEnabled   bool      `json:"alerts_enabled"`
+	Weekdays        int       `json:"weekdays"`    // бит i — день time.Weekday(i); 0 — любой день
+	DayPeriods      int       `json:"day_periods"` // бит i — DayPeriods[i]; 0 — любое время
+	AlertsCheckedAt time.Time `json:"alerts_checked_at"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	// Дополнительные поля для удобства (заполняются при выборке)
+	TeacherName string `json:"teacher_name,omitempty"`
+	SubjectName string `json:"subject_name,omitempty"`
+}
+
+// IsSubject проверяет, добавлен ли в избранное отдельный предмет, а не учитель целиком
+func (f *Favorite) IsSubject() bool {
+	return f.SubjectID != nil
+}
+
+// HasWeekday проверяет, отмечен ли день недели как удобный
+func (f *Favorite) HasWeekday(day time.Weekday) bool {
+	return f.Weekdays&(1<<uint(day)) != 0
+}
+
+// HasDayPeriod проверяет, отмечена ли часть дня DayPeriods[index] как удобная
+func (f *Favorite) HasDayPeriod(index int) bool {
+	return f.DayPeriods&(1<<uint(index)) != 0
+}
+
+// TimeFilter возвращает удобное студенту время в виде фильтра подбора
+func (f *Favorite) TimeFilter() FreeTimeFilter {
+	return FreeTimeFilterFromMasks(f.Weekdays, f.DayPeriods)
+}
+
+// FreeTimeFilterFromMasks собирает фильтр по времени из битовых масок дней недели и частей дня
+func FreeTimeFilterFromMasks(weekdays, periods int) FreeTimeFilter {
+	var filter FreeTimeFilter
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if weekdays&(1<<uint(day)) != 0 {
+			filter.Weekdays = append(filter.Weekdays, day)
+		}
+	}
+	for i, period := range DayPeriods {
+		if periods&(1<<uint(i)) != 0 {
+			filter.Periods = append(filter.Periods, period)
+		}
+	}
+	return filter
+}
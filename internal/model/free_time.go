@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// DayPeriod часть дня, в которую студенту удобно заниматься
+type DayPeriod struct {
+	Title    string
+	Emoji    string
+	FromHour int // час начала, включительно
+	ToHour   int // час окончания, не включительно
+}
+
+// DayPeriods части дня для подбора времени занятия
+var DayPeriods = []DayPeriod{
+	{Title: "Утро", Emoji: "🌅", FromHour: 6, ToHour: 12},
+	{Title: "День", Emoji: "☀️", FromHour: 12, ToHour: 17},
+	{Title: "Вечер", Emoji: "🌆", FromHour: 17, ToHour: 23},
+}
+
+// FreeTimeFilter пожелания студента для подбора времени у всех доступных учителей.
+// Пустые Weekdays и Periods — подходит любой день и любое время
+type FreeTimeFilter struct {
+	SubjectID int64  // подбирать среди предметов с таким же названием, как у этого предмета
+	Category  string // подбирать среди предметов категории
+	Weekdays  []time.Weekday
+	Periods   []DayPeriod
+}
+
+// Matches проверяет, подходит ли время начала занятия под пожелания
+func (f FreeTimeFilter) Matches(start time.Time) bool {
+	if len(f.Weekdays) > 0 {
+		found := false
+		for _, weekday := range f.Weekdays {
+			if start.Weekday() == weekday {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Periods) == 0 {
+		return true
+	}
+	for _, period := range f.Periods {
+		if start.Hour() >= period.FromHour && start.Hour() < period.ToHour {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeTimeSlot свободный слот, найденный при подборе времени
+type FreeTimeSlot struct {
+	Slot        *ScheduleSlot
+	Subject     *Subject
+	TeacherName string
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// LedgerEntryKind тип записи в журнале взаиморасчётов
+type LedgerEntryKind string
+
+const (
+	LedgerEntryCharge  LedgerEntryKind = "charge"  // Начисление за занятие или пакет
+	LedgerEntryPayment LedgerEntryKind = "payment" // Оплата, полученная учителем
+)
+
+// LedgerPaymentMethod способ оплаты вне бота
+type LedgerPaymentMethod string
+
+const (
+	LedgerPaymentCash     LedgerPaymentMethod = "cash"     // Наличные
+	LedgerPaymentTransfer LedgerPaymentMethod = "transfer" // Перевод
+)
+
+// LedgerEntry представляет начисление или оплату между учителем и студентом
+type LedgerEntry struct {
+	ID          int64                `json:"id"`
+	TeacherID   int64                `json:"teacher_id"`
+	StudentID   int64                `json:"student_id"`
+	BookingID   *int64               `json:"booking_id,omitempty"`
+	Kind        LedgerEntryKind      `json:"kind"`
+	Amount      int                  `json:"amount"` // в минимальных единицах валюты
+	Currency    Currency             `json:"currency"`
+	Method      *LedgerPaymentMethod `json:"method,omitempty"`
+	Description string               `json:"description"`
+	OccurredAt  time.Time            `json:"occurred_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+
+	StudentName string `json:"student_name,omitempty"` // заполняется в выписках
+}
+
+// LedgerBalance итоги взаиморасчётов учителя и студента в одной валюте
+type LedgerBalance struct {
+	TeacherID int64    `json:"teacher_id"`
+	StudentID int64    `json:"student_id"`
+	Name      string   `json:"name"` // имя второй стороны: студента для учителя, учителя для студента
+	Currency  Currency `json:"currency"`
+	Charged   int      `json:"charged"`
+	Paid      int      `json:"paid"`
+}
+
+// Debt возвращает долг студента; отрицательное значение — переплата (аванс)
+func (b *LedgerBalance) Debt() int {
+	return b.Charged - b.Paid
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// LessonNote заметка учителя к занятию: текст, материалы и домашнее задание
+type LessonNote struct {
+	ID             int64      `json:"id"`
+	BookingID      int64      `json:"booking_id"`
+	TeacherID      int64      `json:"teacher_id"`
+	StudentID      int64      `json:"student_id"`
+	Text           string     `json:"text"`
+	PhotoFileID    string     `json:"photo_file_id"`    // фото (file_id Telegram)
+	DocumentFileID string     `json:"document_file_id"` // документ (file_id Telegram)
+	HomeworkDueAt  *time.Time `json:"homework_due_at"`  // срок домашнего задания
+	ReminderSentAt *time.Time `json:"reminder_sent_at"` // когда напомнили о сроке
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Дополнительные поля для удобства (заполняются при выборке)
+	SubjectName string    `json:"subject_name,omitempty"`
+	LessonStart time.Time `json:"lesson_start,omitempty"`
+}
+
+// HasAttachment проверяет, приложены ли к заметке фото или документ
+func (n *LessonNote) HasAttachment() bool {
+	return n.PhotoFileID != "" || n.DocumentFileID != ""
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// LessonPackage пакет занятий по предмету (например, 10 занятий по цене 9)
+type LessonPackage struct {
+	ID           int64     `json:"id"`
+	SubjectID    int64     `json:"subject_id"`
+	TeacherID    int64     `json:"teacher_id"`
+	LessonCount  int       `json:"lesson_count"`
+	Price        int       `json:"price"`         // в валюте пакета, за весь пакет
+	Currency     Currency  `json:"currency"`      // валюта предмета на момент создания пакета
+	ValidityDays int       `json:"validity_days"` // 0 = бессрочно
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PricePerLesson возвращает цену одного занятия в пакете
+func (p *LessonPackage) PricePerLesson() int {
+	return p.Price / p.LessonCount
+}
+
+// ExpiresAt возвращает срок действия занятий пакета, купленного в момент from (nil = бессрочно)
+func (p *LessonPackage) ExpiresAt(from time.Time) *time.Time {
+	if p.ValidityDays == 0 {
+		return nil
+	}
+	expiresAt := from.AddDate(0, 0, p.ValidityDays)
+	return &expiresAt
+}
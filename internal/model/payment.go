@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending PaymentStatus = "pending" // Счёт выставлен, ждём оплату
+	PaymentStatusPaid    PaymentStatus = "paid"    // Оплачен
+	PaymentStatusExpired PaymentStatus = "expired" // Не оплачен вовремя или запись отменена
+)
+
+// Payment счёт на оплату занятия
+type Payment struct {
+	ID               int64         `json:"id"`
+	BookingID        int64         `json:"booking_id"`
+	StudentID        int64         `json:"student_id"`
+	TeacherID        int64         `json:"teacher_id"`
+	Amount           int           `json:"amount"` // в копейках/центах
+	Currency         string        `json:"currency"`
+	Provider         string        `json:"provider"`
+	Payload          string        `json:"payload"` // invoice_payload счёта в Telegram
+	Status           PaymentStatus `json:"status"`
+	TelegramChargeID *string       `json:"telegram_charge_id,omitempty"`
+	ProviderChargeID *string       `json:"provider_charge_id,omitempty"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	PaidAt           *time.Time    `json:"paid_at,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// Invoice данные счёта, который отправляется студенту
+type Invoice struct {
+	ChatID      int64
+	Title       string
+	Description string
+	Payload     string
+	Currency    string
+	Amount      int // в копейках/центах
+	Label       string
+}
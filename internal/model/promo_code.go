@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// PromoDiscountType тип скидки промокода
+type PromoDiscountType string
+
+const (
+	PromoDiscountPercent PromoDiscountType = "percent" // Скидка в процентах
+	PromoDiscountFixed   PromoDiscountType = "fixed"   // Скидка фиксированной суммой в валюте промокода
+)
+
+// PromoCode представляет промокод учителя на скидку
+type PromoCode struct {
+	ID            int64             `json:"id"`
+	TeacherID     int64             `json:"teacher_id"`
+	Code          string            `json:"code"`
+	DiscountType  PromoDiscountType `json:"discount_type"`
+	DiscountValue int               `json:"discount_value"` // проценты или минимальные единицы валюты
+	Currency      *Currency         `json:"currency"`       // валюта фиксированной скидки
+	SubjectID     *int64            `json:"subject_id"`     // nil = все предметы учителя
+	MaxUses       *int              `json:"max_uses"`       // nil = безлимит
+	CurrentUses   int               `json:"current_uses"`
+	ExpiresAt     *time.Time        `json:"expires_at"` // nil = не истекает
+	IsActive      bool              `json:"is_active"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// IsValid проверяет, можно ли использовать промокод
+func (p *PromoCode) IsValid() bool {
+	if !p.IsActive {
+		return false
+	}
+
+	if p.ExpiresAt != nil && time.Now().After(*p.ExpiresAt) {
+		return false
+	}
+
+	if p.MaxUses != nil && p.CurrentUses >= *p.MaxUses {
+		return false
+	}
+
+	return true
+}
+
+// AppliesTo проверяет, действует ли промокод на предмет.
+// Фиксированная скидка действует только на предметы в той же валюте
+func (p *PromoCode) AppliesTo(subject *Subject) bool {
+	if subject.TeacherID != p.TeacherID {
+		return false
+	}
+	if p.DiscountType == PromoDiscountFixed && (p.Currency == nil || *p.Currency != subject.Currency) {
+		return false
+	}
+	return p.SubjectID == nil || *p.SubjectID == subject.ID
+}
+
+// Apply возвращает цену со скидкой; цена не опускается ниже нуля
+func (p *PromoCode) Apply(price int) int {
+	discounted := price
+	switch p.DiscountType {
+	case PromoDiscountPercent:
+		discounted = price - price*p.DiscountValue/100
+	case PromoDiscountFixed:
+		discounted = price - p.DiscountValue
+	}
+
+	if discounted < 0 {
+		return 0
+	}
+	return discounted
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// TeacherReport сводка работы учителя за период [From, To)
+type TeacherReport struct {
+	From time.Time
+	To   time.Time
+
+	LessonsHeld int // проведённые занятия
+	Canceled    int // отменённые записи
+	NoShows     int // неявки студентов
+
+	BookedMinutes int // время слотов, занятых студентами
+	FreeMinutes   int // время свободных слотов
+
+	Revenue     []*SubjectRevenue
+	Load        []*LessonLoad
+	TopStudents []*StudentActivity
+}
+
+// SubjectRevenue выручка по предмету в одной валюте
+type SubjectRevenue struct {
+	SubjectID   int64
+	SubjectName string
+	Currency    Currency
+	Lessons     int
+	Amount      int // в минимальных единицах валюты
+}
+
+// LessonLoad количество занятий в конкретный день недели и час
+type LessonLoad struct {
+	Weekday time.Weekday
+	Hour    int
+	Lessons int
+}
+
+// StudentActivity количество проведённых занятий студента
+type StudentActivity struct {
+	StudentID int64
+	Name      string
+	Lessons   int
+}
+
+// Utilization возвращает долю занятого времени среди открытых слотов (0..1)
+func (r *TeacherReport) Utilization() float64 {
+	total := r.BookedMinutes + r.FreeMinutes
+	if total == 0 {
+		return 0
+	}
+	return float64(r.BookedMinutes) / float64(total)
+}
+
+// RevenueByCurrency возвращает итог выручки по каждой валюте
+func (r *TeacherReport) RevenueByCurrency() map[Currency]int {
+	totals := make(map[Currency]int)
+	for _, revenue := range r.Revenue {
+		totals[revenue.Currency] += revenue.Amount
+	}
+	return totals
+}
+
+// LoadByWeekday возвращает количество занятий по дням недели, индекс — time.Weekday
+func (r *TeacherReport) LoadByWeekday() [7]int {
+	var load [7]int
+	for _, l := range r.Load {
+		load[l.Weekday] += l.Lessons
+	}
+	return load
+}
+
+// LoadByHour возвращает количество занятий по часу начала
+func (r *TeacherReport) LoadByHour() [24]int {
+	var load [24]int
+	for _, l := range r.Load {
+		load[l.Hour] += l.Lessons
+	}
+	return load
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+)
+
+// Review отзыв студента об учителе по завершённому занятию
+type Review struct {
+	ID           int64      `json:"id"`
+	BookingID    int64      `json:"booking_id"`
+	TeacherID    int64      `json:"teacher_id"`
+	StudentID    int64      `json:"student_id"`
+	Rating       int        `json:"rating"` // от MinReviewRating до MaxReviewRating
+	Comment      string     `json:"comment"`
+	TeacherReply string     `json:"teacher_reply"`
+	RepliedAt    *time.Time `json:"replied_at"`
+	ReportedAt   *time.Time `json:"reported_at"` // учитель пожаловался на отзыв
+	CreatedAt    time.Time  `json:"created_at"`
+
+	// Дополнительные поля для удобства (заполняются при выборке)
+	StudentName string `json:"student_name,omitempty"`
+	SubjectName string `json:"subject_name,omitempty"`
+}
+
+// IsReported проверяет, пожаловался ли учитель на отзыв
+func (r *Review) IsReported() bool {
+	return r.ReportedAt != nil
+}
+
+// TeacherRating сводная оценка учителя по отзывам
+type TeacherRating struct {
+	TeacherID int64   `json:"teacher_id"`
+	Average   float64 `json:"average"`
+	Count     int     `json:"count"`
+}
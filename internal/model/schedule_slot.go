@@ -19,5 +19,6 @@ type ScheduleSlot struct {
 	Status    SlotStatus `json:"status"`
 	StudentID *int64     `json:"student_id"`        // указатель - может быть nil
 	Comment   *string    `json:"comment,omitempty"` // комментарий преподавателя
+	GroupID   *int64     `json:"group_id"`          // слот доступен для записи только участникам группы
 	CreatedAt time.Time  `json:"created_at"`
 }
@@ -0,0 +1,27 @@
+package model
+
+// SlotBulkAction действие над группой слотов
+type SlotBulkAction string
+
+const (
+	SlotBulkCancel  SlotBulkAction = "cancel"  // Отменить (бронирования студентов отменяются)
+	SlotBulkRestore SlotBulkAction = "restore" // Восстановить отменённые
+	SlotBulkBusy    SlotBulkAction = "busy"    // Пометить свободные занятыми
+	SlotBulkDelete  SlotBulkAction = "delete"  // Удалить
+)
+
+// IsValid проверяет, что действие поддерживается
+func (a SlotBulkAction) IsValid() bool {
+	switch a {
+	case SlotBulkCancel, SlotBulkRestore, SlotBulkBusy, SlotBulkDelete:
+		return true
+	}
+	return false
+}
+
+// SlotBulkResult результат применения действия к группе слотов
+type SlotBulkResult struct {
+	Processed        []*ScheduleSlot // слоты, к которым применено действие
+	Skipped          []*ScheduleSlot // слоты, к которым действие неприменимо (статус, прошлое, пересечение)
+	CanceledBookings []*Booking      // отменённые бронирования студентов (Slot заполнен)
+}
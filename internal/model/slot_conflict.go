@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// SlotConflict описывает слот, который не был создан из-за пересечения
+// с уже существующим слотом учителя
+type SlotConflict struct {
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Existing  *ScheduleSlot `json:"existing,omitempty"` // может быть nil, если пересечение обнаружено только на уровне БД
+}
+
+// SlotOverlapAction что сделано с пересекающимся слотом при введении запрета на пересечения
+type SlotOverlapAction string
+
+const (
+	SlotOverlapCanceled SlotOverlapAction = "canceled" // свободный слот отменён
+	SlotOverlapExempt   SlotOverlapAction = "exempt"   // занятый слот оставлен и исключён из проверки
+)
+
+// SlotOverlapResolution слот учителя, изменённый при введении запрета на пересекающиеся слоты
+type SlotOverlapResolution struct {
+	ID        int64             `json:"id"`
+	TeacherID int64             `json:"teacher_id"`
+	Action    SlotOverlapAction `json:"action"`
+	Slot      *ScheduleSlot     `json:"slot"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// SlotCopyPlan описывает результат (или план) копирования слотов на другие недели
+type SlotCopyPlan struct {
+	Slots     []*ScheduleSlot // слоты, которые будут созданы (после выполнения — созданные)
+	Skipped   []*ScheduleSlot // копии, которые уже существуют или попадают в прошлое
+	Conflicts []SlotConflict  // копии, пересекающиеся с другими слотами учителя
+	Failed    []*ScheduleSlot // копии, которые не удалось создать из-за ошибки
+}
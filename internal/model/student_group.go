@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// StudentGroup группа студентов учителя, например класс или кружок
+type StudentGroup struct {
+	ID           int64     `json:"id"`
+	TeacherID    int64     `json:"teacher_id"`
+	Name         string    `json:"name"`
+	MembersCount int       `json:"members_count"` // заполняется при выборке списка групп
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -4,16 +4,28 @@ import "time"
 
 // StudentTeacherAccess represents access relationship between student and private teacher
 type StudentTeacherAccess struct {
-	ID         int64     `json:"id"`
-	StudentID  int64     `json:"student_id"`
-	TeacherID  int64     `json:"teacher_id"`
-	AccessType string    `json:"access_type"` // 'invited', 'approved', 'subscribed'
-	GrantedAt  time.Time `json:"granted_at"`
+	ID             int64      `json:"id"`
+	StudentID      int64      `json:"student_id"`
+	TeacherID      int64      `json:"teacher_id"`
+	AccessType     string     `json:"access_type"` // 'invited', 'approved', 'subscribed'
+	GrantedAt      time.Time  `json:"granted_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`       // nil - access never expires
+	ExpiryWarnedAt *time.Time `json:"expiry_warned_at,omitempty"` // when the student was warned about expiry
 }
 
 // Access type constants
 const (
 	AccessTypeInvited    = "invited"    // Access granted via invite code
 	AccessTypeApproved   = "approved"   // Access granted via approved request
-	AccessTypeSubscribed = "subscribed" // Access granted via subscription (future)
+	AccessTypeSubscribed = "subscribed" // Time-limited access granted via subscription
 )
+
+// IsSubscription checks if access is a time-limited subscription
+func (a *StudentTeacherAccess) IsSubscription() bool {
+	return a.AccessType == AccessTypeSubscribed
+}
+
+// IsExpired checks if access has expired at the given moment
+func (a *StudentTeacherAccess) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !a.ExpiresAt.After(now)
+}
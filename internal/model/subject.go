@@ -7,9 +7,66 @@ type Subject struct {
 	TeacherID               int64     `json:"teacher_id"`
 	Name                    string    `json:"name"`
 	Description             string    `json:"description"`
-	Price                   int       `json:"price"`    // в копейках/центах
+	Price                   int       `json:"price"`    // в минимальных единицах валюты (копейках/центах)
+	Currency                Currency  `json:"currency"` // валюта цены предмета и его пакетов
 	Duration                int       `json:"duration"` // в минутах
 	IsActive                bool      `json:"is_active"`
 	RequiresBookingApproval bool      `json:"requires_booking_approval"` // требуется ли одобрение для записи
 	CreatedAt               time.Time `json:"created_at"`
+
+	// Правила записи (0 = ограничение не действует)
+	MinNoticeMinutes   int `json:"min_notice_minutes"`    // минимум минут до начала занятия
+	MaxAdvanceDays     int `json:"max_advance_days"`      // за сколько дней вперёд открывается запись
+	MaxActiveBookings  int `json:"max_active_bookings"`   // максимум активных будущих записей студента
+	MaxBookingsPerWeek int `json:"max_bookings_per_week"` // максимум записей студента в неделю
+
+	// Перерыв учителя до и после занятия, в минутах
+	BufferBeforeMinutes int `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int `json:"buffer_after_minutes"`
+
+	// Предоплата: запись подтверждается только после оплаты счёта в Telegram
+	RequiresPrepayment bool `json:"requires_prepayment"`
+
+	// Политика отмены: за сколько часов до начала отмена студентом возвращает занятие в пакет (0 = в любое время)
+	FreeCancelHours int `json:"free_cancel_hours"`
+
+	// Категория (код из SubjectCategories, пустая строка — без категории) и свободные теги для поиска
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+}
+
+// BookingWindow возвращает интервал времени начала слотов, на которые сейчас открыта запись.
+// Нулевой to означает, что верхней границы нет.
+func (s *Subject) BookingWindow(now time.Time) (from, to time.Time) {
+	from = now.Add(time.Duration(s.MinNoticeMinutes) * time.Minute)
+	if s.MaxAdvanceDays > 0 {
+		to = now.AddDate(0, 0, s.MaxAdvanceDays)
+	}
+	return from, to
+}
+
+// HasBookingRules проверяет, задано ли хотя бы одно правило записи
+func (s *Subject) HasBookingRules() bool {
+	return s.MinNoticeMinutes > 0 || s.MaxAdvanceDays > 0 || s.MaxActiveBookings > 0 || s.MaxBookingsPerWeek > 0 ||
+		s.FreeCancelHours > 0
+}
+
+// HasBuffer проверяет, задан ли перерыв до или после занятия
+func (s *Subject) HasBuffer() bool {
+	return s.BufferBeforeMinutes > 0 || s.BufferAfterMinutes > 0
+}
+
+// SlotStep возвращает шаг в минутах между началами соседних слотов с учётом перерывов
+func (s *Subject) SlotStep() int {
+	return s.Duration + s.BufferAfterMinutes + s.BufferBeforeMinutes
+}
+
+// NeedsPrepayment проверяет, нужно ли оплатить занятие при записи
+func (s *Subject) NeedsPrepayment() bool {
+	return s.RequiresPrepayment && s.Price > 0
+}
+
+// IsFreeCancel проверяет, укладывается ли отмена занятия, начинающегося в start, в политику отмены
+func (s *Subject) IsFreeCancel(start, now time.Time) bool {
+	return !now.Add(time.Duration(s.FreeCancelHours) * time.Hour).After(start)
 }
@@ -0,0 +1,50 @@
+package model
+
+// SubjectCategory категория предмета
+type SubjectCategory struct {
+	Code  string
+	Title string
+	Emoji string
+}
+
+// SubjectCategories список категорий, из которых учитель выбирает категорию предмета.
+// Названия участвуют в полнотекстовом поиске: SubjectRepository.Search передаёт их в запрос
+var SubjectCategories = []SubjectCategory{
+	{Code: "languages", Title: "Иностранные языки", Emoji: "🌐"},
+	{Code: "math", Title: "Математика", Emoji: "📐"},
+	{Code: "science", Title: "Естественные науки", Emoji: "🔬"},
+	{Code: "humanities", Title: "Гуманитарные предметы", Emoji: "📖"},
+	{Code: "it", Title: "Программирование", Emoji: "💻"},
+	{Code: "exams", Title: "Подготовка к экзаменам", Emoji: "🎓"},
+	{Code: "music", Title: "Музыка", Emoji: "🎵"},
+	{Code: "art", Title: "Искусство", Emoji: "🎨"},
+}
+
+// FindSubjectCategory находит категорию по коду
+func FindSubjectCategory(code string) (SubjectCategory, bool) {
+	for _, category := range SubjectCategories {
+		if category.Code == code {
+			return category, true
+		}
+	}
+	return SubjectCategory{}, false
+}
+
+// SubjectSearchFilter параметры поиска предметов студентом (нулевые значения — без ограничения)
+type SubjectSearchFilter struct {
+	Query        string
+	Category     string
+	Currency     Currency // валюта диапазона цен: предметы в других валютах не показываются
+	MinPrice     int      // в минимальных единицах Currency
+	MaxPrice     int      // в минимальных единицах Currency
+	MaxDuration  int      // в минутах
+	HasFreeSlots bool     // только предметы со свободными слотами на ближайшую неделю
+}
+
+// SubjectSearchResult найденный предмет вместе с учителем и релевантностью
+type SubjectSearchResult struct {
+	Subject     *Subject
+	TeacherName string
+	Rank        float64
+	FreeSlots   int // свободных слотов на ближайшую неделю
+}
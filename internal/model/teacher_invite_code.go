@@ -9,7 +9,9 @@ type TeacherInviteCode struct {
 	Code        string     `json:"code"`
 	MaxUses     *int       `json:"max_uses"` // nil = unlimited uses
 	CurrentUses int        `json:"current_uses"`
-	ExpiresAt   *time.Time `json:"expires_at"` // nil = never expires
+	ExpiresAt   *time.Time `json:"expires_at"`  // nil = never expires
+	AccessDays  *int       `json:"access_days"` // access granted by the code lasts this many days; nil = forever
+	GroupID     *int64     `json:"group_id"`    // student joins this group when using the code; nil = no group
 	IsActive    bool       `json:"is_active"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
@@ -37,3 +39,13 @@ func (t *TeacherInviteCode) IsValid() bool {
 func (t *TeacherInviteCode) CanUse() bool {
 	return t.IsValid()
 }
+
+// AccessExpiresAt returns when access granted by the code at the given moment expires; nil = never
+func (t *TeacherInviteCode) AccessExpiresAt(now time.Time) *time.Time {
+	if t.AccessDays == nil {
+		return nil
+	}
+
+	expiresAt := now.AddDate(0, 0, *t.AccessDays)
+	return &expiresAt
+}
@@ -12,5 +12,6 @@ type User struct {
 	IsTeacher           bool      `json:"is_teacher"`
 	IsPublic            bool      `json:"is_public"`             // Публичный учитель (виден всем) или приватный (нужен доступ)
 	AutoApproveBookings bool      `json:"auto_approve_bookings"` // Автоматически одобрять записи
+	DefaultCurrency     Currency  `json:"default_currency"`      // Валюта новых предметов учителя
 	CreatedAt           time.Time `json:"created_at"`
 }
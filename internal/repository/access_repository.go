@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/jackc/pgx/v5"
@@ -17,12 +18,33 @@ func NewAccessRepository(pool *pgxpool.Pool) *AccessRepository {
 	return &AccessRepository{pool: pool}
 }
 
-// HasAccess проверяет, есть ли у студента доступ к учителю
+const accessColumns = `id, student_id, teacher_id, access_type, granted_at, expires_at, expiry_warned_at`
+
+// scanAccess читает доступ из строки результата в порядке accessColumns
+func scanAccess(row pgx.Row) (*model.StudentTeacherAccess, error) {
+	var access model.StudentTeacherAccess
+	err := row.Scan(
+		&access.ID,
+		&access.StudentID,
+		&access.TeacherID,
+		&access.AccessType,
+		&access.GrantedAt,
+		&access.ExpiresAt,
+		&access.ExpiryWarnedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// HasAccess проверяет, есть ли у студента действующий доступ к учителю
 func (r *AccessRepository) HasAccess(ctx context.Context, studentID, teacherID int64) (bool, error) {
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM student_teacher_access
 			WHERE student_id = $1 AND teacher_id = $2
+			  AND (expires_at IS NULL OR expires_at > NOW())
 		)
 	`
 
@@ -35,15 +57,18 @@ func (r *AccessRepository) HasAccess(ctx context.Context, studentID, teacherID i
 	return exists, nil
 }
 
-// GrantAccess предоставляет доступ студенту к учителю
-func (r *AccessRepository) GrantAccess(ctx context.Context, studentID, teacherID int64, accessType string) error {
+// GrantAccess предоставляет студенту доступ к учителю до expiresAt (nil - бессрочно).
+// Истёкший доступ заменяется новым, действующий не меняется
+func (r *AccessRepository) GrantAccess(ctx context.Context, studentID, teacherID int64, accessType string, expiresAt *time.Time) error {
 	query := `
-		INSERT INTO student_teacher_access (student_id, teacher_id, access_type)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (student_id, teacher_id) DO NOTHING
+		INSERT INTO student_teacher_access (student_id, teacher_id, access_type, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (student_id, teacher_id) DO UPDATE
+		SET access_type = EXCLUDED.access_type, granted_at = NOW(), expires_at = EXCLUDED.expires_at, expiry_warned_at = NULL
+		WHERE student_teacher_access.expires_at IS NOT NULL AND student_teacher_access.expires_at <= NOW()
 	`
 
-	_, err := r.pool.Exec(ctx, query, studentID, teacherID, accessType)
+	_, err := r.pool.Exec(ctx, query, studentID, teacherID, accessType, expiresAt)
 	if err != nil {
 		return fmt.Errorf("grant access: %w", err)
 	}
@@ -70,12 +95,12 @@ func (r *AccessRepository) RevokeAccess(ctx context.Context, studentID, teacherI
 	return nil
 }
 
-// GetStudentTeacherIDs получает ID всех учителей студента
+// GetStudentTeacherIDs получает ID всех учителей студента с действующим доступом
 func (r *AccessRepository) GetStudentTeacherIDs(ctx context.Context, studentID int64) ([]int64, error) {
 	query := `
 		SELECT teacher_id
 		FROM student_teacher_access
-		WHERE student_id = $1
+		WHERE student_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
 		ORDER BY granted_at DESC
 	`
 
@@ -134,21 +159,9 @@ func (r *AccessRepository) GetTeacherStudentIDs(ctx context.Context, teacherID i
 
 // GetAccessInfo получает информацию о доступе
 func (r *AccessRepository) GetAccessInfo(ctx context.Context, studentID, teacherID int64) (*model.StudentTeacherAccess, error) {
-	query := `
-		SELECT id, student_id, teacher_id, access_type, granted_at
-		FROM student_teacher_access
-		WHERE student_id = $1 AND teacher_id = $2
-	`
-
-	var access model.StudentTeacherAccess
-	err := r.pool.QueryRow(ctx, query, studentID, teacherID).Scan(
-		&access.ID,
-		&access.StudentID,
-		&access.TeacherID,
-		&access.AccessType,
-		&access.GrantedAt,
-	)
+	query := `SELECT ` + accessColumns + ` FROM student_teacher_access WHERE student_id = $1 AND teacher_id = $2`
 
+	access, err := scanAccess(r.pool.QueryRow(ctx, query, studentID, teacherID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -156,38 +169,32 @@ func (r *AccessRepository) GetAccessInfo(ctx context.Context, studentID, teacher
 		return nil, fmt.Errorf("get access info: %w", err)
 	}
 
-	return &access, nil
+	return access, nil
 }
 
 // GetStudentAccessList получает полный список доступов студента
 func (r *AccessRepository) GetStudentAccessList(ctx context.Context, studentID int64) ([]*model.StudentTeacherAccess, error) {
-	query := `
-		SELECT id, student_id, teacher_id, access_type, granted_at
-		FROM student_teacher_access
-		WHERE student_id = $1
-		ORDER BY granted_at DESC
-	`
+	query := `SELECT ` + accessColumns + ` FROM student_teacher_access WHERE student_id = $1 ORDER BY granted_at DESC`
 
 	rows, err := r.pool.Query(ctx, query, studentID)
 	if err != nil {
 		return nil, fmt.Errorf("get student access list: %w", err)
 	}
+
+	return collectAccessRows(rows)
+}
+
+// collectAccessRows читает все доступы из результата запроса
+func collectAccessRows(rows pgx.Rows) ([]*model.StudentTeacherAccess, error) {
 	defer rows.Close()
 
 	var accessList []*model.StudentTeacherAccess
 	for rows.Next() {
-		var access model.StudentTeacherAccess
-		err := rows.Scan(
-			&access.ID,
-			&access.StudentID,
-			&access.TeacherID,
-			&access.AccessType,
-			&access.GrantedAt,
-		)
+		access, err := scanAccess(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan access: %w", err)
 		}
-		accessList = append(accessList, &access)
+		accessList = append(accessList, access)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -213,3 +220,59 @@ func (r *AccessRepository) CountTeacherStudents(ctx context.Context, teacherID i
 
 	return count, nil
 }
+
+// SetSubscription оформляет или продлевает подписку студента до expiresAt.
+// Предупреждение об окончании сбрасывается
+func (r *AccessRepository) SetSubscription(ctx context.Context, studentID, teacherID int64, expiresAt time.Time) (*model.StudentTeacherAccess, error) {
+	query := `
+		INSERT INTO student_teacher_access (student_id, teacher_id, access_type, expires_at)
+		VALUES ($1, $2, 'subscribed', $3)
+		ON CONFLICT (student_id, teacher_id) DO UPDATE
+		SET access_type = 'subscribed', expires_at = EXCLUDED.expires_at, expiry_warned_at = NULL
+		RETURNING ` + accessColumns
+
+	access, err := scanAccess(r.pool.QueryRow(ctx, query, studentID, teacherID, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("set subscription: %w", err)
+	}
+
+	return access, nil
+}
+
+// GetExpiringUnwarned получает доступы, истекающие до warnBefore, о которых студент ещё не предупреждён
+func (r *AccessRepository) GetExpiringUnwarned(ctx context.Context, now, warnBefore time.Time) ([]*model.StudentTeacherAccess, error) {
+	query := `SELECT ` + accessColumns + ` FROM student_teacher_access
+		WHERE expires_at > $1 AND expires_at <= $2 AND expiry_warned_at IS NULL
+		ORDER BY expires_at`
+
+	rows, err := r.pool.Query(ctx, query, now, warnBefore)
+	if err != nil {
+		return nil, fmt.Errorf("get expiring access: %w", err)
+	}
+
+	return collectAccessRows(rows)
+}
+
+// MarkExpiryWarned отмечает, что студент предупреждён об окончании доступа
+func (r *AccessRepository) MarkExpiryWarned(ctx context.Context, accessID int64) error {
+	query := `UPDATE student_teacher_access SET expiry_warned_at = NOW() WHERE id = $1`
+
+	_, err := r.pool.Exec(ctx, query, accessID)
+	if err != nil {
+		return fmt.Errorf("mark expiry warned: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired удаляет истёкшие доступы и возвращает их
+func (r *AccessRepository) DeleteExpired(ctx context.Context, now time.Time) ([]*model.StudentTeacherAccess, error) {
+	query := `DELETE FROM student_teacher_access WHERE expires_at <= $1 RETURNING ` + accessColumns
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("delete expired access: %w", err)
+	}
+
+	return collectAccessRows(rows)
+}
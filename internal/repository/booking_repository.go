@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/jackc/pgx/v5"
@@ -17,23 +18,40 @@ func NewBookingRepository(pool *pgxpool.Pool) *BookingRepository {
 	return &BookingRepository{pool: pool}
 }
 
+// createBookingQuery добавляет бронирование и возвращает его ID и время создания
+const createBookingQuery = `
+	INSERT INTO bookings (student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at, updated_at
+`
+
 // Create создаёт новое бронирование
 func (r *BookingRepository) Create(ctx context.Context, booking *model.Booking) error {
-	query := `
-		INSERT INTO bookings (student_id, teacher_id, subject_id, slot_id, status)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
-	`
+	return createBookingRow(r.pool.QueryRow(ctx, createBookingQuery, createBookingArgs(booking)...), booking)
+}
 
-	err := r.pool.QueryRow(
-		ctx, query,
+// createBookingTx создаёт бронирование внутри транзакции
+func createBookingTx(ctx context.Context, tx pgx.Tx, booking *model.Booking) error {
+	return createBookingRow(tx.QueryRow(ctx, createBookingQuery, createBookingArgs(booking)...), booking)
+}
+
+// createBookingArgs параметры createBookingQuery
+func createBookingArgs(booking *model.Booking) []interface{} {
+	return []interface{}{
 		booking.StudentID,
 		booking.TeacherID,
 		booking.SubjectID,
 		booking.SlotID,
 		booking.Status,
-	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
+		booking.Price,
+		booking.Currency,
+		booking.PromoCodeID,
+	}
+}
 
+// createBookingRow заполняет бронирование результатом createBookingQuery
+func createBookingRow(row pgx.Row, booking *model.Booking) error {
+	err := row.Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create booking: %w", err)
 	}
@@ -44,7 +62,7 @@ func (r *BookingRepository) Create(ctx context.Context, booking *model.Booking)
 // GetByID получает бронирование по ID
 func (r *BookingRepository) GetByID(ctx context.Context, id int64) (*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
 		WHERE id = $1
 	`
@@ -57,6 +75,9 @@ func (r *BookingRepository) GetByID(ctx context.Context, id int64) (*model.Booki
 		&booking.SubjectID,
 		&booking.SlotID,
 		&booking.Status,
+		&booking.Price,
+		&booking.Currency,
+		&booking.PromoCodeID,
 		&booking.CreatedAt,
 		&booking.UpdatedAt,
 	)
@@ -74,7 +95,7 @@ func (r *BookingRepository) GetByID(ctx context.Context, id int64) (*model.Booki
 // GetByStudentID получает все бронирования студента
 func (r *BookingRepository) GetByStudentID(ctx context.Context, studentID int64) ([]*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
 		WHERE student_id = $1
 		ORDER BY created_at DESC
@@ -96,6 +117,9 @@ func (r *BookingRepository) GetByStudentID(ctx context.Context, studentID int64)
 			&booking.SubjectID,
 			&booking.SlotID,
 			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
 		)
@@ -108,10 +132,157 @@ func (r *BookingRepository) GetByStudentID(ctx context.Context, studentID int64)
 	return bookings, nil
 }
 
+// GetUpcomingActiveByStudentAndTeacher получает активные бронирования студента у учителя на слоты, начинающиеся после after
+func (r *BookingRepository) GetUpcomingActiveByStudentAndTeacher(ctx context.Context, studentID, teacherID int64, after time.Time) ([]*model.Booking, error) {
+	query := `
+		SELECT b.id, b.student_id, b.teacher_id, b.subject_id, b.slot_id, b.status, b.price, b.currency, b.promo_code_id, b.created_at, b.updated_at
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		WHERE b.student_id = $1 AND b.teacher_id = $2 AND s.start_time > $3
+		  AND b.status IN ('pending', 'confirmed', 'awaiting_payment')
+		ORDER BY s.start_time
+	`
+
+	rows, err := r.pool.Query(ctx, query, studentID, teacherID, after)
+	if err != nil {
+		return nil, fmt.Errorf("get upcoming bookings by student and teacher: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*model.Booking
+	for rows.Next() {
+		var booking model.Booking
+		err := rows.Scan(
+			&booking.ID,
+			&booking.StudentID,
+			&booking.TeacherID,
+			&booking.SubjectID,
+			&booking.SlotID,
+			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan booking: %w", err)
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// lessonSelect выбирает занятие вместе со слотом, предметом и вторым участником u: учителем или студентом
+const lessonSelect = `
+	SELECT b.id, b.student_id, b.teacher_id, b.subject_id, b.slot_id, b.status, b.price, b.currency, b.promo_code_id,
+	       COALESCE(b.cancellation_requested, false), b.cancellation_requested_at, b.created_at, b.updated_at,
+	       s.start_time, s.end_time, s.status,
+	       sub.name,
+	       u.first_name, u.last_name, COALESCE(u.username, '')
+	FROM bookings b
+	JOIN schedule_slots s ON s.id = b.slot_id
+	JOIN subjects sub ON sub.id = b.subject_id
+`
+
+// lessonStatuses статусы записей, которые показываются в расписании занятий
+const lessonStatuses = `('pending', 'confirmed', 'awaiting_payment', 'completed')`
+
+// queryLessons выполняет запрос на основе lessonSelect. Второй участник попадает
+// в Teacher или в Student в зависимости от withStudent
+func (r *BookingRepository) queryLessons(ctx context.Context, query string, withStudent bool, args ...interface{}) ([]*model.Booking, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get lessons: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*model.Booking
+	for rows.Next() {
+		booking := model.Booking{
+			Slot:    &model.ScheduleSlot{},
+			Subject: &model.Subject{},
+		}
+		participant := &model.User{}
+		err := rows.Scan(
+			&booking.ID,
+			&booking.StudentID,
+			&booking.TeacherID,
+			&booking.SubjectID,
+			&booking.SlotID,
+			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
+			&booking.CancellationRequested,
+			&booking.CancellationRequestedAt,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+			&booking.Slot.StartTime,
+			&booking.Slot.EndTime,
+			&booking.Slot.Status,
+			&booking.Subject.Name,
+			&participant.FirstName,
+			&participant.LastName,
+			&participant.Username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan lesson: %w", err)
+		}
+		booking.Slot.ID = booking.SlotID
+		booking.Slot.SubjectID = booking.SubjectID
+		booking.Slot.TeacherID = booking.TeacherID
+		booking.Subject.ID = booking.SubjectID
+		booking.Subject.TeacherID = booking.TeacherID
+		if withStudent {
+			participant.ID = booking.StudentID
+			booking.Student = participant
+		} else {
+			participant.ID = booking.TeacherID
+			participant.IsTeacher = true
+			booking.Teacher = participant
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// GetStudentLessonsInRange получает активные и проведённые занятия студента у всех учителей,
+// начинающиеся в диапазоне [from, to), вместе со слотом, предметом и учителем
+func (r *BookingRepository) GetStudentLessonsInRange(ctx context.Context, studentID int64, from, to time.Time) ([]*model.Booking, error) {
+	query := lessonSelect + `
+		JOIN users u ON u.id = b.teacher_id
+		WHERE b.student_id = $1
+		  AND s.start_time >= $2
+		  AND s.start_time < $3
+		  AND b.status IN ` + lessonStatuses + `
+		ORDER BY s.start_time, b.id
+	`
+
+	return r.queryLessons(ctx, query, false, studentID, from, to)
+}
+
+// GetTeacherLessonsInRange получает активные и проведённые занятия учителя по всем предметам,
+// начинающиеся в диапазоне [from, to), вместе со слотом, предметом и студентом
+func (r *BookingRepository) GetTeacherLessonsInRange(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.Booking, error) {
+	query := lessonSelect + `
+		JOIN users u ON u.id = b.student_id
+		WHERE b.teacher_id = $1
+		  AND s.start_time >= $2
+		  AND s.start_time < $3
+		  AND b.status IN ` + lessonStatuses + `
+		ORDER BY s.start_time, b.id
+	`
+
+	return r.queryLessons(ctx, query, true, teacherID, from, to)
+}
+
 // GetByTeacherID получает все бронирования для учителя
 func (r *BookingRepository) GetByTeacherID(ctx context.Context, teacherID int64) ([]*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
 		WHERE teacher_id = $1
 		ORDER BY created_at DESC
@@ -133,6 +304,9 @@ func (r *BookingRepository) GetByTeacherID(ctx context.Context, teacherID int64)
 			&booking.SubjectID,
 			&booking.SlotID,
 			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
 		)
@@ -168,9 +342,9 @@ func (r *BookingRepository) UpdateStatus(ctx context.Context, id int64, status m
 // GetBySlotID получает активное бронирование для слота
 func (r *BookingRepository) GetBySlotID(ctx context.Context, slotID int64) (*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
-		WHERE slot_id = $1 AND (status = 'confirmed' OR status = 'pending')
+		WHERE slot_id = $1 AND status IN ('pending', 'confirmed', 'awaiting_payment')
 		LIMIT 1
 	`
 
@@ -182,6 +356,9 @@ func (r *BookingRepository) GetBySlotID(ctx context.Context, slotID int64) (*mod
 		&booking.SubjectID,
 		&booking.SlotID,
 		&booking.Status,
+		&booking.Price,
+		&booking.Currency,
+		&booking.PromoCodeID,
 		&booking.CreatedAt,
 		&booking.UpdatedAt,
 	)
@@ -196,10 +373,65 @@ func (r *BookingRepository) GetBySlotID(ctx context.Context, slotID int64) (*mod
 	return &booking, nil
 }
 
+// GetLastBySlotID получает последнее не отменённое бронирование слота, в том числе прошедшее занятие
+func (r *BookingRepository) GetLastBySlotID(ctx context.Context, slotID int64) (*model.Booking, error) {
+	query := `
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
+		FROM bookings
+		WHERE slot_id = $1 AND status NOT IN ('canceled', 'rejected')
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var booking model.Booking
+	err := r.pool.QueryRow(ctx, query, slotID).Scan(
+		&booking.ID,
+		&booking.StudentID,
+		&booking.TeacherID,
+		&booking.SubjectID,
+		&booking.SlotID,
+		&booking.Status,
+		&booking.Price,
+		&booking.Currency,
+		&booking.PromoCodeID,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get last booking by slot: %w", err)
+	}
+
+	return &booking, nil
+}
+
+// MarkNoShowBySlot отмечает неявку студента на проведённое занятие в слоте: завершённое
+// или подтверждённое, но уже закончившееся. Возвращает false, если такой записи учителя в слоте нет
+func (r *BookingRepository) MarkNoShowBySlot(ctx context.Context, slotID, teacherID int64) (bool, error) {
+	query := `
+		UPDATE bookings b
+		SET status = 'no_show'
+		FROM schedule_slots s
+		WHERE b.slot_id = s.id
+		  AND b.slot_id = $1 AND b.teacher_id = $2
+		  AND ` + heldLessonCondition + `
+	`
+
+	result, err := r.pool.Exec(ctx, query, slotID, teacherID)
+	if err != nil {
+		return false, fmt.Errorf("mark booking no-show: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 // GetPendingByTeacherID получает все pending бронирования учителя
 func (r *BookingRepository) GetPendingByTeacherID(ctx context.Context, teacherID int64) ([]*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
 		WHERE teacher_id = $1 AND status = 'pending'
 		ORDER BY created_at ASC
@@ -221,6 +453,9 @@ func (r *BookingRepository) GetPendingByTeacherID(ctx context.Context, teacherID
 			&booking.SubjectID,
 			&booking.SlotID,
 			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
 		)
@@ -236,9 +471,9 @@ func (r *BookingRepository) GetPendingByTeacherID(ctx context.Context, teacherID
 // GetBySubjectID получает все активные бронирования для предмета
 func (r *BookingRepository) GetBySubjectID(ctx context.Context, subjectID int64) ([]*model.Booking, error) {
 	query := `
-		SELECT id, student_id, teacher_id, subject_id, slot_id, status, created_at, updated_at
+		SELECT id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
 		FROM bookings
-		WHERE subject_id = $1 AND (status = 'confirmed' OR status = 'pending')
+		WHERE subject_id = $1 AND status IN ('pending', 'confirmed', 'awaiting_payment')
 		ORDER BY created_at DESC
 	`
 
@@ -258,6 +493,9 @@ func (r *BookingRepository) GetBySubjectID(ctx context.Context, subjectID int64)
 			&booking.SubjectID,
 			&booking.SlotID,
 			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
 		)
@@ -270,6 +508,51 @@ func (r *BookingRepository) GetBySubjectID(ctx context.Context, subjectID int64)
 	return bookings, nil
 }
 
+// CountActiveByStudentAndSubject подсчитывает активные записи студента на предмет,
+// занятие по которым ещё не началось
+func (r *BookingRepository) CountActiveByStudentAndSubject(ctx context.Context, studentID, subjectID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM bookings b
+		INNER JOIN schedule_slots s ON b.slot_id = s.id
+		WHERE b.student_id = $1
+		  AND b.subject_id = $2
+		  AND b.status IN ('pending', 'confirmed', 'awaiting_payment')
+		  AND s.start_time > NOW()
+	`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, studentID, subjectID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count active bookings by student and subject: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountByStudentAndSubjectInRange подсчитывает неотменённые записи студента на предмет,
+// занятия по которым начинаются в интервале [from, to)
+func (r *BookingRepository) CountByStudentAndSubjectInRange(ctx context.Context, studentID, subjectID int64, from, to time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM bookings b
+		INNER JOIN schedule_slots s ON b.slot_id = s.id
+		WHERE b.student_id = $1
+		  AND b.subject_id = $2
+		  AND b.status IN ('pending', 'confirmed', 'awaiting_payment', 'completed')
+		  AND s.start_time >= $3
+		  AND s.start_time < $4
+	`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, studentID, subjectID, from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count bookings by student and subject in range: %w", err)
+	}
+
+	return count, nil
+}
+
 // Delete удаляет бронирование
 func (r *BookingRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM bookings WHERE id = $1`
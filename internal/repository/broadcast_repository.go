@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BroadcastRepository подбирает получателей рассылок учителя
+type BroadcastRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBroadcastRepository(pool *pgxpool.Pool) *BroadcastRepository {
+	return &BroadcastRepository{pool: pool}
+}
+
+// GetSubjectStudentIDs возвращает студентов, которые записывались на предмет учителя (кроме отменённых и отклонённых записей)
+func (r *BroadcastRepository) GetSubjectStudentIDs(ctx context.Context, teacherID, subjectID int64) ([]int64, error) {
+	query := `
+		SELECT DISTINCT student_id
+		FROM bookings
+		WHERE teacher_id = $1 AND subject_id = $2
+		  AND status NOT IN ('canceled', 'rejected')
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("query subject students: %w", err)
+	}
+
+	return collectStudentIDs(rows)
+}
+
+// GetBookedStudentIDs возвращает студентов с записями к учителю на занятия, начинающиеся в [from, to)
+func (r *BroadcastRepository) GetBookedStudentIDs(ctx context.Context, teacherID int64, from, to time.Time) ([]int64, error) {
+	query := `
+		SELECT DISTINCT b.student_id
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		WHERE b.teacher_id = $1
+		  AND s.start_time >= $2 AND s.start_time < $3
+		  AND b.status NOT IN ('canceled', 'rejected')
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query booked students: %w", err)
+	}
+
+	return collectStudentIDs(rows)
+}
+
+// collectStudentIDs читает колонку ID студентов и закрывает rows
+func collectStudentIDs(rows pgx.Rows) ([]int64, error) {
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan student id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
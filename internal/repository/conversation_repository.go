@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ConversationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewConversationRepository(pool *pgxpool.Pool) *ConversationRepository {
+	return &ConversationRepository{pool: pool}
+}
+
+const conversationColumns = `id, teacher_id, student_id, teacher_muted, student_muted, last_message_at, created_at`
+
+func scanConversation(row pgx.Row) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := row.Scan(
+		&conversation.ID,
+		&conversation.TeacherID,
+		&conversation.StudentID,
+		&conversation.TeacherMuted,
+		&conversation.StudentMuted,
+		&conversation.LastMessageAt,
+		&conversation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetOrCreate получает переписку учителя и студента, создавая её при первом обращении
+func (r *ConversationRepository) GetOrCreate(ctx context.Context, teacherID, studentID int64) (*model.Conversation, error) {
+	query := `
+		INSERT INTO conversations (teacher_id, student_id)
+		VALUES ($1, $2)
+		ON CONFLICT (teacher_id, student_id) DO UPDATE SET teacher_id = EXCLUDED.teacher_id
+		RETURNING ` + conversationColumns
+
+	conversation, err := scanConversation(r.pool.QueryRow(ctx, query, teacherID, studentID))
+	if err != nil {
+		return nil, fmt.Errorf("get or create conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// GetByID получает переписку по ID
+func (r *ConversationRepository) GetByID(ctx context.Context, id int64) (*model.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations WHERE id = $1`
+
+	conversation, err := scanConversation(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// GetByUserID получает переписки пользователя (как учителя и как студента), сначала недавние
+func (r *ConversationRepository) GetByUserID(ctx context.Context, userID int64) ([]*model.Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + `
+		FROM conversations
+		WHERE teacher_id = $1 OR student_id = $1
+		ORDER BY last_message_at DESC NULLS LAST, created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*model.Conversation
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan conversation: %w", err)
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, rows.Err()
+}
+
+// SetMuted включает или выключает звук переписки для участника
+func (r *ConversationRepository) SetMuted(ctx context.Context, id, userID int64, muted bool) error {
+	query := `
+		UPDATE conversations SET
+			teacher_muted = CASE WHEN teacher_id = $2 THEN $3 ELSE teacher_muted END,
+			student_muted = CASE WHEN student_id = $2 THEN $3 ELSE student_muted END
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, userID, muted)
+	if err != nil {
+		return fmt.Errorf("set conversation muted: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
+
+// CreateMessage сохраняет сообщение переписки и обновляет время последнего сообщения
+func (r *ConversationRepository) CreateMessage(ctx context.Context, message *model.ConversationMessage) error {
+	query := `
+		WITH inserted AS (
+			INSERT INTO conversation_messages (conversation_id, sender_id, booking_id, text)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		), touched AS (
+			UPDATE conversations SET last_message_at = (SELECT created_at FROM inserted)
+			WHERE id = $1
+		)
+		SELECT id, created_at FROM inserted
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		message.ConversationID,
+		message.SenderID,
+		message.BookingID,
+		message.Text,
+	).Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create conversation message: %w", err)
+	}
+
+	return nil
+}
+
+// SetRelayed запоминает, каким сообщением бота сообщение доставлено получателю
+func (r *ConversationRepository) SetRelayed(ctx context.Context, messageID, chatID int64, relayedMessageID int) error {
+	query := `UPDATE conversation_messages SET relayed_chat_id = $2, relayed_message_id = $3 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, messageID, chatID, relayedMessageID); err != nil {
+		return fmt.Errorf("set message relayed: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessageByRelayed находит сообщение переписки по сообщению бота у получателя
+func (r *ConversationRepository) GetMessageByRelayed(ctx context.Context, chatID int64, relayedMessageID int) (*model.ConversationMessage, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, booking_id, text, relayed_chat_id, relayed_message_id, created_at
+		FROM conversation_messages
+		WHERE relayed_chat_id = $1 AND relayed_message_id = $2
+	`
+
+	var message model.ConversationMessage
+	err := r.pool.QueryRow(ctx, query, chatID, relayedMessageID).Scan(
+		&message.ID,
+		&message.ConversationID,
+		&message.SenderID,
+		&message.BookingID,
+		&message.Text,
+		&message.RelayedChatID,
+		&message.RelayedMessageID,
+		&message.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get message by relayed: %w", err)
+	}
+
+	return &message, nil
+}
+
+// GetRecentMessages получает последние сообщения переписки в хронологическом порядке
+func (r *ConversationRepository) GetRecentMessages(ctx context.Context, conversationID int64, limit int) ([]*model.ConversationMessage, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, booking_id, text, relayed_chat_id, relayed_message_id, created_at
+		FROM (
+			SELECT * FROM conversation_messages
+			WHERE conversation_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		) recent
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, conversationID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get recent messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*model.ConversationMessage
+	for rows.Next() {
+		var message model.ConversationMessage
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.BookingID,
+			&message.Text,
+			&message.RelayedChatID,
+			&message.RelayedMessageID,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan conversation message: %w", err)
+		}
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
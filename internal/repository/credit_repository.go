@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCreditRepository(pool *pgxpool.Pool) *CreditRepository {
+	return &CreditRepository{pool: pool}
+}
+
+const lessonPackageColumns = `id, subject_id, teacher_id, lesson_count, price, currency, validity_days, is_active, created_at`
+
+// scanLessonPackage читает пакет занятий из строки результата в порядке lessonPackageColumns
+func scanLessonPackage(row pgx.Row) (*model.LessonPackage, error) {
+	var pkg model.LessonPackage
+	err := row.Scan(
+		&pkg.ID,
+		&pkg.SubjectID,
+		&pkg.TeacherID,
+		&pkg.LessonCount,
+		&pkg.Price,
+		&pkg.Currency,
+		&pkg.ValidityDays,
+		&pkg.IsActive,
+		&pkg.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// CreatePackage создаёт пакет занятий
+func (r *CreditRepository) CreatePackage(ctx context.Context, pkg *model.LessonPackage) error {
+	query := `
+		INSERT INTO lesson_packages (subject_id, teacher_id, lesson_count, price, currency, validity_days, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(
+		ctx, query,
+		pkg.SubjectID,
+		pkg.TeacherID,
+		pkg.LessonCount,
+		pkg.Price,
+		pkg.Currency,
+		pkg.ValidityDays,
+		pkg.IsActive,
+	).Scan(&pkg.ID, &pkg.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create lesson package: %w", err)
+	}
+
+	return nil
+}
+
+// GetPackageByID получает пакет занятий по ID
+func (r *CreditRepository) GetPackageByID(ctx context.Context, id int64) (*model.LessonPackage, error) {
+	query := `SELECT ` + lessonPackageColumns + ` FROM lesson_packages WHERE id = $1`
+
+	pkg, err := scanLessonPackage(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get lesson package: %w", err)
+	}
+
+	return pkg, nil
+}
+
+// GetPackagesBySubject получает пакеты занятий предмета (активные первыми)
+func (r *CreditRepository) GetPackagesBySubject(ctx context.Context, subjectID int64) ([]*model.LessonPackage, error) {
+	query := `
+		SELECT ` + lessonPackageColumns + `
+		FROM lesson_packages
+		WHERE subject_id = $1
+		ORDER BY is_active DESC, lesson_count, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("query lesson packages: %w", err)
+	}
+	defer rows.Close()
+
+	var packages []*model.LessonPackage
+	for rows.Next() {
+		pkg, err := scanLessonPackage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan lesson package: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, rows.Err()
+}
+
+// SetPackageActive включает или выключает продажу пакета
+func (r *CreditRepository) SetPackageActive(ctx context.Context, id int64, isActive bool) error {
+	query := `UPDATE lesson_packages SET is_active = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, isActive, id)
+	if err != nil {
+		return fmt.Errorf("update lesson package: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("package not found")
+	}
+
+	return nil
+}
+
+// DeactivatePackagesBySubject снимает с продажи все пакеты предмета. Возвращает количество снятых пакетов
+func (r *CreditRepository) DeactivatePackagesBySubject(ctx context.Context, subjectID int64) (int64, error) {
+	query := `UPDATE lesson_packages SET is_active = false WHERE subject_id = $1 AND is_active = true`
+
+	result, err := r.pool.Exec(ctx, query, subjectID)
+	if err != nil {
+		return 0, fmt.Errorf("deactivate lesson packages: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// AddLot начисляет студенту занятия и записывает начисление в журнал
+func (r *CreditRepository) AddLot(ctx context.Context, lot *model.CreditLot) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO credit_lots (student_id, teacher_id, subject_id, package_id, source, total, remaining, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+		RETURNING id, remaining, created_at
+	`,
+		lot.StudentID,
+		lot.TeacherID,
+		lot.SubjectID,
+		lot.PackageID,
+		lot.Source,
+		lot.Total,
+		lot.ExpiresAt,
+	).Scan(&lot.ID, &lot.Remaining, &lot.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create credit lot: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO credit_transactions (lot_id, student_id, teacher_id, kind, amount)
+		VALUES ($1, $2, $3, 'grant', $4)
+	`, lot.ID, lot.StudentID, lot.TeacherID, lot.Total)
+	if err != nil {
+		return fmt.Errorf("create grant transaction: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CreateBookingWithDebit создаёт бронирование и списывает за него одно занятие в одной транзакции.
+// Списывается занятие, которое сгорит раньше других и ещё действует на момент at.
+// false означает, что подходящих занятий нет и бронирование не создано
+func (r *CreditRepository) CreateBookingWithDebit(ctx context.Context, booking *model.Booking, at time.Time) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка начисления не даёт двум одновременным записям списать последнее занятие дважды
+	var lotID, teacherID int64
+	err = tx.QueryRow(ctx, `
+		SELECT id, teacher_id FROM credit_lots
+		WHERE student_id = $1 AND subject_id = $2 AND remaining > 0
+		  AND (expires_at IS NULL OR expires_at > $3)
+		ORDER BY expires_at ASC NULLS LAST, id
+		LIMIT 1
+		FOR UPDATE
+	`, booking.StudentID, booking.SubjectID, at).Scan(&lotID, &teacherID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("select credit lot: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE credit_lots SET remaining = remaining - 1 WHERE id = $1`, lotID)
+	if err != nil {
+		return false, fmt.Errorf("debit credit lot: %w", err)
+	}
+
+	if err := createBookingTx(ctx, tx, booking); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO credit_transactions (lot_id, student_id, teacher_id, booking_id, kind, amount)
+		VALUES ($1, $2, $3, $4, 'debit', -1)
+	`, lotID, booking.StudentID, teacherID, booking.ID)
+	if err != nil {
+		return false, fmt.Errorf("create debit transaction: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// IsDebited проверяет, было ли за бронирование списано занятие, которое ещё не вернули
+func (r *CreditRepository) IsDebited(ctx context.Context, bookingID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM credit_transactions d
+			WHERE d.booking_id = $1 AND d.kind = 'debit'
+			  AND NOT EXISTS (
+				SELECT 1 FROM credit_transactions r
+				WHERE r.booking_id = d.booking_id AND r.kind = 'refund'
+			  )
+		)
+	`
+
+	var debited bool
+	err := r.pool.QueryRow(ctx, query, bookingID).Scan(&debited)
+	if err != nil {
+		return false, fmt.Errorf("check booking debit: %w", err)
+	}
+
+	return debited, nil
+}
+
+// Refund возвращает в начисление занятие, списанное за бронирование.
+// false означает, что за бронирование ничего не списывалось или занятие уже вернули.
+// Если срок действия начисления истёк, возвращённое занятие сгорает вместе с ним
+func (r *CreditRepository) Refund(ctx context.Context, bookingID int64) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var lotID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO credit_transactions (lot_id, student_id, teacher_id, booking_id, kind, amount)
+		SELECT lot_id, student_id, teacher_id, booking_id, 'refund', 1
+		FROM credit_transactions
+		WHERE booking_id = $1 AND kind = 'debit'
+		ON CONFLICT (booking_id, kind) WHERE booking_id IS NOT NULL DO NOTHING
+		RETURNING lot_id
+	`, bookingID).Scan(&lotID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("create refund transaction: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE credit_lots SET remaining = remaining + 1 WHERE id = $1`, lotID)
+	if err != nil {
+		return false, fmt.Errorf("refund credit lot: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetBalancesByStudent получает остатки действующих занятий студента по предметам
+func (r *CreditRepository) GetBalancesByStudent(ctx context.Context, studentID int64, now time.Time) ([]*model.CreditBalance, error) {
+	query := `
+		SELECT l.student_id, l.teacher_id, l.subject_id, s.name,
+		       SUM(l.remaining)::int, MIN(l.expires_at)
+		FROM credit_lots l
+		INNER JOIN subjects s ON s.id = l.subject_id
+		WHERE l.student_id = $1 AND l.remaining > 0
+		  AND (l.expires_at IS NULL OR l.expires_at > $2)
+		GROUP BY l.student_id, l.teacher_id, l.subject_id, s.name
+		ORDER BY l.teacher_id, s.name
+	`
+
+	rows, err := r.pool.Query(ctx, query, studentID, now)
+	if err != nil {
+		return nil, fmt.Errorf("query credit balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*model.CreditBalance
+	for rows.Next() {
+		var balance model.CreditBalance
+		err := rows.Scan(
+			&balance.StudentID,
+			&balance.TeacherID,
+			&balance.SubjectID,
+			&balance.SubjectName,
+			&balance.Remaining,
+			&balance.NextExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan credit balance: %w", err)
+		}
+		balances = append(balances, &balance)
+	}
+
+	return balances, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// exportUserName собирает имя пользователя для выгрузки из таблицы с алиасом u
+const exportUserName = `CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, ''))`
+
+// ExportRepository читает данные учителя для выгрузки в файлы
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{pool: pool}
+}
+
+// GetSlots возвращает слоты учителя с названием предмета и именем студента за период [from, to)
+func (r *ExportRepository) GetSlots(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.SlotExportRow, error) {
+	query := `
+		SELECT s.id, s.start_time, s.end_time, sub.name, s.status,
+		       CASE WHEN u.id IS NULL THEN NULL ELSE ` + exportUserName + ` END,
+		       s.comment
+		FROM schedule_slots s
+		INNER JOIN subjects sub ON sub.id = s.subject_id
+		LEFT JOIN users u ON u.id = s.student_id
+		WHERE s.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		ORDER BY s.start_time, s.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query export slots: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.SlotExportRow
+	for rows.Next() {
+		var row model.SlotExportRow
+		err := rows.Scan(
+			&row.SlotID,
+			&row.StartTime,
+			&row.EndTime,
+			&row.SubjectName,
+			&row.Status,
+			&row.StudentName,
+			&row.Comment,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan export slot: %w", err)
+		}
+		result = append(result, &row)
+	}
+
+	return result, rows.Err()
+}
+
+// GetBookings возвращает записи к учителю со статусом и студентом за период [from, to) по началу слота
+func (r *ExportRepository) GetBookings(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.BookingExportRow, error) {
+	query := `
+		SELECT b.id, s.start_time, s.end_time, sub.name,
+		       ` + exportUserName + `, COALESCE(u.username, ''),
+		       b.status, b.price, b.currency, pc.code, b.created_at
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		INNER JOIN subjects sub ON sub.id = b.subject_id
+		INNER JOIN users u ON u.id = b.student_id
+		LEFT JOIN promo_codes pc ON pc.id = b.promo_code_id
+		WHERE b.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		ORDER BY s.start_time, b.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query export bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.BookingExportRow
+	for rows.Next() {
+		var row model.BookingExportRow
+		err := rows.Scan(
+			&row.BookingID,
+			&row.StartTime,
+			&row.EndTime,
+			&row.SubjectName,
+			&row.StudentName,
+			&row.StudentUsername,
+			&row.Status,
+			&row.Price,
+			&row.Currency,
+			&row.PromoCode,
+			&row.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan export booking: %w", err)
+		}
+		result = append(result, &row)
+	}
+
+	return result, rows.Err()
+}
+
+// GetStudents возвращает студентов учителя с типом и сроком доступа в порядке GetTeacherStudentIDs
+func (r *ExportRepository) GetStudents(ctx context.Context, teacherID int64) ([]*model.StudentExportRow, error) {
+	query := `
+		SELECT u.id, ` + exportUserName + `, COALESCE(u.username, ''),
+		       a.access_type, a.granted_at, a.expires_at
+		FROM student_teacher_access a
+		INNER JOIN users u ON u.id = a.student_id
+		WHERE a.teacher_id = $1
+		ORDER BY a.granted_at DESC, u.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("query export students: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.StudentExportRow
+	for rows.Next() {
+		var row model.StudentExportRow
+		err := rows.Scan(
+			&row.StudentID,
+			&row.Name,
+			&row.Username,
+			&row.AccessType,
+			&row.GrantedAt,
+			&row.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan export student: %w", err)
+		}
+		result = append(result, &row)
+	}
+
+	return result, rows.Err()
+}
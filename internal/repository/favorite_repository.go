@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FavoriteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFavoriteRepository(pool *pgxpool.Pool) *FavoriteRepository {
+	return &FavoriteRepository{pool: pool}
+}
+
+// favoriteSelect выбирает избранное вместе с именем учителя и названием предмета
+const favoriteSelect = `
+	SELECT f.id, f.student_id, f.teacher_id, f.subject_id, f.alerts_enabled, f.weekdays, f.day_periods,
+	       f.alerts_checked_at, f.created_at,
+	       TRIM(t.first_name || ' ' || t.last_name), COALESCE(sub.name, '')
+	FROM favorites f
+	JOIN users t ON t.id = f.teacher_id
+	LEFT JOIN subjects sub ON sub.id = f.subject_id
+`
+
+func scanFavorite(row pgx.Row) (*model.Favorite, error) {
+	var favorite model.Favorite
+	err := row.Scan(
+		&favorite.ID,
+		&favorite.StudentID,
+		&favorite.TeacherID,
+		&favorite.SubjectID,
+		&favorite.AlertsEnabled,
+		&favorite.Weekdays,
+		&favorite.DayPeriods,
+		&favorite.AlertsCheckedAt,
+		&favorite.CreatedAt,
+		&favorite.TeacherName,
+		&favorite.SubjectName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+func (r *FavoriteRepository) queryFavorites(ctx context.Context, query string, args ...interface{}) ([]*model.Favorite, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get favorites: %w", err)
+	}
+	defer rows.Close()
+
+	var favorites []*model.Favorite
+	for rows.Next() {
+		favorite, err := scanFavorite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan favorite: %w", err)
+		}
+		favorites = append(favorites, favorite)
+	}
+
+	return favorites, rows.Err()
+}
+
+// Create добавляет учителя или предмет в избранное. Повторное добавление возвращает ошибку "already favorite"
+func (r *FavoriteRepository) Create(ctx context.Context, favorite *model.Favorite) error {
+	query := `
+		INSERT INTO favorites (student_id, teacher_id, subject_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, alerts_enabled, weekdays, day_periods, alerts_checked_at, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		favorite.StudentID,
+		favorite.TeacherID,
+		favorite.SubjectID,
+	).Scan(
+		&favorite.ID,
+		&favorite.AlertsEnabled,
+		&favorite.Weekdays,
+		&favorite.DayPeriods,
+		&favorite.AlertsCheckedAt,
+		&favorite.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("already favorite")
+		}
+		return fmt.Errorf("create favorite: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает избранное по ID
+func (r *FavoriteRepository) GetByID(ctx context.Context, id int64) (*model.Favorite, error) {
+	favorite, err := scanFavorite(r.pool.QueryRow(ctx, favoriteSelect+` WHERE f.id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get favorite: %w", err)
+	}
+
+	return favorite, nil
+}
+
+// GetByStudentID получает избранное студента: сначала учителя, затем отдельные предметы
+func (r *FavoriteRepository) GetByStudentID(ctx context.Context, studentID int64) ([]*model.Favorite, error) {
+	return r.queryFavorites(ctx, favoriteSelect+`
+		WHERE f.student_id = $1
+		ORDER BY f.subject_id IS NOT NULL, f.created_at
+	`, studentID)
+}
+
+// FindTeacher ищет учителя в избранном студента
+func (r *FavoriteRepository) FindTeacher(ctx context.Context, studentID, teacherID int64) (*model.Favorite, error) {
+	query := favoriteSelect + ` WHERE f.student_id = $1 AND f.teacher_id = $2 AND f.subject_id IS NULL`
+
+	favorite, err := scanFavorite(r.pool.QueryRow(ctx, query, studentID, teacherID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find favorite teacher: %w", err)
+	}
+
+	return favorite, nil
+}
+
+// FindSubject ищет предмет в избранном студента
+func (r *FavoriteRepository) FindSubject(ctx context.Context, studentID, subjectID int64) (*model.Favorite, error) {
+	query := favoriteSelect + ` WHERE f.student_id = $1 AND f.subject_id = $2`
+
+	favorite, err := scanFavorite(r.pool.QueryRow(ctx, query, studentID, subjectID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find favorite subject: %w", err)
+	}
+
+	return favorite, nil
+}
+
+// CountByStudentID считает избранное студента
+func (r *FavoriteRepository) CountByStudentID(ctx context.Context, studentID int64) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM favorites WHERE student_id = $1`, studentID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count favorites: %w", err)
+	}
+
+	return count, nil
+}
+
+// Delete удаляет запись из избранного
+func (r *FavoriteRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM favorites WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete favorite: %w", err)
+	}
+
+	return nil
+}
+
+// SetAlertsEnabled включает или выключает уведомления. При включении слоты,
+// освободившиеся пока уведомления были выключены, уже не присылаются
+func (r *FavoriteRepository) SetAlertsEnabled(ctx context.Context, id int64, enabled bool) error {
+	query := `
+		UPDATE favorites
+		SET alerts_enabled = $2,
+		    alerts_checked_at = CASE WHEN $2 AND NOT alerts_enabled THEN NOW() ELSE alerts_checked_at END
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, enabled)
+	if err != nil {
+		return fmt.Errorf("set favorite alerts: %w", err)
+	}
+
+	return nil
+}
+
+// SetTimes сохраняет удобные дни недели и части дня
+func (r *FavoriteRepository) SetTimes(ctx context.Context, id int64, weekdays, dayPeriods int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE favorites SET weekdays = $2, day_periods = $3 WHERE id = $1`, id, weekdays, dayPeriods)
+	if err != nil {
+		return fmt.Errorf("set favorite times: %w", err)
+	}
+
+	return nil
+}
+
+// GetAlertEnabled получает избранное с включёнными уведомлениями, сгруппированное по студентам
+func (r *FavoriteRepository) GetAlertEnabled(ctx context.Context) ([]*model.Favorite, error) {
+	return r.queryFavorites(ctx, favoriteSelect+`
+		WHERE f.alerts_enabled = true
+		ORDER BY f.student_id, f.id
+	`)
+}
+
+// MarkChecked отмечает, что слоты, освободившиеся до checkedAt, уже учтены в уведомлениях
+func (r *FavoriteRepository) MarkChecked(ctx context.Context, ids []int64, checkedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.pool.Exec(ctx, `UPDATE favorites SET alerts_checked_at = $2 WHERE id = ANY($1)`, ids, checkedAt)
+	if err != nil {
+		return fmt.Errorf("mark favorites checked: %w", err)
+	}
+
+	return nil
+}
+
+// CountAlertsSince считает уведомления о свободных слотах, отправленные студенту после since
+func (r *FavoriteRepository) CountAlertsSince(ctx context.Context, studentID int64, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM slot_alerts WHERE student_id = $1 AND sent_at > $2`
+	if err := r.pool.QueryRow(ctx, query, studentID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count slot alerts: %w", err)
+	}
+
+	return count, nil
+}
+
+// LogAlert записывает отправленное уведомление о свободных слотах
+func (r *FavoriteRepository) LogAlert(ctx context.Context, studentID int64, slotCount int) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO slot_alerts (student_id, slot_count) VALUES ($1, $2)`, studentID, slotCount)
+	if err != nil {
+		return fmt.Errorf("log slot alert: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type GroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGroupRepository(pool *pgxpool.Pool) *GroupRepository {
+	return &GroupRepository{pool: pool}
+}
+
+// Create создает группу учителя
+func (r *GroupRepository) Create(ctx context.Context, group *model.StudentGroup) error {
+	query := `
+		INSERT INTO student_groups (teacher_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query, group.TeacherID, group.Name).Scan(&group.ID, &group.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			// У учителя уже есть группа с таким названием
+			return fmt.Errorf("group already exists")
+		}
+		return fmt.Errorf("create group: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает группу по ID вместе с числом участников
+func (r *GroupRepository) GetByID(ctx context.Context, id int64) (*model.StudentGroup, error) {
+	query := `
+		SELECT g.id, g.teacher_id, g.name,
+		       (SELECT COUNT(*) FROM student_group_members m WHERE m.group_id = g.id),
+		       g.created_at
+		FROM student_groups g
+		WHERE g.id = $1
+	`
+
+	var group model.StudentGroup
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&group.ID,
+		&group.TeacherID,
+		&group.Name,
+		&group.MembersCount,
+		&group.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+
+	return &group, nil
+}
+
+// GetByTeacherID получает группы учителя с числом участников, по алфавиту
+func (r *GroupRepository) GetByTeacherID(ctx context.Context, teacherID int64) ([]*model.StudentGroup, error) {
+	query := `
+		SELECT g.id, g.teacher_id, g.name, COUNT(m.student_id), g.created_at
+		FROM student_groups g
+		LEFT JOIN student_group_members m ON m.group_id = g.id
+		WHERE g.teacher_id = $1
+		GROUP BY g.id
+		ORDER BY g.name
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.StudentGroup
+	for rows.Next() {
+		var group model.StudentGroup
+		err := rows.Scan(
+			&group.ID,
+			&group.TeacherID,
+			&group.Name,
+			&group.MembersCount,
+			&group.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+
+	return groups, rows.Err()
+}
+
+// Delete удаляет группу; коды приглашения и слоты группы остаются без группы
+func (r *GroupRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM student_groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete group: %w", err)
+	}
+
+	return nil
+}
+
+// AddMember добавляет студента в группу; повторное добавление ничего не меняет
+func (r *GroupRepository) AddMember(ctx context.Context, groupID, studentID int64) error {
+	query := `
+		INSERT INTO student_group_members (group_id, student_id)
+		VALUES ($1, $2)
+		ON CONFLICT (group_id, student_id) DO NOTHING
+	`
+
+	if _, err := r.pool.Exec(ctx, query, groupID, studentID); err != nil {
+		return fmt.Errorf("add group member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember удаляет студента из группы
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, studentID int64) error {
+	query := `DELETE FROM student_group_members WHERE group_id = $1 AND student_id = $2`
+
+	result, err := r.pool.Exec(ctx, query, groupID, studentID)
+	if err != nil {
+		return fmt.Errorf("remove group member: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+// RemoveStudentFromTeacherGroups удаляет студента из всех групп учителя (при отзыве доступа)
+func (r *GroupRepository) RemoveStudentFromTeacherGroups(ctx context.Context, teacherID, studentID int64) error {
+	query := `
+		DELETE FROM student_group_members m
+		USING student_groups g
+		WHERE g.id = m.group_id AND g.teacher_id = $1 AND m.student_id = $2
+	`
+
+	if _, err := r.pool.Exec(ctx, query, teacherID, studentID); err != nil {
+		return fmt.Errorf("remove student from teacher groups: %w", err)
+	}
+
+	return nil
+}
+
+// IsMember проверяет, состоит ли студент в группе
+func (r *GroupRepository) IsMember(ctx context.Context, groupID, studentID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM student_group_members
+			WHERE group_id = $1 AND student_id = $2
+		)
+	`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, groupID, studentID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check group member: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetMemberIDs получает ID участников группы в порядке добавления
+func (r *GroupRepository) GetMemberIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	query := `
+		SELECT student_id FROM student_group_members
+		WHERE group_id = $1
+		ORDER BY added_at, student_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("get group member ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan group member id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// SetSlotGroup закрепляет слот за группой; groupID == nil снимает ограничение
+func (r *GroupRepository) SetSlotGroup(ctx context.Context, slotID int64, groupID *int64) error {
+	query := `UPDATE schedule_slots SET group_id = $2 WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, slotID, groupID)
+	if err != nil {
+		return fmt.Errorf("set slot group: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("slot not found")
+	}
+
+	return nil
+}
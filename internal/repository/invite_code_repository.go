@@ -20,8 +20,8 @@ func NewInviteCodeRepository(pool *pgxpool.Pool) *InviteCodeRepository {
 // Create создает новый invite-код
 func (r *InviteCodeRepository) Create(ctx context.Context, code *model.TeacherInviteCode) error {
 	query := `
-		INSERT INTO teacher_invite_codes (teacher_id, code, max_uses, expires_at, is_active)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO teacher_invite_codes (teacher_id, code, max_uses, expires_at, access_days, group_id, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, current_uses, created_at
 	`
 
@@ -31,6 +31,8 @@ func (r *InviteCodeRepository) Create(ctx context.Context, code *model.TeacherIn
 		code.Code,
 		code.MaxUses,
 		code.ExpiresAt,
+		code.AccessDays,
+		code.GroupID,
 		code.IsActive,
 	).Scan(&code.ID, &code.CurrentUses, &code.CreatedAt)
 
@@ -44,7 +46,7 @@ func (r *InviteCodeRepository) Create(ctx context.Context, code *model.TeacherIn
 // GetByCode получает код по строке
 func (r *InviteCodeRepository) GetByCode(ctx context.Context, code string) (*model.TeacherInviteCode, error) {
 	query := `
-		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, is_active, created_at
+		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, access_days, group_id, is_active, created_at
 		FROM teacher_invite_codes
 		WHERE code = $1
 	`
@@ -57,6 +59,8 @@ func (r *InviteCodeRepository) GetByCode(ctx context.Context, code string) (*mod
 		&inviteCode.MaxUses,
 		&inviteCode.CurrentUses,
 		&inviteCode.ExpiresAt,
+		&inviteCode.AccessDays,
+		&inviteCode.GroupID,
 		&inviteCode.IsActive,
 		&inviteCode.CreatedAt,
 	)
@@ -74,7 +78,7 @@ func (r *InviteCodeRepository) GetByCode(ctx context.Context, code string) (*mod
 // GetByID получает код по ID
 func (r *InviteCodeRepository) GetByID(ctx context.Context, id int64) (*model.TeacherInviteCode, error) {
 	query := `
-		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, is_active, created_at
+		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, access_days, group_id, is_active, created_at
 		FROM teacher_invite_codes
 		WHERE id = $1
 	`
@@ -87,6 +91,8 @@ func (r *InviteCodeRepository) GetByID(ctx context.Context, id int64) (*model.Te
 		&inviteCode.MaxUses,
 		&inviteCode.CurrentUses,
 		&inviteCode.ExpiresAt,
+		&inviteCode.AccessDays,
+		&inviteCode.GroupID,
 		&inviteCode.IsActive,
 		&inviteCode.CreatedAt,
 	)
@@ -104,7 +110,7 @@ func (r *InviteCodeRepository) GetByID(ctx context.Context, id int64) (*model.Te
 // GetByTeacherID получает все коды учителя
 func (r *InviteCodeRepository) GetByTeacherID(ctx context.Context, teacherID int64) ([]*model.TeacherInviteCode, error) {
 	query := `
-		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, is_active, created_at
+		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, access_days, group_id, is_active, created_at
 		FROM teacher_invite_codes
 		WHERE teacher_id = $1
 		ORDER BY created_at DESC
@@ -126,6 +132,8 @@ func (r *InviteCodeRepository) GetByTeacherID(ctx context.Context, teacherID int
 			&code.MaxUses,
 			&code.CurrentUses,
 			&code.ExpiresAt,
+			&code.AccessDays,
+			&code.GroupID,
 			&code.IsActive,
 			&code.CreatedAt,
 		)
@@ -145,7 +153,7 @@ func (r *InviteCodeRepository) GetByTeacherID(ctx context.Context, teacherID int
 // GetActiveByTeacherID получает активные коды учителя
 func (r *InviteCodeRepository) GetActiveByTeacherID(ctx context.Context, teacherID int64) ([]*model.TeacherInviteCode, error) {
 	query := `
-		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, is_active, created_at
+		SELECT id, teacher_id, code, max_uses, current_uses, expires_at, access_days, group_id, is_active, created_at
 		FROM teacher_invite_codes
 		WHERE teacher_id = $1 AND is_active = true
 		ORDER BY created_at DESC
@@ -167,6 +175,8 @@ func (r *InviteCodeRepository) GetActiveByTeacherID(ctx context.Context, teacher
 			&code.MaxUses,
 			&code.CurrentUses,
 			&code.ExpiresAt,
+			&code.AccessDays,
+			&code.GroupID,
 			&code.IsActive,
 			&code.CreatedAt,
 		)
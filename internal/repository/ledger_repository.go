@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LedgerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{pool: pool}
+}
+
+// CompletePastBookings переводит прошедшие подтверждённые записи в завершённые и начисляет
+// цену, зафиксированную в записи. Занятия, оплаченные онлайн или списанные из пакета, не начисляются.
+// Возвращает количество завершённых записей и созданных начислений
+func (r *LedgerRepository) CompletePastBookings(ctx context.Context, now time.Time) (int, int, error) {
+	query := `
+		WITH done AS (
+			UPDATE bookings b
+			SET status = 'completed'
+			FROM schedule_slots s
+			WHERE s.id = b.slot_id AND b.status = 'confirmed' AND s.end_time <= $1
+			RETURNING b.id, b.student_id, b.teacher_id, b.subject_id, b.price, b.currency, s.start_time
+		), charged AS (
+			INSERT INTO ledger_entries (teacher_id, student_id, booking_id, kind, amount, currency, description, occurred_at)
+			SELECT d.teacher_id, d.student_id, d.id, 'charge', d.price, d.currency, sub.name, d.start_time
+			FROM done d
+			INNER JOIN subjects sub ON sub.id = d.subject_id
+			WHERE d.price > 0
+			  AND NOT EXISTS (
+				SELECT 1 FROM payments p WHERE p.booking_id = d.id AND p.status = 'paid'
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM credit_transactions ct
+				WHERE ct.booking_id = d.id AND ct.kind = 'debit'
+				  AND NOT EXISTS (
+					SELECT 1 FROM credit_transactions rt
+					WHERE rt.booking_id = d.id AND rt.kind = 'refund'
+				  )
+			  )
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		)
+		SELECT (SELECT COUNT(*) FROM done)::int, (SELECT COUNT(*) FROM charged)::int
+	`
+
+	var completed, charged int
+	err := r.pool.QueryRow(ctx, query, now).Scan(&completed, &charged)
+	if err != nil {
+		return 0, 0, fmt.Errorf("complete past bookings: %w", err)
+	}
+
+	return completed, charged, nil
+}
+
+// Create добавляет запись в журнал взаиморасчётов
+func (r *LedgerRepository) Create(ctx context.Context, entry *model.LedgerEntry) error {
+	query := `
+		INSERT INTO ledger_entries (teacher_id, student_id, booking_id, kind, amount, currency, method, description, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(
+		ctx, query,
+		entry.TeacherID,
+		entry.StudentID,
+		entry.BookingID,
+		entry.Kind,
+		entry.Amount,
+		entry.Currency,
+		entry.Method,
+		entry.Description,
+		entry.OccurredAt,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetBalances получает итоги взаиморасчётов учителя и студента по каждой валюте
+func (r *LedgerRepository) GetBalances(ctx context.Context, teacherID, studentID int64) ([]*model.LedgerBalance, error) {
+	query := `
+		SELECT l.teacher_id, l.student_id, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, '')), l.currency,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'charge'), 0)::int,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'payment'), 0)::int
+		FROM ledger_entries l
+		INNER JOIN users u ON u.id = l.student_id
+		WHERE l.teacher_id = $1 AND l.student_id = $2
+		GROUP BY l.teacher_id, l.student_id, u.first_name, u.last_name, l.currency
+		ORDER BY l.currency
+	`
+
+	return r.queryBalances(ctx, query, teacherID, studentID)
+}
+
+// GetBalancesByTeacher получает итоги взаиморасчётов учителя со всеми студентами
+func (r *LedgerRepository) GetBalancesByTeacher(ctx context.Context, teacherID int64) ([]*model.LedgerBalance, error) {
+	query := `
+		SELECT l.teacher_id, l.student_id, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, '')), l.currency,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'charge'), 0)::int,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'payment'), 0)::int
+		FROM ledger_entries l
+		INNER JOIN users u ON u.id = l.student_id
+		WHERE l.teacher_id = $1
+		GROUP BY l.teacher_id, l.student_id, u.first_name, u.last_name, l.currency
+		ORDER BY u.first_name, u.last_name, l.currency
+	`
+
+	return r.queryBalances(ctx, query, teacherID)
+}
+
+// GetBalancesByStudent получает итоги взаиморасчётов студента со всеми учителями
+func (r *LedgerRepository) GetBalancesByStudent(ctx context.Context, studentID int64) ([]*model.LedgerBalance, error) {
+	query := `
+		SELECT l.teacher_id, l.student_id, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, '')), l.currency,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'charge'), 0)::int,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.kind = 'payment'), 0)::int
+		FROM ledger_entries l
+		INNER JOIN users u ON u.id = l.teacher_id
+		WHERE l.student_id = $1
+		GROUP BY l.teacher_id, l.student_id, u.first_name, u.last_name, l.currency
+		ORDER BY u.first_name, u.last_name, l.currency
+	`
+
+	return r.queryBalances(ctx, query, studentID)
+}
+
+func (r *LedgerRepository) queryBalances(ctx context.Context, query string, args ...any) ([]*model.LedgerBalance, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*model.LedgerBalance
+	for rows.Next() {
+		var balance model.LedgerBalance
+		err := rows.Scan(
+			&balance.TeacherID,
+			&balance.StudentID,
+			&balance.Name,
+			&balance.Currency,
+			&balance.Charged,
+			&balance.Paid,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan ledger balance: %w", err)
+		}
+		balances = append(balances, &balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// GetRecentEntries получает последние записи журнала между учителем и студентом
+func (r *LedgerRepository) GetRecentEntries(ctx context.Context, teacherID, studentID int64, limit int) ([]*model.LedgerEntry, error) {
+	query := `
+		SELECT l.id, l.teacher_id, l.student_id, l.booking_id, l.kind, l.amount, l.currency, l.method,
+		       l.description, l.occurred_at, l.created_at, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, ''))
+		FROM ledger_entries l
+		INNER JOIN users u ON u.id = l.student_id
+		WHERE l.teacher_id = $1 AND l.student_id = $2
+		ORDER BY l.occurred_at DESC, l.id DESC
+		LIMIT $3
+	`
+
+	return r.queryEntries(ctx, query, teacherID, studentID, limit)
+}
+
+// GetEntriesByTeacherAndPeriod получает записи журнала учителя за период [from, to)
+func (r *LedgerRepository) GetEntriesByTeacherAndPeriod(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.LedgerEntry, error) {
+	query := `
+		SELECT l.id, l.teacher_id, l.student_id, l.booking_id, l.kind, l.amount, l.currency, l.method,
+		       l.description, l.occurred_at, l.created_at, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, ''))
+		FROM ledger_entries l
+		INNER JOIN users u ON u.id = l.student_id
+		WHERE l.teacher_id = $1 AND l.occurred_at >= $2 AND l.occurred_at < $3
+		ORDER BY l.occurred_at, l.id
+	`
+
+	return r.queryEntries(ctx, query, teacherID, from, to)
+}
+
+func (r *LedgerRepository) queryEntries(ctx context.Context, query string, args ...any) ([]*model.LedgerEntry, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.LedgerEntry
+	for rows.Next() {
+		var entry model.LedgerEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TeacherID,
+			&entry.StudentID,
+			&entry.BookingID,
+			&entry.Kind,
+			&entry.Amount,
+			&entry.Currency,
+			&entry.Method,
+			&entry.Description,
+			&entry.OccurredAt,
+			&entry.CreatedAt,
+			&entry.StudentName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan ledger entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LessonNoteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLessonNoteRepository(pool *pgxpool.Pool) *LessonNoteRepository {
+	return &LessonNoteRepository{pool: pool}
+}
+
+// lessonNoteSelect выбирает заметки вместе с предметом и временем занятия
+const lessonNoteSelect = `
+	SELECT n.id, n.booking_id, n.teacher_id, n.student_id, n.text,
+	       COALESCE(n.photo_file_id, ''), COALESCE(n.document_file_id, ''),
+	       n.homework_due_at, n.reminder_sent_at, n.created_at,
+	       sub.name, s.start_time
+	FROM lesson_notes n
+	JOIN bookings b ON b.id = n.booking_id
+	JOIN schedule_slots s ON s.id = b.slot_id
+	JOIN subjects sub ON sub.id = b.subject_id
+`
+
+func scanLessonNote(row pgx.Row) (*model.LessonNote, error) {
+	var note model.LessonNote
+	err := row.Scan(
+		&note.ID,
+		&note.BookingID,
+		&note.TeacherID,
+		&note.StudentID,
+		&note.Text,
+		&note.PhotoFileID,
+		&note.DocumentFileID,
+		&note.HomeworkDueAt,
+		&note.ReminderSentAt,
+		&note.CreatedAt,
+		&note.SubjectName,
+		&note.LessonStart,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func collectLessonNotes(rows pgx.Rows) ([]*model.LessonNote, error) {
+	defer rows.Close()
+
+	var notes []*model.LessonNote
+	for rows.Next() {
+		note, err := scanLessonNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan lesson note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+// Create создает заметку к занятию
+func (r *LessonNoteRepository) Create(ctx context.Context, note *model.LessonNote) error {
+	query := `
+		INSERT INTO lesson_notes (booking_id, teacher_id, student_id, text, photo_file_id, document_file_id, homework_due_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		note.BookingID,
+		note.TeacherID,
+		note.StudentID,
+		note.Text,
+		note.PhotoFileID,
+		note.DocumentFileID,
+		note.HomeworkDueAt,
+	).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create lesson note: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает заметку по ID
+func (r *LessonNoteRepository) GetByID(ctx context.Context, id int64) (*model.LessonNote, error) {
+	note, err := scanLessonNote(r.pool.QueryRow(ctx, lessonNoteSelect+` WHERE n.id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get lesson note: %w", err)
+	}
+
+	return note, nil
+}
+
+// GetByBookingID получает заметки к занятию в порядке добавления
+func (r *LessonNoteRepository) GetByBookingID(ctx context.Context, bookingID int64) ([]*model.LessonNote, error) {
+	rows, err := r.pool.Query(ctx, lessonNoteSelect+` WHERE n.booking_id = $1 ORDER BY n.created_at, n.id`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("get booking notes: %w", err)
+	}
+
+	return collectLessonNotes(rows)
+}
+
+// GetByStudentID получает заметки студента, сначала новые. teacherID != 0 оставляет заметки одного учителя
+func (r *LessonNoteRepository) GetByStudentID(ctx context.Context, studentID, teacherID int64, limit int) ([]*model.LessonNote, error) {
+	query := lessonNoteSelect + `
+		WHERE n.student_id = $1 AND ($2 = 0 OR n.teacher_id = $2)
+		ORDER BY s.start_time DESC, n.created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, studentID, teacherID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get student notes: %w", err)
+	}
+
+	return collectLessonNotes(rows)
+}
+
+// CountByStudentBookings считает заметки по записям студента
+func (r *LessonNoteRepository) CountByStudentBookings(ctx context.Context, studentID int64) (map[int64]int, error) {
+	query := `SELECT booking_id, COUNT(*) FROM lesson_notes WHERE student_id = $1 GROUP BY booking_id`
+
+	rows, err := r.pool.Query(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("count student notes: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var bookingID int64
+		var count int
+		if err := rows.Scan(&bookingID, &count); err != nil {
+			return nil, fmt.Errorf("scan note count: %w", err)
+		}
+		counts[bookingID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// SetHomeworkDue устанавливает срок домашнего задания; напоминание отправится заново
+func (r *LessonNoteRepository) SetHomeworkDue(ctx context.Context, id int64, dueAt *time.Time) error {
+	query := `UPDATE lesson_notes SET homework_due_at = $2, reminder_sent_at = NULL WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id, dueAt)
+	if err != nil {
+		return fmt.Errorf("set homework due: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("note not found")
+	}
+
+	return nil
+}
+
+// Delete удаляет заметку
+func (r *LessonNoteRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM lesson_notes WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete lesson note: %w", err)
+	}
+
+	return nil
+}
+
+// GetHomeworkDueUnreminded получает заметки, срок домашнего задания которых наступает в (now, dueBefore], без напоминания
+func (r *LessonNoteRepository) GetHomeworkDueUnreminded(ctx context.Context, now, dueBefore time.Time) ([]*model.LessonNote, error) {
+	query := lessonNoteSelect + `
+		WHERE n.homework_due_at > $1 AND n.homework_due_at <= $2 AND n.reminder_sent_at IS NULL
+		ORDER BY n.homework_due_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, now, dueBefore)
+	if err != nil {
+		return nil, fmt.Errorf("get homework due: %w", err)
+	}
+
+	return collectLessonNotes(rows)
+}
+
+// MarkReminded отмечает, что студенту напомнили о сроке домашнего задания
+func (r *LessonNoteRepository) MarkReminded(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE lesson_notes SET reminder_sent_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("mark homework reminded: %w", err)
+	}
+
+	return nil
+}
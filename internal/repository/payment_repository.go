@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPaymentRepository(pool *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{pool: pool}
+}
+
+const paymentColumns = `id, booking_id, student_id, teacher_id, amount, currency, provider, payload, status,
+		       telegram_charge_id, provider_charge_id, expires_at, paid_at, created_at`
+
+// scanPayment читает платёж из строки результата в порядке paymentColumns
+func scanPayment(row pgx.Row) (*model.Payment, error) {
+	var payment model.Payment
+	err := row.Scan(
+		&payment.ID,
+		&payment.BookingID,
+		&payment.StudentID,
+		&payment.TeacherID,
+		&payment.Amount,
+		&payment.Currency,
+		&payment.Provider,
+		&payment.Payload,
+		&payment.Status,
+		&payment.TelegramChargeID,
+		&payment.ProviderChargeID,
+		&payment.ExpiresAt,
+		&payment.PaidAt,
+		&payment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Create создаёт счёт на оплату
+func (r *PaymentRepository) Create(ctx context.Context, payment *model.Payment) error {
+	query := `
+		INSERT INTO payments (booking_id, student_id, teacher_id, amount, currency, provider, payload, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(
+		ctx, query,
+		payment.BookingID,
+		payment.StudentID,
+		payment.TeacherID,
+		payment.Amount,
+		payment.Currency,
+		payment.Provider,
+		payment.Payload,
+		payment.Status,
+		payment.ExpiresAt,
+	).Scan(&payment.ID, &payment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create payment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPayload получает платёж по invoice_payload
+func (r *PaymentRepository) GetByPayload(ctx context.Context, payload string) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE payload = $1`
+
+	payment, err := scanPayment(r.pool.QueryRow(ctx, query, payload))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get payment by payload: %w", err)
+	}
+
+	return payment, nil
+}
+
+// MarkPaid отмечает платёж оплаченным и переводит бронирование из ожидания оплаты в bookingStatus.
+// Платёж фиксируется в любом случае (деньги уже списаны); false означает,
+// что бронирование к моменту оплаты уже не ожидало оплаты
+func (r *PaymentRepository) MarkPaid(ctx context.Context, paymentID int64, telegramChargeID, providerChargeID string, bookingStatus model.BookingStatus) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var bookingID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE payments
+		SET status = 'paid', telegram_charge_id = $1, provider_charge_id = $2, paid_at = NOW()
+		WHERE id = $3 AND status <> 'paid'
+		RETURNING booking_id
+	`, telegramChargeID, providerChargeID, paymentID).Scan(&bookingID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("payment already paid")
+		}
+		return false, fmt.Errorf("mark payment paid: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bookings
+		SET status = $1
+		WHERE id = $2 AND status = 'awaiting_payment'
+	`, bookingStatus, bookingID)
+	if err != nil {
+		return false, fmt.Errorf("confirm paid booking: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// ExpireByBooking закрывает неоплаченные счета бронирования (например, при отмене записи)
+func (r *PaymentRepository) ExpireByBooking(ctx context.Context, bookingID int64) error {
+	query := `
+		UPDATE payments
+		SET status = 'expired'
+		WHERE booking_id = $1 AND status = 'pending'
+	`
+
+	_, err := r.pool.Exec(ctx, query, bookingID)
+	if err != nil {
+		return fmt.Errorf("expire payments by booking: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseExpired в одной транзакции закрывает просроченные счета, отменяет их бронирования
+// и освобождает слоты. Возвращает закрытые платежи
+func (r *PaymentRepository) ReleaseExpired(ctx context.Context, now time.Time) ([]*model.Payment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE payments
+		SET status = 'expired'
+		WHERE status = 'pending' AND expires_at <= $1
+		RETURNING `+paymentColumns, now)
+	if err != nil {
+		return nil, fmt.Errorf("expire payments: %w", err)
+	}
+
+	var payments []*model.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate payments: %w", err)
+	}
+
+	for _, payment := range payments {
+		if err := releaseUnpaidBookingTx(ctx, tx, payment.BookingID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return payments, nil
+}
+
+// ReleaseBooking отменяет неоплаченное бронирование, закрывает его счета и освобождает слот
+func (r *PaymentRepository) ReleaseBooking(ctx context.Context, bookingID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE payments
+		SET status = 'expired'
+		WHERE booking_id = $1 AND status = 'pending'
+	`, bookingID)
+	if err != nil {
+		return fmt.Errorf("expire payments by booking: %w", err)
+	}
+
+	if err := releaseUnpaidBookingTx(ctx, tx, bookingID); err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// releaseUnpaidBookingTx отменяет бронирование в статусе ожидания оплаты, освобождает его слот
+// и возвращает использование промокода. Бронирования в других статусах не трогает
+func releaseUnpaidBookingTx(ctx context.Context, tx pgx.Tx, bookingID int64) error {
+	var slotID int64
+	var promoCodeID *int64
+	err := tx.QueryRow(ctx, `
+		UPDATE bookings
+		SET status = 'canceled'
+		WHERE id = $1 AND status = 'awaiting_payment'
+		RETURNING slot_id, promo_code_id
+	`, bookingID).Scan(&slotID, &promoCodeID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// Бронирование уже отменено или оплачено
+			return nil
+		}
+		return fmt.Errorf("cancel unpaid booking %d: %w", bookingID, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedule_slots
+		SET status = 'free', student_id = NULL
+		WHERE id = $1 AND status = 'booked'
+	`, slotID)
+	if err != nil {
+		return fmt.Errorf("release slot %d: %w", slotID, err)
+	}
+
+	return releasePromoCodeTx(ctx, tx, promoCodeID)
+}
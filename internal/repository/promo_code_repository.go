@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PromoCodeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPromoCodeRepository(pool *pgxpool.Pool) *PromoCodeRepository {
+	return &PromoCodeRepository{pool: pool}
+}
+
+const promoCodeColumns = `id, teacher_id, code, discount_type, discount_value, currency, subject_id, max_uses, current_uses, expires_at, is_active, created_at`
+
+func scanPromoCode(row pgx.Row) (*model.PromoCode, error) {
+	var promo model.PromoCode
+	err := row.Scan(
+		&promo.ID,
+		&promo.TeacherID,
+		&promo.Code,
+		&promo.DiscountType,
+		&promo.DiscountValue,
+		&promo.Currency,
+		&promo.SubjectID,
+		&promo.MaxUses,
+		&promo.CurrentUses,
+		&promo.ExpiresAt,
+		&promo.IsActive,
+		&promo.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// Create создает новый промокод
+func (r *PromoCodeRepository) Create(ctx context.Context, promo *model.PromoCode) error {
+	query := `
+		INSERT INTO promo_codes (teacher_id, code, discount_type, discount_value, currency, subject_id, max_uses, expires_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, current_uses, created_at
+	`
+
+	err := r.pool.QueryRow(
+		ctx, query,
+		promo.TeacherID,
+		promo.Code,
+		promo.DiscountType,
+		promo.DiscountValue,
+		promo.Currency,
+		promo.SubjectID,
+		promo.MaxUses,
+		promo.ExpiresAt,
+		promo.IsActive,
+	).Scan(&promo.ID, &promo.CurrentUses, &promo.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create promo code: %w", err)
+	}
+
+	return nil
+}
+
+// GetByCode получает промокод по строке
+func (r *PromoCodeRepository) GetByCode(ctx context.Context, code string) (*model.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE code = $1`
+
+	promo, err := scanPromoCode(r.pool.QueryRow(ctx, query, code))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get promo code by code: %w", err)
+	}
+
+	return promo, nil
+}
+
+// GetByID получает промокод по ID
+func (r *PromoCodeRepository) GetByID(ctx context.Context, id int64) (*model.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE id = $1`
+
+	promo, err := scanPromoCode(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get promo code by id: %w", err)
+	}
+
+	return promo, nil
+}
+
+// GetByTeacherID получает все промокоды учителя
+func (r *PromoCodeRepository) GetByTeacherID(ctx context.Context, teacherID int64) ([]*model.PromoCode, error) {
+	query := `
+		SELECT ` + promoCodeColumns + `
+		FROM promo_codes
+		WHERE teacher_id = $1
+		ORDER BY is_active DESC, created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get promo codes by teacher: %w", err)
+	}
+	defer rows.Close()
+
+	var promos []*model.PromoCode
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan promo code: %w", err)
+		}
+		promos = append(promos, promo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate promo codes: %w", err)
+	}
+
+	return promos, nil
+}
+
+// Redeem засчитывает использование промокода, если он ещё действует.
+// Возвращает false, если лимит исчерпан, срок истёк или код отключён
+func (r *PromoCodeRepository) Redeem(ctx context.Context, promoID int64) (bool, error) {
+	query := `
+		UPDATE promo_codes
+		SET current_uses = current_uses + 1
+		WHERE id = $1
+		  AND is_active = true
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_uses IS NULL OR current_uses < max_uses)
+	`
+
+	result, err := r.pool.Exec(ctx, query, promoID)
+	if err != nil {
+		return false, fmt.Errorf("redeem promo code: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// releasePromoCodeQuery возвращает одно использование промокода
+const releasePromoCodeQuery = `
+	UPDATE promo_codes
+	SET current_uses = current_uses - 1
+	WHERE id = $1 AND current_uses > 0
+`
+
+// Release возвращает использование промокода, если запись с ним не состоялась или была отменена
+func (r *PromoCodeRepository) Release(ctx context.Context, promoID int64) error {
+	_, err := r.pool.Exec(ctx, releasePromoCodeQuery, promoID)
+	if err != nil {
+		return fmt.Errorf("release promo code: %w", err)
+	}
+
+	return nil
+}
+
+// releasePromoCodeTx возвращает использование промокода отменённого внутри транзакции бронирования.
+// promoID == nil — запись была без промокода
+func releasePromoCodeTx(ctx context.Context, tx pgx.Tx, promoID *int64) error {
+	if promoID == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, releasePromoCodeQuery, *promoID)
+	if err != nil {
+		return fmt.Errorf("release promo code %d: %w", *promoID, err)
+	}
+
+	return nil
+}
+
+// Deactivate деактивирует промокод
+func (r *PromoCodeRepository) Deactivate(ctx context.Context, promoID int64) error {
+	query := `
+		UPDATE promo_codes
+		SET is_active = false
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, promoID)
+	if err != nil {
+		return fmt.Errorf("deactivate promo code: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("promo code not found")
+	}
+
+	return nil
+}
+
+// CodeExists проверяет, существует ли промокод с такой строкой
+func (r *PromoCodeRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM promo_codes WHERE code = $1)`
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, query, code).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check promo code exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+// SetApplication запоминает промокод, применённый студентом к предмету
+func (r *PromoCodeRepository) SetApplication(ctx context.Context, studentID, subjectID, promoID int64) error {
+	query := `
+		INSERT INTO promo_code_applications (student_id, subject_id, promo_code_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (student_id, subject_id)
+		DO UPDATE SET promo_code_id = EXCLUDED.promo_code_id, applied_at = NOW()
+	`
+
+	_, err := r.pool.Exec(ctx, query, studentID, subjectID, promoID)
+	if err != nil {
+		return fmt.Errorf("set promo code application: %w", err)
+	}
+
+	return nil
+}
+
+// GetApplied получает промокод, применённый студентом к предмету
+func (r *PromoCodeRepository) GetApplied(ctx context.Context, studentID, subjectID int64) (*model.PromoCode, error) {
+	query := `
+		SELECT ` + promoCodeColumns + `
+		FROM promo_codes
+		WHERE id = (
+			SELECT promo_code_id FROM promo_code_applications
+			WHERE student_id = $1 AND subject_id = $2
+		)
+	`
+
+	promo, err := scanPromoCode(r.pool.QueryRow(ctx, query, studentID, subjectID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get applied promo code: %w", err)
+	}
+
+	return promo, nil
+}
+
+// DeleteApplication убирает применённый студентом промокод
+func (r *PromoCodeRepository) DeleteApplication(ctx context.Context, studentID, subjectID int64) error {
+	query := `DELETE FROM promo_code_applications WHERE student_id = $1 AND subject_id = $2`
+
+	_, err := r.pool.Exec(ctx, query, studentID, subjectID)
+	if err != nil {
+		return fmt.Errorf("delete promo code application: %w", err)
+	}
+
+	return nil
+}
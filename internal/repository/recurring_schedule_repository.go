@@ -257,6 +257,46 @@ func (r *RecurringScheduleRepository) Delete(ctx context.Context, id int64) erro
 	return nil
 }
 
+// AddConflicts запоминает пропущенные из-за пересечений слоты расписания.
+// Возвращает время начала только тех пропусков, которые раньше не запоминались
+func (r *RecurringScheduleRepository) AddConflicts(ctx context.Context, scheduleID int64, startTimes []time.Time) ([]time.Time, error) {
+	query := `
+		INSERT INTO recurring_slot_conflicts (recurring_schedule_id, start_time)
+		SELECT $1, unnest($2::timestamptz[])
+		ON CONFLICT DO NOTHING
+		RETURNING start_time
+	`
+
+	rows, err := r.pool.Query(ctx, query, scheduleID, startTimes)
+	if err != nil {
+		return nil, fmt.Errorf("add recurring slot conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var added []time.Time
+	for rows.Next() {
+		var startTime time.Time
+		if err := rows.Scan(&startTime); err != nil {
+			return nil, fmt.Errorf("scan recurring slot conflict: %w", err)
+		}
+		added = append(added, startTime)
+	}
+
+	return added, rows.Err()
+}
+
+// DeleteConflictsBefore удаляет запомненные пропуски слотов, начавшихся до before
+func (r *RecurringScheduleRepository) DeleteConflictsBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM recurring_slot_conflicts WHERE start_time < $1`
+
+	_, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		return fmt.Errorf("delete recurring slot conflicts: %w", err)
+	}
+
+	return nil
+}
+
 // GetSchedulesNeedingSlots возвращает recurring schedules, для которых нужно создать слоты
 // на указанную дату
 func (r *RecurringScheduleRepository) GetSchedulesNeedingSlots(ctx context.Context, date time.Time) ([]*model.RecurringSchedule, error) {
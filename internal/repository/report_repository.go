@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// heldLessonCondition условие проведённого занятия: завершено или подтверждено и уже закончилось
+const heldLessonCondition = `(b.status = 'completed' OR (b.status = 'confirmed' AND s.end_time <= NOW()))`
+
+// ReportRepository считает агрегаты для отчётов учителя.
+// Период задаётся по времени начала слота: [from, to)
+type ReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{pool: pool}
+}
+
+// GetBookingCounts возвращает количество проведённых занятий, отмен и неявок
+func (r *ReportRepository) GetBookingCounts(ctx context.Context, teacherID int64, from, to time.Time) (held, canceled, noShows int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE ` + heldLessonCondition + `)::int,
+		       COUNT(*) FILTER (WHERE b.status = 'canceled')::int,
+		       COUNT(*) FILTER (WHERE b.status = 'no_show')::int
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		WHERE b.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+	`
+
+	err = r.pool.QueryRow(ctx, query, teacherID, from, to).Scan(&held, &canceled, &noShows)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("get booking counts: %w", err)
+	}
+
+	return held, canceled, noShows, nil
+}
+
+// GetSlotMinutes возвращает время слотов, занятых студентами, и свободных слотов в минутах.
+// Слоты, отмеченные учителем как занятые без студента, не учитываются
+func (r *ReportRepository) GetSlotMinutes(ctx context.Context, teacherID int64, from, to time.Time) (booked, free int, err error) {
+	query := `
+		SELECT COALESCE(SUM(EXTRACT(EPOCH FROM end_time - start_time)) FILTER (WHERE status = 'booked' AND student_id IS NOT NULL), 0)::bigint / 60,
+		       COALESCE(SUM(EXTRACT(EPOCH FROM end_time - start_time)) FILTER (WHERE status = 'free'), 0)::bigint / 60
+		FROM schedule_slots
+		WHERE teacher_id = $1 AND start_time >= $2 AND start_time < $3
+	`
+
+	var bookedMinutes, freeMinutes int64
+	err = r.pool.QueryRow(ctx, query, teacherID, from, to).Scan(&bookedMinutes, &freeMinutes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get slot minutes: %w", err)
+	}
+
+	return int(bookedMinutes), int(freeMinutes), nil
+}
+
+// GetRevenueBySubject возвращает выручку проведённых занятий по предметам и валютам
+func (r *ReportRepository) GetRevenueBySubject(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.SubjectRevenue, error) {
+	query := `
+		SELECT sub.id, sub.name, b.currency, COUNT(*)::int, COALESCE(SUM(b.price), 0)::int
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		INNER JOIN subjects sub ON sub.id = b.subject_id
+		WHERE b.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		  AND ` + heldLessonCondition + `
+		GROUP BY sub.id, sub.name, b.currency
+		ORDER BY b.currency, SUM(b.price) DESC, sub.name
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query revenue by subject: %w", err)
+	}
+	defer rows.Close()
+
+	var revenue []*model.SubjectRevenue
+	for rows.Next() {
+		var item model.SubjectRevenue
+		if err := rows.Scan(&item.SubjectID, &item.SubjectName, &item.Currency, &item.Lessons, &item.Amount); err != nil {
+			return nil, fmt.Errorf("scan subject revenue: %w", err)
+		}
+		revenue = append(revenue, &item)
+	}
+
+	return revenue, rows.Err()
+}
+
+// GetLessonLoad возвращает количество занятий по дню недели и часу начала.
+// Учитываются проведённые, предстоящие подтверждённые занятия и неявки.
+// utcOffset — смещение часового пояса бота, в котором считаются дни и часы
+func (r *ReportRepository) GetLessonLoad(ctx context.Context, teacherID int64, from, to time.Time, utcOffset time.Duration) ([]*model.LessonLoad, error) {
+	query := `
+		WITH lessons AS (
+			SELECT s.start_time AT TIME ZONE make_interval(secs => $4) AS local_start
+			FROM bookings b
+			INNER JOIN schedule_slots s ON s.id = b.slot_id
+			WHERE b.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+			  AND b.status IN ('completed', 'confirmed', 'no_show')
+		)
+		SELECT EXTRACT(DOW FROM local_start)::int, EXTRACT(HOUR FROM local_start)::int, COUNT(*)::int
+		FROM lessons
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to, utcOffset.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("query lesson load: %w", err)
+	}
+	defer rows.Close()
+
+	var load []*model.LessonLoad
+	for rows.Next() {
+		var item model.LessonLoad
+		var weekday int
+		if err := rows.Scan(&weekday, &item.Hour, &item.Lessons); err != nil {
+			return nil, fmt.Errorf("scan lesson load: %w", err)
+		}
+		item.Weekday = time.Weekday(weekday)
+		load = append(load, &item)
+	}
+
+	return load, rows.Err()
+}
+
+// GetTopStudents возвращает студентов с наибольшим числом проведённых занятий
+func (r *ReportRepository) GetTopStudents(ctx context.Context, teacherID int64, from, to time.Time, limit int) ([]*model.StudentActivity, error) {
+	query := `
+		SELECT b.student_id, CONCAT_WS(' ', u.first_name, NULLIF(u.last_name, '')), COUNT(*)::int
+		FROM bookings b
+		INNER JOIN schedule_slots s ON s.id = b.slot_id
+		INNER JOIN users u ON u.id = b.student_id
+		WHERE b.teacher_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		  AND ` + heldLessonCondition + `
+		GROUP BY b.student_id, u.first_name, u.last_name
+		ORDER BY COUNT(*) DESC, u.first_name, u.last_name
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query top students: %w", err)
+	}
+	defer rows.Close()
+
+	var students []*model.StudentActivity
+	for rows.Next() {
+		var item model.StudentActivity
+		if err := rows.Scan(&item.StudentID, &item.Name, &item.Lessons); err != nil {
+			return nil, fmt.Errorf("scan student activity: %w", err)
+		}
+		students = append(students, &item)
+	}
+
+	return students, rows.Err()
+}
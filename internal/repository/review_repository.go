@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReviewRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewRepository(pool *pgxpool.Pool) *ReviewRepository {
+	return &ReviewRepository{pool: pool}
+}
+
+// reviewSelect выбирает отзывы вместе с именем студента и предметом занятия
+const reviewSelect = `
+	SELECT r.id, r.booking_id, r.teacher_id, r.student_id, r.rating, r.comment,
+	       r.teacher_reply, r.replied_at, r.reported_at, r.created_at,
+	       u.first_name, sub.name
+	FROM reviews r
+	JOIN users u ON u.id = r.student_id
+	JOIN bookings b ON b.id = r.booking_id
+	JOIN subjects sub ON sub.id = b.subject_id
+`
+
+func scanReview(row pgx.Row) (*model.Review, error) {
+	var review model.Review
+	err := row.Scan(
+		&review.ID,
+		&review.BookingID,
+		&review.TeacherID,
+		&review.StudentID,
+		&review.Rating,
+		&review.Comment,
+		&review.TeacherReply,
+		&review.RepliedAt,
+		&review.ReportedAt,
+		&review.CreatedAt,
+		&review.StudentName,
+		&review.SubjectName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Create создаёт отзыв. Второй отзыв на ту же запись возвращает ошибку "already reviewed"
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) error {
+	query := `
+		INSERT INTO reviews (booking_id, teacher_id, student_id, rating, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		review.BookingID,
+		review.TeacherID,
+		review.StudentID,
+		review.Rating,
+		review.Comment,
+	).Scan(&review.ID, &review.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("already reviewed")
+		}
+		return fmt.Errorf("create review: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает отзыв по ID
+func (r *ReviewRepository) GetByID(ctx context.Context, id int64) (*model.Review, error) {
+	review, err := scanReview(r.pool.QueryRow(ctx, reviewSelect+` WHERE r.id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+
+	return review, nil
+}
+
+// GetByTeacherID получает последние отзывы об учителе
+func (r *ReviewRepository) GetByTeacherID(ctx context.Context, teacherID int64, limit int) ([]*model.Review, error) {
+	query := reviewSelect + `
+		WHERE r.teacher_id = $1
+		ORDER BY r.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*model.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// GetRatings считает сводные оценки учителей. Учителя без отзывов в результат не попадают
+func (r *ReviewRepository) GetRatings(ctx context.Context, teacherIDs []int64) (map[int64]*model.TeacherRating, error) {
+	query := `
+		SELECT teacher_id, AVG(rating)::float8, COUNT(*)
+		FROM reviews
+		WHERE teacher_id = ANY($1)
+		GROUP BY teacher_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, teacherIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[int64]*model.TeacherRating)
+	for rows.Next() {
+		var rating model.TeacherRating
+		if err := rows.Scan(&rating.TeacherID, &rating.Average, &rating.Count); err != nil {
+			return nil, fmt.Errorf("scan teacher rating: %w", err)
+		}
+		ratings[rating.TeacherID] = &rating
+	}
+
+	return ratings, rows.Err()
+}
+
+// SetComment сохраняет комментарий студента к отзыву
+func (r *ReviewRepository) SetComment(ctx context.Context, id int64, comment string) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE reviews SET comment = $2 WHERE id = $1`, id, comment); err != nil {
+		return fmt.Errorf("set review comment: %w", err)
+	}
+
+	return nil
+}
+
+// SetReply сохраняет ответ учителя на отзыв
+func (r *ReviewRepository) SetReply(ctx context.Context, id int64, reply string) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE reviews SET teacher_reply = $2, replied_at = NOW() WHERE id = $1`, id, reply); err != nil {
+		return fmt.Errorf("set review reply: %w", err)
+	}
+
+	return nil
+}
+
+// MarkReported отмечает жалобу учителя на отзыв
+func (r *ReviewRepository) MarkReported(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE reviews SET reported_at = NOW() WHERE id = $1 AND reported_at IS NULL`, id); err != nil {
+		return fmt.Errorf("report review: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnrequestedCompleted получает завершённые после since занятия без отзыва, по которым студента ещё не просили об оценке
+func (r *ReviewRepository) GetUnrequestedCompleted(ctx context.Context, since time.Time) ([]*model.Booking, error) {
+	query := `
+		SELECT b.id, b.student_id, b.teacher_id, b.subject_id, b.slot_id, b.status, b.price, b.currency, b.promo_code_id, b.created_at, b.updated_at
+		FROM bookings b
+		JOIN schedule_slots s ON s.id = b.slot_id
+		WHERE b.status = 'completed'
+		  AND b.review_requested_at IS NULL
+		  AND s.end_time >= $1
+		  AND NOT EXISTS (SELECT 1 FROM reviews r WHERE r.booking_id = b.id)
+		ORDER BY s.end_time
+	`
+
+	rows, err := r.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("get bookings for review: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*model.Booking
+	for rows.Next() {
+		var booking model.Booking
+		err := rows.Scan(
+			&booking.ID,
+			&booking.StudentID,
+			&booking.TeacherID,
+			&booking.SubjectID,
+			&booking.SlotID,
+			&booking.Status,
+			&booking.Price,
+			&booking.Currency,
+			&booking.PromoCodeID,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan booking for review: %w", err)
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// MarkRequested отмечает, что студента попросили оценить занятие
+func (r *ReviewRepository) MarkRequested(ctx context.Context, bookingID int64) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE bookings SET review_requested_at = NOW() WHERE id = $1`, bookingID); err != nil {
+		return fmt.Errorf("mark review requested: %w", err)
+	}
+
+	return nil
+}
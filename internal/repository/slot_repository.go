@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -37,6 +39,9 @@ func (r *SlotRepository) Create(ctx context.Context, slot *model.ScheduleSlot) e
 	).Scan(&slot.ID, &slot.CreatedAt)
 
 	if err != nil {
+		if isSlotOverlapError(err) {
+			return ErrSlotOverlap
+		}
 		return fmt.Errorf("create slot: %w", err)
 	}
 
@@ -46,7 +51,7 @@ func (r *SlotRepository) Create(ctx context.Context, slot *model.ScheduleSlot) e
 // GetByID получает слот по ID
 func (r *SlotRepository) GetByID(ctx context.Context, id int64) (*model.ScheduleSlot, error) {
 	query := `
-		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, created_at
+		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, group_id, created_at
 		FROM schedule_slots
 		WHERE id = $1
 	`
@@ -61,6 +66,7 @@ func (r *SlotRepository) GetByID(ctx context.Context, id int64) (*model.Schedule
 		&slot.Status,
 		&slot.StudentID,
 		&slot.Comment,
+		&slot.GroupID,
 		&slot.CreatedAt,
 	)
 
@@ -74,19 +80,40 @@ func (r *SlotRepository) GetByID(ctx context.Context, id int64) (*model.Schedule
 	return &slot, nil
 }
 
-// GetFreeSlots получает свободные слоты для предмета в заданном диапазоне времени
-func (r *SlotRepository) GetFreeSlots(ctx context.Context, subjectID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
+// bufferConflictCondition подзапрос, находящий занятые слоты того же учителя,
+// которые вместе с перерывами пересекаются со слотом s (предмет слота — ss)
+const bufferConflictCondition = `
+			SELECT 1
+			FROM schedule_slots b
+			JOIN subjects bs ON bs.id = b.subject_id
+			WHERE b.teacher_id = s.teacher_id
+			  AND b.id <> s.id
+			  AND b.status = 'booked'
+			  AND b.start_time - make_interval(mins => bs.buffer_before_minutes + ss.buffer_after_minutes) < s.end_time
+			  AND b.end_time + make_interval(mins => bs.buffer_after_minutes + ss.buffer_before_minutes) > s.start_time
+		`
+
+// GetFreeSlots получает свободные слоты для предмета в заданном диапазоне времени,
+// доступные студенту: слоты, закреплённые за группой, видны только её участникам
+func (r *SlotRepository) GetFreeSlots(ctx context.Context, subjectID, studentID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	// Слоты, попадающие в перерыв вокруг занятых слотов учителя, не считаются свободными
 	query := `
-		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, created_at
-		FROM schedule_slots
-		WHERE subject_id = $1
-		  AND status = 'free'
-		  AND start_time >= $2
-		  AND start_time < $3
-		ORDER BY start_time
+		SELECT s.id, s.teacher_id, s.subject_id, s.start_time, s.end_time, s.status, s.student_id, s.comment, s.group_id, s.created_at
+		FROM schedule_slots s
+		JOIN subjects ss ON ss.id = s.subject_id
+		WHERE s.subject_id = $1
+		  AND s.status = 'free'
+		  AND s.start_time >= $2
+		  AND s.start_time < $3
+		  AND NOT EXISTS (` + bufferConflictCondition + `)
+		  AND (s.group_id IS NULL OR EXISTS (
+			SELECT 1 FROM student_group_members m
+			WHERE m.group_id = s.group_id AND m.student_id = $4
+		  ))
+		ORDER BY s.start_time
 	`
 
-	rows, err := r.pool.Query(ctx, query, subjectID, from, to)
+	rows, err := r.pool.Query(ctx, query, subjectID, from, to, studentID)
 	if err != nil {
 		return nil, fmt.Errorf("get free slots: %w", err)
 	}
@@ -104,6 +131,7 @@ func (r *SlotRepository) GetFreeSlots(ctx context.Context, subjectID int64, from
 			&slot.Status,
 			&slot.StudentID,
 			&slot.Comment,
+			&slot.GroupID,
 			&slot.CreatedAt,
 		)
 		if err != nil {
@@ -115,10 +143,70 @@ func (r *SlotRepository) GetFreeSlots(ctx context.Context, subjectID int64, from
 	return slots, nil
 }
 
+// GetFreeSlotsForSubjects получает свободные слоты нескольких предметов в заданном диапазоне времени,
+// доступные студенту, по тем же правилам, что и GetFreeSlots
+func (r *SlotRepository) GetFreeSlotsForSubjects(ctx context.Context, subjectIDs []int64, studentID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	return r.GetFreedSlotsForSubjects(ctx, subjectIDs, studentID, time.Time{}, from, to)
+}
+
+// GetFreedSlotsForSubjects как GetFreeSlotsForSubjects, но только слоты, ставшие свободными после freedAfter:
+// созданные учителем или освободившиеся после отмены записи
+func (r *SlotRepository) GetFreedSlotsForSubjects(ctx context.Context, subjectIDs []int64, studentID int64, freedAfter, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	if len(subjectIDs) == 0 {
+		return []*model.ScheduleSlot{}, nil
+	}
+
+	query := `
+		SELECT s.id, s.teacher_id, s.subject_id, s.start_time, s.end_time, s.status, s.student_id, s.comment, s.group_id, s.created_at
+		FROM schedule_slots s
+		JOIN subjects ss ON ss.id = s.subject_id
+		WHERE s.subject_id = ANY($1)
+		  AND s.status = 'free'
+		  AND s.start_time >= $2
+		  AND s.start_time < $3
+		  AND s.freed_at > $5
+		  AND NOT EXISTS (` + bufferConflictCondition + `)
+		  AND (s.group_id IS NULL OR EXISTS (
+			SELECT 1 FROM student_group_members m
+			WHERE m.group_id = s.group_id AND m.student_id = $4
+		  ))
+		ORDER BY s.start_time, s.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, subjectIDs, from, to, studentID, freedAfter)
+	if err != nil {
+		return nil, fmt.Errorf("get free slots for subjects: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []*model.ScheduleSlot
+	for rows.Next() {
+		var slot model.ScheduleSlot
+		err := rows.Scan(
+			&slot.ID,
+			&slot.TeacherID,
+			&slot.SubjectID,
+			&slot.StartTime,
+			&slot.EndTime,
+			&slot.Status,
+			&slot.StudentID,
+			&slot.Comment,
+			&slot.GroupID,
+			&slot.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan slot: %w", err)
+		}
+		slots = append(slots, &slot)
+	}
+
+	return slots, rows.Err()
+}
+
 // GetByTeacherID получает все слоты учителя
 func (r *SlotRepository) GetByTeacherID(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
 	query := `
-		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, created_at
+		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, group_id, created_at
 		FROM schedule_slots
 		WHERE teacher_id = $1
 		  AND start_time >= $2
@@ -144,6 +232,7 @@ func (r *SlotRepository) GetByTeacherID(ctx context.Context, teacherID int64, fr
 			&slot.Status,
 			&slot.StudentID,
 			&slot.Comment,
+			&slot.GroupID,
 			&slot.CreatedAt,
 		)
 		if err != nil {
@@ -230,6 +319,9 @@ func (r *SlotRepository) UpdateStatus(ctx context.Context, slotID int64, status
 
 	result, err := r.pool.Exec(ctx, query, status, slotID)
 	if err != nil {
+		if isSlotOverlapError(err) {
+			return ErrSlotOverlap
+		}
 		return fmt.Errorf("update slot status: %w", err)
 	}
 
@@ -257,3 +349,329 @@ func (r *SlotRepository) SlotExists(ctx context.Context, teacherID int64, startT
 
 	return exists, nil
 }
+
+// HasBufferConflict проверяет, пересекается ли слот с перерывами вокруг занятых слотов учителя
+func (r *SlotRepository) HasBufferConflict(ctx context.Context, slotID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM schedule_slots s
+			JOIN subjects ss ON ss.id = s.subject_id
+			WHERE s.id = $1
+			  AND EXISTS (` + bufferConflictCondition + `)
+		)
+	`
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, query, slotID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check buffer conflict: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindOverlapping возвращает первый активный (не отменённый) слот учителя,
+// пересекающийся с интервалом [startTime, endTime), или nil
+func (r *SlotRepository) FindOverlapping(ctx context.Context, teacherID int64, startTime, endTime time.Time) (*model.ScheduleSlot, error) {
+	query := `
+		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, group_id, created_at
+		FROM schedule_slots
+		WHERE teacher_id = $1
+		  AND status <> 'canceled'
+		  AND tstzrange(start_time, end_time, '[)') && tstzrange($2, $3, '[)')
+		ORDER BY start_time
+		LIMIT 1
+	`
+
+	var slot model.ScheduleSlot
+	err := r.pool.QueryRow(ctx, query, teacherID, startTime, endTime).Scan(
+		&slot.ID,
+		&slot.TeacherID,
+		&slot.SubjectID,
+		&slot.StartTime,
+		&slot.EndTime,
+		&slot.Status,
+		&slot.StudentID,
+		&slot.Comment,
+		&slot.GroupID,
+		&slot.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find overlapping slot: %w", err)
+	}
+
+	return &slot, nil
+}
+
+// GetUnnotifiedOverlapResolutions получает слоты, изменённые при введении запрета на пересечения,
+// о которых учителя ещё не уведомлены
+func (r *SlotRepository) GetUnnotifiedOverlapResolutions(ctx context.Context) ([]*model.SlotOverlapResolution, error) {
+	query := `
+		SELECT o.id, o.teacher_id, o.resolution, s.id, s.subject_id, s.start_time, s.end_time, s.status
+		FROM slot_overlap_resolutions o
+		INNER JOIN schedule_slots s ON s.id = o.slot_id
+		WHERE o.notified_at IS NULL
+		ORDER BY o.teacher_id, s.start_time
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get overlap resolutions: %w", err)
+	}
+	defer rows.Close()
+
+	var resolutions []*model.SlotOverlapResolution
+	for rows.Next() {
+		resolution := &model.SlotOverlapResolution{Slot: &model.ScheduleSlot{}}
+		err := rows.Scan(
+			&resolution.ID,
+			&resolution.TeacherID,
+			&resolution.Action,
+			&resolution.Slot.ID,
+			&resolution.Slot.SubjectID,
+			&resolution.Slot.StartTime,
+			&resolution.Slot.EndTime,
+			&resolution.Slot.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan overlap resolution: %w", err)
+		}
+		resolution.Slot.TeacherID = resolution.TeacherID
+		resolutions = append(resolutions, resolution)
+	}
+
+	return resolutions, rows.Err()
+}
+
+// MarkOverlapResolutionsNotified отмечает, что учителя уведомлены об изменённых слотах
+func (r *SlotRepository) MarkOverlapResolutionsNotified(ctx context.Context, ids []int64) error {
+	query := `
+		UPDATE slot_overlap_resolutions
+		SET notified_at = NOW()
+		WHERE id = ANY($1)
+	`
+
+	_, err := r.pool.Exec(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("mark overlap resolutions notified: %w", err)
+	}
+
+	return nil
+}
+
+// ErrSlotOverlap возвращается, когда слот пересекается по времени с другим активным слотом учителя
+var ErrSlotOverlap = errors.New("slot overlaps existing slot")
+
+// isSlotOverlapError проверяет, что ошибка вызвана ограничением на пересечение активных слотов учителя
+func isSlotOverlapError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+// BulkApply применяет действие к слотам учителя в одной транзакции.
+// Неподходящие слоты (по статусу, уже начавшиеся, пересекающиеся при восстановлении)
+// не изменяются и попадают в Skipped. Активные бронирования студентов отменяются
+// и возвращаются в CanceledBookings.
+func (r *SlotRepository) BulkApply(ctx context.Context, teacherID int64, slotIDs []int64, action model.SlotBulkAction) (*model.SlotBulkResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT id, teacher_id, subject_id, start_time, end_time, status, student_id, comment, group_id, created_at
+		FROM schedule_slots
+		WHERE id = ANY($1) AND teacher_id = $2
+		ORDER BY start_time
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, query, slotIDs, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("lock slots: %w", err)
+	}
+
+	var slots []*model.ScheduleSlot
+	for rows.Next() {
+		var slot model.ScheduleSlot
+		err := rows.Scan(
+			&slot.ID,
+			&slot.TeacherID,
+			&slot.SubjectID,
+			&slot.StartTime,
+			&slot.EndTime,
+			&slot.Status,
+			&slot.StudentID,
+			&slot.Comment,
+			&slot.GroupID,
+			&slot.CreatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan slot: %w", err)
+		}
+		slots = append(slots, &slot)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate slots: %w", err)
+	}
+
+	result := &model.SlotBulkResult{}
+	now := time.Now()
+
+	for _, slot := range slots {
+		// Прошедшие и уже идущие занятия не трогаем
+		if !slot.StartTime.After(now) {
+			result.Skipped = append(result.Skipped, slot)
+			continue
+		}
+
+		applied, booking, err := applyBulkAction(ctx, tx, slot, action)
+		if err != nil {
+			return nil, err
+		}
+
+		if !applied {
+			result.Skipped = append(result.Skipped, slot)
+			continue
+		}
+
+		result.Processed = append(result.Processed, slot)
+		if booking != nil {
+			result.CanceledBookings = append(result.CanceledBookings, booking)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyBulkAction применяет действие к одному заблокированному слоту внутри транзакции
+func applyBulkAction(ctx context.Context, tx pgx.Tx, slot *model.ScheduleSlot, action model.SlotBulkAction) (bool, *model.Booking, error) {
+	switch action {
+	case model.SlotBulkCancel:
+		if slot.Status == model.SlotStatusCanceled {
+			return false, nil, nil
+		}
+
+		var booking *model.Booking
+		if slot.Status == model.SlotStatusBooked {
+			var err error
+			booking, err = cancelActiveBookingTx(ctx, tx, slot)
+			if err != nil {
+				return false, nil, err
+			}
+		}
+
+		_, err := tx.Exec(ctx, `UPDATE schedule_slots SET status = 'canceled', student_id = NULL WHERE id = $1`, slot.ID)
+		if err != nil {
+			return false, nil, fmt.Errorf("cancel slot %d: %w", slot.ID, err)
+		}
+		return true, booking, nil
+
+	case model.SlotBulkRestore:
+		if slot.Status != model.SlotStatusCanceled {
+			return false, nil, nil
+		}
+
+		// Savepoint: пересечение с другим слотом не должно откатывать всю операцию
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return false, nil, fmt.Errorf("begin savepoint: %w", err)
+		}
+
+		_, err = sp.Exec(ctx, `UPDATE schedule_slots SET status = 'free' WHERE id = $1`, slot.ID)
+		if err != nil {
+			sp.Rollback(ctx)
+			if isSlotOverlapError(err) {
+				return false, nil, nil
+			}
+			return false, nil, fmt.Errorf("restore slot %d: %w", slot.ID, err)
+		}
+
+		if err := sp.Commit(ctx); err != nil {
+			return false, nil, fmt.Errorf("release savepoint: %w", err)
+		}
+		return true, nil, nil
+
+	case model.SlotBulkBusy:
+		if slot.Status != model.SlotStatusFree {
+			return false, nil, nil
+		}
+
+		_, err := tx.Exec(ctx, `UPDATE schedule_slots SET status = 'booked', student_id = NULL WHERE id = $1`, slot.ID)
+		if err != nil {
+			return false, nil, fmt.Errorf("mark slot %d busy: %w", slot.ID, err)
+		}
+		return true, nil, nil
+
+	case model.SlotBulkDelete:
+		var booking *model.Booking
+		if slot.Status == model.SlotStatusBooked {
+			var err error
+			booking, err = cancelActiveBookingTx(ctx, tx, slot)
+			if err != nil {
+				return false, nil, err
+			}
+		}
+
+		_, err := tx.Exec(ctx, `DELETE FROM schedule_slots WHERE id = $1`, slot.ID)
+		if err != nil {
+			return false, nil, fmt.Errorf("delete slot %d: %w", slot.ID, err)
+		}
+		return true, booking, nil
+	}
+
+	return false, nil, fmt.Errorf("unknown bulk action: %s", action)
+}
+
+// cancelActiveBookingTx отменяет активное бронирование слота внутри транзакции
+// и возвращает использование его промокода. Возвращает nil, если слот занят самим преподавателем
+func cancelActiveBookingTx(ctx context.Context, tx pgx.Tx, slot *model.ScheduleSlot) (*model.Booking, error) {
+	query := `
+		UPDATE bookings
+		SET status = 'canceled'
+		WHERE slot_id = $1 AND status IN ('pending', 'confirmed', 'awaiting_payment')
+		RETURNING id, student_id, teacher_id, subject_id, slot_id, status, price, currency, promo_code_id, created_at, updated_at
+	`
+
+	var booking model.Booking
+	err := tx.QueryRow(ctx, query, slot.ID).Scan(
+		&booking.ID,
+		&booking.StudentID,
+		&booking.TeacherID,
+		&booking.SubjectID,
+		&booking.SlotID,
+		&booking.Status,
+		&booking.Price,
+		&booking.Currency,
+		&booking.PromoCodeID,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cancel booking for slot %d: %w", slot.ID, err)
+	}
+
+	if err := releasePromoCodeTx(ctx, tx, booking.PromoCodeID); err != nil {
+		return nil, err
+	}
+
+	booking.Slot = slot
+	return &booking, nil
+}
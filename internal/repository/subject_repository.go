@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/jackc/pgx/v5"
@@ -34,8 +35,11 @@ func (r *SubjectRepository) Create(ctx context.Context, subject *model.Subject)
 		zap.Bool("requires_approval", subject.RequiresBookingApproval))
 
 	query := `
-		INSERT INTO subjects (teacher_id, name, description, price, duration, is_active, requires_booking_approval)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO subjects (teacher_id, name, description, price, duration, is_active, requires_booking_approval,
+		                      min_notice_minutes, max_advance_days, max_active_bookings, max_bookings_per_week,
+		                      buffer_before_minutes, buffer_after_minutes, requires_prepayment, free_cancel_hours, currency,
+		                      category, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		RETURNING id, created_at
 	`
 
@@ -48,6 +52,17 @@ func (r *SubjectRepository) Create(ctx context.Context, subject *model.Subject)
 		subject.Duration,
 		subject.IsActive,
 		subject.RequiresBookingApproval,
+		subject.MinNoticeMinutes,
+		subject.MaxAdvanceDays,
+		subject.MaxActiveBookings,
+		subject.MaxBookingsPerWeek,
+		subject.BufferBeforeMinutes,
+		subject.BufferAfterMinutes,
+		subject.RequiresPrepayment,
+		subject.FreeCancelHours,
+		subject.Currency,
+		subject.Category,
+		subjectTags(subject),
 	).Scan(&subject.ID, &subject.CreatedAt)
 
 	if err != nil {
@@ -69,7 +84,10 @@ func (r *SubjectRepository) Create(ctx context.Context, subject *model.Subject)
 // GetByID получает предмет по ID
 func (r *SubjectRepository) GetByID(ctx context.Context, id int64) (*model.Subject, error) {
 	query := `
-		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at
+		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at,
+		       min_notice_minutes, max_advance_days, max_active_bookings, max_bookings_per_week,
+		       buffer_before_minutes, buffer_after_minutes, requires_prepayment, free_cancel_hours, currency,
+		       category, tags
 		FROM subjects
 		WHERE id = $1
 	`
@@ -85,6 +103,17 @@ func (r *SubjectRepository) GetByID(ctx context.Context, id int64) (*model.Subje
 		&subject.IsActive,
 		&subject.RequiresBookingApproval,
 		&subject.CreatedAt,
+		&subject.MinNoticeMinutes,
+		&subject.MaxAdvanceDays,
+		&subject.MaxActiveBookings,
+		&subject.MaxBookingsPerWeek,
+		&subject.BufferBeforeMinutes,
+		&subject.BufferAfterMinutes,
+		&subject.RequiresPrepayment,
+		&subject.FreeCancelHours,
+		&subject.Currency,
+		&subject.Category,
+		&subject.Tags,
 	)
 
 	if err != nil {
@@ -103,7 +132,10 @@ func (r *SubjectRepository) GetByTeacherID(ctx context.Context, teacherID int64)
 		zap.Int64("teacher_id", teacherID))
 
 	query := `
-		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at
+		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at,
+		       min_notice_minutes, max_advance_days, max_active_bookings, max_bookings_per_week,
+		       buffer_before_minutes, buffer_after_minutes, requires_prepayment, free_cancel_hours, currency,
+		       category, tags
 		FROM subjects
 		WHERE teacher_id = $1
 		ORDER BY created_at DESC
@@ -131,6 +163,17 @@ func (r *SubjectRepository) GetByTeacherID(ctx context.Context, teacherID int64)
 			&subject.IsActive,
 			&subject.RequiresBookingApproval,
 			&subject.CreatedAt,
+			&subject.MinNoticeMinutes,
+			&subject.MaxAdvanceDays,
+			&subject.MaxActiveBookings,
+			&subject.MaxBookingsPerWeek,
+			&subject.BufferBeforeMinutes,
+			&subject.BufferAfterMinutes,
+			&subject.RequiresPrepayment,
+			&subject.FreeCancelHours,
+			&subject.Currency,
+			&subject.Category,
+			&subject.Tags,
 		)
 		if err != nil {
 			r.logger.Error("Failed to scan subject",
@@ -150,7 +193,10 @@ func (r *SubjectRepository) GetByTeacherID(ctx context.Context, teacherID int64)
 // GetActive получает все активные предметы
 func (r *SubjectRepository) GetActive(ctx context.Context) ([]*model.Subject, error) {
 	query := `
-		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at
+		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at,
+		       min_notice_minutes, max_advance_days, max_active_bookings, max_bookings_per_week,
+		       buffer_before_minutes, buffer_after_minutes, requires_prepayment, free_cancel_hours, currency,
+		       category, tags
 		FROM subjects
 		WHERE is_active = true
 		ORDER BY name
@@ -175,6 +221,17 @@ func (r *SubjectRepository) GetActive(ctx context.Context) ([]*model.Subject, er
 			&subject.IsActive,
 			&subject.RequiresBookingApproval,
 			&subject.CreatedAt,
+			&subject.MinNoticeMinutes,
+			&subject.MaxAdvanceDays,
+			&subject.MaxActiveBookings,
+			&subject.MaxBookingsPerWeek,
+			&subject.BufferBeforeMinutes,
+			&subject.BufferAfterMinutes,
+			&subject.RequiresPrepayment,
+			&subject.FreeCancelHours,
+			&subject.Currency,
+			&subject.Category,
+			&subject.Tags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan subject: %w", err)
@@ -189,8 +246,11 @@ func (r *SubjectRepository) GetActive(ctx context.Context) ([]*model.Subject, er
 func (r *SubjectRepository) Update(ctx context.Context, subject *model.Subject) error {
 	query := `
 		UPDATE subjects
-		SET name = $1, description = $2, price = $3, duration = $4, is_active = $5, requires_booking_approval = $6
-		WHERE id = $7
+		SET name = $1, description = $2, price = $3, duration = $4, is_active = $5, requires_booking_approval = $6,
+		    min_notice_minutes = $7, max_advance_days = $8, max_active_bookings = $9, max_bookings_per_week = $10,
+		    buffer_before_minutes = $11, buffer_after_minutes = $12, requires_prepayment = $13,
+		    free_cancel_hours = $14, currency = $15, category = $16, tags = $17
+		WHERE id = $18
 	`
 
 	result, err := r.pool.Exec(
@@ -201,6 +261,17 @@ func (r *SubjectRepository) Update(ctx context.Context, subject *model.Subject)
 		subject.Duration,
 		subject.IsActive,
 		subject.RequiresBookingApproval,
+		subject.MinNoticeMinutes,
+		subject.MaxAdvanceDays,
+		subject.MaxActiveBookings,
+		subject.MaxBookingsPerWeek,
+		subject.BufferBeforeMinutes,
+		subject.BufferAfterMinutes,
+		subject.RequiresPrepayment,
+		subject.FreeCancelHours,
+		subject.Currency,
+		subject.Category,
+		subjectTags(subject),
 		subject.ID,
 	)
 
@@ -234,7 +305,10 @@ func (r *SubjectRepository) Delete(ctx context.Context, id int64) error {
 // GetPublicActive получает активные предметы публичных учителей
 func (r *SubjectRepository) GetPublicActive(ctx context.Context) ([]*model.Subject, error) {
 	query := `
-		SELECT s.id, s.teacher_id, s.name, s.description, s.price, s.duration, s.is_active, s.requires_booking_approval, s.created_at
+		SELECT s.id, s.teacher_id, s.name, s.description, s.price, s.duration, s.is_active, s.requires_booking_approval, s.created_at,
+		       s.min_notice_minutes, s.max_advance_days, s.max_active_bookings, s.max_bookings_per_week,
+		       s.buffer_before_minutes, s.buffer_after_minutes, s.requires_prepayment, s.free_cancel_hours, s.currency,
+		       s.category, s.tags
 		FROM subjects s
 		INNER JOIN users u ON s.teacher_id = u.id
 		WHERE s.is_active = true AND u.is_teacher = true AND u.is_public = true
@@ -260,6 +334,17 @@ func (r *SubjectRepository) GetPublicActive(ctx context.Context) ([]*model.Subje
 			&subject.IsActive,
 			&subject.RequiresBookingApproval,
 			&subject.CreatedAt,
+			&subject.MinNoticeMinutes,
+			&subject.MaxAdvanceDays,
+			&subject.MaxActiveBookings,
+			&subject.MaxBookingsPerWeek,
+			&subject.BufferBeforeMinutes,
+			&subject.BufferAfterMinutes,
+			&subject.RequiresPrepayment,
+			&subject.FreeCancelHours,
+			&subject.Currency,
+			&subject.Category,
+			&subject.Tags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan subject: %w", err)
@@ -277,7 +362,10 @@ func (r *SubjectRepository) GetActiveByTeacherIDs(ctx context.Context, teacherID
 	}
 
 	query := `
-		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at
+		SELECT id, teacher_id, name, description, price, duration, is_active, requires_booking_approval, created_at,
+		       min_notice_minutes, max_advance_days, max_active_bookings, max_bookings_per_week,
+		       buffer_before_minutes, buffer_after_minutes, requires_prepayment, free_cancel_hours, currency,
+		       category, tags
 		FROM subjects
 		WHERE teacher_id = ANY($1) AND is_active = true
 		ORDER BY teacher_id, name
@@ -302,6 +390,17 @@ func (r *SubjectRepository) GetActiveByTeacherIDs(ctx context.Context, teacherID
 			&subject.IsActive,
 			&subject.RequiresBookingApproval,
 			&subject.CreatedAt,
+			&subject.MinNoticeMinutes,
+			&subject.MaxAdvanceDays,
+			&subject.MaxActiveBookings,
+			&subject.MaxBookingsPerWeek,
+			&subject.BufferBeforeMinutes,
+			&subject.BufferAfterMinutes,
+			&subject.RequiresPrepayment,
+			&subject.FreeCancelHours,
+			&subject.Currency,
+			&subject.Category,
+			&subject.Tags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan subject: %w", err)
@@ -311,3 +410,132 @@ func (r *SubjectRepository) GetActiveByTeacherIDs(ctx context.Context, teacherID
 
 	return subjects, nil
 }
+
+// Search ищет активные предметы по полнотекстовому запросу среди публичных учителей
+// и учителей из teacherIDs. Кроме названия, тегов и описания, запрос ищет по названию категории
+// и имени учителя. Свободные слоты считаются в интервале [from, to) по тем же правилам,
+// что и при записи: с учётом групповых слотов студента, перерывов вокруг занятых слотов
+// и окна записи предмета (from — текущий момент). Результаты упорядочены по релевантности
+func (r *SubjectRepository) Search(ctx context.Context, filter model.SubjectSearchFilter, studentID int64, teacherIDs []int64, from, to time.Time, limit int) ([]*model.SubjectSearchResult, error) {
+	// Названия категорий берутся из model.SubjectCategories, чтобы не дублировать их в базе
+	categoryCodes := make([]string, 0, len(model.SubjectCategories))
+	categoryTitles := make([]string, 0, len(model.SubjectCategories))
+	for _, category := range model.SubjectCategories {
+		categoryCodes = append(categoryCodes, category.Code)
+		categoryTitles = append(categoryTitles, category.Title)
+	}
+
+	query := `
+		SELECT * FROM (
+			SELECT subj.id, subj.teacher_id, subj.name, subj.description, subj.price, subj.duration, subj.is_active,
+			       subj.requires_booking_approval, subj.created_at,
+			       subj.min_notice_minutes, subj.max_advance_days, subj.max_active_bookings, subj.max_bookings_per_week,
+			       subj.buffer_before_minutes, subj.buffer_after_minutes, subj.requires_prepayment, subj.free_cancel_hours, subj.currency,
+			       subj.category, subj.tags,
+			       TRIM(u.first_name || ' ' || COALESCE(u.last_name, '')),
+			       ts_rank(d.document, q.query)::float8 AS rank,
+			       (
+			           SELECT COUNT(*)
+			           FROM schedule_slots s
+			           JOIN subjects ss ON ss.id = s.subject_id
+			           WHERE s.subject_id = subj.id
+			             AND s.status = 'free'
+			             AND s.start_time >= $8::timestamptz + make_interval(mins => ss.min_notice_minutes)
+			             AND s.start_time < $9
+			             AND (ss.max_advance_days = 0 OR s.start_time < $8::timestamptz + make_interval(days => ss.max_advance_days))
+			             AND NOT EXISTS (` + bufferConflictCondition + `)
+			             AND (s.group_id IS NULL OR EXISTS (
+			                 SELECT 1 FROM student_group_members m
+			                 WHERE m.group_id = s.group_id AND m.student_id = $10
+			             ))
+			       )::int AS free_slots
+			FROM subjects subj
+			INNER JOIN users u ON subj.teacher_id = u.id
+			LEFT JOIN unnest($13::text[], $14::text[]) AS c(code, title) ON c.code = subj.category
+			CROSS JOIN websearch_to_tsquery('russian', $1) AS q(query)
+			CROSS JOIN LATERAL (
+			    SELECT subj.search_vector ||
+			           setweight(to_tsvector('russian', COALESCE(c.title, '')), 'B') ||
+			           setweight(to_tsvector('russian', u.first_name || ' ' || COALESCE(u.last_name, '')), 'B')
+			) AS d(document)
+			WHERE subj.is_active = true
+			  AND u.is_teacher = true
+			  AND (u.is_public = true OR subj.teacher_id = ANY($2))
+			  AND d.document @@ q.query
+			  AND ($3 = '' OR subj.category = $3)
+			  AND ($12 = '' OR subj.currency = $12)
+			  AND ($4 = 0 OR subj.price >= $4)
+			  AND ($5 = 0 OR subj.price <= $5)
+			  AND ($6 = 0 OR subj.duration <= $6)
+		) found
+		WHERE NOT $7 OR found.free_slots > 0
+		ORDER BY found.rank DESC, found.free_slots DESC, found.name
+		LIMIT $11
+	`
+
+	rows, err := r.pool.Query(ctx, query,
+		filter.Query,
+		teacherIDs,
+		filter.Category,
+		filter.MinPrice,
+		filter.MaxPrice,
+		filter.MaxDuration,
+		filter.HasFreeSlots,
+		from,
+		to,
+		studentID,
+		limit,
+		filter.Currency,
+		categoryCodes,
+		categoryTitles,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("search subjects: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*model.SubjectSearchResult
+	for rows.Next() {
+		var subject model.Subject
+		result := model.SubjectSearchResult{Subject: &subject}
+		err := rows.Scan(
+			&subject.ID,
+			&subject.TeacherID,
+			&subject.Name,
+			&subject.Description,
+			&subject.Price,
+			&subject.Duration,
+			&subject.IsActive,
+			&subject.RequiresBookingApproval,
+			&subject.CreatedAt,
+			&subject.MinNoticeMinutes,
+			&subject.MaxAdvanceDays,
+			&subject.MaxActiveBookings,
+			&subject.MaxBookingsPerWeek,
+			&subject.BufferBeforeMinutes,
+			&subject.BufferAfterMinutes,
+			&subject.RequiresPrepayment,
+			&subject.FreeCancelHours,
+			&subject.Currency,
+			&subject.Category,
+			&subject.Tags,
+			&result.TeacherName,
+			&result.Rank,
+			&result.FreeSlots,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan subject search result: %w", err)
+		}
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+// subjectTags возвращает теги предмета для записи в NOT NULL колонку
+func subjectTags(subject *model.Subject) []string {
+	if subject.Tags == nil {
+		return []string{}
+	}
+	return subject.Tags
+}
@@ -46,7 +46,7 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 // GetByTelegramID получает пользователя по Telegram ID
 func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
 	query := `
-		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, created_at
+		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, default_currency, created_at
 		FROM users
 		WHERE telegram_id = $1
 	`
@@ -62,6 +62,7 @@ func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 		&user.IsTeacher,
 		&user.IsPublic,
 		&user.AutoApproveBookings,
+		&user.DefaultCurrency,
 		&user.CreatedAt,
 	)
 
@@ -78,7 +79,7 @@ func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 // GetByID получает пользователя по ID
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
 	query := `
-		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, created_at
+		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, default_currency, created_at
 		FROM users
 		WHERE id = $1
 	`
@@ -94,6 +95,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, er
 		&user.IsTeacher,
 		&user.IsPublic,
 		&user.AutoApproveBookings,
+		&user.DefaultCurrency,
 		&user.CreatedAt,
 	)
 
@@ -158,10 +160,30 @@ func (r *UserRepository) UpdatePublicStatus(ctx context.Context, userID int64, i
 	return nil
 }
 
+// UpdateDefaultCurrency обновляет валюту новых предметов учителя
+func (r *UserRepository) UpdateDefaultCurrency(ctx context.Context, userID int64, currency model.Currency) error {
+	query := `
+		UPDATE users
+		SET default_currency = $1
+		WHERE id = $2 AND is_teacher = true
+	`
+
+	result, err := r.pool.Exec(ctx, query, currency, userID)
+	if err != nil {
+		return fmt.Errorf("update default currency: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("teacher not found")
+	}
+
+	return nil
+}
+
 // GetPublicTeachers получает список публичных учителей
 func (r *UserRepository) GetPublicTeachers(ctx context.Context) ([]*model.User, error) {
 	query := `
-		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, created_at
+		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, default_currency, created_at
 		FROM users
 		WHERE is_teacher = true AND is_public = true
 		ORDER BY first_name, last_name
@@ -186,6 +208,7 @@ func (r *UserRepository) GetPublicTeachers(ctx context.Context) ([]*model.User,
 			&teacher.IsTeacher,
 			&teacher.IsPublic,
 			&teacher.AutoApproveBookings,
+			&teacher.DefaultCurrency,
 			&teacher.CreatedAt,
 		)
 		if err != nil {
@@ -208,7 +231,7 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*model.Us
 	}
 
 	query := `
-		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, created_at
+		SELECT id, telegram_id, username, first_name, last_name, language_code, is_teacher, is_public, auto_approve_bookings, default_currency, created_at
 		FROM users
 		WHERE id = ANY($1)
 		ORDER BY first_name, last_name
@@ -233,6 +256,7 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*model.Us
 			&user.IsTeacher,
 			&user.IsPublic,
 			&user.AutoApproveBookings,
+			&user.DefaultCurrency,
 			&user.CreatedAt,
 		)
 		if err != nil {
@@ -17,6 +17,10 @@ type BookingService struct {
 	subjectRepo *repository.SubjectRepository
 	slotRepo    *repository.SlotRepository
 	bookingRepo *repository.BookingRepository
+	groupRepo   *repository.GroupRepository
+	payments    *PaymentService
+	credits     *CreditService
+	promos      *PromoCodeService
 	logger      *zap.Logger
 }
 
@@ -26,6 +30,10 @@ func NewBookingService(
 	subjectRepo *repository.SubjectRepository,
 	slotRepo *repository.SlotRepository,
 	bookingRepo *repository.BookingRepository,
+	groupRepo *repository.GroupRepository,
+	payments *PaymentService,
+	credits *CreditService,
+	promos *PromoCodeService,
 	logger *zap.Logger,
 ) *BookingService {
 	return &BookingService{
@@ -34,6 +42,10 @@ func NewBookingService(
 		subjectRepo: subjectRepo,
 		slotRepo:    slotRepo,
 		bookingRepo: bookingRepo,
+		groupRepo:   groupRepo,
+		payments:    payments,
+		credits:     credits,
+		promos:      promos,
 		logger:      logger,
 	}
 }
@@ -67,6 +79,28 @@ func (s *BookingService) BookSlot(ctx context.Context, studentID, slotID int64)
 		return nil, fmt.Errorf("slot is in the past")
 	}
 
+	// Проверяем что слот не попадает в перерыв вокруг других занятий учителя
+	conflict, err := s.slotRepo.HasBufferConflict(ctx, slotID)
+	if err != nil {
+		return nil, fmt.Errorf("check buffer conflict: %w", err)
+	}
+
+	if conflict {
+		return nil, fmt.Errorf("slot conflicts with teacher buffer")
+	}
+
+	// Слот, закреплённый за группой, доступен только её участникам
+	if slot.GroupID != nil {
+		isMember, err := s.groupRepo.IsMember(ctx, *slot.GroupID, studentID)
+		if err != nil {
+			return nil, fmt.Errorf("check group member: %w", err)
+		}
+
+		if !isMember {
+			return nil, fmt.Errorf("slot is reserved for group")
+		}
+	}
+
 	// Получаем информацию о предмете
 	subject, err := s.subjectRepo.GetByID(ctx, slot.SubjectID)
 	if err != nil {
@@ -82,6 +116,11 @@ func (s *BookingService) BookSlot(ctx context.Context, studentID, slotID int64)
 		return nil, fmt.Errorf("subject is not active")
 	}
 
+	// Проверяем правила записи предмета
+	if err := s.checkBookingRules(ctx, studentID, slot, subject); err != nil {
+		return nil, err
+	}
+
 	// Определяем статус бронирования в зависимости от настроек предмета
 	bookingStatus := model.BookingStatusConfirmed
 	if subject.RequiresBookingApproval {
@@ -94,18 +133,54 @@ func (s *BookingService) BookSlot(ctx context.Context, studentID, slotID int64)
 		return nil, fmt.Errorf("book slot: %w", err)
 	}
 
-	// Создаём запись о бронировании
 	booking := &model.Booking{
 		StudentID: studentID,
 		TeacherID: slot.TeacherID,
 		SubjectID: slot.SubjectID,
 		SlotID:    slotID,
 		Status:    bookingStatus,
+		Price:     subject.Price,
+		Currency:  subject.Currency,
 	}
 
-	err = s.bookingRepo.Create(ctx, booking)
+	// Если у студента есть оплаченные занятия по предмету, запись создаётся вместе со списанием одного из них.
+	// Если занятий не осталось (в том числе их успела списать параллельная запись), запись оплачивается как обычно
+	booking.CreditDebited, err = s.credits.CreateDebitedBooking(ctx, booking, slot.StartTime)
 	if err != nil {
-		return nil, fmt.Errorf("create booking: %w", err)
+		return nil, fmt.Errorf("create booking with credit: %w", err)
+	}
+
+	// Скидка по промокоду действует только на занятия, оплачиваемые деньгами
+	var promo *model.PromoCode
+	prepaid := false
+	if !booking.CreditDebited {
+		booking.Price, promo = s.promos.redeemForBooking(ctx, studentID, subject)
+
+		// При предоплате запись ждёт оплаты счёта, одобрение (если нужно) — после оплаты
+		prepaid = subject.NeedsPrepayment() && booking.Price > 0 && s.payments.Enabled()
+		if prepaid {
+			booking.Status = model.BookingStatusAwaitingPayment
+		} else if subject.NeedsPrepayment() {
+			s.logger.Warn("Subject requires prepayment but payments are not configured",
+				zap.Int64("subject_id", subject.ID))
+		}
+
+		if promo != nil {
+			booking.PromoCodeID = &promo.ID
+		}
+
+		// Создаём запись о бронировании
+		err = s.bookingRepo.Create(ctx, booking)
+		if err != nil {
+			if promo != nil {
+				s.promos.completeRedemption(ctx, studentID, subject.ID, promo, false)
+			}
+			return nil, fmt.Errorf("create booking: %w", err)
+		}
+
+		if promo != nil {
+			s.promos.completeRedemption(ctx, studentID, subject.ID, promo, true)
+		}
 	}
 
 	// Коммитим транзакцию
@@ -119,16 +194,78 @@ func (s *BookingService) BookSlot(ctx context.Context, studentID, slotID int64)
 		zap.Int64("student_id", studentID),
 		zap.Int64("slot_id", slotID),
 		zap.String("subject", subject.Name),
-		zap.String("status", string(bookingStatus)),
+		zap.String("status", string(booking.Status)),
+		zap.Int("price", booking.Price),
+		zap.Bool("credit_debited", booking.CreditDebited),
 	)
 
 	// Возвращаем бронирование с заполненными данными для уведомлений
 	booking.Subject = subject
 	booking.Slot = slot
+	booking.PromoCode = promo
+
+	if prepaid {
+		student, err := s.userRepo.GetByID(ctx, studentID)
+		if err != nil || student == nil {
+			s.payments.releaseBooking(ctx, booking.ID)
+			return nil, fmt.Errorf("get student: %w", err)
+		}
+
+		booking.Payment, err = s.payments.CreateInvoice(ctx, booking, subject, slot, student.TelegramID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send invoice: %w", err)
+		}
+	}
 
 	return booking, nil
 }
 
+// checkBookingRules проверяет минимальное время до начала, горизонт записи
+// и лимиты записей студента на предмет
+func (s *BookingService) checkBookingRules(ctx context.Context, studentID int64, slot *model.ScheduleSlot, subject *model.Subject) error {
+	windowFrom, windowTo := subject.BookingWindow(time.Now())
+
+	if slot.StartTime.Before(windowFrom) {
+		return fmt.Errorf("minimum notice not met")
+	}
+
+	if !windowTo.IsZero() && slot.StartTime.After(windowTo) {
+		return fmt.Errorf("booking window not open yet")
+	}
+
+	if subject.MaxActiveBookings > 0 {
+		active, err := s.bookingRepo.CountActiveByStudentAndSubject(ctx, studentID, subject.ID)
+		if err != nil {
+			return fmt.Errorf("count active bookings: %w", err)
+		}
+
+		if active >= subject.MaxActiveBookings {
+			return fmt.Errorf("active bookings limit reached")
+		}
+	}
+
+	if subject.MaxBookingsPerWeek > 0 {
+		weekStart, weekEnd := weekBounds(slot.StartTime)
+		weekly, err := s.bookingRepo.CountByStudentAndSubjectInRange(ctx, studentID, subject.ID, weekStart, weekEnd)
+		if err != nil {
+			return fmt.Errorf("count weekly bookings: %w", err)
+		}
+
+		if weekly >= subject.MaxBookingsPerWeek {
+			return fmt.Errorf("weekly bookings limit reached")
+		}
+	}
+
+	return nil
+}
+
+// weekBounds возвращает начало (понедельник 00:00) и конец недели, в которую попадает t
+func weekBounds(t time.Time) (time.Time, time.Time) {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	start := time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 7)
+}
+
 // GetPendingBookings получает все pending бронирования учителя
 func (s *BookingService) GetPendingBookings(ctx context.Context, teacherID int64) ([]*model.Booking, error) {
 	return s.bookingRepo.GetPendingByTeacherID(ctx, teacherID)
@@ -209,6 +346,9 @@ func (s *BookingService) RejectBooking(ctx context.Context, bookingID, teacherID
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
+	s.refundCredit(ctx, bookingID)
+	s.promos.releaseForBooking(ctx, booking)
+
 	s.logger.Info("Booking rejected",
 		zap.Int64("booking_id", bookingID),
 		zap.Int64("teacher_id", teacherID),
@@ -227,9 +367,67 @@ func (s *BookingService) GetByID(ctx context.Context, bookingID int64) (*model.B
 	return s.bookingRepo.GetByID(ctx, bookingID)
 }
 
-// GetAvailableSlots получает доступные слоты для предмета
-func (s *BookingService) GetAvailableSlots(ctx context.Context, subjectID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
-	return s.slotRepo.GetFreeSlots(ctx, subjectID, from, to)
+// GetSlotBooking получает запись на слот, в том числе на прошедшее занятие
+func (s *BookingService) GetSlotBooking(ctx context.Context, slotID int64) (*model.Booking, error) {
+	return s.bookingRepo.GetLastBySlotID(ctx, slotID)
+}
+
+// GetAvailableSlots получает доступные студенту слоты для предмета
+// Слоты за пределами окна записи предмета (минимальное время до начала, горизонт записи) не возвращаются,
+// слоты чужих групп тоже
+func (s *BookingService) GetAvailableSlots(ctx context.Context, studentID, subjectID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject != nil {
+		windowFrom, windowTo := subject.BookingWindow(time.Now())
+		if from.Before(windowFrom) {
+			from = windowFrom
+		}
+		if !windowTo.IsZero() && to.After(windowTo) {
+			to = windowTo
+		}
+	}
+
+	if !from.Before(to) {
+		return []*model.ScheduleSlot{}, nil
+	}
+
+	return s.slotRepo.GetFreeSlots(ctx, subjectID, studentID, from, to)
+}
+
+// GetAvailableSlotsForSubjects получает свободные слоты нескольких предметов с учётом правил записи каждого предмета
+func (s *BookingService) GetAvailableSlotsForSubjects(ctx context.Context, studentID int64, subjects []*model.Subject, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	return s.GetNewAvailableSlotsForSubjects(ctx, studentID, subjects, time.Time{}, from, to)
+}
+
+// GetNewAvailableSlotsForSubjects как GetAvailableSlotsForSubjects, но только слоты, ставшие свободными после freedAfter
+func (s *BookingService) GetNewAvailableSlotsForSubjects(ctx context.Context, studentID int64, subjects []*model.Subject, freedAfter, from, to time.Time) ([]*model.ScheduleSlot, error) {
+	now := time.Now()
+	subjectIDs := make([]int64, 0, len(subjects))
+	bySubject := make(map[int64]*model.Subject, len(subjects))
+	for _, subject := range subjects {
+		subjectIDs = append(subjectIDs, subject.ID)
+		bySubject[subject.ID] = subject
+	}
+
+	slots, err := s.slotRepo.GetFreedSlotsForSubjects(ctx, subjectIDs, studentID, freedAfter, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]*model.ScheduleSlot, 0, len(slots))
+	for _, slot := range slots {
+		windowFrom, windowTo := bySubject[slot.SubjectID].BookingWindow(now)
+		if slot.StartTime.Before(windowFrom) || (!windowTo.IsZero() && !slot.StartTime.Before(windowTo)) {
+			continue
+		}
+		available = append(available, slot)
+	}
+
+	return available, nil
 }
 
 // GetStudentBookings получает все бронирования студента
@@ -237,6 +435,24 @@ func (s *BookingService) GetStudentBookings(ctx context.Context, studentID int64
 	return s.bookingRepo.GetByStudentID(ctx, studentID)
 }
 
+// GetStudentWeekLessons получает занятия студента у всех учителей на неделе, в которую попадает day.
+// Возвращает также понедельник этой недели
+func (s *BookingService) GetStudentWeekLessons(ctx context.Context, studentID int64, day time.Time) ([]*model.Booking, time.Time, error) {
+	start, end := weekBounds(day)
+	bookings, err := s.bookingRepo.GetStudentLessonsInRange(ctx, studentID, start, end)
+	return bookings, start, err
+}
+
+// GetStudentLessons получает занятия студента у всех учителей в диапазоне [from, to)
+func (s *BookingService) GetStudentLessons(ctx context.Context, studentID int64, from, to time.Time) ([]*model.Booking, error) {
+	return s.bookingRepo.GetStudentLessonsInRange(ctx, studentID, from, to)
+}
+
+// GetTeacherLessons получает занятия учителя по всем предметам в диапазоне [from, to)
+func (s *BookingService) GetTeacherLessons(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.Booking, error) {
+	return s.bookingRepo.GetTeacherLessonsInRange(ctx, teacherID, from, to)
+}
+
 // CancelBooking отменяет бронирование
 func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID int64) error {
 	// Получаем бронирование
@@ -255,7 +471,7 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID in
 	}
 
 	// Проверяем что бронирование активно
-	if booking.Status != model.BookingStatusConfirmed && booking.Status != model.BookingStatusPending {
+	if !booking.Status.IsActive() {
 		return fmt.Errorf("booking is not active")
 	}
 
@@ -278,12 +494,26 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID in
 		return fmt.Errorf("cancel slot: %w", err)
 	}
 
+	// Закрываем неоплаченный счёт, чтобы его нельзя было оплатить после отмены
+	if booking.Status == model.BookingStatusAwaitingPayment && s.payments.Enabled() {
+		err = s.payments.CancelPending(ctx, bookingID)
+		if err != nil {
+			return fmt.Errorf("cancel pending payment: %w", err)
+		}
+	}
+
 	// Коммитим транзакцию
 	err = tx.Commit(ctx)
 	if err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
+	// Учитель отменяет без последствий для студента, студент — в рамках политики отмены
+	if booking.TeacherID == userID || s.isFreeCancel(ctx, booking) {
+		s.refundCredit(ctx, bookingID)
+	}
+	s.promos.releaseForBooking(ctx, booking)
+
 	s.logger.Info("Booking canceled",
 		zap.Int64("booking_id", bookingID),
 		zap.Int64("user_id", userID),
@@ -291,3 +521,69 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID in
 
 	return nil
 }
+
+// CancelUpcomingForStudent отменяет от имени учителя все будущие записи студента к нему.
+// Списанные из пакета занятия возвращаются; возвращает отменённые записи
+func (s *BookingService) CancelUpcomingForStudent(ctx context.Context, teacherID, studentID int64) ([]*model.Booking, error) {
+	bookings, err := s.bookingRepo.GetUpcomingActiveByStudentAndTeacher(ctx, studentID, teacherID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var canceled []*model.Booking
+	for _, booking := range bookings {
+		if err := s.CancelBooking(ctx, booking.ID, teacherID); err != nil {
+			s.logger.Error("Failed to cancel upcoming booking",
+				zap.Int64("booking_id", booking.ID),
+				zap.Int64("student_id", studentID),
+				zap.Error(err))
+			continue
+		}
+		canceled = append(canceled, booking)
+	}
+
+	return canceled, nil
+}
+
+// LateCancelForfeitsCredit проверяет, сгорит ли занятие из пакета, если студент отменит запись сейчас
+func (s *BookingService) LateCancelForfeitsCredit(ctx context.Context, bookingID int64) (bool, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return false, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil {
+		return false, fmt.Errorf("booking not found")
+	}
+
+	debited, err := s.credits.IsBookingDebited(ctx, bookingID)
+	if err != nil || !debited {
+		return false, err
+	}
+
+	return !s.isFreeCancel(ctx, booking), nil
+}
+
+// isFreeCancel проверяет, укладывается ли отмена бронирования сейчас в политику отмены предмета
+func (s *BookingService) isFreeCancel(ctx context.Context, booking *model.Booking) bool {
+	slot, err := s.slotRepo.GetByID(ctx, booking.SlotID)
+	if err != nil || slot == nil {
+		return false
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, booking.SubjectID)
+	if err != nil || subject == nil {
+		return false
+	}
+
+	return subject.IsFreeCancel(slot.StartTime, time.Now())
+}
+
+// refundCredit возвращает занятие, списанное за бронирование. Ошибка не мешает отмене и только логируется
+func (s *BookingService) refundCredit(ctx context.Context, bookingID int64) {
+	if _, err := s.credits.RefundForBooking(ctx, bookingID); err != nil {
+		s.logger.Error("Failed to refund credit",
+			zap.Int64("booking_id", bookingID),
+			zap.Error(err))
+	}
+}
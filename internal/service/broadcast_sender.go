@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// BroadcastSender доставляет сообщения рассылок учителя
+type BroadcastSender interface {
+	// SendBroadcast отправляет сообщение с HTML-текстом и вложением в чат студента.
+	// Если студент заблокировал бота, возвращает ошибку "recipient blocked"
+	SendBroadcast(ctx context.Context, chatID int64, message *model.BroadcastMessage) error
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxBroadcastTextLength максимальная длина текста рассылки без вложения: с заголовком должна влезть в сообщение Telegram
+	MaxBroadcastTextLength = 3500
+	// MaxBroadcastCaptionLength максимальная длина текста рассылки с фото или документом: подпись Telegram короче сообщения
+	MaxBroadcastCaptionLength = 900
+	// BroadcastSendInterval пауза между сообщениями рассылки, чтобы не упереться в лимиты Telegram (~30 сообщений в секунду)
+	BroadcastSendInterval = 50 * time.Millisecond
+)
+
+// BroadcastService отправляет рассылки учителя его студентам
+type BroadcastService struct {
+	broadcastRepo *repository.BroadcastRepository
+	accessRepo    *repository.AccessRepository
+	groupRepo     *repository.GroupRepository
+	subjectRepo   *repository.SubjectRepository
+	userRepo      *repository.UserRepository
+	sender        BroadcastSender
+	notifier      Notifier
+	logger        *zap.Logger
+
+	// running учителя, у которых сейчас идёт рассылка: одновременно у учителя может идти только одна
+	mu      sync.Mutex
+	running map[int64]bool
+}
+
+func NewBroadcastService(
+	broadcastRepo *repository.BroadcastRepository,
+	accessRepo *repository.AccessRepository,
+	groupRepo *repository.GroupRepository,
+	subjectRepo *repository.SubjectRepository,
+	userRepo *repository.UserRepository,
+	sender BroadcastSender,
+	notifier Notifier,
+	logger *zap.Logger,
+) *BroadcastService {
+	return &BroadcastService{
+		broadcastRepo: broadcastRepo,
+		accessRepo:    accessRepo,
+		groupRepo:     groupRepo,
+		subjectRepo:   subjectRepo,
+		userRepo:      userRepo,
+		sender:        sender,
+		notifier:      notifier,
+		logger:        logger,
+		running:       make(map[int64]bool),
+	}
+}
+
+// GetRecipients возвращает получателей рассылки. В рассылку попадают только студенты с действующим доступом к учителю
+func (s *BroadcastService) GetRecipients(ctx context.Context, teacherID int64, audience model.BroadcastAudience) ([]*model.User, error) {
+	var ids []int64
+	var err error
+
+	switch audience.Type {
+	case model.BroadcastAudienceAll:
+		ids, err = s.accessRepo.GetTeacherStudentIDs(ctx, teacherID)
+	case model.BroadcastAudienceSubject:
+		subject, subjectErr := s.subjectRepo.GetByID(ctx, audience.SubjectID)
+		if subjectErr != nil {
+			return nil, fmt.Errorf("get subject: %w", subjectErr)
+		}
+		if subject == nil || subject.TeacherID != teacherID {
+			return nil, fmt.Errorf("subject not found")
+		}
+		ids, err = s.broadcastRepo.GetSubjectStudentIDs(ctx, teacherID, audience.SubjectID)
+	case model.BroadcastAudienceBooked:
+		if !audience.From.Before(audience.To) {
+			return nil, fmt.Errorf("invalid audience")
+		}
+		ids, err = s.broadcastRepo.GetBookedStudentIDs(ctx, teacherID, audience.From, audience.To)
+	case model.BroadcastAudienceGroup:
+		group, groupErr := s.groupRepo.GetByID(ctx, audience.GroupID)
+		if groupErr != nil {
+			return nil, groupErr
+		}
+		if group == nil || group.TeacherID != teacherID {
+			return nil, fmt.Errorf("group not found")
+		}
+		ids, err = s.groupRepo.GetMemberIDs(ctx, audience.GroupID)
+	case model.BroadcastAudiencePicked:
+		ids = audience.StudentIDs
+	default:
+		return nil, fmt.Errorf("invalid audience")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Студенты без доступа (отозванного или истёкшего) рассылку не получают
+	studentIDs, err := s.accessRepo.GetTeacherStudentIDs(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	hasAccess := make(map[int64]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		hasAccess[id] = true
+	}
+
+	var recipientIDs []int64
+	for _, id := range ids {
+		if hasAccess[id] {
+			recipientIDs = append(recipientIDs, id)
+			delete(hasAccess, id) // без повторов
+		}
+	}
+
+	return s.userRepo.GetByIDs(ctx, recipientIDs)
+}
+
+// Render проверяет черновик рассылки и возвращает сообщение в том виде, в каком его получат студенты
+func (s *BroadcastService) Render(ctx context.Context, teacherID int64, draft *model.BroadcastMessage) (*model.BroadcastMessage, error) {
+	text := strings.TrimSpace(draft.Text)
+	if text == "" && !draft.HasMedia() {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	maxLength := MaxBroadcastTextLength
+	if draft.HasMedia() {
+		maxLength = MaxBroadcastCaptionLength
+	}
+	if utf8.RuneCountInString(text) > maxLength {
+		return nil, fmt.Errorf("message too long")
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil || teacher == nil {
+		return nil, fmt.Errorf("get teacher: %w", err)
+	}
+
+	rendered := fmt.Sprintf("📣 <b>Сообщение от учителя %s</b>", html.EscapeString(userFullName(teacher)))
+	if text != "" {
+		rendered += "\n\n" + html.EscapeString(text)
+	}
+
+	return &model.BroadcastMessage{
+		Text:           rendered,
+		PhotoFileID:    draft.PhotoFileID,
+		DocumentFileID: draft.DocumentFileID,
+	}, nil
+}
+
+// SendPreview отправляет учителю сообщение рассылки так, как его увидят студенты
+func (s *BroadcastService) SendPreview(ctx context.Context, teacherID, chatID int64, draft *model.BroadcastMessage) error {
+	if s.sender == nil {
+		return fmt.Errorf("broadcasts unavailable")
+	}
+
+	message, err := s.Render(ctx, teacherID, draft)
+	if err != nil {
+		return err
+	}
+
+	return s.sender.SendBroadcast(ctx, chatID, message)
+}
+
+// Start запускает рассылку в фоне и возвращает число получателей.
+// Сообщения уходят с паузой BroadcastSendInterval, по окончании учитель получает отчёт о доставке
+func (s *BroadcastService) Start(ctx context.Context, teacherID int64, audience model.BroadcastAudience, draft *model.BroadcastMessage) (int, error) {
+	if s.sender == nil {
+		return 0, fmt.Errorf("broadcasts unavailable")
+	}
+
+	message, err := s.Render(ctx, teacherID, draft)
+	if err != nil {
+		return 0, err
+	}
+
+	recipients, err := s.GetRecipients(ctx, teacherID, audience)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(recipients) == 0 {
+		return 0, fmt.Errorf("no recipients")
+	}
+
+	s.mu.Lock()
+	if s.running[teacherID] {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("broadcast in progress")
+	}
+	s.running[teacherID] = true
+	s.mu.Unlock()
+
+	s.logger.Info("Broadcast started",
+		zap.Int64("teacher_id", teacherID),
+		zap.String("audience", string(audience.Type)),
+		zap.Int("recipients", len(recipients)),
+	)
+
+	// Рассылка переживает обработку callback, поэтому не зависит от его отмены
+	go s.deliver(context.WithoutCancel(ctx), teacherID, recipients, message)
+
+	return len(recipients), nil
+}
+
+// deliver отправляет сообщение получателям по одному с паузой и сообщает учителю итоги
+func (s *BroadcastService) deliver(ctx context.Context, teacherID int64, recipients []*model.User, message *model.BroadcastMessage) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, teacherID)
+		s.mu.Unlock()
+	}()
+
+	result := model.BroadcastResult{Total: len(recipients)}
+
+	ticker := time.NewTicker(BroadcastSendInterval)
+	defer ticker.Stop()
+
+	for i, recipient := range recipients {
+		if i > 0 {
+			<-ticker.C
+		}
+
+		err := s.sender.SendBroadcast(ctx, recipient.TelegramID, message)
+		switch {
+		case err == nil:
+			result.Delivered++
+		case err.Error() == "recipient blocked":
+			result.Blocked++
+		default:
+			result.Failed++
+			s.logger.Warn("Failed to deliver broadcast",
+				zap.Int64("teacher_id", teacherID),
+				zap.Int64("student_id", recipient.ID),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Broadcast finished",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int("total", result.Total),
+		zap.Int("delivered", result.Delivered),
+		zap.Int("blocked", result.Blocked),
+		zap.Int("failed", result.Failed),
+	)
+
+	s.reportResult(ctx, teacherID, result)
+}
+
+// reportResult отправляет учителю отчёт о доставке рассылки
+func (s *BroadcastService) reportResult(ctx context.Context, teacherID int64, result model.BroadcastResult) {
+	if s.notifier == nil {
+		return
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil || teacher == nil {
+		return
+	}
+
+	text := fmt.Sprintf(
+		"📣 <b>Рассылка завершена</b>\n\n"+
+			"Получателей: %d\n"+
+			"✅ Доставлено: %d\n"+
+			"🚫 Заблокировали бота: %d\n"+
+			"❌ Не удалось отправить: %d",
+		result.Total,
+		result.Delivered,
+		result.Blocked,
+		result.Failed,
+	)
+
+	if err := s.notifier.Notify(ctx, teacher.TelegramID, text); err != nil {
+		s.logger.Warn("Failed to send broadcast report", zap.Int64("teacher_id", teacherID), zap.Error(err))
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// MaxConversationMessageLength максимальная длина сообщения переписки: с заголовком должна влезть в сообщение Telegram
+const MaxConversationMessageLength = 3500
+
+// ConversationService ведёт переписку студентов и учителей через бота
+type ConversationService struct {
+	conversationRepo *repository.ConversationRepository
+	accessRepo       *repository.AccessRepository
+	bookingRepo      *repository.BookingRepository
+	slotRepo         *repository.SlotRepository
+	subjectRepo      *repository.SubjectRepository
+	userRepo         *repository.UserRepository
+	relay            MessageRelay
+	logger           *zap.Logger
+}
+
+func NewConversationService(
+	conversationRepo *repository.ConversationRepository,
+	accessRepo *repository.AccessRepository,
+	bookingRepo *repository.BookingRepository,
+	slotRepo *repository.SlotRepository,
+	subjectRepo *repository.SubjectRepository,
+	userRepo *repository.UserRepository,
+	relay MessageRelay,
+	logger *zap.Logger,
+) *ConversationService {
+	return &ConversationService{
+		conversationRepo: conversationRepo,
+		accessRepo:       accessRepo,
+		bookingRepo:      bookingRepo,
+		slotRepo:         slotRepo,
+		subjectRepo:      subjectRepo,
+		userRepo:         userRepo,
+		relay:            relay,
+		logger:           logger,
+	}
+}
+
+// OpenForBooking открывает переписку участника записи с другой стороной записи
+func (s *ConversationService) OpenForBooking(ctx context.Context, userID, bookingID int64) (*model.Conversation, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil || (booking.StudentID != userID && booking.TeacherID != userID) {
+		return nil, fmt.Errorf("booking not found")
+	}
+
+	return s.conversationRepo.GetOrCreate(ctx, booking.TeacherID, booking.StudentID)
+}
+
+// OpenWithStudent открывает переписку учителя со студентом, у которого есть доступ к учителю
+func (s *ConversationService) OpenWithStudent(ctx context.Context, teacherID, studentID int64) (*model.Conversation, error) {
+	return s.openWithAccess(ctx, teacherID, studentID)
+}
+
+// OpenWithTeacher открывает переписку студента с учителем, к которому у него есть доступ
+func (s *ConversationService) OpenWithTeacher(ctx context.Context, studentID, teacherID int64) (*model.Conversation, error) {
+	return s.openWithAccess(ctx, teacherID, studentID)
+}
+
+func (s *ConversationService) openWithAccess(ctx context.Context, teacherID, studentID int64) (*model.Conversation, error) {
+	hasAccess, err := s.accessRepo.HasAccess(ctx, studentID, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("check access: %w", err)
+	}
+
+	if !hasAccess {
+		return nil, fmt.Errorf("no access")
+	}
+
+	return s.conversationRepo.GetOrCreate(ctx, teacherID, studentID)
+}
+
+// GetConversation получает переписку пользователя с заполненными участниками
+func (s *ConversationService) GetConversation(ctx context.Context, userID, conversationID int64) (*model.Conversation, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation == nil || !conversation.IsParticipant(userID) {
+		return nil, fmt.Errorf("conversation not found")
+	}
+
+	if err := s.fillParticipants(ctx, []*model.Conversation{conversation}); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// GetUserConversations получает переписки пользователя, сначала недавние
+func (s *ConversationService) GetUserConversations(ctx context.Context, userID int64) ([]*model.Conversation, error) {
+	conversations, err := s.conversationRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fillParticipants(ctx, conversations); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+// GetRecentMessages получает последние сообщения переписки пользователя
+func (s *ConversationService) GetRecentMessages(ctx context.Context, userID, conversationID int64, limit int) ([]*model.ConversationMessage, error) {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	return s.conversationRepo.GetRecentMessages(ctx, conversationID, limit)
+}
+
+// SetMuted включает или выключает звук переписки для пользователя
+func (s *ConversationService) SetMuted(ctx context.Context, userID, conversationID int64, muted bool) error {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	return s.conversationRepo.SetMuted(ctx, conversationID, userID, muted)
+}
+
+// FindReplyTarget находит сообщение переписки, которое бот переслал в чат; nil - это не пересланное сообщение
+func (s *ConversationService) FindReplyTarget(ctx context.Context, chatID int64, messageID int) (*model.ConversationMessage, error) {
+	return s.conversationRepo.GetMessageByRelayed(ctx, chatID, messageID)
+}
+
+// SendMessage сохраняет сообщение и пересылает его собеседнику с заголовком: от кого и о каком занятии.
+// bookingID привязывает сообщение к занятию собеседников, чужие записи игнорируются.
+// Если переслать не удалось, сообщение остаётся в переписке и возвращается ошибка "delivery failed"
+func (s *ConversationService) SendMessage(ctx context.Context, senderID, conversationID int64, bookingID *int64, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("empty message")
+	}
+
+	if utf8.RuneCountInString(text) > MaxConversationMessageLength {
+		return fmt.Errorf("message too long")
+	}
+
+	conversation, err := s.GetConversation(ctx, senderID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	var booking *model.Booking
+	if bookingID != nil {
+		booking, err = s.bookingRepo.GetByID(ctx, *bookingID)
+		if err != nil {
+			return fmt.Errorf("get booking: %w", err)
+		}
+		if booking == nil || booking.TeacherID != conversation.TeacherID || booking.StudentID != conversation.StudentID {
+			booking, bookingID = nil, nil
+		}
+	}
+
+	message := &model.ConversationMessage{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		BookingID:      bookingID,
+		Text:           text,
+	}
+	if err := s.conversationRepo.CreateMessage(ctx, message); err != nil {
+		return err
+	}
+
+	if s.relay == nil {
+		return fmt.Errorf("delivery failed")
+	}
+
+	recipientID := conversation.PeerID(senderID)
+	sender, recipient := conversation.Teacher, conversation.Student
+	senderRole := "учителя"
+	if senderID == conversation.StudentID {
+		sender, recipient = conversation.Student, conversation.Teacher
+		senderRole = "студента"
+	}
+	if sender == nil || recipient == nil {
+		return fmt.Errorf("delivery failed")
+	}
+
+	relayed := fmt.Sprintf("💬 <b>Сообщение от %s %s</b>\n", senderRole, html.EscapeString(userFullName(sender)))
+	if booking != nil {
+		relayed += s.bookingLine(ctx, booking)
+	}
+	relayed += "\n" + html.EscapeString(text) + "\n\n<i>Ответьте на это сообщение, чтобы написать в ответ</i>"
+
+	relayedMessageID, err := s.relay.Relay(ctx, recipient.TelegramID, relayed, conversation.IsMutedFor(recipientID))
+	if err != nil {
+		s.logger.Warn("Failed to relay conversation message",
+			zap.Int64("conversation_id", conversationID),
+			zap.Int64("recipient_id", recipientID),
+			zap.Error(err))
+		return fmt.Errorf("delivery failed")
+	}
+
+	if err := s.conversationRepo.SetRelayed(ctx, message.ID, recipient.TelegramID, relayedMessageID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Conversation message relayed",
+		zap.Int64("conversation_id", conversationID),
+		zap.Int64("sender_id", senderID),
+	)
+
+	return nil
+}
+
+// bookingLine описывает занятие, о котором сообщение: предмет и время
+func (s *ConversationService) bookingLine(ctx context.Context, booking *model.Booking) string {
+	line := "📚 "
+	if subject, err := s.subjectRepo.GetByID(ctx, booking.SubjectID); err == nil && subject != nil {
+		line += html.EscapeString(subject.Name)
+	} else {
+		line += fmt.Sprintf("Запись #%d", booking.ID)
+	}
+
+	if slot, err := s.slotRepo.GetByID(ctx, booking.SlotID); err == nil && slot != nil {
+		line += ", " + slot.StartTime.Format("02.01.2006 15:04")
+	}
+
+	return line + "\n"
+}
+
+// fillParticipants заполняет учителя и студента переписок
+func (s *ConversationService) fillParticipants(ctx context.Context, conversations []*model.Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(conversations)*2)
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.TeacherID, conversation.StudentID)
+	}
+
+	users, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*model.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	for _, conversation := range conversations {
+		conversation.Teacher = byID[conversation.TeacherID]
+		conversation.Student = byID[conversation.StudentID]
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Ограничения пакетов и ручных начислений
+const (
+	MaxPackageLessons      = 100
+	MaxPackageValidityDays = 730
+	MaxGrantCredits        = 100
+)
+
+// CreditService управляет пакетами занятий и балансом оплаченных занятий студентов
+type CreditService struct {
+	creditRepo  *repository.CreditRepository
+	subjectRepo *repository.SubjectRepository
+	userRepo    *repository.UserRepository
+	logger      *zap.Logger
+}
+
+func NewCreditService(
+	creditRepo *repository.CreditRepository,
+	subjectRepo *repository.SubjectRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *CreditService {
+	return &CreditService{
+		creditRepo:  creditRepo,
+		subjectRepo: subjectRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+	}
+}
+
+// getTeacherSubject получает предмет и проверяет, что он принадлежит учителю
+func (s *CreditService) getTeacherSubject(ctx context.Context, teacherID, subjectID int64) (*model.Subject, error) {
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject == nil {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	if subject.TeacherID != teacherID {
+		return nil, fmt.Errorf("subject does not belong to teacher")
+	}
+
+	return subject, nil
+}
+
+// CreatePackage создаёт пакет занятий для предмета учителя
+func (s *CreditService) CreatePackage(ctx context.Context, teacherID, subjectID int64, lessonCount, price, validityDays int) (*model.LessonPackage, error) {
+	if lessonCount < 1 || lessonCount > MaxPackageLessons {
+		return nil, fmt.Errorf("invalid lesson count")
+	}
+
+	if validityDays < 0 || validityDays > MaxPackageValidityDays {
+		return nil, fmt.Errorf("invalid validity period")
+	}
+
+	subject, err := s.getTeacherSubject(ctx, teacherID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if price < 0 || price > subject.Currency.MaxPrice() {
+		return nil, fmt.Errorf("invalid package price")
+	}
+
+	pkg := &model.LessonPackage{
+		SubjectID:    subjectID,
+		TeacherID:    teacherID,
+		LessonCount:  lessonCount,
+		Price:        price,
+		Currency:     subject.Currency,
+		ValidityDays: validityDays,
+		IsActive:     true,
+	}
+
+	err = s.creditRepo.CreatePackage(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Lesson package created",
+		zap.Int64("package_id", pkg.ID),
+		zap.Int64("subject_id", subjectID),
+		zap.Int("lesson_count", lessonCount),
+		zap.Int("price", price),
+		zap.Int("validity_days", validityDays))
+
+	return pkg, nil
+}
+
+// deactivateSubjectPackages снимает с продажи пакеты предмета при смене его валюты
+func (s *CreditService) deactivateSubjectPackages(ctx context.Context, subjectID int64) (int64, error) {
+	deactivated, err := s.creditRepo.DeactivatePackagesBySubject(ctx, subjectID)
+	if err != nil {
+		return 0, err
+	}
+
+	if deactivated > 0 {
+		s.logger.Info("Lesson packages deactivated",
+			zap.Int64("subject_id", subjectID),
+			zap.Int64("count", deactivated))
+	}
+
+	return deactivated, nil
+}
+
+// GetSubjectPackages получает все пакеты занятий предмета
+func (s *CreditService) GetSubjectPackages(ctx context.Context, subjectID int64) ([]*model.LessonPackage, error) {
+	return s.creditRepo.GetPackagesBySubject(ctx, subjectID)
+}
+
+// GetActiveSubjectPackages получает пакеты занятий предмета, которые сейчас продаются
+func (s *CreditService) GetActiveSubjectPackages(ctx context.Context, subjectID int64) ([]*model.LessonPackage, error) {
+	packages, err := s.creditRepo.GetPackagesBySubject(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*model.LessonPackage, 0, len(packages))
+	for _, pkg := range packages {
+		if pkg.IsActive {
+			active = append(active, pkg)
+		}
+	}
+
+	return active, nil
+}
+
+// GetPackageByID получает пакет занятий по ID
+func (s *CreditService) GetPackageByID(ctx context.Context, packageID int64) (*model.LessonPackage, error) {
+	return s.creditRepo.GetPackageByID(ctx, packageID)
+}
+
+// TogglePackageActive включает или выключает продажу пакета
+func (s *CreditService) TogglePackageActive(ctx context.Context, teacherID, packageID int64) (*model.LessonPackage, error) {
+	pkg, err := s.creditRepo.GetPackageByID(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg == nil {
+		return nil, fmt.Errorf("package not found")
+	}
+
+	if pkg.TeacherID != teacherID {
+		return nil, fmt.Errorf("package does not belong to teacher")
+	}
+
+	// Пакет в прежней валюте предмета нельзя вернуть в продажу — его цена уже не соответствует предмету
+	if !pkg.IsActive {
+		subject, err := s.subjectRepo.GetByID(ctx, pkg.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("get subject: %w", err)
+		}
+
+		if subject == nil || subject.Currency != pkg.Currency {
+			return nil, fmt.Errorf("package currency differs from subject")
+		}
+	}
+
+	pkg.IsActive = !pkg.IsActive
+	err = s.creditRepo.SetPackageActive(ctx, packageID, pkg.IsActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// GrantPackage начисляет студенту занятия пакета (например, после оплаты наличными)
+func (s *CreditService) GrantPackage(ctx context.Context, teacherID, studentID, packageID int64) (*model.CreditLot, error) {
+	pkg, err := s.creditRepo.GetPackageByID(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg == nil {
+		return nil, fmt.Errorf("package not found")
+	}
+
+	if pkg.TeacherID != teacherID {
+		return nil, fmt.Errorf("package does not belong to teacher")
+	}
+
+	lot := &model.CreditLot{
+		StudentID: studentID,
+		TeacherID: teacherID,
+		SubjectID: pkg.SubjectID,
+		PackageID: &pkg.ID,
+		Source:    model.CreditSourcePackage,
+		Total:     pkg.LessonCount,
+		ExpiresAt: pkg.ExpiresAt(time.Now()),
+	}
+
+	return lot, s.addLot(ctx, lot)
+}
+
+// GrantCredits вручную начисляет студенту занятия по предмету без срока действия
+func (s *CreditService) GrantCredits(ctx context.Context, teacherID, studentID, subjectID int64, count int) (*model.CreditLot, error) {
+	if count < 1 || count > MaxGrantCredits {
+		return nil, fmt.Errorf("invalid credit count")
+	}
+
+	if _, err := s.getTeacherSubject(ctx, teacherID, subjectID); err != nil {
+		return nil, err
+	}
+
+	lot := &model.CreditLot{
+		StudentID: studentID,
+		TeacherID: teacherID,
+		SubjectID: subjectID,
+		Source:    model.CreditSourceGrant,
+		Total:     count,
+	}
+
+	return lot, s.addLot(ctx, lot)
+}
+
+// addLot проверяет студента и сохраняет начисление
+func (s *CreditService) addLot(ctx context.Context, lot *model.CreditLot) error {
+	student, err := s.userRepo.GetByID(ctx, lot.StudentID)
+	if err != nil {
+		return fmt.Errorf("get student: %w", err)
+	}
+
+	if student == nil {
+		return fmt.Errorf("student not found")
+	}
+
+	err = s.creditRepo.AddLot(ctx, lot)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Credits granted",
+		zap.Int64("lot_id", lot.ID),
+		zap.Int64("student_id", lot.StudentID),
+		zap.Int64("teacher_id", lot.TeacherID),
+		zap.Int64("subject_id", lot.SubjectID),
+		zap.String("source", string(lot.Source)),
+		zap.Int("count", lot.Total))
+
+	return nil
+}
+
+// CreateDebitedBooking создаёт бронирование, оплаченное занятием из баланса студента.
+// Занятие списывается в той же транзакции; false означает, что действующих занятий нет и запись не создана
+func (s *CreditService) CreateDebitedBooking(ctx context.Context, booking *model.Booking, lessonStart time.Time) (bool, error) {
+	debited, err := s.creditRepo.CreateBookingWithDebit(ctx, booking, lessonStart)
+	if err != nil {
+		return false, err
+	}
+
+	if debited {
+		s.logger.Info("Credit debited for booking",
+			zap.Int64("booking_id", booking.ID),
+			zap.Int64("student_id", booking.StudentID),
+			zap.Int64("subject_id", booking.SubjectID))
+	}
+
+	return debited, nil
+}
+
+// IsBookingDebited проверяет, оплачено ли бронирование занятием из баланса
+func (s *CreditService) IsBookingDebited(ctx context.Context, bookingID int64) (bool, error) {
+	return s.creditRepo.IsDebited(ctx, bookingID)
+}
+
+// RefundForBooking возвращает занятие, списанное за отменённое бронирование
+func (s *CreditService) RefundForBooking(ctx context.Context, bookingID int64) (bool, error) {
+	refunded, err := s.creditRepo.Refund(ctx, bookingID)
+	if err != nil {
+		return false, err
+	}
+
+	if refunded {
+		s.logger.Info("Credit refunded for booking", zap.Int64("booking_id", bookingID))
+	}
+
+	return refunded, nil
+}
+
+// GetStudentBalances получает остатки занятий студента по всем учителям и предметам
+func (s *CreditService) GetStudentBalances(ctx context.Context, studentID int64) ([]*model.CreditBalance, error) {
+	return s.creditRepo.GetBalancesByStudent(ctx, studentID, time.Now())
+}
+
+// GetStudentBalancesWithTeacher получает остатки занятий студента у конкретного учителя
+func (s *CreditService) GetStudentBalancesWithTeacher(ctx context.Context, studentID, teacherID int64) ([]*model.CreditBalance, error) {
+	balances, err := s.GetStudentBalances(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*model.CreditBalance
+	for _, balance := range balances {
+		if balance.TeacherID == teacherID {
+			result = append(result, balance)
+		}
+	}
+
+	return result, nil
+}
+
+// GetSubjectBalance возвращает остаток занятий студента по предмету
+func (s *CreditService) GetSubjectBalance(ctx context.Context, studentID, subjectID int64) (int, error) {
+	balances, err := s.GetStudentBalances(ctx, studentID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, balance := range balances {
+		if balance.SubjectID == subjectID {
+			return balance.Remaining, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Наборы колонок выгрузок. Порядок и ключи колонок не меняются:
+// новые колонки добавляются только в конец, чтобы не ломать таблицы учителей
+var (
+	slotExportColumns = []model.ExportColumn{
+		{Key: "slot_id", Title: "ID слота", Numeric: true},
+		{Key: "date", Title: "Дата"},
+		{Key: "start", Title: "Начало"},
+		{Key: "end", Title: "Конец"},
+		{Key: "duration_min", Title: "Длительность, мин", Numeric: true},
+		{Key: "subject", Title: "Предмет"},
+		{Key: "status", Title: "Статус"},
+		{Key: "student", Title: "Студент"},
+		{Key: "comment", Title: "Комментарий"},
+	}
+
+	bookingExportColumns = []model.ExportColumn{
+		{Key: "booking_id", Title: "ID записи", Numeric: true},
+		{Key: "date", Title: "Дата"},
+		{Key: "start", Title: "Начало"},
+		{Key: "end", Title: "Конец"},
+		{Key: "subject", Title: "Предмет"},
+		{Key: "student", Title: "Студент"},
+		{Key: "username", Title: "Username"},
+		{Key: "status", Title: "Статус"},
+		{Key: "price", Title: "Цена", Numeric: true},
+		{Key: "currency", Title: "Валюта"},
+		{Key: "promo_code", Title: "Промокод"},
+		{Key: "created_at", Title: "Создана"},
+	}
+
+	studentExportColumns = []model.ExportColumn{
+		{Key: "student_id", Title: "ID студента", Numeric: true},
+		{Key: "name", Title: "Имя"},
+		{Key: "username", Title: "Username"},
+		{Key: "access_type", Title: "Тип доступа"},
+		{Key: "granted_at", Title: "Доступ выдан"},
+		{Key: "expires_at", Title: "Доступ до"},
+	}
+
+	inviteCodeExportColumns = []model.ExportColumn{
+		{Key: "code", Title: "Код"},
+		{Key: "created_at", Title: "Создан"},
+		{Key: "uses", Title: "Использований", Numeric: true},
+		{Key: "max_uses", Title: "Лимит", Numeric: true},
+		{Key: "expires_at", Title: "Действует до"},
+		{Key: "status", Title: "Состояние"},
+	}
+)
+
+// Форматы дат в выгрузках
+const (
+	exportDateFormat     = "02.01.2006"
+	exportTimeFormat     = "15:04"
+	exportDateTimeFormat = "02.01.2006 15:04"
+)
+
+// ExportService выгружает данные учителя в CSV и XLSX
+type ExportService struct {
+	exportRepo     *repository.ExportRepository
+	inviteCodeRepo *repository.InviteCodeRepository
+	userRepo       *repository.UserRepository
+	logger         *zap.Logger
+}
+
+func NewExportService(
+	exportRepo *repository.ExportRepository,
+	inviteCodeRepo *repository.InviteCodeRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *ExportService {
+	return &ExportService{
+		exportRepo:     exportRepo,
+		inviteCodeRepo: inviteCodeRepo,
+		userRepo:       userRepo,
+		logger:         logger,
+	}
+}
+
+// Export формирует файл выгрузки. Период [from, to) учитывается только для слотов и записей
+func (s *ExportService) Export(ctx context.Context, teacherID int64, dataset model.ExportDataset, format model.ExportFormat, from, to time.Time) (*model.ExportFile, error) {
+	if !dataset.IsValid() {
+		return nil, fmt.Errorf("unknown export dataset")
+	}
+
+	if !format.IsValid() {
+		return nil, fmt.Errorf("unknown export format")
+	}
+
+	if dataset.HasPeriod() && !from.Before(to) {
+		return nil, fmt.Errorf("invalid export period")
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher: %w", err)
+	}
+
+	if teacher == nil || !teacher.IsTeacher {
+		return nil, fmt.Errorf("user is not a teacher")
+	}
+
+	var table *model.ExportTable
+	switch dataset {
+	case model.ExportDatasetSlots:
+		table, err = s.slotsTable(ctx, teacherID, from, to)
+	case model.ExportDatasetBookings:
+		table, err = s.bookingsTable(ctx, teacherID, from, to)
+	case model.ExportDatasetStudents:
+		table, err = s.studentsTable(ctx, teacherID)
+	case model.ExportDatasetInviteCodes:
+		table, err = s.inviteCodesTable(ctx, teacherID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if format == model.ExportFormatXLSX {
+		data, err = writeXLSX(table)
+	} else {
+		data, err = writeExportCSV(table)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filename := string(dataset)
+	if dataset.HasPeriod() {
+		filename += "_" + from.Format("2006-01-02") + "_" + to.AddDate(0, 0, -1).Format("2006-01-02")
+	} else {
+		filename += "_" + time.Now().Format("2006-01-02")
+	}
+
+	s.logger.Info("Teacher data exported",
+		zap.Int64("teacher_id", teacherID),
+		zap.String("dataset", string(dataset)),
+		zap.String("format", string(format)),
+		zap.Int("rows", len(table.Rows)))
+
+	return &model.ExportFile{
+		Filename: filename + "." + string(format),
+		Data:     data,
+		Rows:     len(table.Rows),
+	}, nil
+}
+
+// slotsTable собирает таблицу слотов за период
+func (s *ExportService) slotsTable(ctx context.Context, teacherID int64, from, to time.Time) (*model.ExportTable, error) {
+	slots, err := s.exportRepo.GetSlots(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &model.ExportTable{Name: "Слоты", Columns: slotExportColumns}
+	for _, slot := range slots {
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(slot.SlotID, 10),
+			slot.StartTime.Format(exportDateFormat),
+			slot.StartTime.Format(exportTimeFormat),
+			slot.EndTime.Format(exportTimeFormat),
+			strconv.Itoa(int(slot.EndTime.Sub(slot.StartTime).Minutes())),
+			slot.SubjectName,
+			exportSlotStatusName(slot.Status),
+			stringOrEmpty(slot.StudentName),
+			stringOrEmpty(slot.Comment),
+		})
+	}
+
+	return table, nil
+}
+
+// bookingsTable собирает таблицу записей за период
+func (s *ExportService) bookingsTable(ctx context.Context, teacherID int64, from, to time.Time) (*model.ExportTable, error) {
+	bookings, err := s.exportRepo.GetBookings(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &model.ExportTable{Name: "Записи", Columns: bookingExportColumns}
+	for _, booking := range bookings {
+		username := ""
+		if booking.StudentUsername != "" {
+			username = "@" + booking.StudentUsername
+		}
+
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(booking.BookingID, 10),
+			booking.StartTime.Format(exportDateFormat),
+			booking.StartTime.Format(exportTimeFormat),
+			booking.EndTime.Format(exportTimeFormat),
+			booking.SubjectName,
+			booking.StudentName,
+			username,
+			exportBookingStatusName(booking.Status),
+			exportAmount(booking.Price, booking.Currency),
+			string(booking.Currency),
+			stringOrEmpty(booking.PromoCode),
+			booking.CreatedAt.Format(exportDateTimeFormat),
+		})
+	}
+
+	return table, nil
+}
+
+// studentsTable собирает таблицу студентов с доступом к учителю
+func (s *ExportService) studentsTable(ctx context.Context, teacherID int64) (*model.ExportTable, error) {
+	students, err := s.exportRepo.GetStudents(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &model.ExportTable{Name: "Студенты", Columns: studentExportColumns}
+	for _, student := range students {
+		username := ""
+		if student.Username != "" {
+			username = "@" + student.Username
+		}
+
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(student.StudentID, 10),
+			student.Name,
+			username,
+			exportAccessTypeName(student.AccessType),
+			student.GrantedAt.Format(exportDateTimeFormat),
+			exportOptionalTime(student.ExpiresAt),
+		})
+	}
+
+	return table, nil
+}
+
+// inviteCodesTable собирает таблицу кодов приглашения и их использования
+func (s *ExportService) inviteCodesTable(ctx context.Context, teacherID int64) (*model.ExportTable, error) {
+	codes, err := s.inviteCodeRepo.GetByTeacherID(ctx, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get invite codes: %w", err)
+	}
+
+	table := &model.ExportTable{Name: "Коды приглашения", Columns: inviteCodeExportColumns}
+	for _, code := range codes {
+		maxUses := ""
+		if code.MaxUses != nil {
+			maxUses = strconv.Itoa(*code.MaxUses)
+		}
+
+		status := "Активен"
+		switch {
+		case !code.IsActive:
+			status = "Деактивирован"
+		case code.ExpiresAt != nil && time.Now().After(*code.ExpiresAt):
+			status = "Истёк"
+		case code.MaxUses != nil && code.CurrentUses >= *code.MaxUses:
+			status = "Исчерпан"
+		}
+
+		table.Rows = append(table.Rows, []string{
+			code.Code,
+			code.CreatedAt.Format(exportDateTimeFormat),
+			strconv.Itoa(code.CurrentUses),
+			maxUses,
+			exportOptionalTime(code.ExpiresAt),
+			status,
+		})
+	}
+
+	return table, nil
+}
+
+// writeExportCSV записывает таблицу в CSV для Excel: BOM, разделитель ';' и десятичная запятая в числах
+func writeExportCSV(table *model.ExportTable) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	header := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		header[i] = column.Title
+	}
+	w.Write(header)
+
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if i < len(table.Columns) && table.Columns[i].Numeric {
+				value = strings.Replace(value, ".", ",", 1)
+			}
+			record[i] = value
+		}
+		w.Write(record)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write export csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exportAmount форматирует сумму в минимальных единицах валюты числом с десятичной точкой
+func exportAmount(amount int, currency model.Currency) string {
+	return strings.Replace(formatCSVAmount(amount, currency), ",", ".", 1)
+}
+
+// exportOptionalTime форматирует необязательную дату, пустая строка — без ограничения
+func exportOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(exportDateTimeFormat)
+}
+
+// stringOrEmpty возвращает значение строки или пустую строку для nil
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// exportSlotStatusName возвращает название статуса слота для выгрузки
+func exportSlotStatusName(status model.SlotStatus) string {
+	switch status {
+	case model.SlotStatusFree:
+		return "Свободен"
+	case model.SlotStatusBooked:
+		return "Занят"
+	case model.SlotStatusCanceled:
+		return "Отменён"
+	default:
+		return string(status)
+	}
+}
+
+// exportBookingStatusName возвращает название статуса записи для выгрузки
+func exportBookingStatusName(status model.BookingStatus) string {
+	switch status {
+	case model.BookingStatusPending:
+		return "Ожидает подтверждения"
+	case model.BookingStatusConfirmed:
+		return "Подтверждена"
+	case model.BookingStatusCompleted:
+		return "Проведена"
+	case model.BookingStatusCanceled:
+		return "Отменена"
+	case model.BookingStatusRejected:
+		return "Отклонена"
+	case model.BookingStatusAwaitingPayment:
+		return "Ожидает оплаты"
+	case model.BookingStatusNoShow:
+		return "Неявка"
+	default:
+		return string(status)
+	}
+}
+
+// exportAccessTypeName возвращает название типа доступа для выгрузки
+func exportAccessTypeName(accessType string) string {
+	switch accessType {
+	case model.AccessTypeInvited:
+		return "По коду приглашения"
+	case model.AccessTypeApproved:
+		return "По заявке"
+	case model.AccessTypeSubscribed:
+		return "Подписка"
+	default:
+		return accessType
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxFavorites сколько учителей и предметов студент может держать в избранном
+	MaxFavorites = 30
+	// MaxSlotAlertsPerDay сколько уведомлений о свободных слотах студент получает за сутки
+	MaxSlotAlertsPerDay = 3
+	// SlotAlertWindow на сколько вперёд смотреть свободные слоты для уведомлений
+	SlotAlertWindow = 30 * 24 * time.Hour
+	// slotAlertListLimit сколько слотов перечислять в одном уведомлении
+	slotAlertListLimit = 10
+)
+
+// FavoriteService управляет избранными учителями и предметами студентов
+// и уведомляет о появившихся у них свободных слотах
+type FavoriteService struct {
+	favoriteRepo *repository.FavoriteRepository
+	subjectRepo  *repository.SubjectRepository
+	userRepo     *repository.UserRepository
+	access       *StudentAccessService
+	bookings     *BookingService
+	notifier     Notifier
+	logger       *zap.Logger
+}
+
+func NewFavoriteService(
+	favoriteRepo *repository.FavoriteRepository,
+	subjectRepo *repository.SubjectRepository,
+	userRepo *repository.UserRepository,
+	access *StudentAccessService,
+	bookings *BookingService,
+	notifier Notifier,
+	logger *zap.Logger,
+) *FavoriteService {
+	return &FavoriteService{
+		favoriteRepo: favoriteRepo,
+		subjectRepo:  subjectRepo,
+		userRepo:     userRepo,
+		access:       access,
+		bookings:     bookings,
+		notifier:     notifier,
+		logger:       logger,
+	}
+}
+
+// ToggleTeacher добавляет учителя в избранное или убирает из него. Возвращает true, если учитель добавлен
+func (s *FavoriteService) ToggleTeacher(ctx context.Context, studentID, teacherID int64) (bool, error) {
+	existing, err := s.favoriteRepo.FindTeacher(ctx, studentID, teacherID)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, s.favoriteRepo.Delete(ctx, existing.ID)
+	}
+
+	if err := s.checkCanAdd(ctx, studentID, teacherID); err != nil {
+		return false, err
+	}
+
+	return true, s.favoriteRepo.Create(ctx, &model.Favorite{StudentID: studentID, TeacherID: teacherID})
+}
+
+// ToggleSubject добавляет предмет в избранное или убирает из него. Возвращает true, если предмет добавлен
+func (s *FavoriteService) ToggleSubject(ctx context.Context, studentID, subjectID int64) (bool, error) {
+	existing, err := s.favoriteRepo.FindSubject(ctx, studentID, subjectID)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, s.favoriteRepo.Delete(ctx, existing.ID)
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return false, fmt.Errorf("get subject: %w", err)
+	}
+	if subject == nil || !subject.IsActive {
+		return false, fmt.Errorf("subject not found")
+	}
+
+	if err := s.checkCanAdd(ctx, studentID, subject.TeacherID); err != nil {
+		return false, err
+	}
+
+	return true, s.favoriteRepo.Create(ctx, &model.Favorite{
+		StudentID: studentID,
+		TeacherID: subject.TeacherID,
+		SubjectID: &subject.ID,
+	})
+}
+
+// checkCanAdd проверяет доступ к учителю и лимит избранного
+func (s *FavoriteService) checkCanAdd(ctx context.Context, studentID, teacherID int64) error {
+	canSee, err := s.access.CanStudentSeeTeacher(ctx, studentID, teacherID)
+	if err != nil {
+		return err
+	}
+	if !canSee {
+		return fmt.Errorf("teacher not available")
+	}
+
+	count, err := s.favoriteRepo.CountByStudentID(ctx, studentID)
+	if err != nil {
+		return err
+	}
+	if count >= MaxFavorites {
+		return fmt.Errorf("too many favorites")
+	}
+
+	return nil
+}
+
+// IsTeacherFavorite проверяет, есть ли учитель в избранном студента
+func (s *FavoriteService) IsTeacherFavorite(ctx context.Context, studentID, teacherID int64) (bool, error) {
+	favorite, err := s.favoriteRepo.FindTeacher(ctx, studentID, teacherID)
+	return favorite != nil, err
+}
+
+// IsSubjectFavorite проверяет, есть ли предмет в избранном студента
+func (s *FavoriteService) IsSubjectFavorite(ctx context.Context, studentID, subjectID int64) (bool, error) {
+	favorite, err := s.favoriteRepo.FindSubject(ctx, studentID, subjectID)
+	return favorite != nil, err
+}
+
+// GetFavorites получает избранное студента
+func (s *FavoriteService) GetFavorites(ctx context.Context, studentID int64) ([]*model.Favorite, error) {
+	return s.favoriteRepo.GetByStudentID(ctx, studentID)
+}
+
+// GetFavorite получает запись избранного студента
+func (s *FavoriteService) GetFavorite(ctx context.Context, studentID, favoriteID int64) (*model.Favorite, error) {
+	favorite, err := s.favoriteRepo.GetByID(ctx, favoriteID)
+	if err != nil {
+		return nil, err
+	}
+	if favorite == nil || favorite.StudentID != studentID {
+		return nil, fmt.Errorf("favorite not found")
+	}
+
+	return favorite, nil
+}
+
+// Remove убирает запись из избранного студента
+func (s *FavoriteService) Remove(ctx context.Context, studentID, favoriteID int64) error {
+	favorite, err := s.GetFavorite(ctx, studentID, favoriteID)
+	if err != nil {
+		return err
+	}
+
+	return s.favoriteRepo.Delete(ctx, favorite.ID)
+}
+
+// ToggleAlerts включает или выключает уведомления о свободных слотах
+func (s *FavoriteService) ToggleAlerts(ctx context.Context, studentID, favoriteID int64) (*model.Favorite, error) {
+	favorite, err := s.GetFavorite(ctx, studentID, favoriteID)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite.AlertsEnabled = !favorite.AlertsEnabled
+	if err := s.favoriteRepo.SetAlertsEnabled(ctx, favorite.ID, favorite.AlertsEnabled); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// ToggleWeekday отмечает день недели удобным или снимает отметку
+func (s *FavoriteService) ToggleWeekday(ctx context.Context, studentID, favoriteID int64, day time.Weekday) (*model.Favorite, error) {
+	if day < time.Sunday || day > time.Saturday {
+		return nil, fmt.Errorf("invalid weekday")
+	}
+
+	favorite, err := s.GetFavorite(ctx, studentID, favoriteID)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite.Weekdays ^= 1 << uint(day)
+	if err := s.favoriteRepo.SetTimes(ctx, favorite.ID, favorite.Weekdays, favorite.DayPeriods); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// ToggleDayPeriod отмечает часть дня model.DayPeriods[index] удобной или снимает отметку
+func (s *FavoriteService) ToggleDayPeriod(ctx context.Context, studentID, favoriteID int64, index int) (*model.Favorite, error) {
+	if index < 0 || index >= len(model.DayPeriods) {
+		return nil, fmt.Errorf("invalid day period")
+	}
+
+	favorite, err := s.GetFavorite(ctx, studentID, favoriteID)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite.DayPeriods ^= 1 << uint(index)
+	if err := s.favoriteRepo.SetTimes(ctx, favorite.ID, favorite.Weekdays, favorite.DayPeriods); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// SendSlotAlerts уведомляет студентов о новых свободных слотах избранных учителей и предметов.
+// Слоты одного студента собираются в одно сообщение; не больше MaxSlotAlertsPerDay сообщений за сутки.
+// Возвращает число отправленных уведомлений
+func (s *FavoriteService) SendSlotAlerts(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	favorites, err := s.favoriteRepo.GetAlertEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Избранное отсортировано по студентам
+	sent := 0
+	for start := 0; start < len(favorites); {
+		end := start
+		for end < len(favorites) && favorites[end].StudentID == favorites[start].StudentID {
+			end++
+		}
+
+		if s.sendStudentSlotAlert(ctx, favorites[start].StudentID, favorites[start:end]) {
+			sent++
+		}
+		start = end
+	}
+
+	return sent, nil
+}
+
+// sendStudentSlotAlert собирает новые слоты по избранному студента и отправляет одно уведомление.
+// Возвращает true, если уведомление отправлено
+func (s *FavoriteService) sendStudentSlotAlert(ctx context.Context, studentID int64, favorites []*model.Favorite) bool {
+	// При исчерпанном лимите слоты не отмечаются просмотренными и попадут в следующее уведомление
+	sentToday, err := s.favoriteRepo.CountAlertsSince(ctx, studentID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		s.logger.Error("Failed to count slot alerts", zap.Int64("student_id", studentID), zap.Error(err))
+		return false
+	}
+	if sentToday >= MaxSlotAlertsPerDay {
+		return false
+	}
+
+	// Доступные предметы учитывают видимость учителя: приватный учитель мог закрыть доступ
+	subjects, err := s.access.GetAccessibleSubjects(ctx, studentID)
+	if err != nil {
+		s.logger.Error("Failed to get accessible subjects", zap.Int64("student_id", studentID), zap.Error(err))
+		return false
+	}
+
+	// Время проверки фиксируем до выборки, чтобы не потерять слоты, освободившиеся во время рассылки
+	checkedAt := time.Now()
+	found := make(map[int64]bool)
+	var slots []*model.FreeTimeSlot
+	ids := make([]int64, 0, len(favorites))
+
+	for _, favorite := range favorites {
+		ids = append(ids, favorite.ID)
+
+		var favoriteSubjects []*model.Subject
+		for _, subject := range subjects {
+			if subject.TeacherID != favorite.TeacherID || (favorite.IsSubject() && subject.ID != *favorite.SubjectID) {
+				continue
+			}
+			favoriteSubjects = append(favoriteSubjects, subject)
+		}
+		if len(favoriteSubjects) == 0 {
+			continue
+		}
+
+		freed, err := s.bookings.GetNewAvailableSlotsForSubjects(ctx, studentID, favoriteSubjects,
+			favorite.AlertsCheckedAt, checkedAt, checkedAt.Add(SlotAlertWindow))
+		if err != nil {
+			s.logger.Error("Failed to get new free slots",
+				zap.Int64("favorite_id", favorite.ID),
+				zap.Error(err))
+			return false
+		}
+
+		filter := favorite.TimeFilter()
+		for _, slot := range freed {
+			if found[slot.ID] || !filter.Matches(slot.StartTime) {
+				continue
+			}
+			found[slot.ID] = true
+
+			var subject *model.Subject
+			for _, candidate := range favoriteSubjects {
+				if candidate.ID == slot.SubjectID {
+					subject = candidate
+					break
+				}
+			}
+			slots = append(slots, &model.FreeTimeSlot{Slot: slot, Subject: subject, TeacherName: favorite.TeacherName})
+		}
+	}
+
+	// Избранное отмечается проверенным только после отправки, иначе при сбое уведомления слоты потеряются
+	if len(slots) == 0 {
+		if err := s.favoriteRepo.MarkChecked(ctx, ids, checkedAt); err != nil {
+			s.logger.Error("Failed to mark favorites checked", zap.Int64("student_id", studentID), zap.Error(err))
+		}
+		return false
+	}
+
+	student, err := s.userRepo.GetByID(ctx, studentID)
+	if err != nil || student == nil {
+		s.logger.Error("Failed to get student for slot alert", zap.Int64("student_id", studentID), zap.Error(err))
+		return false
+	}
+
+	if err := s.notifier.Notify(ctx, student.TelegramID, formatSlotAlert(slots)); err != nil {
+		s.logger.Warn("Failed to send slot alert", zap.Int64("student_id", studentID), zap.Error(err))
+		return false
+	}
+
+	if err := s.favoriteRepo.MarkChecked(ctx, ids, checkedAt); err != nil {
+		s.logger.Error("Failed to mark favorites checked", zap.Int64("student_id", studentID), zap.Error(err))
+	}
+
+	if err := s.favoriteRepo.LogAlert(ctx, studentID, len(slots)); err != nil {
+		s.logger.Error("Failed to log slot alert", zap.Int64("student_id", studentID), zap.Error(err))
+	}
+
+	return true
+}
+
+// formatSlotAlert форматирует уведомление о новых свободных слотах (HTML)
+func formatSlotAlert(slots []*model.FreeTimeSlot) string {
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Slot.StartTime.Before(slots[j].Slot.StartTime)
+	})
+
+	text := "❤️ <b>Появилось свободное время в избранном</b>\n\n"
+	for i, slot := range slots {
+		if i == slotAlertListLimit {
+			text += fmt.Sprintf("…и ещё %d\n", len(slots)-slotAlertListLimit)
+			break
+		}
+
+		subjectName := ""
+		if slot.Subject != nil {
+			subjectName = slot.Subject.Name
+		}
+		text += fmt.Sprintf("• %s — %s (%s)\n",
+			slot.Slot.StartTime.Format("02.01.2006 15:04"),
+			html.EscapeString(subjectName),
+			html.EscapeString(slot.TeacherName))
+	}
+
+	text += "\nЗаписаться: /favorites"
+	return text
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxGroupNameLength максимальная длина названия группы в символах
+	MaxGroupNameLength = 40
+	// MaxGroupBroadcastLength максимальная длина рассылки группе: с заголовком должна влезть в сообщение Telegram
+	MaxGroupBroadcastLength = 3500
+)
+
+// GroupService управляет группами студентов учителя: участники, рассылки и слоты только для группы
+type GroupService struct {
+	groupRepo   *repository.GroupRepository
+	accessRepo  *repository.AccessRepository
+	userRepo    *repository.UserRepository
+	slotRepo    *repository.SlotRepository
+	subjectRepo *repository.SubjectRepository
+	notifier    Notifier
+	logger      *zap.Logger
+}
+
+func NewGroupService(
+	groupRepo *repository.GroupRepository,
+	accessRepo *repository.AccessRepository,
+	userRepo *repository.UserRepository,
+	slotRepo *repository.SlotRepository,
+	subjectRepo *repository.SubjectRepository,
+	notifier Notifier,
+	logger *zap.Logger,
+) *GroupService {
+	return &GroupService{
+		groupRepo:   groupRepo,
+		accessRepo:  accessRepo,
+		userRepo:    userRepo,
+		slotRepo:    slotRepo,
+		subjectRepo: subjectRepo,
+		notifier:    notifier,
+		logger:      logger,
+	}
+}
+
+// CreateGroup создает группу учителя
+func (s *GroupService) CreateGroup(ctx context.Context, teacherID int64, name string) (*model.StudentGroup, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxGroupNameLength {
+		return nil, fmt.Errorf("invalid group name")
+	}
+
+	group := &model.StudentGroup{
+		TeacherID: teacherID,
+		Name:      name,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Student group created",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("group_id", group.ID),
+	)
+
+	return group, nil
+}
+
+// GetTeacherGroups получает группы учителя с числом участников
+func (s *GroupService) GetTeacherGroups(ctx context.Context, teacherID int64) ([]*model.StudentGroup, error) {
+	return s.groupRepo.GetByTeacherID(ctx, teacherID)
+}
+
+// GetGroup получает группу учителя, проверяя владельца
+func (s *GroupService) GetGroup(ctx context.Context, teacherID, groupID int64) (*model.StudentGroup, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group == nil || group.TeacherID != teacherID {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	return group, nil
+}
+
+// DeleteGroup удаляет группу учителя. Слоты группы становятся доступны всем студентам
+func (s *GroupService) DeleteGroup(ctx context.Context, teacherID, groupID int64) error {
+	if _, err := s.GetGroup(ctx, teacherID, groupID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(ctx, groupID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Student group deleted",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("group_id", groupID),
+	)
+
+	return nil
+}
+
+// GetMembers получает участников группы учителя
+func (s *GroupService) GetMembers(ctx context.Context, teacherID, groupID int64) ([]*model.User, error) {
+	if _, err := s.GetGroup(ctx, teacherID, groupID); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.groupRepo.GetMemberIDs(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.GetByIDs(ctx, ids)
+}
+
+// AddMember добавляет в группу студента учителя. Добавить можно только студента с доступом к учителю
+func (s *GroupService) AddMember(ctx context.Context, teacherID, groupID, studentID int64) error {
+	if _, err := s.GetGroup(ctx, teacherID, groupID); err != nil {
+		return err
+	}
+
+	hasAccess, err := s.accessRepo.HasAccess(ctx, studentID, teacherID)
+	if err != nil {
+		return fmt.Errorf("check access: %w", err)
+	}
+
+	if !hasAccess {
+		return fmt.Errorf("student has no access")
+	}
+
+	if err := s.groupRepo.AddMember(ctx, groupID, studentID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Student added to group",
+		zap.Int64("group_id", groupID),
+		zap.Int64("student_id", studentID),
+	)
+
+	return nil
+}
+
+// RemoveMember удаляет студента из группы учителя
+func (s *GroupService) RemoveMember(ctx context.Context, teacherID, groupID, studentID int64) error {
+	if _, err := s.GetGroup(ctx, teacherID, groupID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMember(ctx, groupID, studentID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Student removed from group",
+		zap.Int64("group_id", groupID),
+		zap.Int64("student_id", studentID),
+	)
+
+	return nil
+}
+
+// Broadcast отправляет сообщение учителя всем участникам группы. Возвращает число доставленных сообщений
+func (s *GroupService) Broadcast(ctx context.Context, teacherID, groupID int64, text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, fmt.Errorf("empty message")
+	}
+
+	if utf8.RuneCountInString(text) > MaxGroupBroadcastLength {
+		return 0, fmt.Errorf("message too long")
+	}
+
+	group, err := s.GetGroup(ctx, teacherID, groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil || teacher == nil {
+		return 0, fmt.Errorf("get teacher: %w", err)
+	}
+
+	message := fmt.Sprintf(
+		"📣 <b>Сообщение от учителя %s</b>\nГруппа «%s»\n\n%s",
+		html.EscapeString(userFullName(teacher)),
+		html.EscapeString(group.Name),
+		html.EscapeString(text),
+	)
+
+	sent, err := s.notifyMembers(ctx, groupID, message)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Group broadcast sent",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("group_id", groupID),
+		zap.Int("sent", sent),
+	)
+
+	return sent, nil
+}
+
+// SetSlotGroup закрепляет свободный слот учителя за группой: записаться на него смогут только её участники.
+// groupID == nil снимает ограничение
+func (s *GroupService) SetSlotGroup(ctx context.Context, teacherID, slotID int64, groupID *int64) error {
+	slot, err := s.slotRepo.GetByID(ctx, slotID)
+	if err != nil {
+		return fmt.Errorf("get slot: %w", err)
+	}
+
+	if slot == nil || slot.TeacherID != teacherID {
+		return fmt.Errorf("slot not found")
+	}
+
+	if groupID != nil {
+		if _, err := s.GetGroup(ctx, teacherID, *groupID); err != nil {
+			return err
+		}
+
+		if slot.Status != model.SlotStatusFree {
+			return fmt.Errorf("slot is not free")
+		}
+	}
+
+	if err := s.groupRepo.SetSlotGroup(ctx, slotID, groupID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Slot group changed",
+		zap.Int64("slot_id", slotID),
+		zap.Any("group_id", groupID),
+	)
+
+	return nil
+}
+
+// InviteGroupToSlot приглашает участников группы записаться на закреплённое за ней занятие.
+// Возвращает число доставленных приглашений
+func (s *GroupService) InviteGroupToSlot(ctx context.Context, teacherID, slotID int64) (int, error) {
+	slot, err := s.slotRepo.GetByID(ctx, slotID)
+	if err != nil {
+		return 0, fmt.Errorf("get slot: %w", err)
+	}
+
+	if slot == nil || slot.TeacherID != teacherID {
+		return 0, fmt.Errorf("slot not found")
+	}
+
+	if slot.GroupID == nil {
+		return 0, fmt.Errorf("slot has no group")
+	}
+
+	if slot.Status != model.SlotStatusFree || slot.StartTime.Before(time.Now()) {
+		return 0, fmt.Errorf("slot is not free")
+	}
+
+	group, err := s.GetGroup(ctx, teacherID, *slot.GroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, slot.SubjectID)
+	if err != nil || subject == nil {
+		return 0, fmt.Errorf("get subject: %w", err)
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil || teacher == nil {
+		return 0, fmt.Errorf("get teacher: %w", err)
+	}
+
+	message := fmt.Sprintf(
+		"👥 <b>Занятие для группы «%s»</b>\n\n"+
+			"Учитель: %s\n"+
+			"Предмет: %s\n"+
+			"Время: %s — %s\n\n"+
+			"Запишитесь через /subjects, пока время свободно.",
+		html.EscapeString(group.Name),
+		html.EscapeString(userFullName(teacher)),
+		html.EscapeString(subject.Name),
+		slot.StartTime.Format("02.01.2006 15:04"),
+		slot.EndTime.Format("15:04"),
+	)
+
+	sent, err := s.notifyMembers(ctx, group.ID, message)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Group invited to slot",
+		zap.Int64("slot_id", slotID),
+		zap.Int64("group_id", group.ID),
+		zap.Int("sent", sent),
+	)
+
+	return sent, nil
+}
+
+// notifyMembers отправляет сообщение всем участникам группы и возвращает число доставленных
+func (s *GroupService) notifyMembers(ctx context.Context, groupID int64, message string) (int, error) {
+	if s.notifier == nil {
+		return 0, fmt.Errorf("notifications unavailable")
+	}
+
+	ids, err := s.groupRepo.GetMemberIDs(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	members, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, member := range members {
+		if err := s.notifier.Notify(ctx, member.TelegramID, message); err != nil {
+			s.logger.Warn("Failed to send group message",
+				zap.Int64("group_id", groupID),
+				zap.Int64("student_id", member.ID),
+				zap.Error(err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// userFullName возвращает имя и фамилию пользователя
+func userFullName(user *model.User) string {
+	if user.LastName == "" {
+		return user.FirstName
+	}
+	return user.FirstName + " " + user.LastName
+}
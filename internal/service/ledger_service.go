@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// MaxLedgerPayment максимальная сумма одной оплаты в минимальных единицах валюты
+const MaxLedgerPayment = 10_000_000 * 100
+
+// LedgerService ведёт взаиморасчёты учителя и студентов за занятия, оплаченные вне бота
+type LedgerService struct {
+	ledgerRepo *repository.LedgerRepository
+	userRepo   *repository.UserRepository
+	logger     *zap.Logger
+}
+
+func NewLedgerService(
+	ledgerRepo *repository.LedgerRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: ledgerRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+	}
+}
+
+// CompletePastLessons завершает прошедшие занятия и начисляет их стоимость студентам
+func (s *LedgerService) CompletePastLessons(ctx context.Context) (int, error) {
+	completed, charged, err := s.ledgerRepo.CompletePastBookings(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if completed > 0 {
+		s.logger.Info("Past lessons completed",
+			zap.Int("completed", completed),
+			zap.Int("charged", charged))
+	}
+
+	return completed, nil
+}
+
+// RecordPayment записывает оплату, полученную учителем от студента
+func (s *LedgerService) RecordPayment(ctx context.Context, teacherID, studentID int64, amount int, currency model.Currency, method model.LedgerPaymentMethod, description string) (*model.LedgerEntry, error) {
+	if amount <= 0 || amount > MaxLedgerPayment {
+		return nil, fmt.Errorf("invalid payment amount")
+	}
+
+	if !currency.IsValid() {
+		return nil, fmt.Errorf("invalid currency")
+	}
+
+	if method != model.LedgerPaymentCash && method != model.LedgerPaymentTransfer {
+		return nil, fmt.Errorf("invalid payment method")
+	}
+
+	student, err := s.userRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("get student: %w", err)
+	}
+
+	if student == nil {
+		return nil, fmt.Errorf("student not found")
+	}
+
+	entry := &model.LedgerEntry{
+		TeacherID:   teacherID,
+		StudentID:   studentID,
+		Kind:        model.LedgerEntryPayment,
+		Amount:      amount,
+		Currency:    currency,
+		Method:      &method,
+		Description: strings.TrimSpace(description),
+		OccurredAt:  time.Now(),
+	}
+
+	if err := s.ledgerRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Ledger payment recorded",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("student_id", studentID),
+		zap.Int("amount", amount),
+		zap.String("currency", string(currency)),
+		zap.String("method", string(method)))
+
+	return entry, nil
+}
+
+// ChargePackage начисляет студенту стоимость выданного пакета занятий в валюте пакета
+func (s *LedgerService) ChargePackage(ctx context.Context, teacherID, studentID int64, pkg *model.LessonPackage, subject *model.Subject) error {
+	if pkg.Price <= 0 {
+		return nil
+	}
+
+	return s.ledgerRepo.Create(ctx, &model.LedgerEntry{
+		TeacherID:   teacherID,
+		StudentID:   studentID,
+		Kind:        model.LedgerEntryCharge,
+		Amount:      pkg.Price,
+		Currency:    pkg.Currency,
+		Description: fmt.Sprintf("Пакет: %s, %d зан.", subject.Name, pkg.LessonCount),
+		OccurredAt:  time.Now(),
+	})
+}
+
+// GetBalances получает итоги взаиморасчётов учителя со студентом по каждой валюте
+func (s *LedgerService) GetBalances(ctx context.Context, teacherID, studentID int64) ([]*model.LedgerBalance, error) {
+	return s.ledgerRepo.GetBalances(ctx, teacherID, studentID)
+}
+
+// GetTeacherBalances получает итоги взаиморасчётов учителя со всеми студентами
+func (s *LedgerService) GetTeacherBalances(ctx context.Context, teacherID int64) ([]*model.LedgerBalance, error) {
+	return s.ledgerRepo.GetBalancesByTeacher(ctx, teacherID)
+}
+
+// GetStudentBalances получает итоги взаиморасчётов студента со всеми учителями
+func (s *LedgerService) GetStudentBalances(ctx context.Context, studentID int64) ([]*model.LedgerBalance, error) {
+	return s.ledgerRepo.GetBalancesByStudent(ctx, studentID)
+}
+
+// GetRecentEntries получает последние начисления и оплаты между учителем и студентом
+func (s *LedgerService) GetRecentEntries(ctx context.Context, teacherID, studentID int64, limit int) ([]*model.LedgerEntry, error) {
+	return s.ledgerRepo.GetRecentEntries(ctx, teacherID, studentID, limit)
+}
+
+// BuildMonthlyStatement формирует CSV-выписку учителя за месяц, в который попадает month
+func (s *LedgerService) BuildMonthlyStatement(ctx context.Context, teacherID int64, month time.Time) ([]byte, error) {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	to := from.AddDate(0, 1, 0)
+
+	entries, err := s.ledgerRepo.GetEntriesByTeacherAndPeriod(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	// BOM, чтобы Excel открыл файл в UTF-8
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	w.Write([]string{"Дата", "Студент", "Операция", "Сумма", "Валюта", "Способ", "Описание"})
+
+	// Итоги считаются отдельно по каждой валюте, суммы в разных валютах не складываются
+	type totalsKey struct {
+		studentID int64
+		currency  model.Currency
+	}
+	type totals struct {
+		name    string
+		charged int
+		paid    int
+	}
+	var order []totalsKey
+	byStudent := make(map[totalsKey]*totals)
+
+	for _, entry := range entries {
+		operation := "Начисление"
+		method := ""
+		if entry.Kind == model.LedgerEntryPayment {
+			operation = "Оплата"
+			if entry.Method != nil {
+				method = ledgerMethodName(*entry.Method)
+			}
+		}
+
+		w.Write([]string{
+			entry.OccurredAt.Format("02.01.2006 15:04"),
+			entry.StudentName,
+			operation,
+			formatCSVAmount(entry.Amount, entry.Currency),
+			string(entry.Currency),
+			method,
+			entry.Description,
+		})
+
+		key := totalsKey{studentID: entry.StudentID, currency: entry.Currency}
+		t, ok := byStudent[key]
+		if !ok {
+			t = &totals{name: entry.StudentName}
+			byStudent[key] = t
+			order = append(order, key)
+		}
+		if entry.Kind == model.LedgerEntryPayment {
+			t.paid += entry.Amount
+		} else {
+			t.charged += entry.Amount
+		}
+	}
+
+	// Итоги месяца по студентам
+	w.Write(nil)
+	w.Write([]string{"Итого за " + from.Format("01.2006"), "Студент", "Начислено", "Оплачено", "Разница", "Валюта"})
+	for _, key := range order {
+		t := byStudent[key]
+		w.Write([]string{
+			"",
+			t.name,
+			formatCSVAmount(t.charged, key.currency),
+			formatCSVAmount(t.paid, key.currency),
+			formatCSVAmount(t.charged-t.paid, key.currency),
+			string(key.currency),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write statement: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ledgerMethodName возвращает название способа оплаты для выписки
+func ledgerMethodName(method model.LedgerPaymentMethod) string {
+	switch method {
+	case model.LedgerPaymentCash:
+		return "Наличные"
+	case model.LedgerPaymentTransfer:
+		return "Перевод"
+	default:
+		return string(method)
+	}
+}
+
+// formatCSVAmount форматирует сумму в минимальных единицах валюты для выписки
+func formatCSVAmount(amount int, currency model.Currency) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if currency.MinorUnits() == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+
+	perMajor := currency.MinorPerMajor()
+	return fmt.Sprintf("%s%d,%0*d", sign, amount/perMajor, currency.MinorUnits(), amount%perMajor)
+}
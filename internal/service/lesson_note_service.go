@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxLessonNoteLength максимальная длина текста заметки к занятию
+	MaxLessonNoteLength = 3000
+	// LessonNoteHistoryLimit сколько последних заметок показывать в истории студента
+	LessonNoteHistoryLimit = 15
+	// HomeworkRemindBefore за сколько до срока домашнего задания напоминать студенту
+	HomeworkRemindBefore = 24 * time.Hour
+)
+
+// LessonNoteService управляет заметками учителя к занятиям: материалы, домашние задания и напоминания о них
+type LessonNoteService struct {
+	noteRepo    *repository.LessonNoteRepository
+	bookingRepo *repository.BookingRepository
+	userRepo    *repository.UserRepository
+	notifier    Notifier
+	logger      *zap.Logger
+}
+
+func NewLessonNoteService(
+	noteRepo *repository.LessonNoteRepository,
+	bookingRepo *repository.BookingRepository,
+	userRepo *repository.UserRepository,
+	notifier Notifier,
+	logger *zap.Logger,
+) *LessonNoteService {
+	return &LessonNoteService{
+		noteRepo:    noteRepo,
+		bookingRepo: bookingRepo,
+		userRepo:    userRepo,
+		notifier:    notifier,
+		logger:      logger,
+	}
+}
+
+// AddNote добавляет заметку учителя к записи на занятие и уведомляет студента
+func (s *LessonNoteService) AddNote(ctx context.Context, teacherID, bookingID int64, text, photoFileID, documentFileID string) (*model.LessonNote, error) {
+	text = strings.TrimSpace(text)
+	if text == "" && photoFileID == "" && documentFileID == "" {
+		return nil, fmt.Errorf("empty note")
+	}
+
+	if utf8.RuneCountInString(text) > MaxLessonNoteLength {
+		return nil, fmt.Errorf("note too long")
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil || booking.TeacherID != teacherID {
+		return nil, fmt.Errorf("booking not found")
+	}
+
+	note := &model.LessonNote{
+		BookingID:      bookingID,
+		TeacherID:      teacherID,
+		StudentID:      booking.StudentID,
+		Text:           text,
+		PhotoFileID:    photoFileID,
+		DocumentFileID: documentFileID,
+	}
+	if err := s.noteRepo.Create(ctx, note); err != nil {
+		return nil, err
+	}
+
+	// Перечитываем заметку с предметом и временем занятия для уведомления
+	if saved, err := s.noteRepo.GetByID(ctx, note.ID); err == nil && saved != nil {
+		note = saved
+	}
+
+	s.logger.Info("Lesson note added",
+		zap.Int64("note_id", note.ID),
+		zap.Int64("booking_id", bookingID),
+	)
+
+	s.notifyStudent(ctx, note)
+
+	return note, nil
+}
+
+// GetBookingNotes получает заметки к записи. Смотреть их могут учитель и студент записи
+func (s *LessonNoteService) GetBookingNotes(ctx context.Context, userID, bookingID int64) (*model.Booking, []*model.LessonNote, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil || (booking.TeacherID != userID && booking.StudentID != userID) {
+		return nil, nil, fmt.Errorf("booking not found")
+	}
+
+	notes, err := s.noteRepo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return booking, notes, nil
+}
+
+// GetNote получает заметку учителя или студента, к занятию которых она относится
+func (s *LessonNoteService) GetNote(ctx context.Context, userID, noteID int64) (*model.LessonNote, error) {
+	note, err := s.noteRepo.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note == nil || (note.TeacherID != userID && note.StudentID != userID) {
+		return nil, fmt.Errorf("note not found")
+	}
+
+	return note, nil
+}
+
+// GetStudentHistory получает последние заметки учителя по студенту
+func (s *LessonNoteService) GetStudentHistory(ctx context.Context, teacherID, studentID int64) ([]*model.LessonNote, error) {
+	return s.noteRepo.GetByStudentID(ctx, studentID, teacherID, LessonNoteHistoryLimit)
+}
+
+// GetMyHistory получает последние заметки всех учителей студента
+func (s *LessonNoteService) GetMyHistory(ctx context.Context, studentID int64) ([]*model.LessonNote, error) {
+	return s.noteRepo.GetByStudentID(ctx, studentID, 0, LessonNoteHistoryLimit)
+}
+
+// CountByBookings считает заметки по записям студента
+func (s *LessonNoteService) CountByBookings(ctx context.Context, studentID int64) (map[int64]int, error) {
+	return s.noteRepo.CountByStudentBookings(ctx, studentID)
+}
+
+// SetHomeworkDue устанавливает срок домашнего задания по заметке; days == 0 снимает срок.
+// Срок — конец дня через days дней
+func (s *LessonNoteService) SetHomeworkDue(ctx context.Context, teacherID, noteID int64, days int) (*time.Time, error) {
+	note, err := s.noteRepo.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note == nil || note.TeacherID != teacherID {
+		return nil, fmt.Errorf("note not found")
+	}
+
+	var dueAt *time.Time
+	if days > 0 {
+		now := time.Now()
+		due := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, now.Location()).AddDate(0, 0, days)
+		dueAt = &due
+	}
+
+	if err := s.noteRepo.SetHomeworkDue(ctx, noteID, dueAt); err != nil {
+		return nil, err
+	}
+
+	return dueAt, nil
+}
+
+// DeleteNote удаляет заметку учителя
+func (s *LessonNoteService) DeleteNote(ctx context.Context, teacherID, noteID int64) (*model.LessonNote, error) {
+	note, err := s.noteRepo.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note == nil || note.TeacherID != teacherID {
+		return nil, fmt.Errorf("note not found")
+	}
+
+	if err := s.noteRepo.Delete(ctx, noteID); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// SendHomeworkReminders напоминает студентам о домашних заданиях, срок которых наступает в ближайшие сутки.
+// Возвращает число отправленных напоминаний
+func (s *LessonNoteService) SendHomeworkReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	notes, err := s.noteRepo.GetHomeworkDueUnreminded(ctx, now, now.Add(HomeworkRemindBefore))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, note := range notes {
+		teacherName := "учителя"
+		if teacher, err := s.userRepo.GetByID(ctx, note.TeacherID); err == nil && teacher != nil {
+			teacherName = userFullName(teacher)
+		}
+
+		text := fmt.Sprintf(
+			"📚 <b>Напоминание о домашнем задании</b>\n\n"+
+				"Срок сдачи: %s\n"+
+				"Занятие: %s, %s\n"+
+				"Учитель: %s\n",
+			note.HomeworkDueAt.Format("02.01.2006 15:04"),
+			html.EscapeString(note.SubjectName),
+			note.LessonStart.Format("02.01.2006 15:04"),
+			html.EscapeString(teacherName),
+		)
+		if note.Text != "" {
+			text += "\n" + html.EscapeString(truncateRunes(note.Text, 1000)) + "\n"
+		}
+		text += "\nВсе заметки к занятиям — в /mybookings"
+
+		if s.notify(ctx, note.StudentID, text) {
+			sent++
+		}
+
+		// Отмечаем и недоставленные напоминания, чтобы не повторять их каждый час
+		if err := s.noteRepo.MarkReminded(ctx, note.ID); err != nil {
+			s.logger.Error("Failed to mark homework reminded", zap.Int64("note_id", note.ID), zap.Error(err))
+		}
+	}
+
+	if sent > 0 {
+		s.logger.Info("Homework reminders sent", zap.Int("count", sent))
+	}
+
+	return sent, nil
+}
+
+// notifyStudent сообщает студенту о новой заметке к занятию
+func (s *LessonNoteService) notifyStudent(ctx context.Context, note *model.LessonNote) {
+	teacherName := "Учитель"
+	if teacher, err := s.userRepo.GetByID(ctx, note.TeacherID); err == nil && teacher != nil {
+		teacherName = userFullName(teacher)
+	}
+
+	text := fmt.Sprintf("📝 <b>%s оставил заметку к занятию</b>\n", html.EscapeString(teacherName))
+	if note.SubjectName != "" {
+		text += fmt.Sprintf("📚 %s, %s\n", html.EscapeString(note.SubjectName), note.LessonStart.Format("02.01.2006 15:04"))
+	}
+	if note.Text != "" {
+		text += "\n" + html.EscapeString(truncateRunes(note.Text, 1000)) + "\n"
+	}
+	if note.HasAttachment() {
+		text += "\n📎 К заметке приложен файл."
+	}
+	text += "\nЗаметки к занятиям — в /mybookings"
+
+	s.notify(ctx, note.StudentID, text)
+}
+
+// notify отправляет уведомление пользователю; возвращает true, если оно доставлено
+func (s *LessonNoteService) notify(ctx context.Context, userID int64, text string) bool {
+	if s.notifier == nil {
+		return false
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return false
+	}
+
+	if err := s.notifier.Notify(ctx, user.TelegramID, text); err != nil {
+		s.logger.Warn("Failed to send lesson note notification", zap.Int64("user_id", userID), zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+// truncateRunes обрезает строку до limit символов
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,10 @@
+package service
+
+import "context"
+
+// MessageRelay пересылает сообщения переписки студента и учителя
+type MessageRelay interface {
+	// Relay отправляет HTML-сообщение в чат получателя и возвращает ID отправленного сообщения,
+	// чтобы ответ на него можно было вернуть в переписку. silent отправляет сообщение без звука
+	Relay(ctx context.Context, chatID int64, text string, silent bool) (int, error)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// Notifier отправляет пользователям уведомления из фоновых задач
+type Notifier interface {
+	// Notify отправляет HTML-сообщение в чат пользователя
+	Notify(ctx context.Context, chatID int64, text string) error
+}
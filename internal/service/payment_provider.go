@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+)
+
+// PaymentProvider выставляет счета на оплату занятий
+type PaymentProvider interface {
+	// Name возвращает название провайдера для записи в платёж
+	Name() string
+	// SendInvoice отправляет счёт студенту
+	SendInvoice(ctx context.Context, invoice *model.Invoice) error
+}
+
+// FakePaymentProvider провайдер для тестов: не обращается к Telegram,
+// а запоминает выставленные счета. Оплату можно сымитировать через
+// PaymentService.ConfirmPayment с payload из Invoices()
+type FakePaymentProvider struct {
+	mu       sync.Mutex
+	invoices []*model.Invoice
+
+	// Err, если задана, возвращается из SendInvoice вместо отправки
+	Err error
+}
+
+// NewFakePaymentProvider создаёт фейковый провайдер платежей
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{}
+}
+
+// Name возвращает название провайдера
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+// SendInvoice запоминает счёт
+func (p *FakePaymentProvider) SendInvoice(ctx context.Context, invoice *model.Invoice) error {
+	if p.Err != nil {
+		return p.Err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.invoices = append(p.invoices, invoice)
+	return nil
+}
+
+// Invoices возвращает все выставленные счета
+func (p *FakePaymentProvider) Invoices() []*model.Invoice {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	invoices := make([]*model.Invoice, len(p.invoices))
+	copy(invoices, p.invoices)
+	return invoices
+}
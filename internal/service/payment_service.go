@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PaymentService выставляет счета за занятия и подтверждает записи после оплаты
+type PaymentService struct {
+	paymentRepo *repository.PaymentRepository
+	bookingRepo *repository.BookingRepository
+	subjectRepo *repository.SubjectRepository
+	provider    PaymentProvider
+	timeout     time.Duration
+	logger      *zap.Logger
+}
+
+func NewPaymentService(
+	paymentRepo *repository.PaymentRepository,
+	bookingRepo *repository.BookingRepository,
+	subjectRepo *repository.SubjectRepository,
+	provider PaymentProvider,
+	timeout time.Duration,
+	logger *zap.Logger,
+) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		bookingRepo: bookingRepo,
+		subjectRepo: subjectRepo,
+		provider:    provider,
+		timeout:     timeout,
+		logger:      logger,
+	}
+}
+
+// Enabled проверяет, настроен ли провайдер платежей
+func (s *PaymentService) Enabled() bool {
+	return s != nil && s.provider != nil
+}
+
+// Timeout возвращает время, за которое нужно оплатить счёт
+func (s *PaymentService) Timeout() time.Duration {
+	return s.timeout
+}
+
+// bookingPayload формирует invoice_payload для бронирования
+func bookingPayload(bookingID int64) string {
+	return fmt.Sprintf("booking:%d", bookingID)
+}
+
+// CreateInvoice создаёт платёж для бронирования и отправляет счёт студенту.
+// Если счёт отправить не удалось, бронирование отменяется, а слот освобождается
+func (s *PaymentService) CreateInvoice(ctx context.Context, booking *model.Booking, subject *model.Subject, slot *model.ScheduleSlot, chatID int64) (*model.Payment, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("payments are not configured")
+	}
+
+	payment := &model.Payment{
+		BookingID: booking.ID,
+		StudentID: booking.StudentID,
+		TeacherID: booking.TeacherID,
+		Amount:    booking.Price,
+		Currency:  string(booking.Currency),
+		Provider:  s.provider.Name(),
+		Payload:   bookingPayload(booking.ID),
+		Status:    model.PaymentStatusPending,
+		ExpiresAt: time.Now().Add(s.timeout),
+	}
+
+	err := s.paymentRepo.Create(ctx, payment)
+	if err != nil {
+		s.releaseBooking(ctx, booking.ID)
+		return nil, fmt.Errorf("create payment: %w", err)
+	}
+
+	invoice := &model.Invoice{
+		ChatID: chatID,
+		Title:  subject.Name,
+		Description: fmt.Sprintf("Занятие %s, %s-%s",
+			slot.StartTime.Format("02.01.2006"),
+			slot.StartTime.Format("15:04"),
+			slot.EndTime.Format("15:04")),
+		Payload:  payment.Payload,
+		Currency: payment.Currency,
+		Amount:   payment.Amount,
+		Label:    subject.Name,
+	}
+
+	err = s.provider.SendInvoice(ctx, invoice)
+	if err != nil {
+		s.releaseBooking(ctx, booking.ID)
+		return nil, fmt.Errorf("send invoice: %w", err)
+	}
+
+	s.logger.Info("Invoice sent",
+		zap.Int64("payment_id", payment.ID),
+		zap.Int64("booking_id", booking.ID),
+		zap.Int("amount", payment.Amount),
+		zap.String("currency", payment.Currency),
+		zap.String("provider", payment.Provider),
+	)
+
+	return payment, nil
+}
+
+// releaseBooking отменяет бронирование, для которого не удалось выставить счёт
+func (s *PaymentService) releaseBooking(ctx context.Context, bookingID int64) {
+	if err := s.paymentRepo.ReleaseBooking(ctx, bookingID); err != nil {
+		s.logger.Error("Failed to release unpaid booking",
+			zap.Int64("booking_id", bookingID),
+			zap.Error(err))
+	}
+}
+
+// ValidatePreCheckout проверяет, можно ли принять оплату счёта (ответ на pre_checkout_query)
+func (s *PaymentService) ValidatePreCheckout(ctx context.Context, payload, currency string, amount int) error {
+	payment, err := s.paymentRepo.GetByPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("get payment: %w", err)
+	}
+
+	if payment == nil {
+		return fmt.Errorf("payment not found")
+	}
+
+	if payment.Status != model.PaymentStatusPending || time.Now().After(payment.ExpiresAt) {
+		return fmt.Errorf("payment expired")
+	}
+
+	if payment.Currency != currency || payment.Amount != amount {
+		return fmt.Errorf("payment amount mismatch")
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, payment.BookingID)
+	if err != nil {
+		return fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil || booking.Status != model.BookingStatusAwaitingPayment {
+		return fmt.Errorf("payment expired")
+	}
+
+	return nil
+}
+
+// ConfirmPayment фиксирует успешную оплату и подтверждает бронирование.
+// Если предмет требует одобрения учителя, бронирование переходит в ожидание одобрения
+func (s *PaymentService) ConfirmPayment(ctx context.Context, payload, currency string, amount int, telegramChargeID, providerChargeID string) (*model.Booking, error) {
+	payment, err := s.paymentRepo.GetByPayload(ctx, payload)
+	if err != nil {
+		return nil, fmt.Errorf("get payment: %w", err)
+	}
+
+	if payment == nil {
+		return nil, fmt.Errorf("payment not found")
+	}
+
+	if payment.Currency != currency || payment.Amount != amount {
+		s.logger.Warn("Paid amount differs from invoice",
+			zap.Int64("payment_id", payment.ID),
+			zap.Int("expected", payment.Amount),
+			zap.Int("paid", amount),
+			zap.String("currency", currency))
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, payment.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil {
+		return nil, fmt.Errorf("booking not found")
+	}
+
+	bookingStatus := model.BookingStatusConfirmed
+	subject, err := s.subjectRepo.GetByID(ctx, booking.SubjectID)
+	if err == nil && subject != nil && subject.RequiresBookingApproval {
+		bookingStatus = model.BookingStatusPending
+	}
+
+	confirmed, err := s.paymentRepo.MarkPaid(ctx, payment.ID, telegramChargeID, providerChargeID, bookingStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if !confirmed {
+		// Деньги получены, но запись уже освобождена — нужен возврат вручную
+		s.logger.Error("Payment received for released booking",
+			zap.Int64("payment_id", payment.ID),
+			zap.Int64("booking_id", booking.ID),
+			zap.String("telegram_charge_id", telegramChargeID))
+		return booking, fmt.Errorf("booking is no longer awaiting payment")
+	}
+
+	payment.Status = model.PaymentStatusPaid
+	booking.Status = bookingStatus
+	booking.Subject = subject
+	booking.Payment = payment
+
+	s.logger.Info("Booking paid",
+		zap.Int64("payment_id", payment.ID),
+		zap.Int64("booking_id", booking.ID),
+		zap.String("booking_status", string(bookingStatus)),
+	)
+
+	return booking, nil
+}
+
+// CancelPending закрывает неоплаченные счета отменённого бронирования
+func (s *PaymentService) CancelPending(ctx context.Context, bookingID int64) error {
+	return s.paymentRepo.ExpireByBooking(ctx, bookingID)
+}
+
+// ReleaseExpired отменяет бронирования, которые не оплатили вовремя, и освобождает слоты
+func (s *PaymentService) ReleaseExpired(ctx context.Context) ([]*model.Payment, error) {
+	payments, err := s.paymentRepo.ReleaseExpired(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("release expired payments: %w", err)
+	}
+
+	if len(payments) > 0 {
+		s.logger.Info("Unpaid bookings released", zap.Int("count", len(payments)))
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// promoCodePattern допустимый вид промокода: латиница, цифры, дефис и подчёркивание
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,20}$`)
+
+// PromoCodeService управляет промокодами учителей и скидками при записи
+type PromoCodeService struct {
+	promoRepo   *repository.PromoCodeRepository
+	subjectRepo *repository.SubjectRepository
+	userRepo    *repository.UserRepository
+	logger      *zap.Logger
+}
+
+func NewPromoCodeService(
+	promoRepo *repository.PromoCodeRepository,
+	subjectRepo *repository.SubjectRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *PromoCodeService {
+	return &PromoCodeService{
+		promoRepo:   promoRepo,
+		subjectRepo: subjectRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+	}
+}
+
+// NormalizePromoCode приводит введённый промокод к виду, в котором он хранится
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreatePromoCode создает промокод учителя. subjectID == nil — скидка на все предметы учителя
+func (s *PromoCodeService) CreatePromoCode(ctx context.Context, teacherID int64, code string, discountType model.PromoDiscountType, discountValue int, subjectID *int64, maxUses *int, expiresAt *time.Time) (*model.PromoCode, error) {
+	code = NormalizePromoCode(code)
+	if !promoCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("invalid promo code")
+	}
+
+	switch discountType {
+	case model.PromoDiscountPercent:
+		if discountValue <= 0 || discountValue > 100 {
+			return nil, fmt.Errorf("invalid discount")
+		}
+	case model.PromoDiscountFixed:
+		if discountValue <= 0 {
+			return nil, fmt.Errorf("invalid discount")
+		}
+	default:
+		return nil, fmt.Errorf("invalid discount")
+	}
+
+	if maxUses != nil && *maxUses <= 0 {
+		return nil, fmt.Errorf("invalid max uses")
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher: %w", err)
+	}
+
+	if teacher == nil || !teacher.IsTeacher {
+		return nil, fmt.Errorf("user is not a teacher")
+	}
+
+	// Фиксированная скидка задаётся в валюте предмета, а для всех предметов — в валюте учителя по умолчанию
+	currency := teacher.DefaultCurrency
+	if subjectID != nil {
+		subject, err := s.subjectRepo.GetByID(ctx, *subjectID)
+		if err != nil {
+			return nil, fmt.Errorf("get subject: %w", err)
+		}
+
+		if subject == nil || subject.TeacherID != teacherID {
+			return nil, fmt.Errorf("subject not found")
+		}
+		currency = subject.Currency
+	}
+
+	if discountType == model.PromoDiscountFixed && discountValue > currency.MaxPrice() {
+		return nil, fmt.Errorf("invalid discount")
+	}
+
+	exists, err := s.promoRepo.CodeExists(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, fmt.Errorf("promo code already exists")
+	}
+
+	promo := &model.PromoCode{
+		TeacherID:     teacherID,
+		Code:          code,
+		DiscountType:  discountType,
+		DiscountValue: discountValue,
+		SubjectID:     subjectID,
+		MaxUses:       maxUses,
+		ExpiresAt:     expiresAt,
+		IsActive:      true,
+	}
+	if discountType == model.PromoDiscountFixed {
+		promo.Currency = &currency
+	}
+
+	if err := s.promoRepo.Create(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Promo code created",
+		zap.Int64("teacher_id", teacherID),
+		zap.String("code", code),
+		zap.String("discount_type", string(discountType)),
+		zap.Int("discount_value", discountValue),
+	)
+
+	return promo, nil
+}
+
+// GetTeacherPromoCodes получает промокоды учителя
+func (s *PromoCodeService) GetTeacherPromoCodes(ctx context.Context, teacherID int64) ([]*model.PromoCode, error) {
+	return s.promoRepo.GetByTeacherID(ctx, teacherID)
+}
+
+// DeactivatePromoCode отключает промокод учителя
+func (s *PromoCodeService) DeactivatePromoCode(ctx context.Context, teacherID, promoID int64) error {
+	promo, err := s.promoRepo.GetByID(ctx, promoID)
+	if err != nil {
+		return fmt.Errorf("get promo code: %w", err)
+	}
+
+	if promo == nil {
+		return fmt.Errorf("promo code not found")
+	}
+
+	if promo.TeacherID != teacherID {
+		return fmt.Errorf("access denied: promo code belongs to another teacher")
+	}
+
+	if err := s.promoRepo.Deactivate(ctx, promoID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Promo code deactivated",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("promo_id", promoID),
+	)
+
+	return nil
+}
+
+// ApplyPromoCode применяет промокод студента к предмету; скидка сработает при следующей записи
+func (s *PromoCodeService) ApplyPromoCode(ctx context.Context, studentID, subjectID int64, code string) (*model.PromoCode, error) {
+	promo, err := s.promoRepo.GetByCode(ctx, NormalizePromoCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("get promo code: %w", err)
+	}
+
+	if promo == nil {
+		return nil, fmt.Errorf("promo code not found")
+	}
+
+	if !promo.IsValid() {
+		return nil, fmt.Errorf("promo code is not valid")
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject == nil {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	if !promo.AppliesTo(subject) {
+		return nil, fmt.Errorf("promo code does not apply to subject")
+	}
+
+	if err := s.promoRepo.SetApplication(ctx, studentID, subjectID, promo.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Promo code applied",
+		zap.Int64("student_id", studentID),
+		zap.Int64("subject_id", subjectID),
+		zap.String("code", promo.Code),
+	)
+
+	return promo, nil
+}
+
+// GetAppliedPromoCode получает действующий промокод, применённый студентом к предмету
+func (s *PromoCodeService) GetAppliedPromoCode(ctx context.Context, studentID int64, subject *model.Subject) (*model.PromoCode, error) {
+	promo, err := s.promoRepo.GetApplied(ctx, studentID, subject.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if promo == nil || !promo.IsValid() || !promo.AppliesTo(subject) {
+		return nil, nil
+	}
+
+	return promo, nil
+}
+
+// RemoveAppliedPromoCode убирает промокод, применённый студентом к предмету
+func (s *PromoCodeService) RemoveAppliedPromoCode(ctx context.Context, studentID, subjectID int64) error {
+	return s.promoRepo.DeleteApplication(ctx, studentID, subjectID)
+}
+
+// redeemForBooking засчитывает использование применённого промокода при записи.
+// Возвращает цену занятия и промокод, если скидка сработала
+func (s *PromoCodeService) redeemForBooking(ctx context.Context, studentID int64, subject *model.Subject) (int, *model.PromoCode) {
+	promo, err := s.GetAppliedPromoCode(ctx, studentID, subject)
+	if err != nil {
+		s.logger.Error("Failed to get applied promo code",
+			zap.Int64("student_id", studentID),
+			zap.Int64("subject_id", subject.ID),
+			zap.Error(err))
+		return subject.Price, nil
+	}
+
+	if promo == nil {
+		return subject.Price, nil
+	}
+
+	redeemed, err := s.promoRepo.Redeem(ctx, promo.ID)
+	if err != nil || !redeemed {
+		// Лимит исчерпан другим студентом или ошибка БД — записываем по полной цене
+		s.logger.Warn("Promo code was not redeemed",
+			zap.Int64("promo_id", promo.ID),
+			zap.Bool("redeemed", redeemed),
+			zap.Error(err))
+		return subject.Price, nil
+	}
+
+	return promo.Apply(subject.Price), promo
+}
+
+// completeRedemption убирает применённый промокод после записи или возвращает его использование,
+// если запись не состоялась
+func (s *PromoCodeService) completeRedemption(ctx context.Context, studentID, subjectID int64, promo *model.PromoCode, booked bool) {
+	var err error
+	if booked {
+		err = s.promoRepo.DeleteApplication(ctx, studentID, subjectID)
+	} else {
+		err = s.promoRepo.Release(ctx, promo.ID)
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to complete promo code redemption",
+			zap.Int64("promo_id", promo.ID),
+			zap.Bool("booked", booked),
+			zap.Error(err))
+	}
+}
+
+// releaseForBooking возвращает использование промокода отменённого или отклонённого бронирования.
+// Ошибка не мешает отмене и только логируется
+func (s *PromoCodeService) releaseForBooking(ctx context.Context, booking *model.Booking) {
+	if booking.PromoCodeID == nil {
+		return
+	}
+
+	if err := s.promoRepo.Release(ctx, *booking.PromoCodeID); err != nil {
+		s.logger.Error("Failed to release promo code",
+			zap.Int64("booking_id", booking.ID),
+			zap.Int64("promo_id", *booking.PromoCodeID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+// reportTopStudents сколько студентов показывать в рейтинге отчёта
+const reportTopStudents = 5
+
+// ReportService строит отчёты учителя о занятиях, выручке и загрузке расписания
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+	userRepo   *repository.UserRepository
+	logger     *zap.Logger
+}
+
+func NewReportService(
+	reportRepo *repository.ReportRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+	}
+}
+
+// BuildTeacherReport собирает отчёт учителя за период [from, to)
+func (s *ReportService) BuildTeacherReport(ctx context.Context, teacherID int64, from, to time.Time) (*model.TeacherReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period")
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get teacher: %w", err)
+	}
+
+	if teacher == nil || !teacher.IsTeacher {
+		return nil, fmt.Errorf("user is not a teacher")
+	}
+
+	report := &model.TeacherReport{From: from, To: to}
+
+	report.LessonsHeld, report.Canceled, report.NoShows, err = s.reportRepo.GetBookingCounts(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.BookedMinutes, report.FreeMinutes, err = s.reportRepo.GetSlotMinutes(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Revenue, err = s.reportRepo.GetRevenueBySubject(ctx, teacherID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Дни и часы считаются в часовом поясе бота, как и всё расписание
+	_, offset := from.Zone()
+	report.Load, err = s.reportRepo.GetLessonLoad(ctx, teacherID, from, to, time.Duration(offset)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	report.TopStudents, err = s.reportRepo.GetTopStudents(ctx, teacherID, from, to, reportTopStudents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Teacher report built",
+		zap.Int64("teacher_id", teacherID),
+		zap.Time("from", from),
+		zap.Time("to", to),
+		zap.Int("lessons_held", report.LessonsHeld))
+
+	return report, nil
+}
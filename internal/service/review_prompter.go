@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// ReviewPrompter предлагает студенту оценить завершённое занятие
+type ReviewPrompter interface {
+	// PromptReview отправляет HTML-сообщение с кнопками оценки записи bookingID
+	PromptReview(ctx context.Context, chatID, bookingID int64, text string) error
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Freeeeeet/scheduler_bot/internal/model"
+	"github.com/Freeeeeet/scheduler_bot/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxReviewCommentLength максимальная длина комментария к отзыву и ответа учителя
+	MaxReviewCommentLength = 1000
+	// ReviewRequestWindow сколько после занятия ещё можно попросить студента об оценке
+	ReviewRequestWindow = 3 * 24 * time.Hour
+	// ProfileReviewsLimit сколько последних отзывов показывать в профиле учителя
+	ProfileReviewsLimit = 3
+	// TeacherReviewsLimit сколько последних отзывов показывать учителю для ответа
+	TeacherReviewsLimit = 10
+)
+
+// ReviewService управляет отзывами студентов об учителях
+type ReviewService struct {
+	reviewRepo  *repository.ReviewRepository
+	bookingRepo *repository.BookingRepository
+	subjectRepo *repository.SubjectRepository
+	slotRepo    *repository.SlotRepository
+	userRepo    *repository.UserRepository
+	prompter    ReviewPrompter
+	notifier    Notifier
+	logger      *zap.Logger
+}
+
+func NewReviewService(
+	reviewRepo *repository.ReviewRepository,
+	bookingRepo *repository.BookingRepository,
+	subjectRepo *repository.SubjectRepository,
+	slotRepo *repository.SlotRepository,
+	userRepo *repository.UserRepository,
+	prompter ReviewPrompter,
+	notifier Notifier,
+	logger *zap.Logger,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:  reviewRepo,
+		bookingRepo: bookingRepo,
+		subjectRepo: subjectRepo,
+		slotRepo:    slotRepo,
+		userRepo:    userRepo,
+		prompter:    prompter,
+		notifier:    notifier,
+		logger:      logger,
+	}
+}
+
+// RequestReviews предлагает студентам оценить недавно завершённые занятия.
+// Возвращает число отправленных просьб
+func (s *ReviewService) RequestReviews(ctx context.Context) (int, error) {
+	if s.prompter == nil {
+		return 0, nil
+	}
+
+	bookings, err := s.reviewRepo.GetUnrequestedCompleted(ctx, time.Now().Add(-ReviewRequestWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, booking := range bookings {
+		// Отмечаем заранее: просьбу об отзыве не повторяем, даже если её не удалось доставить
+		if err := s.reviewRepo.MarkRequested(ctx, booking.ID); err != nil {
+			s.logger.Error("Failed to mark review requested", zap.Int64("booking_id", booking.ID), zap.Error(err))
+			continue
+		}
+
+		student, err := s.userRepo.GetByID(ctx, booking.StudentID)
+		if err != nil || student == nil {
+			continue
+		}
+
+		if err := s.prompter.PromptReview(ctx, student.TelegramID, booking.ID, s.promptText(ctx, booking)); err != nil {
+			s.logger.Warn("Failed to send review prompt", zap.Int64("booking_id", booking.ID), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.logger.Info("Review prompts sent", zap.Int("count", sent))
+	}
+
+	return sent, nil
+}
+
+// Rate сохраняет оценку студента за завершённое занятие. На запись можно оставить один отзыв
+func (s *ReviewService) Rate(ctx context.Context, studentID, bookingID int64, rating int) (*model.Review, error) {
+	if rating < model.MinReviewRating || rating > model.MaxReviewRating {
+		return nil, fmt.Errorf("invalid rating")
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("get booking: %w", err)
+	}
+
+	if booking == nil || booking.StudentID != studentID {
+		return nil, fmt.Errorf("booking not found")
+	}
+
+	if booking.Status != model.BookingStatusCompleted {
+		return nil, fmt.Errorf("lesson not completed")
+	}
+
+	review := &model.Review{
+		BookingID: booking.ID,
+		TeacherID: booking.TeacherID,
+		StudentID: studentID,
+		Rating:    rating,
+	}
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Review created",
+		zap.Int64("review_id", review.ID),
+		zap.Int64("teacher_id", review.TeacherID),
+		zap.Int("rating", rating))
+
+	s.notifyUser(ctx, booking.TeacherID, fmt.Sprintf(
+		"⭐ <b>Новая оценка: %s</b>\n\nСтудент оценил занятие. Отзывы и ответы на них — в настройках учителя.",
+		ratingStars(rating),
+	))
+
+	return review, nil
+}
+
+// SetComment добавляет комментарий студента к его отзыву
+func (s *ReviewService) SetComment(ctx context.Context, studentID, reviewID int64, comment string) (*model.Review, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, fmt.Errorf("empty comment")
+	}
+
+	if utf8.RuneCountInString(comment) > MaxReviewCommentLength {
+		return nil, fmt.Errorf("comment too long")
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil || review.StudentID != studentID {
+		return nil, fmt.Errorf("review not found")
+	}
+
+	if review.Comment != "" {
+		return nil, fmt.Errorf("comment already set")
+	}
+
+	if err := s.reviewRepo.SetComment(ctx, reviewID, comment); err != nil {
+		return nil, err
+	}
+	review.Comment = comment
+
+	s.notifyUser(ctx, review.TeacherID, fmt.Sprintf(
+		"💬 <b>Отзыв к оценке %s</b>\n📚 %s\n\n%s\n\nОтветить можно в настройках учителя → ⭐ Отзывы",
+		ratingStars(review.Rating),
+		html.EscapeString(review.SubjectName),
+		html.EscapeString(comment),
+	))
+
+	return review, nil
+}
+
+// Reply сохраняет ответ учителя на отзыв и сообщает о нём студенту
+func (s *ReviewService) Reply(ctx context.Context, teacherID, reviewID int64, reply string) (*model.Review, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	if utf8.RuneCountInString(reply) > MaxReviewCommentLength {
+		return nil, fmt.Errorf("reply too long")
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil || review.TeacherID != teacherID {
+		return nil, fmt.Errorf("review not found")
+	}
+
+	if err := s.reviewRepo.SetReply(ctx, reviewID, reply); err != nil {
+		return nil, err
+	}
+	review.TeacherReply = reply
+
+	teacherName := "Учитель"
+	if teacher, err := s.userRepo.GetByID(ctx, teacherID); err == nil && teacher != nil {
+		teacherName = userFullName(teacher)
+	}
+
+	s.notifyUser(ctx, review.StudentID, fmt.Sprintf(
+		"↩️ <b>%s ответил на ваш отзыв</b>\n📚 %s\n\n%s",
+		html.EscapeString(teacherName),
+		html.EscapeString(review.SubjectName),
+		html.EscapeString(reply),
+	))
+
+	return review, nil
+}
+
+// Report отмечает жалобу учителя на отзыв: комментарий остаётся в профиле с пометкой об оспаривании,
+// оценка продолжает учитываться в рейтинге
+func (s *ReviewService) Report(ctx context.Context, teacherID, reviewID int64) error {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+
+	if review == nil || review.TeacherID != teacherID {
+		return fmt.Errorf("review not found")
+	}
+
+	if review.IsReported() {
+		return fmt.Errorf("already reported")
+	}
+
+	if err := s.reviewRepo.MarkReported(ctx, reviewID); err != nil {
+		return err
+	}
+
+	s.logger.Warn("Review reported by teacher",
+		zap.Int64("review_id", reviewID),
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("student_id", review.StudentID))
+
+	return nil
+}
+
+// GetTeacherRating получает сводную оценку учителя; nil, если отзывов нет
+func (s *ReviewService) GetTeacherRating(ctx context.Context, teacherID int64) (*model.TeacherRating, error) {
+	ratings, err := s.reviewRepo.GetRatings(ctx, []int64{teacherID})
+	if err != nil {
+		return nil, err
+	}
+
+	return ratings[teacherID], nil
+}
+
+// GetRatings получает сводные оценки учителей
+func (s *ReviewService) GetRatings(ctx context.Context, teachers []*model.User) (map[int64]*model.TeacherRating, error) {
+	ids := make([]int64, 0, len(teachers))
+	for _, teacher := range teachers {
+		ids = append(ids, teacher.ID)
+	}
+
+	return s.reviewRepo.GetRatings(ctx, ids)
+}
+
+// GetRatingsByTeacherIDs получает сводные оценки учителей по их ID
+func (s *ReviewService) GetRatingsByTeacherIDs(ctx context.Context, teacherIDs []int64) (map[int64]*model.TeacherRating, error) {
+	return s.reviewRepo.GetRatings(ctx, teacherIDs)
+}
+
+// GetPublicReviews получает последние отзывы для профиля учителя; отзывы с жалобой показываются с пометкой
+func (s *ReviewService) GetPublicReviews(ctx context.Context, teacherID int64) ([]*model.Review, error) {
+	return s.reviewRepo.GetByTeacherID(ctx, teacherID, ProfileReviewsLimit)
+}
+
+// GetTeacherReviews получает последние отзывы учителю для ответа
+func (s *ReviewService) GetTeacherReviews(ctx context.Context, teacherID int64) ([]*model.Review, error) {
+	return s.reviewRepo.GetByTeacherID(ctx, teacherID, TeacherReviewsLimit)
+}
+
+// GetReview получает отзыв, если пользователь — его автор или учитель
+func (s *ReviewService) GetReview(ctx context.Context, userID, reviewID int64) (*model.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil || (review.TeacherID != userID && review.StudentID != userID) {
+		return nil, fmt.Errorf("review not found")
+	}
+
+	return review, nil
+}
+
+// SortTeachersByRating упорядочивает учителей по средней оценке, при равной — по числу отзывов.
+// Учителя без отзывов остаются в конце в прежнем порядке
+func SortTeachersByRating(teachers []*model.User, ratings map[int64]*model.TeacherRating) {
+	sort.SliceStable(teachers, func(i, j int) bool {
+		a, b := ratings[teachers[i].ID], ratings[teachers[j].ID]
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case a.Average != b.Average:
+			return a.Average > b.Average
+		default:
+			return a.Count > b.Count
+		}
+	})
+}
+
+// promptText формирует просьбу оценить занятие
+func (s *ReviewService) promptText(ctx context.Context, booking *model.Booking) string {
+	text := "⭐ <b>Как прошло занятие?</b>\n\n"
+
+	if subject, err := s.subjectRepo.GetByID(ctx, booking.SubjectID); err == nil && subject != nil {
+		text += fmt.Sprintf("📚 %s", html.EscapeString(subject.Name))
+		if slot, err := s.slotRepo.GetByID(ctx, booking.SlotID); err == nil && slot != nil {
+			text += ", " + slot.StartTime.Format("02.01.2006 15:04")
+		}
+		text += "\n"
+	}
+
+	if teacher, err := s.userRepo.GetByID(ctx, booking.TeacherID); err == nil && teacher != nil {
+		text += fmt.Sprintf("👤 %s\n", html.EscapeString(userFullName(teacher)))
+	}
+
+	text += "\nОцените занятие от 1 до 5 — отзыв поможет другим студентам выбрать учителя."
+
+	return text
+}
+
+// notifyUser отправляет уведомление пользователю
+func (s *ReviewService) notifyUser(ctx context.Context, userID int64, text string) {
+	if s.notifier == nil {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, user.TelegramID, text); err != nil {
+		s.logger.Warn("Failed to send review notification", zap.Int64("user_id", userID), zap.Error(err))
+	}
+}
+
+// ratingStars изображает оценку звёздами
+func ratingStars(rating int) string {
+	return strings.Repeat("⭐", rating)
+}
@@ -5,20 +5,50 @@ import (
 	"crypto/rand"
 	"encoding/base32"
 	"fmt"
+	"html"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/Freeeeeet/scheduler_bot/internal/repository"
 	"go.uber.org/zap"
 )
 
+// Ограничения подписок
+const (
+	MaxSubscriptionDays = 366
+	// SubscriptionWarnBefore за сколько до окончания подписки предупреждать студента
+	SubscriptionWarnBefore = 3 * 24 * time.Hour
+)
+
+// Параметры поиска предметов
+const (
+	// SubjectSearchLimit сколько найденных предметов показывать студенту
+	SubjectSearchLimit = 30
+	// MaxSubjectSearchQueryLength максимальная длина поискового запроса
+	MaxSubjectSearchQueryLength = 100
+	// SubjectSearchFreeSlotsWindow за какой период считать свободные слоты найденных предметов
+	SubjectSearchFreeSlotsWindow = 7 * 24 * time.Hour
+)
+
+// Параметры подбора времени у всех доступных учителей
+const (
+	// FreeTimeSearchWindow на какой период вперёд подбирать свободное время
+	FreeTimeSearchWindow = 14 * 24 * time.Hour
+	// FreeTimeSearchLimit сколько подходящих слотов показывать студенту
+	FreeTimeSearchLimit = 40
+)
+
 type StudentAccessService struct {
 	accessRepo     *repository.AccessRepository
 	inviteCodeRepo *repository.InviteCodeRepository
 	requestRepo    *repository.AccessRequestRepository
 	userRepo       *repository.UserRepository
 	subjectRepo    *repository.SubjectRepository
+	groupRepo      *repository.GroupRepository
+	bookings       *BookingService
+	notifier       Notifier
 	logger         *zap.Logger
 }
 
@@ -28,6 +58,9 @@ func NewStudentAccessService(
 	requestRepo *repository.AccessRequestRepository,
 	userRepo *repository.UserRepository,
 	subjectRepo *repository.SubjectRepository,
+	groupRepo *repository.GroupRepository,
+	bookings *BookingService,
+	notifier Notifier,
 	logger *zap.Logger,
 ) *StudentAccessService {
 	return &StudentAccessService{
@@ -36,6 +69,9 @@ func NewStudentAccessService(
 		requestRepo:    requestRepo,
 		userRepo:       userRepo,
 		subjectRepo:    subjectRepo,
+		groupRepo:      groupRepo,
+		bookings:       bookings,
+		notifier:       notifier,
 		logger:         logger,
 	}
 }
@@ -139,6 +175,123 @@ func (s *StudentAccessService) GetAccessibleSubjects(ctx context.Context, studen
 	return subjects, nil
 }
 
+// SearchSubjects ищет предметы, доступные студенту: у публичных учителей и у учителей, к которым у него есть доступ
+func (s *StudentAccessService) SearchSubjects(ctx context.Context, studentID int64, filter model.SubjectSearchFilter) ([]*model.SubjectSearchResult, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	if utf8.RuneCountInString(filter.Query) > MaxSubjectSearchQueryLength {
+		return nil, fmt.Errorf("query too long")
+	}
+
+	teacherIDs, err := s.accessRepo.GetStudentTeacherIDs(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("get student teacher ids: %w", err)
+	}
+
+	now := time.Now()
+	results, err := s.subjectRepo.Search(ctx, filter, studentID, teacherIDs, now, now.Add(SubjectSearchFreeSlotsWindow), SubjectSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Subjects searched",
+		zap.Int64("student_id", studentID),
+		zap.String("query", filter.Query),
+		zap.Int("found", len(results)))
+
+	return results, nil
+}
+
+// FindFreeTime подбирает свободные слоты у всех учителей, доступных студенту, по предмету или категории
+// и удобным дням и времени. Слоты отсортированы по времени начала
+func (s *StudentAccessService) FindFreeTime(ctx context.Context, studentID int64, filter model.FreeTimeFilter) ([]*model.FreeTimeSlot, error) {
+	subjects, err := s.GetAccessibleSubjects(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := FilterFreeTimeSubjects(subjects, filter)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no subjects")
+	}
+
+	now := time.Now()
+	slots, err := s.bookings.GetAvailableSlotsForSubjects(ctx, studentID, matched, now, now.Add(FreeTimeSearchWindow))
+	if err != nil {
+		return nil, fmt.Errorf("get free slots: %w", err)
+	}
+
+	bySubject := make(map[int64]*model.Subject, len(matched))
+	teacherIDs := make([]int64, 0, len(matched))
+	for _, subject := range matched {
+		bySubject[subject.ID] = subject
+		teacherIDs = append(teacherIDs, subject.TeacherID)
+	}
+
+	teachers, err := s.userRepo.GetByIDs(ctx, teacherIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get teachers: %w", err)
+	}
+	teacherNames := make(map[int64]string, len(teachers))
+	for _, teacher := range teachers {
+		teacherNames[teacher.ID] = userFullName(teacher)
+	}
+
+	result := []*model.FreeTimeSlot{}
+	for _, slot := range slots {
+		if !filter.Matches(slot.StartTime) {
+			continue
+		}
+		result = append(result, &model.FreeTimeSlot{
+			Slot:        slot,
+			Subject:     bySubject[slot.SubjectID],
+			TeacherName: teacherNames[slot.TeacherID],
+		})
+		if len(result) >= FreeTimeSearchLimit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// FilterFreeTimeSubjects выбирает предметы для подбора времени: с тем же названием, что у filter.SubjectID,
+// или из категории filter.Category. Без предмета и категории подходят все предметы
+func FilterFreeTimeSubjects(subjects []*model.Subject, filter model.FreeTimeFilter) []*model.Subject {
+	name := ""
+	if filter.SubjectID != 0 {
+		for _, subject := range subjects {
+			if subject.ID == filter.SubjectID {
+				name = SubjectNameKey(subject.Name)
+				break
+			}
+		}
+		if name == "" {
+			return nil
+		}
+	}
+
+	var matched []*model.Subject
+	for _, subject := range subjects {
+		switch {
+		case name != "" && SubjectNameKey(subject.Name) != name:
+			continue
+		case filter.Category != "" && subject.Category != filter.Category:
+			continue
+		}
+		matched = append(matched, subject)
+	}
+	return matched
+}
+
+// SubjectNameKey приводит название предмета к виду для сравнения предметов разных учителей
+func SubjectNameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
 // ============ Invite-коды ============
 
 // generateInviteCode генерирует уникальный invite-код
@@ -173,8 +326,13 @@ func (s *StudentAccessService) generateInviteCode(ctx context.Context) (string,
 	return "", fmt.Errorf("failed to generate unique code after %d attempts", maxAttempts)
 }
 
-// CreateInviteCode создает invite-код для учителя
-func (s *StudentAccessService) CreateInviteCode(ctx context.Context, teacherID int64, maxUses *int, expiresAt *time.Time) (*model.TeacherInviteCode, error) {
+// CreateInviteCode создает invite-код для учителя.
+// accessDays задаёт срок доступа, выданного по коду (nil - бессрочно), groupID - группу, в которую попадёт студент
+func (s *StudentAccessService) CreateInviteCode(ctx context.Context, teacherID int64, maxUses *int, expiresAt *time.Time, accessDays *int, groupID *int64) (*model.TeacherInviteCode, error) {
+	if !isValidAccessDays(accessDays) {
+		return nil, fmt.Errorf("invalid access period")
+	}
+
 	// Проверяем, что пользователь - учитель
 	teacher, err := s.userRepo.GetByID(ctx, teacherID)
 	if err != nil {
@@ -185,6 +343,17 @@ func (s *StudentAccessService) CreateInviteCode(ctx context.Context, teacherID i
 		return nil, fmt.Errorf("user is not a teacher")
 	}
 
+	if groupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *groupID)
+		if err != nil {
+			return nil, fmt.Errorf("get group: %w", err)
+		}
+
+		if group == nil || group.TeacherID != teacherID {
+			return nil, fmt.Errorf("group not found")
+		}
+	}
+
 	// Генерируем уникальный код
 	code, err := s.generateInviteCode(ctx)
 	if err != nil {
@@ -193,11 +362,13 @@ func (s *StudentAccessService) CreateInviteCode(ctx context.Context, teacherID i
 
 	// Создаем код
 	inviteCode := &model.TeacherInviteCode{
-		TeacherID: teacherID,
-		Code:      code,
-		MaxUses:   maxUses,
-		ExpiresAt: expiresAt,
-		IsActive:  true,
+		TeacherID:  teacherID,
+		Code:       code,
+		MaxUses:    maxUses,
+		ExpiresAt:  expiresAt,
+		AccessDays: accessDays,
+		GroupID:    groupID,
+		IsActive:   true,
 	}
 
 	err = s.inviteCodeRepo.Create(ctx, inviteCode)
@@ -213,7 +384,8 @@ func (s *StudentAccessService) CreateInviteCode(ctx context.Context, teacherID i
 	return inviteCode, nil
 }
 
-// UseInviteCode использует invite-код студентом
+// UseInviteCode использует invite-код студентом.
+// Код группы добавляет студента в группу; студент, у которого уже есть доступ, по такому коду только вступает в группу
 func (s *StudentAccessService) UseInviteCode(ctx context.Context, studentID int64, code string) error {
 	// Получаем код
 	inviteCode, err := s.inviteCodeRepo.GetByCode(ctx, code)
@@ -236,14 +408,22 @@ func (s *StudentAccessService) UseInviteCode(ctx context.Context, studentID int6
 		return fmt.Errorf("check access: %w", err)
 	}
 
-	if hasAccess {
+	if hasAccess && inviteCode.GroupID == nil {
 		return fmt.Errorf("access already granted")
 	}
 
 	// Предоставляем доступ
-	err = s.accessRepo.GrantAccess(ctx, studentID, inviteCode.TeacherID, model.AccessTypeInvited)
-	if err != nil {
-		return fmt.Errorf("grant access: %w", err)
+	if !hasAccess {
+		err = s.accessRepo.GrantAccess(ctx, studentID, inviteCode.TeacherID, model.AccessTypeInvited, inviteCode.AccessExpiresAt(time.Now()))
+		if err != nil {
+			return fmt.Errorf("grant access: %w", err)
+		}
+	}
+
+	if inviteCode.GroupID != nil {
+		if err := s.groupRepo.AddMember(ctx, *inviteCode.GroupID, studentID); err != nil {
+			return fmt.Errorf("join group: %w", err)
+		}
 	}
 
 	// Инкрементируем использования
@@ -361,8 +541,13 @@ func (s *StudentAccessService) CreateAccessRequest(ctx context.Context, studentI
 	return nil
 }
 
-// ApproveAccessRequest одобряет заявку (учитель)
-func (s *StudentAccessService) ApproveAccessRequest(ctx context.Context, teacherID, requestID int64, response string) error {
+// ApproveAccessRequest одобряет заявку (учитель).
+// accessDays задаёт срок выданного доступа (nil - бессрочно)
+func (s *StudentAccessService) ApproveAccessRequest(ctx context.Context, teacherID, requestID int64, response string, accessDays *int) error {
+	if !isValidAccessDays(accessDays) {
+		return fmt.Errorf("invalid access period")
+	}
+
 	// Получаем заявку
 	request, err := s.requestRepo.GetByID(ctx, requestID)
 	if err != nil {
@@ -390,7 +575,13 @@ func (s *StudentAccessService) ApproveAccessRequest(ctx context.Context, teacher
 	}
 
 	// Предоставляем доступ
-	err = s.accessRepo.GrantAccess(ctx, request.StudentID, teacherID, model.AccessTypeApproved)
+	var expiresAt *time.Time
+	if accessDays != nil {
+		t := time.Now().AddDate(0, 0, *accessDays)
+		expiresAt = &t
+	}
+
+	err = s.accessRepo.GrantAccess(ctx, request.StudentID, teacherID, model.AccessTypeApproved, expiresAt)
 	if err != nil {
 		return fmt.Errorf("grant access: %w", err)
 	}
@@ -465,13 +656,13 @@ func (s *StudentAccessService) GetStudentRequests(ctx context.Context, studentID
 
 // RevokeStudentAccess отзывает доступ у студента (учитель)
 func (s *StudentAccessService) RevokeStudentAccess(ctx context.Context, teacherID, studentID int64) error {
-	// Проверяем, что доступ существует
-	hasAccess, err := s.accessRepo.HasAccess(ctx, studentID, teacherID)
+	// Проверяем, что доступ существует (в том числе истёкший, но ещё не отозванный)
+	access, err := s.accessRepo.GetAccessInfo(ctx, studentID, teacherID)
 	if err != nil {
 		return fmt.Errorf("check access: %w", err)
 	}
 
-	if !hasAccess {
+	if access == nil {
 		return fmt.Errorf("access not found")
 	}
 
@@ -481,6 +672,8 @@ func (s *StudentAccessService) RevokeStudentAccess(ctx context.Context, teacherI
 		return fmt.Errorf("revoke access: %w", err)
 	}
 
+	s.leaveTeacherGroups(ctx, teacherID, studentID)
+
 	s.logger.Info("Access revoked",
 		zap.Int64("teacher_id", teacherID),
 		zap.Int64("student_id", studentID),
@@ -529,3 +722,161 @@ func (s *StudentAccessService) CountStudents(ctx context.Context, teacherID int6
 
 	return count, nil
 }
+
+// ============ Подписки ============
+
+// ExtendSubscription оформляет или продлевает подписку студента на days дней.
+// Действующая подписка продлевается от даты окончания, иначе — от текущего момента
+func (s *StudentAccessService) ExtendSubscription(ctx context.Context, teacherID, studentID int64, days int) (*model.StudentTeacherAccess, error) {
+	if days <= 0 || days > MaxSubscriptionDays {
+		return nil, fmt.Errorf("invalid subscription period")
+	}
+
+	access, err := s.accessRepo.GetAccessInfo(ctx, studentID, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("get access info: %w", err)
+	}
+
+	if access == nil {
+		return nil, fmt.Errorf("access not found")
+	}
+
+	now := time.Now()
+	base := now
+	if access.IsSubscription() && access.ExpiresAt != nil && access.ExpiresAt.After(now) {
+		base = *access.ExpiresAt
+	}
+
+	access, err = s.accessRepo.SetSubscription(ctx, studentID, teacherID, base.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Subscription extended",
+		zap.Int64("teacher_id", teacherID),
+		zap.Int64("student_id", studentID),
+		zap.Int("days", days),
+		zap.Time("expires_at", *access.ExpiresAt),
+	)
+
+	return access, nil
+}
+
+// ProcessAccessExpiry предупреждает студентов о скором окончании доступа и отзывает истёкший доступ
+func (s *StudentAccessService) ProcessAccessExpiry(ctx context.Context) error {
+	now := time.Now()
+
+	expiring, err := s.accessRepo.GetExpiringUnwarned(ctx, now, now.Add(SubscriptionWarnBefore))
+	if err != nil {
+		return err
+	}
+
+	for _, access := range expiring {
+		teacherName := s.userDisplayName(ctx, access.TeacherID)
+		s.notify(ctx, access.StudentID, fmt.Sprintf(
+			"⏳ <b>Доступ заканчивается</b>\n\n"+
+				"Ваш доступ к расписанию учителя <b>%s</b> действует до %s.\n"+
+				"Чтобы продолжить занятия, попросите учителя продлить доступ.",
+			html.EscapeString(teacherName),
+			access.ExpiresAt.Format("02.01.2006 15:04"),
+		))
+
+		if err := s.accessRepo.MarkExpiryWarned(ctx, access.ID); err != nil {
+			s.logger.Error("Failed to mark expiry warning", zap.Int64("access_id", access.ID), zap.Error(err))
+		}
+	}
+
+	expired, err := s.accessRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, access := range expired {
+		teacherName := s.userDisplayName(ctx, access.TeacherID)
+		studentName := s.userDisplayName(ctx, access.StudentID)
+
+		s.leaveTeacherGroups(ctx, access.TeacherID, access.StudentID)
+
+		// Будущие занятия без доступа не проводятся: отменяем их, освобождая слоты учителя
+		var canceled []*model.Booking
+		if s.bookings != nil {
+			canceled, err = s.bookings.CancelUpcomingForStudent(ctx, access.TeacherID, access.StudentID)
+			if err != nil {
+				s.logger.Error("Failed to cancel bookings after access expiry",
+					zap.Int64("teacher_id", access.TeacherID),
+					zap.Int64("student_id", access.StudentID),
+					zap.Error(err))
+			}
+		}
+
+		canceledNote := ""
+		if len(canceled) > 0 {
+			canceledNote = fmt.Sprintf("\n\nОтменено будущих записей: %d.", len(canceled))
+		}
+
+		s.notify(ctx, access.StudentID, fmt.Sprintf(
+			"🔒 <b>Доступ закончился</b>\n\n"+
+				"Срок доступа к учителю <b>%s</b> истёк. Записаться на новые занятия можно после продления.%s",
+			html.EscapeString(teacherName),
+			canceledNote,
+		))
+		s.notify(ctx, access.TeacherID, fmt.Sprintf(
+			"🔒 Доступ студента <b>%s</b> к вашему расписанию истёк и был отозван.%s",
+			html.EscapeString(studentName),
+			canceledNote,
+		))
+
+		s.logger.Info("Expired access revoked",
+			zap.Int64("teacher_id", access.TeacherID),
+			zap.Int64("student_id", access.StudentID),
+			zap.Int("canceled_bookings", len(canceled)),
+		)
+	}
+
+	return nil
+}
+
+// isValidAccessDays проверяет срок доступа в днях: nil - бессрочно
+func isValidAccessDays(days *int) bool {
+	return days == nil || (*days > 0 && *days <= MaxSubscriptionDays)
+}
+
+// leaveTeacherGroups убирает студента без доступа из групп учителя
+func (s *StudentAccessService) leaveTeacherGroups(ctx context.Context, teacherID, studentID int64) {
+	if err := s.groupRepo.RemoveStudentFromTeacherGroups(ctx, teacherID, studentID); err != nil {
+		s.logger.Error("Failed to remove student from teacher groups",
+			zap.Int64("teacher_id", teacherID),
+			zap.Int64("student_id", studentID),
+			zap.Error(err))
+	}
+}
+
+// notify отправляет уведомление пользователю по его ID, если настроен notifier
+func (s *StudentAccessService) notify(ctx context.Context, userID int64, text string) {
+	if s.notifier == nil {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, user.TelegramID, text); err != nil {
+		s.logger.Warn("Failed to send notification", zap.Int64("user_id", userID), zap.Error(err))
+	}
+}
+
+// userDisplayName возвращает имя пользователя для уведомлений
+func (s *StudentAccessService) userDisplayName(ctx context.Context, userID int64) string {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return ""
+	}
+
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	return name
+}
@@ -2,20 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Freeeeeet/scheduler_bot/internal/model"
 	"github.com/Freeeeeet/scheduler_bot/internal/repository"
 	"go.uber.org/zap"
 )
 
+// Ограничения тегов предмета
+const (
+	MaxSubjectTags      = 10
+	MaxSubjectTagLength = 30
+)
+
+// MaxCopyWeeks на сколько недель вперёд можно скопировать неделю расписания за раз
+const MaxCopyWeeks = 8
+
+// maxNotifiedConflicts сколько пропущенных слотов перечислять в уведомлении учителю
+const maxNotifiedConflicts = 10
+
 type TeacherService struct {
 	userRepo      *repository.UserRepository
 	subjectRepo   *repository.SubjectRepository
 	slotRepo      *repository.SlotRepository
 	bookingRepo   *repository.BookingRepository
 	recurringRepo *repository.RecurringScheduleRepository
+	credits       *CreditService
+	promos        *PromoCodeService
+	notifier      Notifier
 	logger        *zap.Logger
 }
 
@@ -25,6 +45,9 @@ func NewTeacherService(
 	slotRepo *repository.SlotRepository,
 	bookingRepo *repository.BookingRepository,
 	recurringRepo *repository.RecurringScheduleRepository,
+	credits *CreditService,
+	promos *PromoCodeService,
+	notifier Notifier,
 	logger *zap.Logger,
 ) *TeacherService {
 	return &TeacherService{
@@ -33,6 +56,9 @@ func NewTeacherService(
 		slotRepo:      slotRepo,
 		bookingRepo:   bookingRepo,
 		recurringRepo: recurringRepo,
+		credits:       credits,
+		promos:        promos,
+		notifier:      notifier,
 		logger:        logger,
 	}
 }
@@ -73,12 +99,19 @@ func (s *TeacherService) CreateSubject(ctx context.Context, teacherID int64, nam
 		zap.Int64("teacher_id", teacherID),
 		zap.String("teacher_name", teacher.FirstName))
 
+	// Цена нового предмета задаётся в валюте учителя по умолчанию
+	currency := teacher.DefaultCurrency
+	if !currency.IsValid() {
+		currency = model.DefaultCurrency
+	}
+
 	// Создаём предмет
 	subject := &model.Subject{
 		TeacherID:               teacherID,
 		Name:                    name,
 		Description:             description,
 		Price:                   price,
+		Currency:                currency,
 		Duration:                duration,
 		IsActive:                true,
 		RequiresBookingApproval: requiresApproval,
@@ -179,6 +212,15 @@ func (s *TeacherService) UpdateSubject(ctx context.Context, teacherID int64, sub
 		return fmt.Errorf("subject does not belong to teacher")
 	}
 
+	// Валюта меняется только вместе с ценой через ChangeSubjectCurrency
+	if subject.Currency != existing.Currency {
+		return fmt.Errorf("currency change requires new price")
+	}
+
+	if subject.Price != existing.Price && (subject.Price < 0 || subject.Price > subject.Currency.MaxPrice()) {
+		return fmt.Errorf("invalid price")
+	}
+
 	err = s.subjectRepo.Update(ctx, subject)
 	if err != nil {
 		return fmt.Errorf("update subject: %w", err)
@@ -191,6 +233,153 @@ func (s *TeacherService) UpdateSubject(ctx context.Context, teacherID int64, sub
 	return nil
 }
 
+// ChangeSubjectCurrency меняет валюту предмета вместе с ценой занятия, заново введённой в новой валюте.
+// Пакеты предмета снимаются с продажи: их цена указана в прежней валюте.
+// Возвращает количество снятых пакетов
+func (s *TeacherService) ChangeSubjectCurrency(ctx context.Context, teacherID, subjectID int64, currency model.Currency, price int) (int64, error) {
+	if !currency.IsValid() {
+		return 0, fmt.Errorf("invalid currency")
+	}
+
+	if price < 0 || price > currency.MaxPrice() {
+		return 0, fmt.Errorf("invalid price")
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return 0, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject == nil {
+		return 0, fmt.Errorf("subject not found")
+	}
+
+	if subject.TeacherID != teacherID {
+		return 0, fmt.Errorf("subject does not belong to teacher")
+	}
+
+	previous := subject.Currency
+	subject.Currency = currency
+	subject.Price = price
+	if err := s.subjectRepo.Update(ctx, subject); err != nil {
+		return 0, fmt.Errorf("update subject: %w", err)
+	}
+
+	var deactivated int64
+	if previous != currency {
+		deactivated, err = s.credits.deactivateSubjectPackages(ctx, subjectID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	s.logger.Info("Subject currency changed",
+		zap.Int64("subject_id", subjectID),
+		zap.String("from", string(previous)),
+		zap.String("to", string(currency)),
+		zap.Int("price", price),
+		zap.Int64("packages_deactivated", deactivated),
+	)
+
+	return deactivated, nil
+}
+
+// SetSubjectCategory задаёт категорию предмета; пустой код убирает категорию
+func (s *TeacherService) SetSubjectCategory(ctx context.Context, teacherID, subjectID int64, category string) (*model.Subject, error) {
+	if category != "" {
+		if _, ok := model.FindSubjectCategory(category); !ok {
+			return nil, fmt.Errorf("unknown category")
+		}
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject == nil {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	if subject.TeacherID != teacherID {
+		return nil, fmt.Errorf("subject does not belong to teacher")
+	}
+
+	subject.Category = category
+	if err := s.subjectRepo.Update(ctx, subject); err != nil {
+		return nil, fmt.Errorf("update subject: %w", err)
+	}
+
+	return subject, nil
+}
+
+// SetSubjectTags заменяет теги предмета тегами из текста через запятую; "-" очищает теги
+func (s *TeacherService) SetSubjectTags(ctx context.Context, teacherID, subjectID int64, text string) (*model.Subject, error) {
+	tags, err := ParseSubjectTags(text)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get subject: %w", err)
+	}
+
+	if subject == nil {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	if subject.TeacherID != teacherID {
+		return nil, fmt.Errorf("subject does not belong to teacher")
+	}
+
+	subject.Tags = tags
+	if err := s.subjectRepo.Update(ctx, subject); err != nil {
+		return nil, fmt.Errorf("update subject: %w", err)
+	}
+
+	s.logger.Info("Subject tags updated",
+		zap.Int64("subject_id", subjectID),
+		zap.Strings("tags", tags),
+	)
+
+	return subject, nil
+}
+
+// ParseSubjectTags разбирает теги через запятую: приводит к нижнему регистру,
+// убирает # и повторы. "-" означает пустой список тегов
+func ParseSubjectTags(text string) ([]string, error) {
+	text = strings.TrimSpace(text)
+	if text == "-" {
+		return []string{}, nil
+	}
+
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "#")))
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxSubjectTagLength {
+			return nil, fmt.Errorf("tag too long")
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("empty tags")
+	}
+
+	if len(tags) > MaxSubjectTags {
+		return nil, fmt.Errorf("too many tags")
+	}
+
+	return tags, nil
+}
+
 // DeleteSubject удаляет предмет
 func (s *TeacherService) DeleteSubject(ctx context.Context, teacherID, subjectID int64) error {
 	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
@@ -245,6 +434,16 @@ func (s *TeacherService) CreateSlot(ctx context.Context, teacherID, subjectID in
 		return nil, fmt.Errorf("cannot create slot in the past")
 	}
 
+	// Проверяем пересечение с другими слотами учителя (включая другие предметы)
+	overlapping, err := s.slotRepo.FindOverlapping(ctx, teacherID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("find overlapping slot: %w", err)
+	}
+
+	if overlapping != nil {
+		return nil, repository.ErrSlotOverlap
+	}
+
 	// Создаём слот
 	slot := &model.ScheduleSlot{
 		TeacherID: teacherID,
@@ -257,6 +456,9 @@ func (s *TeacherService) CreateSlot(ctx context.Context, teacherID, subjectID in
 
 	err = s.slotRepo.Create(ctx, slot)
 	if err != nil {
+		if errors.Is(err, repository.ErrSlotOverlap) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("create slot: %w", err)
 	}
 
@@ -269,6 +471,114 @@ func (s *TeacherService) CreateSlot(ctx context.Context, teacherID, subjectID in
 	return slot, nil
 }
 
+// PlanWeekCopy рассчитывает копирование не отменённых слотов недели, начинающейся с weekStart,
+// на weeks следующих недель. subjectID = 0 означает все предметы учителя. Ничего не создаёт.
+func (s *TeacherService) PlanWeekCopy(ctx context.Context, teacherID int64, weekStart time.Time, subjectID int64, weeks int) (*model.SlotCopyPlan, error) {
+	if weeks < 1 || weeks > MaxCopyWeeks {
+		return nil, fmt.Errorf("invalid weeks count")
+	}
+
+	sourceSlots, err := s.slotRepo.GetByTeacherID(ctx, teacherID, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, fmt.Errorf("get source slots: %w", err)
+	}
+
+	now := time.Now()
+	plan := &model.SlotCopyPlan{}
+
+	for week := 1; week <= weeks; week++ {
+		for _, source := range sourceSlots {
+			if source.Status == model.SlotStatusCanceled {
+				continue
+			}
+			if subjectID != 0 && source.SubjectID != subjectID {
+				continue
+			}
+
+			// AddDate сохраняет время суток при переходе на летнее/зимнее время
+			copySlot := &model.ScheduleSlot{
+				TeacherID: teacherID,
+				SubjectID: source.SubjectID,
+				StartTime: source.StartTime.AddDate(0, 0, 7*week),
+				EndTime:   source.EndTime.AddDate(0, 0, 7*week),
+				Status:    model.SlotStatusFree,
+				StudentID: nil,
+			}
+
+			if copySlot.StartTime.Before(now) {
+				plan.Skipped = append(plan.Skipped, copySlot)
+				continue
+			}
+
+			overlapping, err := s.slotRepo.FindOverlapping(ctx, teacherID, copySlot.StartTime, copySlot.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("find overlapping slot: %w", err)
+			}
+
+			if overlapping == nil {
+				plan.Slots = append(plan.Slots, copySlot)
+				continue
+			}
+
+			// Такой же слот уже есть — копировать нечего
+			if overlapping.SubjectID == copySlot.SubjectID &&
+				overlapping.StartTime.Equal(copySlot.StartTime) && overlapping.EndTime.Equal(copySlot.EndTime) {
+				plan.Skipped = append(plan.Skipped, copySlot)
+				continue
+			}
+
+			plan.Conflicts = append(plan.Conflicts, model.SlotConflict{
+				StartTime: copySlot.StartTime,
+				EndTime:   copySlot.EndTime,
+				Existing:  overlapping,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// CopyWeek копирует слоты недели на следующие недели по плану PlanWeekCopy
+// Пересечения, появившиеся между расчётом и созданием, попадают в Conflicts, остальные ошибки создания — в Failed
+func (s *TeacherService) CopyWeek(ctx context.Context, teacherID int64, weekStart time.Time, subjectID int64, weeks int) (*model.SlotCopyPlan, error) {
+	plan, err := s.PlanWeekCopy(ctx, teacherID, weekStart, subjectID, weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*model.ScheduleSlot, 0, len(plan.Slots))
+	for _, slot := range plan.Slots {
+		err := s.slotRepo.Create(ctx, slot)
+		if errors.Is(err, repository.ErrSlotOverlap) {
+			plan.Conflicts = append(plan.Conflicts, model.SlotConflict{StartTime: slot.StartTime, EndTime: slot.EndTime})
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("Failed to create copied slot",
+				zap.Error(err),
+				zap.Time("start_time", slot.StartTime),
+			)
+			plan.Failed = append(plan.Failed, slot)
+			continue
+		}
+		created = append(created, slot)
+	}
+	plan.Slots = created
+
+	s.logger.Info("Week copied",
+		zap.Int64("teacher_id", teacherID),
+		zap.Time("week_start", weekStart),
+		zap.Int64("subject_id", subjectID),
+		zap.Int("weeks", weeks),
+		zap.Int("created", len(plan.Slots)),
+		zap.Int("skipped", len(plan.Skipped)),
+		zap.Int("conflicts", len(plan.Conflicts)),
+		zap.Int("failed", len(plan.Failed)),
+	)
+
+	return plan, nil
+}
+
 // GetTeacherSchedule получает расписание учителя за период
 func (s *TeacherService) GetTeacherSchedule(ctx context.Context, teacherID int64, from, to time.Time) ([]*model.ScheduleSlot, error) {
 	return s.slotRepo.GetByTeacherID(ctx, teacherID, from, to)
@@ -281,19 +591,20 @@ func (s *TeacherService) GetTeacherBookings(ctx context.Context, teacherID int64
 
 // CreateWeeklySlots создаёт регулярное расписание (recurring schedule) и первичные слоты
 // Этот метод устарел, используйте CreateWeeklySlotsGroup для создания группы расписаний
-func (s *TeacherService) CreateWeeklySlots(ctx context.Context, teacherID, subjectID int64, weekday time.Weekday, startHour, startMinute, durationMinutes int) error {
+// Возвращает слоты, пропущенные из-за пересечения с существующими
+func (s *TeacherService) CreateWeeklySlots(ctx context.Context, teacherID, subjectID int64, weekday time.Weekday, startHour, startMinute, durationMinutes int) ([]model.SlotConflict, error) {
 	// Проверяем что предмет принадлежит учителю
 	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
 	if err != nil {
-		return fmt.Errorf("get subject: %w", err)
+		return nil, fmt.Errorf("get subject: %w", err)
 	}
 
 	if subject == nil {
-		return fmt.Errorf("subject not found")
+		return nil, fmt.Errorf("subject not found")
 	}
 
 	if subject.TeacherID != teacherID {
-		return fmt.Errorf("subject does not belong to teacher")
+		return nil, fmt.Errorf("subject does not belong to teacher")
 	}
 
 	// Создаём recurring schedule (шаблон регулярного расписания)
@@ -311,7 +622,7 @@ func (s *TeacherService) CreateWeeklySlots(ctx context.Context, teacherID, subje
 
 	err = s.recurringRepo.Create(ctx, recurringSchedule)
 	if err != nil {
-		return fmt.Errorf("create recurring schedule: %w", err)
+		return nil, fmt.Errorf("create recurring schedule: %w", err)
 	}
 
 	s.logger.Info("Recurring schedule created",
@@ -323,7 +634,7 @@ func (s *TeacherService) CreateWeeklySlots(ctx context.Context, teacherID, subje
 	)
 
 	// Создаём начальные слоты на следующие 4 недели
-	count, err := s.generateSlotsForRecurringSchedule(ctx, recurringSchedule, 4)
+	count, conflicts, err := s.generateSlotsForRecurringSchedule(ctx, recurringSchedule, 4)
 	if err != nil {
 		s.logger.Error("Failed to generate initial slots", zap.Error(err))
 		// Не возвращаем ошибку, т.к. recurring schedule уже создан
@@ -332,37 +643,42 @@ func (s *TeacherService) CreateWeeklySlots(ctx context.Context, teacherID, subje
 	s.logger.Info("Initial weekly slots created",
 		zap.Int64("recurring_schedule_id", recurringSchedule.ID),
 		zap.Int("count", count),
+		zap.Int("conflicts", len(conflicts)),
 	)
 
-	return nil
+	return conflicts, nil
 }
 
 // CreateWeeklySlotsGroup создаёт группу регулярных расписаний с общим group_id
 // weekdays - массив дней недели (0 = Sunday, 6 = Saturday)
 // timeSlots - массив временных слотов (час и минута)
-func (s *TeacherService) CreateWeeklySlotsGroup(ctx context.Context, teacherID, subjectID int64, weekdays []int, timeSlots []struct{ Hour, Minute int }, durationMinutes int) (int64, error) {
+func (s *TeacherService) CreateWeeklySlotsGroup(ctx context.Context, teacherID, subjectID int64, weekdays []int, timeSlots []struct{ Hour, Minute int }, durationMinutes int) (int64, []model.SlotConflict, error) {
 	// Проверяем что предмет принадлежит учителю
 	subject, err := s.subjectRepo.GetByID(ctx, subjectID)
 	if err != nil {
-		return 0, fmt.Errorf("get subject: %w", err)
+		return 0, nil, fmt.Errorf("get subject: %w", err)
 	}
 
 	if subject == nil {
-		return 0, fmt.Errorf("subject not found")
+		return 0, nil, fmt.Errorf("subject not found")
 	}
 
 	if subject.TeacherID != teacherID {
-		return 0, fmt.Errorf("subject does not belong to teacher")
+		return 0, nil, fmt.Errorf("subject does not belong to teacher")
 	}
 
+	// Убираем время, попадающее в перерыв после предыдущего занятия
+	timeSlots = applySlotBuffer(timeSlots, subject)
+
 	// Получаем следующий свободный group_id
 	groupID, err := s.recurringRepo.GetNextGroupID(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("get next group_id: %w", err)
+		return 0, nil, fmt.Errorf("get next group_id: %w", err)
 	}
 
 	// Создаём recurring schedules для каждого дня и каждого времени
 	createdCount := 0
+	var conflicts []model.SlotConflict
 	for _, weekday := range weekdays {
 		for _, slot := range timeSlots {
 			recurringSchedule := &model.RecurringSchedule{
@@ -388,7 +704,7 @@ func (s *TeacherService) CreateWeeklySlotsGroup(ctx context.Context, teacherID,
 			}
 
 			// Создаём начальные слоты на следующие 4 недели
-			count, err := s.generateSlotsForRecurringSchedule(ctx, recurringSchedule, 4)
+			count, scheduleConflicts, err := s.generateSlotsForRecurringSchedule(ctx, recurringSchedule, 4)
 			if err != nil {
 				s.logger.Error("Failed to generate initial slots",
 					zap.Error(err),
@@ -398,6 +714,7 @@ func (s *TeacherService) CreateWeeklySlotsGroup(ctx context.Context, teacherID,
 					zap.Int64("recurring_schedule_id", recurringSchedule.ID),
 					zap.Int("count", count))
 			}
+			conflicts = append(conflicts, scheduleConflicts...)
 
 			createdCount++
 		}
@@ -410,18 +727,48 @@ func (s *TeacherService) CreateWeeklySlotsGroup(ctx context.Context, teacherID,
 		zap.Int("weekdays_count", len(weekdays)),
 		zap.Int("time_slots_count", len(timeSlots)),
 		zap.Int("total_created", createdCount),
+		zap.Int("conflicts", len(conflicts)),
 	)
 
-	return groupID, nil
+	return groupID, conflicts, nil
+}
+
+// applySlotBuffer сортирует время начала слотов и отбрасывает те, что начинаются раньше,
+// чем закончится предыдущее занятие вместе с перерывами предмета
+func applySlotBuffer(timeSlots []struct{ Hour, Minute int }, subject *model.Subject) []struct{ Hour, Minute int } {
+	if !subject.HasBuffer() {
+		return timeSlots
+	}
+
+	sorted := make([]struct{ Hour, Minute int }, len(timeSlots))
+	copy(sorted, timeSlots)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Hour*60+sorted[i].Minute < sorted[j].Hour*60+sorted[j].Minute
+	})
+
+	result := make([]struct{ Hour, Minute int }, 0, len(sorted))
+	nextAllowed := -1
+	for _, slot := range sorted {
+		start := slot.Hour*60 + slot.Minute
+		if nextAllowed >= 0 && start < nextAllowed {
+			continue
+		}
+		result = append(result, slot)
+		nextAllowed = start + subject.SlotStep()
+	}
+
+	return result
 }
 
 // generateSlotsForRecurringSchedule генерирует слоты для recurring schedule на указанное количество недель
-func (s *TeacherService) generateSlotsForRecurringSchedule(ctx context.Context, schedule *model.RecurringSchedule, weeksAhead int) (int, error) {
+// Возвращает количество созданных слотов и слоты, пропущенные из-за пересечения с другими слотами учителя
+func (s *TeacherService) generateSlotsForRecurringSchedule(ctx context.Context, schedule *model.RecurringSchedule, weeksAhead int) (int, []model.SlotConflict, error) {
 	now := time.Now()
 	location := now.Location()
 	weekday := time.Weekday(schedule.Weekday)
 
 	count := 0
+	var conflicts []model.SlotConflict
 	daysToCheck := weeksAhead * 7
 
 	for i := 0; i < daysToCheck; i++ {
@@ -437,7 +784,30 @@ func (s *TeacherService) generateSlotsForRecurringSchedule(ctx context.Context,
 				continue
 			}
 
-			// Проверяем, не существует ли уже такой слот
+			// Проверяем пересечение с активными слотами учителя
+			overlapping, err := s.slotRepo.FindOverlapping(ctx, schedule.TeacherID, startTime, endTime)
+			if err != nil {
+				s.logger.Warn("Failed to check slot overlap",
+					zap.Error(err),
+					zap.Time("start_time", startTime),
+				)
+				continue
+			}
+
+			if overlapping != nil {
+				// Слот этого же расписания уже создан ранее — это не конфликт
+				if overlapping.SubjectID != schedule.SubjectID ||
+					!overlapping.StartTime.Equal(startTime) || !overlapping.EndTime.Equal(endTime) {
+					conflicts = append(conflicts, model.SlotConflict{
+						StartTime: startTime,
+						EndTime:   endTime,
+						Existing:  overlapping,
+					})
+				}
+				continue
+			}
+
+			// Проверяем, не существует ли уже такой слот (например, отменённый учителем)
 			exists, err := s.slotRepo.SlotExists(ctx, schedule.TeacherID, startTime)
 			if err != nil {
 				s.logger.Warn("Failed to check slot existence",
@@ -464,6 +834,10 @@ func (s *TeacherService) generateSlotsForRecurringSchedule(ctx context.Context,
 			}
 
 			err = s.slotRepo.Create(ctx, slot)
+			if errors.Is(err, repository.ErrSlotOverlap) {
+				conflicts = append(conflicts, model.SlotConflict{StartTime: startTime, EndTime: endTime})
+				continue
+			}
 			if err != nil {
 				s.logger.Warn("Failed to create slot",
 					zap.Error(err),
@@ -476,20 +850,28 @@ func (s *TeacherService) generateSlotsForRecurringSchedule(ctx context.Context,
 		}
 	}
 
-	return count, nil
+	return count, conflicts, nil
 }
 
 // GenerateSlotsForAllRecurringSchedules генерирует слоты для всех активных recurring schedules
-// Эта функция будет вызываться периодически (например, раз в день)
+// Эта функция будет вызываться периодически (например, раз в день).
+// О пропущенных из-за пересечений слотах учитель получает одно уведомление по всем своим расписаниям
 func (s *TeacherService) GenerateSlotsForAllRecurringSchedules(ctx context.Context, weeksAhead int) error {
 	schedules, err := s.recurringRepo.GetAllActive(ctx)
 	if err != nil {
 		return fmt.Errorf("get all active recurring schedules: %w", err)
 	}
 
+	if err := s.recurringRepo.DeleteConflictsBefore(ctx, time.Now()); err != nil {
+		s.logger.Warn("Failed to delete past recurring slot conflicts", zap.Error(err))
+	}
+
 	totalCount := 0
+	totalConflicts := 0
+	conflictsByTeacher := make(map[int64][]model.SlotConflict)
+	var teacherIDs []int64
 	for _, schedule := range schedules {
-		count, err := s.generateSlotsForRecurringSchedule(ctx, schedule, weeksAhead)
+		count, conflicts, err := s.generateSlotsForRecurringSchedule(ctx, schedule, weeksAhead)
 		if err != nil {
 			s.logger.Error("Failed to generate slots for recurring schedule",
 				zap.Error(err),
@@ -498,16 +880,209 @@ func (s *TeacherService) GenerateSlotsForAllRecurringSchedules(ctx context.Conte
 			continue
 		}
 		totalCount += count
+
+		if len(conflicts) == 0 {
+			continue
+		}
+
+		s.logger.Warn("Recurring slots skipped due to overlap",
+			zap.Int64("recurring_schedule_id", schedule.ID),
+			zap.Int64("teacher_id", schedule.TeacherID),
+			zap.Int("conflicts", len(conflicts)),
+		)
+		totalConflicts += len(conflicts)
+
+		// Генерация повторяется каждый день — учитель узнаёт только о новых пропусках
+		conflicts, err = s.newRecurringConflicts(ctx, schedule.ID, conflicts)
+		if err != nil {
+			s.logger.Warn("Failed to save recurring slot conflicts",
+				zap.Int64("recurring_schedule_id", schedule.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if len(conflicts) == 0 {
+			continue
+		}
+		if _, ok := conflictsByTeacher[schedule.TeacherID]; !ok {
+			teacherIDs = append(teacherIDs, schedule.TeacherID)
+		}
+		conflictsByTeacher[schedule.TeacherID] = append(conflictsByTeacher[schedule.TeacherID], conflicts...)
+	}
+
+	for _, teacherID := range teacherIDs {
+		conflicts := conflictsByTeacher[teacherID]
+		sort.Slice(conflicts, func(i, j int) bool {
+			return conflicts[i].StartTime.Before(conflicts[j].StartTime)
+		})
+
+		text := "🔁 <b>Регулярное расписание</b>\n\n" +
+			"Часть слотов не создана: на это время у вас уже есть другие слоты.\n\n" +
+			formatSlotConflicts(conflicts)
+		s.notifyTeacher(ctx, teacherID, text)
 	}
 
 	s.logger.Info("Generated slots for all recurring schedules",
 		zap.Int("total_schedules", len(schedules)),
 		zap.Int("total_slots_created", totalCount),
+		zap.Int("total_conflicts", totalConflicts),
+		zap.Int("teachers_notified", len(teacherIDs)),
+	)
+
+	return nil
+}
+
+// newRecurringConflicts запоминает пропуски слотов расписания и возвращает те, о которых учитель ещё не знает
+func (s *TeacherService) newRecurringConflicts(ctx context.Context, scheduleID int64, conflicts []model.SlotConflict) ([]model.SlotConflict, error) {
+	startTimes := make([]time.Time, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		startTimes = append(startTimes, conflict.StartTime)
+	}
+
+	added, err := s.recurringRepo.AddConflicts(ctx, scheduleID, startTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.SlotConflict
+	for _, conflict := range conflicts {
+		for _, startTime := range added {
+			if conflict.StartTime.Equal(startTime) {
+				result = append(result, conflict)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// NotifySlotOverlapResolutions уведомляет учителей о слотах, отменённых или оставленных пересекающимися
+// при введении запрета на пересечения. Прошедшие слоты только отмечаются без уведомления
+func (s *TeacherService) NotifySlotOverlapResolutions(ctx context.Context) error {
+	resolutions, err := s.slotRepo.GetUnnotifiedOverlapResolutions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(resolutions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	byTeacher := make(map[int64][]*model.SlotOverlapResolution)
+	var teacherIDs []int64
+	ids := make([]int64, 0, len(resolutions))
+	for _, resolution := range resolutions {
+		ids = append(ids, resolution.ID)
+		if !resolution.Slot.EndTime.After(now) {
+			continue
+		}
+		if _, ok := byTeacher[resolution.TeacherID]; !ok {
+			teacherIDs = append(teacherIDs, resolution.TeacherID)
+		}
+		byTeacher[resolution.TeacherID] = append(byTeacher[resolution.TeacherID], resolution)
+	}
+
+	for _, teacherID := range teacherIDs {
+		s.notifyTeacher(ctx, teacherID, s.formatOverlapResolutions(ctx, byTeacher[teacherID]))
+	}
+
+	if err := s.slotRepo.MarkOverlapResolutionsNotified(ctx, ids); err != nil {
+		return err
+	}
+
+	s.logger.Info("Slot overlap resolutions notified",
+		zap.Int("resolutions", len(resolutions)),
+		zap.Int("teachers", len(teacherIDs)),
 	)
 
 	return nil
 }
 
+// formatSlotTime форматирует время слота для уведомления, например 02.01.2006 15:04-16:00
+func formatSlotTime(start, end time.Time) string {
+	return fmt.Sprintf("%s-%s", start.Format("02.01.2006 15:04"), end.Format("15:04"))
+}
+
+// formatSlotConflicts перечисляет слоты, пропущенные из-за пересечений, для уведомления учителю
+func formatSlotConflicts(conflicts []model.SlotConflict) string {
+	text := fmt.Sprintf("⚠️ Пропущено из-за пересечения с другими слотами: %d\n", len(conflicts))
+	for i, conflict := range conflicts {
+		if i == maxNotifiedConflicts {
+			text += fmt.Sprintf("…и ещё %d\n", len(conflicts)-maxNotifiedConflicts)
+			break
+		}
+
+		text += "• " + formatSlotTime(conflict.StartTime, conflict.EndTime)
+		if conflict.Existing != nil {
+			text += fmt.Sprintf(" — занято %s-%s", conflict.Existing.StartTime.Format("15:04"), conflict.Existing.EndTime.Format("15:04"))
+		}
+		text += "\n"
+	}
+
+	return text
+}
+
+// formatOverlapResolutions формирует уведомление учителю о слотах, изменённых из-за пересечений
+func (s *TeacherService) formatOverlapResolutions(ctx context.Context, resolutions []*model.SlotOverlapResolution) string {
+	subjectNames := make(map[int64]string)
+	line := func(slot *model.ScheduleSlot) string {
+		name, ok := subjectNames[slot.SubjectID]
+		if !ok {
+			if subject, err := s.subjectRepo.GetByID(ctx, slot.SubjectID); err == nil && subject != nil {
+				name = subject.Name
+			}
+			subjectNames[slot.SubjectID] = name
+		}
+
+		text := "• " + formatSlotTime(slot.StartTime, slot.EndTime)
+		if name != "" {
+			text += " — " + html.EscapeString(name)
+		}
+		return text + "\n"
+	}
+
+	var canceled, exempt string
+	for _, resolution := range resolutions {
+		switch resolution.Action {
+		case model.SlotOverlapCanceled:
+			canceled += line(resolution.Slot)
+		case model.SlotOverlapExempt:
+			exempt += line(resolution.Slot)
+		}
+	}
+
+	text := "⚠️ <b>Пересекающиеся слоты</b>\n\n" +
+		"Слоты одного учителя больше не могут пересекаться по времени, даже по разным предметам.\n"
+	if canceled != "" {
+		text += "\n🚫 Отменены свободные слоты, пересекавшиеся с другими:\n" + canceled
+	}
+	if exempt != "" {
+		text += "\n❗ Эти занятия пересекаются с другими занятиями студентов. " +
+			"Они сохранены — перенесите или отмените лишние:\n" + exempt
+	}
+
+	return text
+}
+
+// notifyTeacher отправляет уведомление учителю
+func (s *TeacherService) notifyTeacher(ctx context.Context, teacherID int64, text string) {
+	if s.notifier == nil {
+		return
+	}
+
+	teacher, err := s.userRepo.GetByID(ctx, teacherID)
+	if err != nil || teacher == nil {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, teacher.TelegramID, text); err != nil {
+		s.logger.Warn("Failed to send teacher notification", zap.Int64("teacher_id", teacherID), zap.Error(err))
+	}
+}
+
 // GetRecurringSchedules возвращает все recurring schedules учителя
 func (s *TeacherService) GetRecurringSchedules(ctx context.Context, teacherID int64) ([]*model.RecurringSchedule, error) {
 	return s.recurringRepo.GetByTeacherID(ctx, teacherID)
@@ -607,9 +1182,22 @@ func (s *TeacherService) RestoreSlot(ctx context.Context, slotID int64) error {
 		return fmt.Errorf("can only restore canceled slots")
 	}
 
+	// Пока слот был отменён, на это время мог появиться другой слот
+	overlapping, err := s.slotRepo.FindOverlapping(ctx, slot.TeacherID, slot.StartTime, slot.EndTime)
+	if err != nil {
+		return fmt.Errorf("find overlapping slot: %w", err)
+	}
+
+	if overlapping != nil {
+		return repository.ErrSlotOverlap
+	}
+
 	// Восстанавливаем слот как свободный
 	err = s.slotRepo.UpdateStatus(ctx, slotID, model.SlotStatusFree)
 	if err != nil {
+		if errors.Is(err, repository.ErrSlotOverlap) {
+			return err
+		}
 		return fmt.Errorf("restore slot: %w", err)
 	}
 
@@ -662,6 +1250,9 @@ func (s *TeacherService) CancelBookingBySlot(ctx context.Context, slotID int64,
 		return fmt.Errorf("cancel slot: %w", err)
 	}
 
+	s.refundCredit(ctx, activeBooking.ID)
+	s.promos.releaseForBooking(ctx, activeBooking)
+
 	s.logger.Info("Booking canceled by teacher",
 		zap.Int64("booking_id", activeBooking.ID),
 		zap.Int64("slot_id", slotID),
@@ -671,6 +1262,85 @@ func (s *TeacherService) CancelBookingBySlot(ctx context.Context, slotID int64,
 	return nil
 }
 
+// MarkNoShow отмечает, что студент не пришёл на занятие в слоте.
+// Доступно после завершения занятия; начисление за занятие сохраняется
+func (s *TeacherService) MarkNoShow(ctx context.Context, slotID, teacherID int64) error {
+	slot, err := s.slotRepo.GetByID(ctx, slotID)
+	if err != nil {
+		return fmt.Errorf("get slot: %w", err)
+	}
+
+	if slot == nil {
+		return fmt.Errorf("slot not found")
+	}
+
+	if slot.TeacherID != teacherID {
+		return fmt.Errorf("slot does not belong to teacher")
+	}
+
+	if slot.EndTime.After(time.Now()) {
+		return fmt.Errorf("lesson has not ended")
+	}
+
+	marked, err := s.bookingRepo.MarkNoShowBySlot(ctx, slotID, teacherID)
+	if err != nil {
+		return err
+	}
+
+	if !marked {
+		return fmt.Errorf("no held booking found for this slot")
+	}
+
+	s.logger.Info("Booking marked as no-show",
+		zap.Int64("slot_id", slotID),
+		zap.Int64("teacher_id", teacherID),
+	)
+
+	return nil
+}
+
+// refundCredit возвращает студенту занятие, списанное за отменённое учителем бронирование
+func (s *TeacherService) refundCredit(ctx context.Context, bookingID int64) {
+	if _, err := s.credits.RefundForBooking(ctx, bookingID); err != nil {
+		s.logger.Error("Failed to refund credit",
+			zap.Int64("booking_id", bookingID),
+			zap.Error(err))
+	}
+}
+
+// BulkSlotAction применяет действие к нескольким слотам учителя в одной транзакции.
+// Слоты других учителей игнорируются, неподходящие по статусу — пропускаются
+func (s *TeacherService) BulkSlotAction(ctx context.Context, teacherID int64, slotIDs []int64, action model.SlotBulkAction) (*model.SlotBulkResult, error) {
+	if !action.IsValid() {
+		return nil, fmt.Errorf("unknown bulk action")
+	}
+
+	if len(slotIDs) == 0 {
+		return nil, fmt.Errorf("no slots selected")
+	}
+
+	result, err := s.slotRepo.BulkApply(ctx, teacherID, slotIDs, action)
+	if err != nil {
+		return nil, fmt.Errorf("bulk apply: %w", err)
+	}
+
+	// Занятия, отменённые учителем, возвращаются студентам в пакет
+	for _, booking := range result.CanceledBookings {
+		s.refundCredit(ctx, booking.ID)
+	}
+
+	s.logger.Info("Bulk slot action applied",
+		zap.Int64("teacher_id", teacherID),
+		zap.String("action", string(action)),
+		zap.Int("requested", len(slotIDs)),
+		zap.Int("processed", len(result.Processed)),
+		zap.Int("skipped", len(result.Skipped)),
+		zap.Int("canceled_bookings", len(result.CanceledBookings)),
+	)
+
+	return result, nil
+}
+
 // MarkSlotBusy помечает слот как занятый без привязки к студенту
 func (s *TeacherService) MarkSlotBusy(ctx context.Context, slotID, teacherID int64) error {
 	return s.MarkSlotBusyWithComment(ctx, slotID, teacherID, nil)
@@ -747,6 +1417,8 @@ func (s *TeacherService) AssignSlotToStudent(ctx context.Context, slotID, teache
 			SubjectID: slot.SubjectID,
 			SlotID:    slotID,
 			Status:    bookingStatus,
+			Price:     subject.Price,
+			Currency:  subject.Currency,
 		}
 
 		// Игнорируем ошибку создания booking - это не критично
@@ -112,3 +112,21 @@ func (s *UserService) MakeTeacher(ctx context.Context, telegramID int64) error {
 
 	return nil
 }
+
+// SetDefaultCurrency задаёт валюту, в которой учитель создаёт новые предметы
+func (s *UserService) SetDefaultCurrency(ctx context.Context, userID int64, currency model.Currency) error {
+	if !currency.IsValid() {
+		return fmt.Errorf("invalid currency")
+	}
+
+	if err := s.userRepo.UpdateDefaultCurrency(ctx, userID, currency); err != nil {
+		return err
+	}
+
+	s.logger.Info("Teacher default currency updated",
+		zap.Int64("user_id", userID),
+		zap.String("currency", string(currency)),
+	)
+
+	return nil
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Правила записи на уровне предмета (0 = ограничение не действует)
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS min_notice_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS max_advance_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS max_active_bookings INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS max_bookings_per_week INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE subjects ADD CONSTRAINT valid_booking_rules CHECK (
+    min_notice_minutes >= 0 AND
+    max_advance_days >= 0 AND
+    max_active_bookings >= 0 AND
+    max_bookings_per_week >= 0
+);
+
+COMMENT ON COLUMN subjects.min_notice_minutes IS 'Минимум минут до начала занятия, когда ещё можно записаться (0 = без ограничения)';
+COMMENT ON COLUMN subjects.max_advance_days IS 'За сколько дней вперёд открывается запись (0 = без ограничения)';
+COMMENT ON COLUMN subjects.max_active_bookings IS 'Максимум активных будущих записей студента на предмет (0 = без ограничения)';
+COMMENT ON COLUMN subjects.max_bookings_per_week IS 'Максимум записей студента на предмет в неделю (0 = без ограничения)';
+
+-- Индекс для подсчёта активных записей студента по предмету
+CREATE INDEX IF NOT EXISTS idx_bookings_student_subject_active
+ON bookings(student_id, subject_id)
+WHERE status IN ('pending', 'confirmed');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_student_subject_active;
+
+ALTER TABLE subjects DROP CONSTRAINT IF EXISTS valid_booking_rules;
+ALTER TABLE subjects DROP COLUMN IF EXISTS max_bookings_per_week;
+ALTER TABLE subjects DROP COLUMN IF EXISTS max_active_bookings;
+ALTER TABLE subjects DROP COLUMN IF EXISTS max_advance_days;
+ALTER TABLE subjects DROP COLUMN IF EXISTS min_notice_minutes;
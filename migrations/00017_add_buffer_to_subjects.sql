@@ -0,0 +1,24 @@
+-- +goose Up
+-- Перерыв учителя до и после занятия (0 = без перерыва)
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS buffer_before_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS buffer_after_minutes INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE subjects ADD CONSTRAINT valid_buffer CHECK (
+    buffer_before_minutes >= 0 AND
+    buffer_after_minutes >= 0
+);
+
+COMMENT ON COLUMN subjects.buffer_before_minutes IS 'Перерыв учителя перед занятием в минутах';
+COMMENT ON COLUMN subjects.buffer_after_minutes IS 'Перерыв учителя после занятия в минутах';
+
+-- Индекс для поиска занятых слотов учителя при проверке перерывов
+CREATE INDEX IF NOT EXISTS idx_schedule_slots_teacher_booked
+ON schedule_slots(teacher_id, start_time)
+WHERE status = 'booked';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_schedule_slots_teacher_booked;
+
+ALTER TABLE subjects DROP CONSTRAINT IF EXISTS valid_buffer;
+ALTER TABLE subjects DROP COLUMN IF EXISTS buffer_after_minutes;
+ALTER TABLE subjects DROP COLUMN IF EXISTS buffer_before_minutes;
@@ -0,0 +1,81 @@
+-- +goose Up
+-- Запрет пересекающихся по времени слотов учителя (в том числе разных предметов)
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Слоты, изменённые при добавлении ограничения; учитель получает о них уведомление
+CREATE TABLE slot_overlap_resolutions (
+    id BIGSERIAL PRIMARY KEY,
+    slot_id BIGINT NOT NULL REFERENCES schedule_slots(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resolution TEXT NOT NULL,
+    notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_resolution CHECK (resolution IN ('canceled', 'exempt'))
+);
+
+CREATE INDEX idx_slot_overlap_resolutions_pending ON slot_overlap_resolutions(teacher_id) WHERE notified_at IS NULL;
+
+COMMENT ON TABLE slot_overlap_resolutions IS 'Пересекающиеся слоты, найденные при добавлении schedule_slots_no_time_overlap';
+COMMENT ON COLUMN slot_overlap_resolutions.resolution IS 'canceled - свободный слот отменён; exempt - занятый слот оставлен и исключён из проверки';
+
+-- Занятые слоты, пересекающиеся с другими занятыми, нельзя отменить без студента:
+-- они остаются как есть и не участвуют в ограничении, пока учитель не разберётся с ними сам
+ALTER TABLE schedule_slots ADD COLUMN overlap_exempt BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN schedule_slots.overlap_exempt IS 'Занятый слот, пересекавшийся с другим занятым до появления ограничения на пересечения';
+
+WITH exempt AS (
+    UPDATE schedule_slots s
+    SET overlap_exempt = true
+    WHERE s.status = 'booked'
+      AND EXISTS (
+          SELECT 1 FROM schedule_slots o
+          WHERE o.teacher_id = s.teacher_id
+            AND o.id < s.id
+            AND o.status = 'booked'
+            AND tstzrange(o.start_time, o.end_time, '[)') && tstzrange(s.start_time, s.end_time, '[)')
+      )
+    RETURNING s.id, s.teacher_id
+)
+INSERT INTO slot_overlap_resolutions (slot_id, teacher_id, resolution)
+SELECT id, teacher_id, 'exempt' FROM exempt;
+
+-- Свободные слоты, пересекающиеся с занятыми или с более ранними свободными слотами того же учителя, отменяются
+WITH canceled AS (
+    UPDATE schedule_slots s
+    SET status = 'canceled'
+    WHERE s.status = 'free'
+      AND EXISTS (
+          SELECT 1 FROM schedule_slots o
+          WHERE o.teacher_id = s.teacher_id
+            AND o.id <> s.id
+            AND o.status <> 'canceled'
+            AND (o.status = 'booked' OR o.id < s.id)
+            AND tstzrange(o.start_time, o.end_time, '[)') && tstzrange(s.start_time, s.end_time, '[)')
+      )
+    RETURNING s.id, s.teacher_id
+)
+INSERT INTO slot_overlap_resolutions (slot_id, teacher_id, resolution)
+SELECT id, teacher_id, 'canceled' FROM canceled;
+
+ALTER TABLE schedule_slots ADD CONSTRAINT schedule_slots_no_time_overlap
+    EXCLUDE USING gist (
+        teacher_id WITH =,
+        tstzrange(start_time, end_time, '[)') WITH &&
+    )
+    WHERE (status <> 'canceled' AND NOT overlap_exempt);
+
+COMMENT ON CONSTRAINT schedule_slots_no_time_overlap ON schedule_slots IS 'Активные слоты учителя не пересекаются по времени';
+
+-- Уникальный индекс по точному времени учитывал и отменённые слоты и мешал создать слот
+-- на место отменённого; пересечения активных слотов теперь запрещает ограничение выше
+DROP INDEX IF EXISTS idx_schedule_slots_no_overlap;
+
+-- +goose Down
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedule_slots_no_overlap
+ON schedule_slots (teacher_id, start_time, end_time);
+
+ALTER TABLE schedule_slots DROP CONSTRAINT IF EXISTS schedule_slots_no_time_overlap;
+ALTER TABLE schedule_slots DROP COLUMN IF EXISTS overlap_exempt;
+DROP TABLE IF EXISTS slot_overlap_resolutions;
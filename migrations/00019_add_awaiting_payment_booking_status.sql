@@ -0,0 +1,9 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Новое значение enum нельзя использовать в той же транзакции, где оно добавлено,
+-- поэтому статус добавляется отдельной миграцией без транзакции
+ALTER TYPE booking_status ADD VALUE IF NOT EXISTS 'awaiting_payment';
+
+-- +goose Down
+-- PostgreSQL не умеет удалять значения enum: переводим такие записи в отменённые
+UPDATE bookings SET status = 'canceled' WHERE status = 'awaiting_payment';
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Предоплата занятий через Telegram Payments
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS requires_prepayment BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN subjects.requires_prepayment IS 'Запись подтверждается только после оплаты счёта';
+
+CREATE TABLE payments (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL, -- в копейках/центах
+    currency TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    payload TEXT NOT NULL UNIQUE, -- invoice_payload, по нему находим платёж
+    status TEXT NOT NULL DEFAULT 'pending', -- 'pending', 'paid', 'expired'
+    telegram_charge_id TEXT,
+    provider_charge_id TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_payment_status CHECK (status IN ('pending', 'paid', 'expired')),
+    CONSTRAINT positive_payment_amount CHECK (amount > 0)
+);
+
+CREATE INDEX idx_payments_booking ON payments(booking_id);
+CREATE INDEX idx_payments_pending_expiry ON payments(expires_at) WHERE status = 'pending';
+
+-- Активные записи теперь включают ожидающие оплаты
+DROP INDEX IF EXISTS idx_bookings_student_subject_active;
+CREATE INDEX idx_bookings_student_subject_active
+ON bookings(student_id, subject_id)
+WHERE status IN ('pending', 'confirmed', 'awaiting_payment');
+
+COMMENT ON TABLE payments IS 'Счета на оплату занятий и их статус';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_student_subject_active;
+CREATE INDEX IF NOT EXISTS idx_bookings_student_subject_active
+ON bookings(student_id, subject_id)
+WHERE status IN ('pending', 'confirmed');
+
+DROP TABLE IF EXISTS payments;
+ALTER TABLE subjects DROP COLUMN IF EXISTS requires_prepayment;
@@ -0,0 +1,79 @@
+-- +goose Up
+-- Политика отмены: за сколько часов до начала студент может отменить запись с возвратом занятия в пакет
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS free_cancel_hours INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subjects ADD CONSTRAINT valid_free_cancel_hours CHECK (free_cancel_hours >= 0);
+
+COMMENT ON COLUMN subjects.free_cancel_hours IS 'За сколько часов до начала отмена возвращает занятие в пакет (0 = в любое время)';
+
+-- Пакеты занятий, которые продаёт учитель (например, 10 занятий по цене 9)
+CREATE TABLE lesson_packages (
+    id BIGSERIAL PRIMARY KEY,
+    subject_id BIGINT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    lesson_count INTEGER NOT NULL,
+    price INTEGER NOT NULL, -- в копейках/центах, за весь пакет
+    validity_days INTEGER NOT NULL DEFAULT 0, -- 0 = бессрочно
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT positive_lesson_count CHECK (lesson_count > 0),
+    CONSTRAINT valid_package_price CHECK (price >= 0),
+    CONSTRAINT valid_validity_days CHECK (validity_days >= 0)
+);
+
+CREATE INDEX idx_lesson_packages_subject ON lesson_packages(subject_id);
+
+-- Начисления занятий студенту: пакет или ручное начисление учителем
+CREATE TABLE credit_lots (
+    id BIGSERIAL PRIMARY KEY,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subject_id BIGINT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    package_id BIGINT REFERENCES lesson_packages(id) ON DELETE SET NULL,
+    source TEXT NOT NULL, -- 'package', 'grant'
+    total INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ, -- NULL = бессрочно
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_credit_source CHECK (source IN ('package', 'grant')),
+    CONSTRAINT positive_credit_total CHECK (total > 0),
+    CONSTRAINT valid_credit_remaining CHECK (remaining >= 0 AND remaining <= total)
+);
+
+CREATE INDEX idx_credit_lots_student_subject ON credit_lots(student_id, subject_id) WHERE remaining > 0;
+CREATE INDEX idx_credit_lots_teacher ON credit_lots(teacher_id);
+
+-- Журнал движения занятий: начисления, списания за записи и возвраты
+CREATE TABLE credit_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    lot_id BIGINT NOT NULL REFERENCES credit_lots(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL, -- 'grant', 'debit', 'refund'
+    amount INTEGER NOT NULL, -- положительное — начисление, отрицательное — списание
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_credit_kind CHECK (kind IN ('grant', 'debit', 'refund')),
+    CONSTRAINT nonzero_credit_amount CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_credit_transactions_lot ON credit_transactions(lot_id);
+
+-- Одно списание и не больше одного возврата на бронирование
+CREATE UNIQUE INDEX idx_credit_transactions_booking_kind
+ON credit_transactions(booking_id, kind)
+WHERE booking_id IS NOT NULL;
+
+COMMENT ON TABLE lesson_packages IS 'Пакеты занятий по предметам';
+COMMENT ON TABLE credit_lots IS 'Начисленные студенту занятия и их остаток';
+COMMENT ON TABLE credit_transactions IS 'Журнал начислений, списаний и возвратов занятий';
+
+-- +goose Down
+DROP TABLE IF EXISTS credit_transactions;
+DROP TABLE IF EXISTS credit_lots;
+DROP TABLE IF EXISTS lesson_packages;
+
+ALTER TABLE subjects DROP CONSTRAINT IF EXISTS valid_free_cancel_hours;
+ALTER TABLE subjects DROP COLUMN IF EXISTS free_cancel_hours;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Журнал взаиморасчётов учителя и студента: начисления за занятия и полученные оплаты
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL, -- 'charge' (начисление), 'payment' (оплата)
+    amount INTEGER NOT NULL, -- в копейках
+    method TEXT, -- способ оплаты: 'cash', 'transfer'
+    description TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_ledger_kind CHECK (kind IN ('charge', 'payment')),
+    CONSTRAINT valid_ledger_method CHECK (method IS NULL OR method IN ('cash', 'transfer')),
+    CONSTRAINT positive_ledger_amount CHECK (amount > 0)
+);
+
+CREATE INDEX idx_ledger_entries_pair ON ledger_entries(teacher_id, student_id, occurred_at);
+CREATE INDEX idx_ledger_entries_teacher_occurred ON ledger_entries(teacher_id, occurred_at);
+
+-- Одно начисление на занятие
+CREATE UNIQUE INDEX idx_ledger_entries_booking_charge
+ON ledger_entries(booking_id) WHERE kind = 'charge' AND booking_id IS NOT NULL;
+
+-- Поиск подтверждённых записей, занятие по которым уже прошло
+CREATE INDEX IF NOT EXISTS idx_bookings_confirmed_slot
+ON bookings(slot_id) WHERE status = 'confirmed';
+
+COMMENT ON TABLE ledger_entries IS 'Начисления за проведённые занятия и оплаты, полученные учителем вне бота';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_confirmed_slot;
+DROP TABLE IF EXISTS ledger_entries;
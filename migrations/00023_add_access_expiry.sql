@@ -0,0 +1,18 @@
+-- +goose Up
+-- Срок действия доступа: подписка на приватного учителя ограничена по времени
+ALTER TABLE student_teacher_access ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE student_teacher_access ADD COLUMN IF NOT EXISTS expiry_warned_at TIMESTAMPTZ;
+
+ALTER TABLE student_teacher_access ADD CONSTRAINT subscription_has_expiry
+CHECK (access_type <> 'subscribed' OR expires_at IS NOT NULL);
+
+CREATE INDEX idx_access_expires_at ON student_teacher_access(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON COLUMN student_teacher_access.expires_at IS 'Когда доступ истекает; NULL - бессрочно';
+COMMENT ON COLUMN student_teacher_access.expiry_warned_at IS 'Когда студент был предупреждён об окончании доступа';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_access_expires_at;
+ALTER TABLE student_teacher_access DROP CONSTRAINT IF EXISTS subscription_has_expiry;
+ALTER TABLE student_teacher_access DROP COLUMN IF EXISTS expiry_warned_at;
+ALTER TABLE student_teacher_access DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Промокоды учителей: скидка в процентах или фиксированной суммой
+CREATE TABLE promo_codes (
+    id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    discount_type TEXT NOT NULL, -- 'percent', 'fixed'
+    discount_value INTEGER NOT NULL, -- проценты или копейки
+    subject_id BIGINT REFERENCES subjects(id) ON DELETE CASCADE, -- NULL = все предметы учителя
+    max_uses INTEGER DEFAULT NULL, -- NULL = безлимит
+    current_uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ DEFAULT NULL, -- NULL = не истекает
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_discount_type CHECK (discount_type IN ('percent', 'fixed')),
+    CONSTRAINT valid_discount_value CHECK (discount_value > 0 AND (discount_type = 'fixed' OR discount_value <= 100)),
+    CONSTRAINT promo_positive_max_uses CHECK (max_uses IS NULL OR max_uses > 0),
+    CONSTRAINT promo_valid_current_uses CHECK (current_uses >= 0)
+);
+
+CREATE INDEX idx_promo_codes_teacher ON promo_codes(teacher_id);
+
+-- Промокод, который студент применил к предмету и который сработает при следующей записи
+CREATE TABLE promo_code_applications (
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subject_id BIGINT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    promo_code_id BIGINT NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (student_id, subject_id)
+);
+
+-- Фактическая цена занятия фиксируется в бронировании
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS price INTEGER;
+UPDATE bookings b SET price = s.price FROM subjects s WHERE s.id = b.subject_id AND b.price IS NULL;
+ALTER TABLE bookings ALTER COLUMN price SET NOT NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code_id BIGINT REFERENCES promo_codes(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE promo_codes IS 'Промокоды учителей на скидку при записи';
+COMMENT ON COLUMN bookings.price IS 'Цена занятия в копейках на момент записи с учётом скидки';
+
+-- +goose Down
+ALTER TABLE bookings DROP COLUMN IF EXISTS promo_code_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS price;
+DROP TABLE IF EXISTS promo_code_applications;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Валюта по умолчанию для новых предметов учителя
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_currency TEXT NOT NULL DEFAULT 'RUB';
+ALTER TABLE users ADD CONSTRAINT valid_default_currency CHECK (default_currency IN ('RUB', 'KZT', 'EUR', 'USD'));
+
+-- Валюта цены предмета
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB';
+ALTER TABLE subjects ADD CONSTRAINT valid_subject_currency CHECK (currency IN ('RUB', 'KZT', 'EUR', 'USD'));
+
+-- Цена пакета фиксируется в валюте предмета на момент создания пакета
+ALTER TABLE lesson_packages ADD COLUMN IF NOT EXISTS currency TEXT;
+UPDATE lesson_packages p SET currency = s.currency FROM subjects s WHERE s.id = p.subject_id AND p.currency IS NULL;
+ALTER TABLE lesson_packages ALTER COLUMN currency SET NOT NULL;
+
+-- Валюта цены, зафиксированной в записи
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS currency TEXT;
+UPDATE bookings b SET currency = s.currency FROM subjects s WHERE s.id = b.subject_id AND b.currency IS NULL;
+ALTER TABLE bookings ALTER COLUMN currency SET NOT NULL;
+
+-- Взаиморасчёты ведутся отдельно по каждой валюте
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB';
+DROP INDEX IF EXISTS idx_ledger_entries_pair;
+CREATE INDEX idx_ledger_entries_pair ON ledger_entries(teacher_id, student_id, currency, occurred_at);
+
+-- Фиксированная скидка промокода задаётся в конкретной валюте
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS currency TEXT;
+UPDATE promo_codes SET currency = 'RUB' WHERE discount_type = 'fixed' AND currency IS NULL;
+ALTER TABLE promo_codes ADD CONSTRAINT fixed_promo_has_currency CHECK (discount_type = 'percent' OR currency IS NOT NULL);
+
+COMMENT ON COLUMN subjects.price IS 'Price in minor units of subjects.currency (kopecks/tiyn/cents)';
+
+-- +goose Down
+ALTER TABLE promo_codes DROP CONSTRAINT IF EXISTS fixed_promo_has_currency;
+ALTER TABLE promo_codes DROP COLUMN IF EXISTS currency;
+DROP INDEX IF EXISTS idx_ledger_entries_pair;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS currency;
+CREATE INDEX idx_ledger_entries_pair ON ledger_entries(teacher_id, student_id, occurred_at);
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
+ALTER TABLE lesson_packages DROP COLUMN IF EXISTS currency;
+ALTER TABLE subjects DROP CONSTRAINT IF EXISTS valid_subject_currency;
+ALTER TABLE subjects DROP COLUMN IF EXISTS currency;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS valid_default_currency;
+ALTER TABLE users DROP COLUMN IF EXISTS default_currency;
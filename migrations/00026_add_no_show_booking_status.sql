@@ -0,0 +1,8 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Неявка студента на проведённое занятие, отмечается учителем
+ALTER TYPE booking_status ADD VALUE IF NOT EXISTS 'no_show';
+
+-- +goose Down
+-- PostgreSQL не умеет удалять значения enum: возвращаем такие записи в завершённые
+UPDATE bookings SET status = 'completed' WHERE status = 'no_show';
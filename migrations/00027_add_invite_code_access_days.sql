@@ -0,0 +1,12 @@
+-- +goose Up
+-- Срок доступа, который выдаёт код приглашения: например, на семестр
+ALTER TABLE teacher_invite_codes ADD COLUMN IF NOT EXISTS access_days INTEGER;
+
+ALTER TABLE teacher_invite_codes ADD CONSTRAINT positive_access_days
+CHECK (access_days IS NULL OR access_days > 0);
+
+COMMENT ON COLUMN teacher_invite_codes.access_days IS 'Сколько дней действует доступ, выданный по коду; NULL - бессрочно';
+
+-- +goose Down
+ALTER TABLE teacher_invite_codes DROP CONSTRAINT IF EXISTS positive_access_days;
+ALTER TABLE teacher_invite_codes DROP COLUMN IF EXISTS access_days;
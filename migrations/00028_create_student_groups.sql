@@ -0,0 +1,34 @@
+-- +goose Up
+-- Группы студентов учителя (классы, кружки): участники, коды приглашения в группу и слоты только для группы
+CREATE TABLE student_groups (
+    id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_teacher_group_name UNIQUE (teacher_id, name)
+);
+
+CREATE INDEX idx_student_groups_teacher ON student_groups(teacher_id);
+
+CREATE TABLE student_group_members (
+    group_id BIGINT NOT NULL REFERENCES student_groups(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, student_id)
+);
+
+CREATE INDEX idx_student_group_members_student ON student_group_members(student_id);
+
+ALTER TABLE teacher_invite_codes ADD COLUMN IF NOT EXISTS group_id BIGINT REFERENCES student_groups(id) ON DELETE SET NULL;
+ALTER TABLE schedule_slots ADD COLUMN IF NOT EXISTS group_id BIGINT REFERENCES student_groups(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN teacher_invite_codes.group_id IS 'Группа, в которую попадает студент по коду; NULL - без группы';
+COMMENT ON COLUMN schedule_slots.group_id IS 'Слот доступен для записи только участникам группы; NULL - всем студентам';
+
+-- +goose Down
+ALTER TABLE schedule_slots DROP COLUMN IF EXISTS group_id;
+ALTER TABLE teacher_invite_codes DROP COLUMN IF EXISTS group_id;
+DROP TABLE IF EXISTS student_group_members;
+DROP TABLE IF EXISTS student_groups;
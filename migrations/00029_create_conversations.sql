@@ -0,0 +1,39 @@
+-- +goose Up
+-- Переписка студента и учителя через бота: сообщения пересылаются ботом, ответ - реплаем на пересланное сообщение
+CREATE TABLE conversations (
+    id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teacher_muted BOOLEAN NOT NULL DEFAULT FALSE,
+    student_muted BOOLEAN NOT NULL DEFAULT FALSE,
+    last_message_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_conversation UNIQUE (teacher_id, student_id)
+);
+
+CREATE INDEX idx_conversations_student ON conversations(student_id);
+
+CREATE TABLE conversation_messages (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    text TEXT NOT NULL,
+    relayed_chat_id BIGINT,
+    relayed_message_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_conversation_messages_conversation ON conversation_messages(conversation_id, created_at);
+CREATE UNIQUE INDEX idx_conversation_messages_relayed ON conversation_messages(relayed_chat_id, relayed_message_id)
+    WHERE relayed_message_id IS NOT NULL;
+
+COMMENT ON COLUMN conversations.teacher_muted IS 'Учитель получает сообщения переписки без звука';
+COMMENT ON COLUMN conversations.student_muted IS 'Студент получает сообщения переписки без звука';
+COMMENT ON COLUMN conversation_messages.booking_id IS 'Занятие, о котором сообщение; NULL - без привязки к занятию';
+COMMENT ON COLUMN conversation_messages.relayed_message_id IS 'Сообщение бота у получателя: ответ на него уходит в эту переписку';
+
+-- +goose Down
+DROP TABLE IF EXISTS conversation_messages;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Заметки учителя к занятию: текст, материалы (file_id Telegram) и домашнее задание со сроком
+CREATE TABLE lesson_notes (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL DEFAULT '',
+    photo_file_id TEXT,
+    document_file_id TEXT,
+    homework_due_at TIMESTAMPTZ,
+    reminder_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_lesson_notes_booking ON lesson_notes(booking_id);
+CREATE INDEX idx_lesson_notes_student ON lesson_notes(student_id, created_at);
+CREATE INDEX idx_lesson_notes_homework_due ON lesson_notes(homework_due_at)
+    WHERE homework_due_at IS NOT NULL AND reminder_sent_at IS NULL;
+
+COMMENT ON COLUMN lesson_notes.homework_due_at IS 'Срок домашнего задания; NULL - без срока';
+COMMENT ON COLUMN lesson_notes.reminder_sent_at IS 'Когда студенту напомнили о сроке домашнего задания';
+
+-- +goose Down
+DROP TABLE IF EXISTS lesson_notes;
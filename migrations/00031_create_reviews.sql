@@ -0,0 +1,27 @@
+-- +goose Up
+-- Отзывы студентов об учителях после завершённых занятий: не больше одного отзыва на запись
+CREATE TABLE reviews (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    teacher_reply TEXT NOT NULL DEFAULT '',
+    replied_at TIMESTAMPTZ,
+    reported_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_review_booking UNIQUE (booking_id)
+);
+
+CREATE INDEX idx_reviews_teacher ON reviews(teacher_id, created_at);
+
+ALTER TABLE bookings ADD COLUMN review_requested_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN reviews.reported_at IS 'Учитель пожаловался на отзыв: комментарий показывается в профиле с пометкой, оценка учитывается';
+COMMENT ON COLUMN bookings.review_requested_at IS 'Когда студенту предложили оценить завершённое занятие';
+
+-- +goose Down
+ALTER TABLE bookings DROP COLUMN IF EXISTS review_requested_at;
+DROP TABLE IF EXISTS reviews;
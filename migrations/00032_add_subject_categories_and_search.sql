@@ -0,0 +1,38 @@
+-- +goose Up
+-- Категория и свободные теги предмета, задаются учителем
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT '';
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Документ для полнотекстового поиска: название весомее тегов, описание — меньше всего.
+-- Названия категорий и имя учителя добавляются к документу при поиске: названия категорий
+-- задаются только в model.SubjectCategories, а имя учителя хранится в users.
+-- array_to_string не IMMUTABLE, поэтому документ собирается в отдельной функции для генерируемой колонки
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION subject_search_document(name TEXT, description TEXT, tags TEXT[])
+RETURNS tsvector
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+           setweight(to_tsvector('russian', coalesce(array_to_string(tags, ' '), '')), 'B') ||
+           setweight(to_tsvector('russian', coalesce(description, '')), 'C')
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (subject_search_document(name, description, tags)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_subjects_search_vector ON subjects USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_subjects_category ON subjects(category) WHERE is_active = true;
+
+COMMENT ON COLUMN subjects.category IS 'Код категории предмета (пустая строка — без категории)';
+COMMENT ON COLUMN subjects.tags IS 'Свободные теги учителя в нижнем регистре, например егэ, для детей';
+COMMENT ON COLUMN subjects.search_vector IS 'Полнотекстовый индекс по названию, тегам и описанию';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subjects_category;
+DROP INDEX IF EXISTS idx_subjects_search_vector;
+ALTER TABLE subjects DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS subject_search_document(TEXT, TEXT, TEXT[]);
+ALTER TABLE subjects DROP COLUMN IF EXISTS tags;
+ALTER TABLE subjects DROP COLUMN IF EXISTS category;
@@ -0,0 +1,67 @@
+-- +goose Up
+-- Избранные учителя и предметы студента с подпиской на появление свободных слотов
+CREATE TABLE favorites (
+    id BIGSERIAL PRIMARY KEY,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teacher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subject_id BIGINT REFERENCES subjects(id) ON DELETE CASCADE,
+    alerts_enabled BOOLEAN NOT NULL DEFAULT true,
+    weekdays INTEGER NOT NULL DEFAULT 0,
+    day_periods INTEGER NOT NULL DEFAULT 0,
+    alerts_checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Учителя целиком и отдельный предмет можно добавить в избранное по одному разу
+CREATE UNIQUE INDEX idx_favorites_teacher ON favorites(student_id, teacher_id) WHERE subject_id IS NULL;
+CREATE UNIQUE INDEX idx_favorites_subject ON favorites(student_id, subject_id) WHERE subject_id IS NOT NULL;
+CREATE INDEX idx_favorites_alerts ON favorites(student_id) WHERE alerts_enabled = true;
+
+COMMENT ON COLUMN favorites.subject_id IS 'Избранный предмет; NULL - все предметы учителя';
+COMMENT ON COLUMN favorites.weekdays IS 'Удобные дни недели для уведомлений: бит i - день time.Weekday(i); 0 - любой день';
+COMMENT ON COLUMN favorites.day_periods IS 'Удобные части дня для уведомлений: бит i - model.DayPeriods[i]; 0 - любое время';
+COMMENT ON COLUMN favorites.alerts_checked_at IS 'Слоты, освободившиеся до этого момента, уже учтены в уведомлениях';
+
+-- Журнал уведомлений о свободных слотах для дневного лимита
+CREATE TABLE slot_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    slot_count INTEGER NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_slot_alerts_student ON slot_alerts(student_id, sent_at);
+
+-- Когда слот стал свободным: при создании или при освобождении после отмены записи
+ALTER TABLE schedule_slots ADD COLUMN IF NOT EXISTS freed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION schedule_slots_set_freed_at()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF NEW.status = 'free' AND OLD.status <> 'free' THEN
+        NEW.freed_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER schedule_slots_freed_at
+BEFORE UPDATE OF status ON schedule_slots
+FOR EACH ROW
+EXECUTE FUNCTION schedule_slots_set_freed_at();
+
+CREATE INDEX idx_schedule_slots_freed ON schedule_slots(freed_at) WHERE status = 'free';
+
+COMMENT ON COLUMN schedule_slots.freed_at IS 'Когда слот стал свободным (создан или освобождён); по нему рассылаются уведомления избранного';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_schedule_slots_freed;
+DROP TRIGGER IF EXISTS schedule_slots_freed_at ON schedule_slots;
+DROP FUNCTION IF EXISTS schedule_slots_set_freed_at();
+ALTER TABLE schedule_slots DROP COLUMN IF EXISTS freed_at;
+DROP TABLE IF EXISTS slot_alerts;
+DROP TABLE IF EXISTS favorites;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Слоты регулярного расписания, пропущенные при автоматической генерации из-за пересечения
+-- с другими слотами учителя. Учитель получает уведомление о каждом пропуске один раз
+CREATE TABLE recurring_slot_conflicts (
+    recurring_schedule_id BIGINT NOT NULL REFERENCES recurring_schedules(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (recurring_schedule_id, start_time)
+);
+
+CREATE INDEX idx_recurring_slot_conflicts_start ON recurring_slot_conflicts(start_time);
+
+-- +goose Down
+DROP TABLE IF EXISTS recurring_slot_conflicts;